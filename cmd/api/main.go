@@ -89,6 +89,22 @@ func main() {
 	transitionRepo := postgres.NewOrderStateTransitionRepository(db.DB)
 	circuitBreakerRepo := postgres.NewCircuitBreakerRepository(db.DB)
 	jobRepo := postgres.NewBackgroundJobRepository(db.DB)
+	settingsRepo := postgres.NewSettingsRepository(db.DB)
+	approvalRuleRepo := postgres.NewApprovalRuleRepository(db.DB)
+	disputeRepo := postgres.NewPaymentDisputeRepository(db.DB)
+	reconRepo := postgres.NewReconciliationRepository(db.DB)
+	webhookSubRepo := postgres.NewWebhookSubscriptionRepository(db.DB)
+	webhookDeliveryRepo := postgres.NewWebhookDeliveryRepository(db.DB)
+	customerRepo := postgres.NewCustomerRepository(db.DB)
+	licenseRepo := postgres.NewLicenseRepository(db.DB)
+	uploadSessionRepo := postgres.NewUploadSessionRepository(db.DB)
+	reviewRepo := postgres.NewTemplateReviewRepository(db.DB)
+	bundleRepo := postgres.NewBundleRepository(db.DB)
+	priceRuleRepo := postgres.NewPriceRuleRepository(db.DB)
+	campaignRepo := postgres.NewNewsletterCampaignRepository(db.DB)
+
+	// Redis-backed stores
+	orderLookupStore := redis.NewOrderLookupStore(redisClient)
 
 	// Blog System (EXISTING)
 	blogPostRepo := postgres.NewBlogPostRepository(db)
@@ -105,20 +121,29 @@ func main() {
 	// INITIALIZE SERVICES
 	// ========================================================================
 
+	// Settings (read by most other services)
+	settingsService := service.NewSettingsService(settingsRepo, activityLogRepo)
+
 	// Storage & Files
 	storageService, err := service.NewStorageService(cfg)
 	if err != nil {
 		logger.Fatal("Failed to initialize storage service", zap.Error(err))
 	}
 
+	// Responsive renditions of uploaded gallery and blog images
+	imageService := service.NewImageService(storageService, cfg)
+
 	// PDF Service
 	pdfService := service.NewPDFService(storageService)
 	// Email Service
-	emailService := service.NewEmailService(cfg)
+	emailService := service.NewEmailService(cfg, settingsService)
 
 	// Payment Service (NEW - with circuit breaker)
 	paymentService := service.NewPaymentService(cfg, webhookRepo, circuitBreakerRepo)
 
+	// Outbound webhooks (published by order, newsletter and contact services)
+	outboundWebhookService := service.NewOutboundWebhookService(webhookSubRepo, webhookDeliveryRepo, jobRepo, activityLogRepo)
+
 	// Auth Service
 	authService, err := service.NewAuthService(adminRepo, sessionRepo, activityLogRepo, cfg)
 	if err != nil {
@@ -130,12 +155,35 @@ func main() {
 
 	// Marketplace Services (NEW)
 	categoryService := service.NewCategoryService(categoryRepo, activityLogRepo)
-	templateService := service.NewTemplateService(templateRepo, categoryRepo, activityLogRepo)
+	templateService := service.NewTemplateService(templateRepo, categoryRepo, activityLogRepo, imageService, priceRuleRepo, jobRepo)
+	templateVersionService := service.NewTemplateVersionService(templateRepo, orderRepo, jobRepo, activityLogRepo, storageService, emailService, settingsService)
+	chunkedUploadService, err := service.NewChunkedUploadService(uploadSessionRepo, templateVersionService, storageService, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize chunked upload service", zap.Error(err))
+	}
+
+	templatePreviewService := service.NewTemplatePreviewService(templateRepo, jobRepo, storageService, imageService, cfg)
+
+	recommendationService := service.NewRecommendationService(templateRepo)
+	priceRuleService := service.NewPriceRuleService(priceRuleRepo, templateRepo, categoryRepo, jobRepo, activityLogRepo)
+	approvalRuleService := service.NewApprovalRuleService(approvalRuleRepo, orderRepo, settingsService, activityLogRepo)
+	riskService := service.NewRiskService(orderRepo, paymentRepo, settingsService)
+	// License keys (issued on approval, revoked on refund or lost dispute)
+	licenseService, err := service.NewLicenseService(licenseRepo, orderRepo, orderItemRepo, templateRepo, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize license service", zap.Error(err))
+	}
+
+	reviewService := service.NewReviewService(reviewRepo, orderItemRepo, orderRepo, templateRepo, activityLogRepo)
+	bundleService := service.NewBundleService(bundleRepo, templateRepo, activityLogRepo, imageService)
+	disputeService := service.NewDisputeService(disputeRepo, paymentRepo, orderRepo, downloadTokenRepo, licenseService, jobRepo, activityLogRepo, outboundWebhookService)
+	reconService := service.NewReconciliationService(reconRepo, paymentRepo, paymentService, activityLogRepo)
 
 	orderService := service.NewOrderService(
 		orderRepo,
 		orderItemRepo,
 		templateRepo,
+		bundleRepo,
 		paymentRepo,
 		idempotencyRepo,
 		transitionRepo,
@@ -143,8 +191,14 @@ func main() {
 		paymentService,
 		emailService,
 		jobRepo,
+		settingsService,
+		approvalRuleService,
+		riskService,
+		outboundWebhookService,
 	)
 
+	webhookService := service.NewWebhookService(webhookRepo, orderService, disputeService, activityLogRepo)
+
 	downloadTokenService := service.NewDownloadTokenService(
 		downloadTokenRepo,
 		downloadRepo,
@@ -152,16 +206,47 @@ func main() {
 		orderItemRepo,
 		templateRepo,
 		storageService,
+		settingsService,
 	)
 
+	// Customer accounts (magic-link login)
+	customerService, err := service.NewCustomerService(
+		customerRepo,
+		orderRepo,
+		orderItemRepo,
+		downloadTokenService,
+		emailService,
+		pdfService,
+		cfg,
+	)
+	if err != nil {
+		logger.Fatal("Failed to initialize customer service", zap.Error(err))
+	}
+
+	// Guest order lookup (emailed one-time codes)
+	orderLookupService, err := service.NewOrderLookupService(
+		orderLookupStore,
+		orderRepo,
+		jobRepo,
+		downloadTokenService,
+		emailService,
+		cfg,
+	)
+	if err != nil {
+		logger.Fatal("Failed to initialize order lookup service", zap.Error(err))
+	}
+
 	// Blog Services (EXISTING)
 	blogAuthorService := service.NewBlogAuthorService(blogAuthorRepo, activityLogRepo)
 	blogCategoryService := service.NewBlogCategoryService(blogCategoryRepo, activityLogRepo)
-	blogPostService := service.NewBlogPostService(blogPostRepo, blogAuthorRepo, blogCategoryRepo, activityLogRepo)
+	blogPostService := service.NewBlogPostService(blogPostRepo, blogAuthorRepo, blogCategoryRepo, activityLogRepo, imageService, jobRepo)
 
 	// Newsletter & Contact Services (EXISTING)
-	newsletterService := service.NewNewsletterService(newsletterRepo, emailService)
-	contactService := service.NewContactService(contactRepo, activityLogRepo, emailService)
+	newsletterService := service.NewNewsletterService(newsletterRepo, emailService, outboundWebhookService)
+	contactService := service.NewContactService(contactRepo, activityLogRepo, emailService, outboundWebhookService)
+
+	// Scheduled templates and blog posts
+	publishingService := service.NewPublishingService(templateRepo, blogPostRepo, campaignRepo, jobRepo, emailService, outboundWebhookService, cfg)
 
 	// Dashboard Service (EXISTING)
 	dashboardService := service.NewDashboardService(db.Pool)
@@ -182,14 +267,26 @@ func main() {
 		idempotencyRepo,
 		emailService,
 		downloadTokenService,
+		licenseService,
+		templateVersionService,
+		chunkedUploadService,
+		templatePreviewService,
+		recommendationService,
+		priceRuleService,
 		paymentService,
 		pdfService,
 		storageService,
+		reconService,
+		outboundWebhookService,
+		publishingService,
 		"worker-api-1",
 	)
 
 	scheduledRunner := worker.NewScheduledJobRunner(jobRepo)
 
+	// Webhook retries need the order service, so they only run in the API process
+	webhookSweeper := worker.NewWebhookSweeper(webhookService)
+
 	// Start workers in background (optional - can run cmd/worker/main.go separately)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -206,6 +303,12 @@ func main() {
 		}
 	}()
 
+	go func() {
+		if err := webhookSweeper.Start(ctx); err != nil {
+			logger.Error("Webhook sweeper error", zap.Error(err))
+		}
+	}()
+
 	logger.Info("✅ Background workers started")
 
 	// ========================================================================
@@ -214,29 +317,45 @@ func main() {
 
 	// Public Handlers
 	publicHandlersStruct := &routes.PublicHandlers{
-		Template:   publicHandlers.NewTemplateHandler(templateService, categoryService),
-		Order:      publicHandlers.NewOrderHandler(orderService),
-		Checkout:   publicHandlers.NewCheckoutHandler(orderService, paymentService),
-		Download:   publicHandlers.NewDownloadHandler(downloadTokenService),
+		Template:   publicHandlers.NewTemplateHandler(templateService, categoryService, recommendationService),
+		Order:      publicHandlers.NewOrderHandler(orderService, orderLookupService),
+		Checkout:   publicHandlers.NewCheckoutHandler(orderService, paymentService, webhookService),
+		Download:   publicHandlers.NewDownloadHandler(downloadTokenService, orderLookupService),
 		Blog:       publicHandlers.NewBlogHandler(blogPostService, blogAuthorService, blogCategoryService),
 		Newsletter: publicHandlers.NewNewsletterHandler(newsletterService),
 		Contact:    publicHandlers.NewContactHandler(contactService),
-		Utility:    publicHandlers.NewUtilityHandler(db, redisClient),
+		Utility:    publicHandlers.NewUtilityHandler(db, redisClient, storageService),
+		File:       publicHandlers.NewFileHandler(storageService),
+		Customer:   publicHandlers.NewCustomerHandler(customerService, cfg),
+		License:    publicHandlers.NewLicenseHandler(licenseService),
+		Review:     publicHandlers.NewReviewHandler(reviewService),
+		Bundle:     publicHandlers.NewBundleHandler(bundleService),
 	}
 
 	// Admin Handlers
 	adminHandlersStruct := &routes.AdminHandlers{
-		Auth:         adminHandlers.NewAuthHandler(authService),
-		Dashboard:    adminHandlers.NewDashboardHandler(dashboardService),
-		Order:        adminHandlers.NewOrderHandler(orderService),
-		Template:     adminHandlers.NewTemplateHandler(templateService, storageService),
-		Category:     adminHandlers.NewCategoryHandler(categoryService),
-		BlogPost:     adminHandlers.NewBlogPostHandler(blogPostService),
-		BlogAuthor:   adminHandlers.NewBlogAuthorHandler(blogAuthorService),
-		BlogCategory: adminHandlers.NewBlogCategoryHandler(blogCategoryService),
-		Newsletter:   adminHandlers.NewNewsletterHandler(newsletterService),
-		Contact:      adminHandlers.NewContactHandler(contactService),
-		AdminUser:    adminHandlers.NewAdminUserHandler(adminService),
+		Auth:            adminHandlers.NewAuthHandler(authService),
+		Dashboard:       adminHandlers.NewDashboardHandler(dashboardService),
+		Order:           adminHandlers.NewOrderHandler(orderService, licenseService),
+		Template:        adminHandlers.NewTemplateHandler(templateService, templateVersionService, templatePreviewService),
+		TemplateVersion: adminHandlers.NewTemplateVersionHandler(templateVersionService),
+		Upload:          adminHandlers.NewUploadHandler(chunkedUploadService),
+		Category:        adminHandlers.NewCategoryHandler(categoryService),
+		BlogPost:        adminHandlers.NewBlogPostHandler(blogPostService),
+		BlogAuthor:      adminHandlers.NewBlogAuthorHandler(blogAuthorService),
+		BlogCategory:    adminHandlers.NewBlogCategoryHandler(blogCategoryService),
+		Newsletter:      adminHandlers.NewNewsletterHandler(newsletterService),
+		Contact:         adminHandlers.NewContactHandler(contactService),
+		AdminUser:       adminHandlers.NewAdminUserHandler(adminService),
+		Settings:        adminHandlers.NewSettingsHandler(settingsService),
+		ApprovalRule:    adminHandlers.NewApprovalRuleHandler(approvalRuleService),
+		Dispute:         adminHandlers.NewDisputeHandler(disputeService),
+		Reconciliation:  adminHandlers.NewReconciliationHandler(reconService),
+		Webhook:         adminHandlers.NewWebhookHandler(webhookService),
+		OutboundWebhook: adminHandlers.NewOutboundWebhookHandler(outboundWebhookService),
+		Review:          adminHandlers.NewReviewHandler(reviewService),
+		Bundle:          adminHandlers.NewBundleHandler(bundleService),
+		PriceRule:       adminHandlers.NewPriceRuleHandler(priceRuleService),
 	}
 
 	logger.Info("✅ Handlers initialized")
@@ -249,7 +368,7 @@ func main() {
 		AppName:               "Merraki API v1.0.0",
 		ServerHeader:          "Merraki",
 		ErrorHandler:          customErrorHandler(cfg),
-		BodyLimit:             10 * 1024 * 1024, // 10MB
+		BodyLimit:             (cfg.Upload.MaxTemplateMB + 1) * 1024 * 1024, // largest upload plus form fields
 		ReadTimeout:           30 * time.Second,
 		WriteTimeout:          30 * time.Second,
 		IdleTimeout:           120 * time.Second,
@@ -286,7 +405,10 @@ func main() {
 	api := app.Group("/api/v1")
 
 	// Setup Public Routes
-	routes.SetupPublicRoutes(api, publicHandlersStruct)
+	routes.SetupPublicRoutes(api, publicHandlersStruct, cfg)
+
+	// Setup Customer Account Routes
+	routes.SetupCustomerRoutes(api, publicHandlersStruct.Customer, publicHandlersStruct.Review, cfg)
 
 	// Setup Admin Routes
 	routes.SetupAdminRoutes(api, adminHandlersStruct, cfg)
@@ -336,6 +458,7 @@ func main() {
 	cancel()
 	jobProcessor.Stop()
 	scheduledRunner.Stop()
+	webhookSweeper.Stop()
 
 	// Shutdown server
 	if err := app.ShutdownWithTimeout(30 * time.Second); err != nil {
@@ -65,6 +65,15 @@ func main() {
 	templateRepo := postgres.NewTemplateRepository(db.DB)
 	circuitBreakerRepo := postgres.NewCircuitBreakerRepository(db.DB)
 	jobRepo := postgres.NewBackgroundJobRepository(db.DB)
+	settingsRepo := postgres.NewSettingsRepository(db.DB)
+	reconRepo := postgres.NewReconciliationRepository(db.DB)
+	webhookSubRepo := postgres.NewWebhookSubscriptionRepository(db.DB)
+	webhookDeliveryRepo := postgres.NewWebhookDeliveryRepository(db.DB)
+	licenseRepo := postgres.NewLicenseRepository(db.DB)
+	uploadSessionRepo := postgres.NewUploadSessionRepository(db.DB)
+	priceRuleRepo := postgres.NewPriceRuleRepository(db.DB)
+	campaignRepo := postgres.NewNewsletterCampaignRepository(db.DB)
+	blogPostRepo := postgres.NewBlogPostRepository(db)
 
 	logger.Info("✅ Repositories initialized")
 
@@ -72,6 +81,9 @@ func main() {
 	// INITIALIZE SERVICES
 	// ========================================================================
 	
+	// Settings
+	settingsService := service.NewSettingsService(settingsRepo, nil)
+
 	// Storage
 	storageService, err := service.NewStorageService(cfg)
 	if err != nil {
@@ -79,7 +91,7 @@ func main() {
 	}
 
 	// Email
-	emailService := service.NewEmailService(cfg)
+	emailService := service.NewEmailService(cfg, settingsService)
 
 	// PDF
 	pdfService := service.NewPDFService(storageService)
@@ -95,8 +107,43 @@ func main() {
 		orderItemRepo,
 		templateRepo,
 		storageService,
+		settingsService,
 	)
 
+	// Licenses
+	licenseService, err := service.NewLicenseService(licenseRepo, orderRepo, orderItemRepo, templateRepo, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize license service", zap.Error(err))
+	}
+
+	// Template version update emails
+	templateVersionService := service.NewTemplateVersionService(templateRepo, orderRepo, jobRepo, nil, storageService, emailService, settingsService)
+
+	// Abandoned chunked uploads; needs the API's UPLOAD_STAGING_DIR
+	chunkedUploadService, err := service.NewChunkedUploadService(uploadSessionRepo, templateVersionService, storageService, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize chunked upload service", zap.Error(err))
+	}
+
+	// Watermarked previews of uploaded template files
+	imageService := service.NewImageService(storageService, cfg)
+	templatePreviewService := service.NewTemplatePreviewService(templateRepo, jobRepo, storageService, imageService, cfg)
+
+	// Nightly related templates
+	recommendationService := service.NewRecommendationService(templateRepo)
+
+	// Scheduled sales
+	priceRuleService := service.NewPriceRuleService(priceRuleRepo, templateRepo, nil, jobRepo, nil)
+
+	// Reconciliation
+	reconService := service.NewReconciliationService(reconRepo, paymentRepo, paymentService, nil)
+
+	// Outbound webhooks
+	outboundWebhookService := service.NewOutboundWebhookService(webhookSubRepo, webhookDeliveryRepo, jobRepo, nil)
+
+	// Scheduled templates and blog posts
+	publishingService := service.NewPublishingService(templateRepo, blogPostRepo, campaignRepo, jobRepo, emailService, outboundWebhookService, cfg)
+
 	logger.Info("✅ Services initialized")
 
 	// ========================================================================
@@ -114,9 +161,18 @@ func main() {
 		idempotencyRepo,
 		emailService,
 		downloadTokenService,
+		licenseService,
+		templateVersionService,
+		chunkedUploadService,
+		templatePreviewService,
+		recommendationService,
+		priceRuleService,
 		paymentService,
 		pdfService,
 		storageService,
+		reconService,
+		outboundWebhookService,
+		publishingService,
 		"worker-standalone-1",
 	)
 
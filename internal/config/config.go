@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -12,7 +14,11 @@ type Config struct {
 	Database DatabaseConfig
 	Redis    RedisConfig
 	Auth     AuthConfig
+	License  LicenseConfig
 	Storage  StorageConfig
+	Upload   UploadConfig
+	Image    ImageConfig
+	Preview  PreviewConfig
 	Payment  PaymentConfig
 	Email    EmailConfig
 	Frontend FrontendConfig
@@ -50,19 +56,83 @@ type RedisConfig struct {
 }
 
 type AuthConfig struct {
-	PasetoKey           string
-	AccessTokenExpires  time.Duration
-	RefreshTokenExpires time.Duration
-	CookieSecure        bool
-	CookieSameSite      string
+	PasetoKey            string
+	AccessTokenExpires   time.Duration
+	RefreshTokenExpires  time.Duration
+	CustomerTokenExpires time.Duration
+	MagicLinkExpires     time.Duration
+	LookupCodeExpires    time.Duration
+	LookupSessionExpires time.Duration
+	CookieSecure         bool
+	CookieSameSite       string
 }
 
+// LicenseConfig holds the Ed25519 key used to sign license keys. SigningKey
+// is the base64-encoded 32-byte seed.
+type LicenseConfig struct {
+	SigningKey string
+}
+
+// StorageConfig selects the file storage backend. Provider is one of
+// "cloudinary" (default), "local" or "s3" (also used for MinIO).
 type StorageConfig struct {
 	Provider         string
 	CloudinaryName   string
 	CloudinaryKey    string
 	CloudinarySecret string
 	CloudinaryFolder string
+
+	// Local disk; BaseURL is where the API serves /public/files
+	LocalPath    string
+	LocalBaseURL string
+	SigningKey   string
+
+	S3Endpoint     string
+	S3Region       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3Bucket       string
+	S3PublicBucket string
+	S3PublicURL    string
+	S3UseSSL       bool
+	S3PathStyle    bool
+}
+
+// UploadConfig limits and scans admin uploads. Scanner is "none" (default)
+// or "clamav"; ClamAVAddress is "unix:///path/to/clamd.sock" or
+// "tcp://host:port".
+type UploadConfig struct {
+	MaxTemplateMB int
+	MaxImageMB    int
+	Scanner       string
+	ClamAVAddress string
+	ScanTimeout   time.Duration
+
+	// Chunked uploads are assembled in StagingDir and abandoned after
+	// SessionTTL without a new chunk. Consecutive chunks may reach different
+	// API replicas, so StagingDir must be one volume shared by every API
+	// replica and the worker. StagingShared declares that it is; production
+	// refuses to start without it.
+	StagingDir    string
+	StagingShared bool
+	SessionTTL    time.Duration
+	MaxChunkMB    int
+}
+
+// ImageConfig controls the renditions made from uploaded gallery and blog
+// images. Widths wider than the original are skipped.
+type ImageConfig struct {
+	RenditionWidths []int
+	JPEGQuality     int
+	MaxMegapixels   int
+}
+
+// PreviewConfig controls the watermarked preview images generated from
+// uploaded PDF and spreadsheet templates. PDFs need poppler's pdftoppm.
+type PreviewConfig struct {
+	MaxPages int
+	Width    int
+	PDFToPPM string
 }
 
 type PaymentConfig struct {
@@ -117,6 +187,30 @@ type LoggingConfig struct {
 func Load() (*Config, error) {
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv()
+	viper.SetDefault("RAZORPAY_BASE_URL", "https://api.razorpay.com/v1")
+	viper.SetDefault("CUSTOMER_TOKEN_EXPIRES", "168h")
+	viper.SetDefault("MAGIC_LINK_EXPIRES", "15m")
+	viper.SetDefault("ORDER_LOOKUP_CODE_EXPIRES", "10m")
+	viper.SetDefault("ORDER_LOOKUP_SESSION_EXPIRES", "30m")
+	viper.SetDefault("STORAGE_PROVIDER", "cloudinary")
+	viper.SetDefault("STORAGE_LOCAL_PATH", "./storage")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_USE_SSL", true)
+	viper.SetDefault("UPLOAD_MAX_TEMPLATE_MB", 50)
+	viper.SetDefault("UPLOAD_MAX_IMAGE_MB", 5)
+	viper.SetDefault("UPLOAD_SCANNER", "none")
+	viper.SetDefault("CLAMAV_ADDRESS", "tcp://localhost:3310")
+	viper.SetDefault("UPLOAD_SCAN_TIMEOUT", "2m")
+	viper.SetDefault("UPLOAD_STAGING_DIR", "./storage/staging")
+	viper.SetDefault("UPLOAD_STAGING_SHARED", false)
+	viper.SetDefault("UPLOAD_SESSION_TTL", "24h")
+	viper.SetDefault("UPLOAD_MAX_CHUNK_MB", 8)
+	viper.SetDefault("IMAGE_RENDITION_WIDTHS", "320,640,960,1280,1920")
+	viper.SetDefault("IMAGE_JPEG_QUALITY", 82)
+	viper.SetDefault("IMAGE_MAX_MEGAPIXELS", 25)
+	viper.SetDefault("PREVIEW_MAX_PAGES", 3)
+	viper.SetDefault("PREVIEW_WIDTH", 1200)
+	viper.SetDefault("PREVIEW_PDFTOPPM_PATH", "pdftoppm")
 	if err := viper.ReadInConfig(); err != nil {
 
 	}
@@ -147,11 +241,18 @@ func Load() (*Config, error) {
 			MaxActive: viper.GetInt("REDIS_MAX_ACTIVE"),
 		},
 		Auth: AuthConfig{
-			PasetoKey:           viper.GetString("PASETO_SYMMETRIC_KEY"),
-			AccessTokenExpires:  viper.GetDuration("ACCESS_TOKEN_EXPIRES"),
-			RefreshTokenExpires: viper.GetDuration("REFRESH_TOKEN_EXPIRES"),
-			CookieSecure:        viper.GetBool("COOKIE_SECURE"),
-			CookieSameSite:      viper.GetString("COOKIE_SAME_SITE"),
+			PasetoKey:            viper.GetString("PASETO_SYMMETRIC_KEY"),
+			AccessTokenExpires:   viper.GetDuration("ACCESS_TOKEN_EXPIRES"),
+			RefreshTokenExpires:  viper.GetDuration("REFRESH_TOKEN_EXPIRES"),
+			CustomerTokenExpires: viper.GetDuration("CUSTOMER_TOKEN_EXPIRES"),
+			MagicLinkExpires:     viper.GetDuration("MAGIC_LINK_EXPIRES"),
+			LookupCodeExpires:    viper.GetDuration("ORDER_LOOKUP_CODE_EXPIRES"),
+			LookupSessionExpires: viper.GetDuration("ORDER_LOOKUP_SESSION_EXPIRES"),
+			CookieSecure:         viper.GetBool("COOKIE_SECURE"),
+			CookieSameSite:       viper.GetString("COOKIE_SAME_SITE"),
+		},
+		License: LicenseConfig{
+			SigningKey: viper.GetString("LICENSE_SIGNING_KEY"),
 		},
 		Storage: StorageConfig{
 			Provider:         viper.GetString("STORAGE_PROVIDER"),
@@ -159,6 +260,39 @@ func Load() (*Config, error) {
 			CloudinaryKey:    viper.GetString("CLOUDINARY_API_KEY"),
 			CloudinarySecret: viper.GetString("CLOUDINARY_API_SECRET"),
 			CloudinaryFolder: viper.GetString("CLOUDINARY_FOLDER"),
+			LocalPath:        viper.GetString("STORAGE_LOCAL_PATH"),
+			LocalBaseURL:     viper.GetString("STORAGE_LOCAL_BASE_URL"),
+			SigningKey:       viper.GetString("STORAGE_SIGNING_KEY"),
+			S3Endpoint:       viper.GetString("S3_ENDPOINT"),
+			S3Region:         viper.GetString("S3_REGION"),
+			S3AccessKey:      viper.GetString("S3_ACCESS_KEY"),
+			S3SecretKey:      viper.GetString("S3_SECRET_KEY"),
+			S3Bucket:         viper.GetString("S3_BUCKET"),
+			S3PublicBucket:   viper.GetString("S3_PUBLIC_BUCKET"),
+			S3PublicURL:      viper.GetString("S3_PUBLIC_URL"),
+			S3UseSSL:         viper.GetBool("S3_USE_SSL"),
+			S3PathStyle:      viper.GetBool("S3_PATH_STYLE"),
+		},
+		Upload: UploadConfig{
+			MaxTemplateMB: viper.GetInt("UPLOAD_MAX_TEMPLATE_MB"),
+			MaxImageMB:    viper.GetInt("UPLOAD_MAX_IMAGE_MB"),
+			Scanner:       viper.GetString("UPLOAD_SCANNER"),
+			ClamAVAddress: viper.GetString("CLAMAV_ADDRESS"),
+			ScanTimeout:   viper.GetDuration("UPLOAD_SCAN_TIMEOUT"),
+			StagingDir:    viper.GetString("UPLOAD_STAGING_DIR"),
+			StagingShared: viper.GetBool("UPLOAD_STAGING_SHARED"),
+			SessionTTL:    viper.GetDuration("UPLOAD_SESSION_TTL"),
+			MaxChunkMB:    viper.GetInt("UPLOAD_MAX_CHUNK_MB"),
+		},
+		Image: ImageConfig{
+			RenditionWidths: intList(viper.GetString("IMAGE_RENDITION_WIDTHS")),
+			JPEGQuality:     viper.GetInt("IMAGE_JPEG_QUALITY"),
+			MaxMegapixels:   viper.GetInt("IMAGE_MAX_MEGAPIXELS"),
+		},
+		Preview: PreviewConfig{
+			MaxPages: viper.GetInt("PREVIEW_MAX_PAGES"),
+			Width:    viper.GetInt("PREVIEW_WIDTH"),
+			PDFToPPM: viper.GetString("PREVIEW_PDFTOPPM_PATH"),
 		},
 		Payment: PaymentConfig{
 			RazorpayKeyID:         viper.GetString("RAZORPAY_KEY_ID"),
@@ -168,7 +302,7 @@ func Load() (*Config, error) {
 				KeyID:         viper.GetString("RAZORPAY_KEY_ID"),
 				KeySecret:     viper.GetString("RAZORPAY_KEY_SECRET"),
 				WebhookSecret: viper.GetString("RAZORPAY_WEBHOOK_SECRET"),
-				BaseURL:       "https://api.razorpay.com/v1",
+				BaseURL:       viper.GetString("RAZORPAY_BASE_URL"),
 			},
 		},
 		Email: EmailConfig{
@@ -229,3 +363,15 @@ func (c *Config) IsDevelopment() bool {
 func (c *Config) IsProduction() bool {
 	return c.Server.Environment == "production"
 }
+
+// intList parses a comma-separated list of integers, skipping anything
+// that isn't one.
+func intList(s string) []int {
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -81,6 +81,42 @@ func (j *JSONMap) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// JSONValue for JSONB fields that may hold any JSON value (scalar, object or array)
+type JSONValue json.RawMessage
+
+func (v JSONValue) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return []byte(v), nil
+}
+
+func (v *JSONValue) Scan(value interface{}) error {
+	switch src := value.(type) {
+	case nil:
+		*v = nil
+	case []byte:
+		*v = append((*v)[:0], src...)
+	case string:
+		*v = JSONValue(src)
+	default:
+		return fmt.Errorf("failed to scan JSONValue: unexpected type %T", value)
+	}
+	return nil
+}
+
+func (v JSONValue) MarshalJSON() ([]byte, error) {
+	if len(v) == 0 {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+func (v *JSONValue) UnmarshalJSON(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
+
 // StringArray for PostgreSQL arrays
 type StringArray []string
 
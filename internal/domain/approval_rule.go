@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// ============================================================================
+// APPROVAL RULES
+// ============================================================================
+
+type ApprovalRuleType string
+
+const (
+	ApprovalRuleMaxTotal          ApprovalRuleType = "max_total"
+	ApprovalRuleReturningCustomer ApprovalRuleType = "returning_customer"
+	ApprovalRuleCountryAllowlist  ApprovalRuleType = "country_allowlist"
+	ApprovalRuleNoRiskFlags       ApprovalRuleType = "no_risk_flags"
+)
+
+// ApprovalRule is a single condition a paid order must satisfy to skip
+// manual review. Params depend on RuleType:
+//
+//	max_total:          {"max_total_usd_cents": 5000}
+//	returning_customer: {"min_approved_orders": 1}
+//	country_allowlist:  {"countries": ["US", "GB"]}
+//	no_risk_flags:      {} or {"max_score": 40}
+type ApprovalRule struct {
+	ID          int64            `json:"id" db:"id"`
+	Name        string           `json:"name" db:"name"`
+	Description *string          `json:"description,omitempty" db:"description"`
+	RuleType    ApprovalRuleType `json:"rule_type" db:"rule_type"`
+	Params      JSONMap          `json:"params" db:"params"`
+	IsActive    bool             `json:"is_active" db:"is_active"`
+	Priority    int              `json:"priority" db:"priority"`
+	CreatedBy   *int64           `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// ApprovalRuleResult is the outcome of one rule against one order.
+type ApprovalRuleResult struct {
+	RuleID   int64            `json:"rule_id"`
+	RuleName string           `json:"rule_name"`
+	RuleType ApprovalRuleType `json:"rule_type"`
+	Passed   bool             `json:"passed"`
+	Reason   string           `json:"reason"`
+}
+
+// ApprovalEvaluation is the combined outcome of all active rules.
+type ApprovalEvaluation struct {
+	OrderID  int64                 `json:"order_id"`
+	Enabled  bool                  `json:"enabled"`
+	Approved bool                  `json:"approved"`
+	Results  []*ApprovalRuleResult `json:"results"`
+	Reasons  []string              `json:"reasons"`
+}
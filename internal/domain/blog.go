@@ -34,6 +34,16 @@ type BlogCategory struct {
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// BlogCategoryNode is a blog category in the nested category tree. PostCount
+// is the published posts filed directly under it; TotalPostCount adds those
+// in every subcategory below it.
+type BlogCategoryNode struct {
+	*BlogCategory
+	PostCount      int                 `json:"post_count"`
+	TotalPostCount int                 `json:"total_post_count"`
+	Children       []*BlogCategoryNode `json:"children"`
+}
+
 // BlogPost represents a blog post
 type BlogPost struct {
 	ID                 int64          `db:"id" json:"id"`
@@ -55,6 +65,15 @@ type BlogPost struct {
 	PublishedAt        *time.Time     `db:"published_at" json:"published_at,omitempty"`
 	CreatedAt          time.Time      `db:"created_at" json:"created_at"`
 	UpdatedAt          time.Time      `db:"updated_at" json:"updated_at"`
+
+	// Set when the featured image was uploaded rather than given as a URL
+	FeaturedImageWidth      *int            `db:"featured_image_width" json:"featured_image_width,omitempty"`
+	FeaturedImageHeight     *int            `db:"featured_image_height" json:"featured_image_height,omitempty"`
+	FeaturedImageBlurHash   *string         `db:"featured_image_blurhash" json:"featured_image_blurhash,omitempty"`
+	FeaturedImageRenditions ImageRenditions `db:"featured_image_renditions" json:"featured_image_renditions"`
+
+	// Email newsletter subscribers when a scheduled post goes live
+	AnnounceOnPublish bool `db:"announce_on_publish" json:"announce_on_publish"`
 }
 
 // BlogPostWithRelations - For API responses with joined data
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// ============================================================================
+// TEMPLATE BUNDLE
+// ============================================================================
+
+// TemplateBundle is a curated pack of templates sold at its own price. At
+// checkout it is expanded into one order item per template, so buyers get
+// the same download links and invoice lines as for single purchases.
+type TemplateBundle struct {
+	ID                int64          `json:"id" db:"id"`
+	Name              string         `json:"name" db:"name"`
+	Slug              string         `json:"slug" db:"slug"`
+	Tagline           *string        `json:"tagline,omitempty" db:"tagline"`
+	Description       string         `json:"description" db:"description"`
+	PriceUSDCents     int64          `json:"price_usd_cents" db:"price_usd_cents"`
+	SalePriceUSDCents *int64         `json:"sale_price_usd_cents,omitempty" db:"sale_price_usd_cents"`
+	Status            TemplateStatus `json:"status" db:"status"`
+	IsFeatured        bool           `json:"is_featured" db:"is_featured"`
+	MetaTitle         *string        `json:"meta_title,omitempty" db:"meta_title"`
+	MetaDescription   *string        `json:"meta_description,omitempty" db:"meta_description"`
+	PublishedAt       *time.Time     `json:"published_at,omitempty" db:"published_at"`
+	CreatedAt         time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// IsAvailable returns true if the bundle can be purchased
+func (b *TemplateBundle) IsAvailable() bool {
+	return b.Status == TemplateStatusActive
+}
+
+// GetCurrentPriceCents returns the applicable price in USD cents
+func (b *TemplateBundle) GetCurrentPriceCents() int64 {
+	if b.SalePriceUSDCents != nil {
+		return *b.SalePriceUSDCents
+	}
+	return b.PriceUSDCents
+}
+
+// ProratePrices splits the bundle's current price across its templates in
+// proportion to their own current prices, returning one share per template
+// in the same order. Shares are whole cents and always add up to the bundle
+// price; templates that are all free split it evenly.
+func (b *TemplateBundle) ProratePrices(templates []*Template) []int64 {
+	shares := make([]int64, len(templates))
+	if len(templates) == 0 {
+		return shares
+	}
+
+	total := b.GetCurrentPriceCents()
+	weights := make([]int64, len(templates))
+	var sum int64
+	for i, t := range templates {
+		weights[i] = t.GetCurrentPriceCents()
+		sum += weights[i]
+	}
+	if sum == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+		sum = int64(len(weights))
+	}
+
+	// Largest remainder: floor every share, then hand the leftover cents to
+	// the shares that were rounded down the most
+	remainders := make([]int64, len(templates))
+	var allocated int64
+	for i, w := range weights {
+		shares[i] = total * w / sum
+		remainders[i] = total * w % sum
+		allocated += shares[i]
+	}
+
+	order := make([]int, len(templates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := int64(0); i < total-allocated; i++ {
+		shares[order[i]]++
+	}
+
+	return shares
+}
+
+// BundleImage is a gallery image of a bundle; see TemplateImage
+type BundleImage struct {
+	ID           int64     `json:"id" db:"id"`
+	BundleID     int64     `json:"bundle_id" db:"bundle_id"`
+	URL          string    `json:"url" db:"url"`
+	AltText      *string   `json:"alt_text,omitempty" db:"alt_text"`
+	DisplayOrder int       `json:"display_order" db:"display_order"`
+	IsPrimary    bool      `json:"is_primary" db:"is_primary"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Set for uploaded images; images added by URL have none
+	Width      *int            `json:"width,omitempty" db:"width"`
+	Height     *int            `json:"height,omitempty" db:"height"`
+	BlurHash   *string         `json:"blurhash,omitempty" db:"blurhash"`
+	Renditions ImageRenditions `json:"renditions" db:"renditions"`
+}
+
+// BundleWithTemplates includes the bundle's templates, in display order,
+// and what they would cost bought one by one
+type BundleWithTemplates struct {
+	TemplateBundle
+	Templates       []*Template    `json:"templates"`
+	Images          []*BundleImage `json:"images"`
+	ValueUSDCents   int64          `json:"value_usd_cents"`
+	SavingsUSDCents int64          `json:"savings_usd_cents"`
+}
+
+func NewBundleWithTemplates(bundle *TemplateBundle, templates []*Template, images []*BundleImage) *BundleWithTemplates {
+	result := &BundleWithTemplates{
+		TemplateBundle: *bundle,
+		Templates:      templates,
+		Images:         images,
+	}
+	for _, t := range templates {
+		result.ValueUSDCents += t.GetCurrentPriceCents()
+	}
+	if savings := result.ValueUSDCents - bundle.GetCurrentPriceCents(); savings > 0 {
+		result.SavingsUSDCents = savings
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// ============================================================================
+// CUSTOMER
+// ============================================================================
+
+// Customer is a buyer identity proven by redeeming a magic link. Guest
+// orders placed with the same email are attached once it is verified.
+type Customer struct {
+	ID              int64      `json:"id" db:"id"`
+	Email           string     `json:"email" db:"email"`
+	Name            *string    `json:"name,omitempty" db:"name"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	LastLoginAt     *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	LastLoginIP     *string    `json:"-" db:"last_login_ip"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ============================================================================
+// CUSTOMER LOGIN TOKEN
+// ============================================================================
+
+// CustomerLoginToken is a single-use magic link. Only the SHA-256 hash of
+// the emailed token is stored.
+type CustomerLoginToken struct {
+	ID          int64      `json:"id" db:"id"`
+	CustomerID  int64      `json:"customer_id" db:"customer_id"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty" db:"used_at"`
+	RequestedIP *string    `json:"requested_ip,omitempty" db:"requested_ip"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ============================================================================
+// ORDER LOOKUP CODE
+// ============================================================================
+
+// OrderLookupCode is a short-lived one-time code emailed to a guest who
+// wants to see their orders. It lives in Redis, never in Postgres.
+type OrderLookupCode struct {
+	CodeHash string
+	// Attempts counts guesses against the email, across resent codes
+	Attempts int
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// ============================================================================
+// DISPUTE STATUS
+// ============================================================================
+
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen        DisputeStatus = "open"
+	DisputeStatusUnderReview DisputeStatus = "under_review"
+	DisputeStatusWon         DisputeStatus = "won"
+	DisputeStatusLost        DisputeStatus = "lost"
+	DisputeStatusClosed      DisputeStatus = "closed"
+)
+
+// IsResolved reports whether the gateway has reached a final decision.
+// A closed dispute was accepted by the merchant and counts as lost.
+func (s DisputeStatus) IsResolved() bool {
+	return s == DisputeStatusWon || s == DisputeStatusLost || s == DisputeStatusClosed
+}
+
+// ============================================================================
+// PAYMENT DISPUTE
+// ============================================================================
+
+// PaymentDispute is a chargeback raised through the gateway. Its amounts
+// are in the smallest unit of Currency, as the gateway reports them.
+type PaymentDispute struct {
+	ID                  int64         `json:"id" db:"id"`
+	PaymentID           int64         `json:"payment_id" db:"payment_id"`
+	OrderID             int64         `json:"order_id" db:"order_id"`
+	GatewayDisputeID    string        `json:"gateway_dispute_id" db:"gateway_dispute_id"`
+	ReasonCode          *string       `json:"reason_code,omitempty" db:"reason_code"`
+	ReasonDescription   *string       `json:"reason_description,omitempty" db:"reason_description"`
+	AmountMinor         int64         `json:"amount_minor" db:"amount_minor"`
+	AmountDeductedMinor int64         `json:"amount_deducted_minor" db:"amount_deducted_minor"`
+	Currency            string        `json:"currency" db:"currency"`
+	Phase               *string       `json:"phase,omitempty" db:"phase"`
+	Status              DisputeStatus `json:"status" db:"status"`
+	RespondBy           *time.Time    `json:"respond_by,omitempty" db:"respond_by"`
+	ResolvedAt          *time.Time    `json:"resolved_at,omitempty" db:"resolved_at"`
+	GatewayPayload      JSONMap       `json:"gateway_payload,omitempty" db:"gateway_payload"`
+	CreatedAt           time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+type DisputeEvidence struct {
+	ID          int64     `json:"id" db:"id"`
+	DisputeID   int64     `json:"dispute_id" db:"dispute_id"`
+	Notes       string    `json:"notes" db:"notes"`
+	DocumentURL *string   `json:"document_url,omitempty" db:"document_url"`
+	AddedBy     *int64    `json:"added_by,omitempty" db:"added_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type DisputeWithEvidence struct {
+	PaymentDispute
+	Evidence []*DisputeEvidence `json:"evidence"`
+}
@@ -11,4 +11,6 @@ var (
 	ErrForbidden              = errors.New("forbidden")
 	ErrDuplicateEntry         = errors.New("duplicate entry")
 	ErrInvalidInput           = errors.New("invalid input")
+	ErrVersionConflict        = errors.New("version conflict")
+	ErrQuarantined            = errors.New("file failed malware scan")
 )
\ No newline at end of file
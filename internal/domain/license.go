@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// ============================================================================
+// LICENSE TIERS
+// ============================================================================
+
+type LicenseTier string
+
+const (
+	LicenseTierPersonal   LicenseTier = "personal"
+	LicenseTierCommercial LicenseTier = "commercial"
+	LicenseTierTeam       LicenseTier = "team"
+)
+
+func (t LicenseTier) IsValid() bool {
+	switch t {
+	case LicenseTierPersonal, LicenseTierCommercial, LicenseTierTeam:
+		return true
+	}
+	return false
+}
+
+// ============================================================================
+// LICENSE
+// ============================================================================
+
+type LicenseStatus string
+
+const (
+	LicenseStatusActive  LicenseStatus = "active"
+	LicenseStatusRevoked LicenseStatus = "revoked"
+)
+
+// License is the signed proof of purchase issued for one order item. The
+// key itself verifies offline; Status is what makes a refund stick.
+type License struct {
+	ID            int64         `json:"id" db:"id"`
+	Serial        string        `json:"serial" db:"serial"`
+	LicenseKey    string        `json:"license_key" db:"license_key"`
+	OrderID       int64         `json:"order_id" db:"order_id"`
+	OrderItemID   int64         `json:"order_item_id" db:"order_item_id"`
+	TemplateID    int64         `json:"template_id" db:"template_id"`
+	CustomerEmail string        `json:"customer_email" db:"customer_email"`
+	CustomerName  string        `json:"customer_name" db:"customer_name"`
+	Tier          LicenseTier   `json:"tier" db:"tier"`
+	Seats         int           `json:"seats" db:"seats"`
+	Status        LicenseStatus `json:"status" db:"status"`
+	IssuedAt      time.Time     `json:"issued_at" db:"issued_at"`
+	RevokedAt     *time.Time    `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokeReason  *string       `json:"revoke_reason,omitempty" db:"revoke_reason"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+}
+
+// LicenseWithTemplate adds the template name for emails and listings.
+type LicenseWithTemplate struct {
+	License
+	TemplateName string `json:"template_name" db:"template_name"`
+	TemplateSlug string `json:"template_slug" db:"template_slug"`
+}
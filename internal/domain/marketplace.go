@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -13,9 +17,21 @@ import (
 type TemplateStatus string
 
 const (
-	TemplateStatusDraft    TemplateStatus = "draft"
-	TemplateStatusActive   TemplateStatus = "active"
-	TemplateStatusArchived TemplateStatus = "archived"
+	TemplateStatusDraft     TemplateStatus = "draft"
+	TemplateStatusScheduled TemplateStatus = "scheduled" // goes active at PublishedAt
+	TemplateStatusActive    TemplateStatus = "active"
+	TemplateStatusArchived  TemplateStatus = "archived"
+)
+
+// ScanStatus is the malware scan verdict of an uploaded file. Files
+// uploaded before scanning existed, or with no scanner configured, are
+// "unscanned".
+type ScanStatus string
+
+const (
+	ScanStatusClean       ScanStatus = "clean"
+	ScanStatusQuarantined ScanStatus = "quarantined"
+	ScanStatusUnscanned   ScanStatus = "unscanned"
 )
 
 type OrderStatus string
@@ -70,6 +86,16 @@ func USDToCents(usd float64) int64 {
 	return int64(usd * 100)
 }
 
+// FormatMinorAmount renders an amount given in the smallest unit of
+// currency, e.g. 150000 INR as "1500.00 INR". USD keeps the "$" form used
+// elsewhere. Every currency is assumed to have two decimal places.
+func FormatMinorAmount(amount int64, currency string) string {
+	if currency == "" || strings.EqualFold(currency, Currency) {
+		return fmt.Sprintf("$%.2f", CentsToUSD(amount))
+	}
+	return fmt.Sprintf("%.2f %s", float64(amount)/100.0, strings.ToUpper(currency))
+}
+
 // ============================================================================
 // CATEGORY
 // ============================================================================
@@ -88,6 +114,16 @@ type Category struct {
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// CategoryNode is a category in the nested category tree. TemplateCount is
+// the templates filed directly under it; TotalTemplateCount adds those in
+// every subcategory below it.
+type CategoryNode struct {
+	*Category
+	TemplateCount      int             `json:"template_count"`
+	TotalTemplateCount int             `json:"total_template_count"`
+	Children           []*CategoryNode `json:"children"`
+}
+
 // ============================================================================
 // TEMPLATE (Digital Product)
 // ============================================================================
@@ -101,6 +137,8 @@ type Template struct {
 	CategoryID        *int64         `json:"category_id,omitempty" db:"category_id"`
 	PriceUSDCents     int64          `json:"price_usd_cents" db:"price_usd_cents"`
 	SalePriceUSDCents *int64         `json:"sale_price_usd_cents,omitempty" db:"sale_price_usd_cents"`
+	SaleEndsAt        *time.Time     `json:"sale_ends_at,omitempty" db:"sale_ends_at"` // set while a price rule's sale runs
+	SaleRuleID        *int64         `json:"sale_rule_id,omitempty" db:"sale_rule_id"`
 	FileURL           *string        `json:"file_url,omitempty" db:"file_url"`
 	FileSizeMB        *float64       `json:"file_size_mb,omitempty" db:"file_size_mb"`
 	FileFormat        *string        `json:"file_format,omitempty" db:"file_format"`
@@ -111,11 +149,17 @@ type Template struct {
 	IsFeatured        bool           `json:"is_featured" db:"is_featured"`
 	IsBestseller      bool           `json:"is_bestseller" db:"is_bestseller"`
 	IsNew             bool           `json:"is_new" db:"is_new"`
+	WatermarkEnabled  bool           `json:"watermark_enabled" db:"watermark_enabled"`
+	LicenseTier       LicenseTier    `json:"license_tier" db:"license_tier"`
+	LicenseSeats      int            `json:"license_seats" db:"license_seats"`
 	MetaTitle         *string        `json:"meta_title,omitempty" db:"meta_title"`
 	MetaDescription   *string        `json:"meta_description,omitempty" db:"meta_description"`
 	MetaKeywords      pq.StringArray `json:"meta_keywords,omitempty" db:"meta_keywords"`
 	CurrentVersion    string         `json:"current_version" db:"current_version"`
+	RatingAverage     float64        `json:"rating_average" db:"rating_average"` // approved reviews only
+	RatingCount       int            `json:"rating_count" db:"rating_count"`
 	PublishedAt       *time.Time     `json:"published_at,omitempty" db:"published_at"`
+	AnnounceOnPublish bool           `json:"announce_on_publish" db:"announce_on_publish"` // newsletter when a scheduled publish goes live
 	CreatedAt         time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at" db:"updated_at"`
 }
@@ -152,6 +196,18 @@ type TemplateWithRelations struct {
 	Tags     []string           `json:"tags,omitempty"`
 }
 
+// RelatedTemplate is a template recommended alongside another. Reason is
+// its strongest link: "bought_together", "shared_tags", "same_category",
+// or "popular" for the fallback shown before recommendations are computed.
+type RelatedTemplate struct {
+	Template
+	Score        float64 `json:"score" db:"score"`
+	CoPurchases  int     `json:"co_purchases" db:"co_purchases"`
+	SharedTags   int     `json:"shared_tags" db:"shared_tags"`
+	SameCategory bool    `json:"same_category" db:"same_category"`
+	Reason       string  `json:"reason" db:"-"`
+}
+
 type TemplateVersion struct {
 	ID            int64     `json:"id" db:"id"`
 	TemplateID    int64     `json:"template_id" db:"template_id"`
@@ -162,6 +218,51 @@ type TemplateVersion struct {
 	IsCurrent     bool      `json:"is_current" db:"is_current"`
 	UploadedBy    *int64    `json:"uploaded_by,omitempty" db:"uploaded_by"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+
+	// Measured by the server on upload
+	ChecksumSHA256 *string    `json:"checksum_sha256,omitempty" db:"checksum_sha256"`
+	ContentType    *string    `json:"content_type,omitempty" db:"content_type"`
+	ScanStatus     ScanStatus `json:"scan_status" db:"scan_status"`
+	ScanSignature  *string    `json:"scan_signature,omitempty" db:"scan_signature"`
+}
+
+// TemplateUpdateSubscriber is a past buyer who asked to hear about new
+// versions of a template.
+type TemplateUpdateSubscriber struct {
+	OrderID          int64   `json:"order_id" db:"order_id"`
+	OrderNumber      string  `json:"order_number" db:"order_number"`
+	CustomerEmail    string  `json:"customer_email" db:"customer_email"`
+	CustomerName     string  `json:"customer_name" db:"customer_name"`
+	PurchasedVersion *string `json:"purchased_version,omitempty" db:"purchased_version"`
+}
+
+type UploadSessionStatus string
+
+const (
+	UploadSessionUploading UploadSessionStatus = "uploading"
+	UploadSessionCompleted UploadSessionStatus = "completed"
+	UploadSessionFailed    UploadSessionStatus = "failed"
+)
+
+// UploadSession is a resumable upload of a new template version. Chunks
+// are staged on disk; the session tracks how many bytes have arrived.
+type UploadSession struct {
+	ID            string              `json:"id" db:"id"`
+	TemplateID    int64               `json:"template_id" db:"template_id"`
+	AdminID       int64               `json:"admin_id" db:"admin_id"`
+	Filename      string              `json:"filename" db:"filename"`
+	TotalBytes    int64               `json:"total_bytes" db:"total_bytes"`
+	ReceivedBytes int64               `json:"received_bytes" db:"received_bytes"`
+	VersionNumber string              `json:"version_number" db:"version_number"`
+	Changelog     *string             `json:"changelog,omitempty" db:"changelog"`
+	NotifyBuyers  bool                `json:"notify_buyers" db:"notify_buyers"`
+	Status        UploadSessionStatus `json:"status" db:"status"`
+	FailureReason *string             `json:"failure_reason,omitempty" db:"failure_reason"`
+	VersionID     *int64              `json:"version_id,omitempty" db:"version_id"`
+	LockedUntil   *time.Time          `json:"-" db:"locked_until"`
+	ExpiresAt     time.Time           `json:"expires_at" db:"expires_at"`
+	CreatedAt     time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at" db:"updated_at"`
 }
 
 type TemplateImage struct {
@@ -171,7 +272,63 @@ type TemplateImage struct {
 	AltText      *string   `json:"alt_text,omitempty" db:"alt_text"`
 	DisplayOrder int       `json:"display_order" db:"display_order"`
 	IsPrimary    bool      `json:"is_primary" db:"is_primary"`
+	IsPreview    bool      `json:"is_preview" db:"is_preview"` // generated from the template file
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Set for uploaded images; images added by URL have none
+	Width      *int            `json:"width,omitempty" db:"width"`
+	Height     *int            `json:"height,omitempty" db:"height"`
+	BlurHash   *string         `json:"blurhash,omitempty" db:"blurhash"`
+	Renditions ImageRenditions `json:"renditions" db:"renditions"`
+}
+
+// ImageRendition is one resized copy of an uploaded image. Renditions are
+// kept narrowest first, so a client can build an <img srcset> from them
+// as-is.
+type ImageRendition struct {
+	URL      string `json:"url"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Format   string `json:"format"`
+	Bytes    int    `json:"bytes"`
+	PublicID string `json:"public_id"`
+	// WebP is the same rendition encoded as WebP, set only when that came
+	// out smaller. Clients can offer it in a <picture> <source>.
+	WebP *ImageVariant `json:"webp,omitempty"`
+}
+
+// ImageVariant is a rendition stored in an alternative format.
+type ImageVariant struct {
+	URL      string `json:"url"`
+	Format   string `json:"format"`
+	Bytes    int    `json:"bytes"`
+	PublicID string `json:"public_id"`
+}
+
+type ImageRenditions []ImageRendition
+
+func (r ImageRenditions) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *ImageRenditions) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok || len(b) == 0 {
+		*r = ImageRenditions{}
+		return nil
+	}
+	return json.Unmarshal(b, r)
+}
+
+// MarshalJSON writes an empty list rather than null.
+func (r ImageRenditions) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]ImageRendition(r))
 }
 
 type TemplateFeature struct {
@@ -211,6 +368,7 @@ type TemplateAnalytics struct {
 type Order struct {
 	ID                     int64        `json:"id" db:"id"`
 	OrderNumber            string       `json:"order_number" db:"order_number"`
+	CustomerID             *int64       `json:"customer_id,omitempty" db:"customer_id"`
 	CustomerEmail          string       `json:"customer_email" db:"customer_email"`
 	CustomerName           string       `json:"customer_name" db:"customer_name"`
 	CustomerPhone          *string      `json:"customer_phone,omitempty" db:"customer_phone"`
@@ -243,6 +401,10 @@ type Order struct {
 	StatusUpdatedAt        *time.Time   `json:"status_updated_at,omitempty"  db:"status_updated_at"`
 	PreviousStatus         *OrderStatus `json:"previous_status,omitempty"   db:"previous_status"`
 	IdempotencyKey         *string      `json:"idempotency_key,omitempty" db:"idempotency_key"`
+	RiskScore              *int         `json:"risk_score,omitempty" db:"risk_score"`
+	RiskReasons            RiskReasons  `json:"risk_reasons" db:"risk_reasons"`
+	RiskEvaluatedAt        *time.Time   `json:"risk_evaluated_at,omitempty" db:"risk_evaluated_at"`
+	NotifyUpdates          bool         `json:"notify_updates" db:"notify_updates"`
 	Metadata               JSONMap      `json:"metadata,omitempty" db:"metadata"`
 	CreatedAt              time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt              time.Time    `json:"updated_at" db:"updated_at"`
@@ -332,6 +494,17 @@ type OrderItem struct {
 	DownloadCount    int        `json:"download_count" db:"download_count"`
 	LastDownloadedAt *time.Time `json:"last_downloaded_at,omitempty" db:"last_downloaded_at"`
 	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+
+	// Set when the template was bought as part of a bundle; the price is its
+	// prorated share of the bundle price
+	BundleID   *int64  `json:"bundle_id,omitempty" db:"bundle_id"`
+	BundleName *string `json:"bundle_name,omitempty" db:"bundle_name"`
+
+	// Buyer-stamped copy of the deliverable, cached per item. WatermarkSourceURL
+	// records which template file it was built from so a new version invalidates it.
+	WatermarkedFileURL *string    `json:"-" db:"watermarked_file_url"`
+	WatermarkSourceURL *string    `json:"-" db:"watermark_source_url"`
+	WatermarkedAt      *time.Time `json:"-" db:"watermarked_at"`
 }
 
 // ============================================================================
@@ -368,6 +541,8 @@ type Payment struct {
 	CapturedAt           *time.Time    `json:"captured_at,omitempty" db:"captured_at"`
 	FailedAt             *time.Time    `json:"failed_at,omitempty" db:"failed_at"`
 	RefundedAt           *time.Time    `json:"refunded_at,omitempty" db:"refunded_at"`
+	SettlementID         *string       `json:"settlement_id,omitempty" db:"settlement_id"`
+	SettledAt            *time.Time    `json:"settled_at,omitempty" db:"settled_at"`
 	CreatedAt            time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time     `json:"updated_at" db:"updated_at"`
 }
@@ -377,24 +552,26 @@ type Payment struct {
 // ============================================================================
 
 type PaymentWebhook struct {
-	ID                int64      `json:"id" db:"id"`
-	WebhookID         *string    `json:"webhook_id,omitempty" db:"webhook_id"`
-	EventType         string     `json:"event_type" db:"event_type"`
-	OrderID           *int64     `json:"order_id,omitempty" db:"order_id"`
-	PaymentID         *int64     `json:"payment_id,omitempty" db:"payment_id"`
-	GatewayOrderID    *string    `json:"gateway_order_id,omitempty" db:"gateway_order_id"`
-	GatewayPaymentID  *string    `json:"gateway_payment_id,omitempty" db:"gateway_payment_id"`
-	Payload           JSONMap    `json:"payload" db:"payload"`
-	Signature         *string    `json:"signature,omitempty" db:"signature"`
-	SignatureVerified bool       `json:"signature_verified" db:"signature_verified"`
-	Processed         bool       `json:"processed" db:"processed"`
-	ProcessedAt       *time.Time `json:"processed_at,omitempty" db:"processed_at"`
-	ProcessingError   *string    `json:"processing_error,omitempty" db:"processing_error"`
-	RetryCount        int        `json:"retry_count" db:"retry_count"`
-	MaxRetries        int        `json:"max_retries" db:"max_retries"`
-	SourceIP          *string    `json:"source_ip,omitempty" db:"source_ip"`
-	UserAgent         *string    `json:"user_agent,omitempty" db:"user_agent"`
-	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	ID                  int64      `json:"id" db:"id"`
+	WebhookID           *string    `json:"webhook_id,omitempty" db:"webhook_id"`
+	EventType           string     `json:"event_type" db:"event_type"`
+	OrderID             *int64     `json:"order_id,omitempty" db:"order_id"`
+	PaymentID           *int64     `json:"payment_id,omitempty" db:"payment_id"`
+	GatewayOrderID      *string    `json:"gateway_order_id,omitempty" db:"gateway_order_id"`
+	GatewayPaymentID    *string    `json:"gateway_payment_id,omitempty" db:"gateway_payment_id"`
+	Payload             JSONMap    `json:"payload" db:"payload"`
+	Signature           *string    `json:"signature,omitempty" db:"signature"`
+	SignatureVerified   bool       `json:"signature_verified" db:"signature_verified"`
+	Processed           bool       `json:"processed" db:"processed"`
+	ProcessedAt         *time.Time `json:"processed_at,omitempty" db:"processed_at"`
+	ProcessingError     *string    `json:"processing_error,omitempty" db:"processing_error"`
+	RetryCount          int        `json:"retry_count" db:"retry_count"`
+	MaxRetries          int        `json:"max_retries" db:"max_retries"`
+	NextRetryAt         *time.Time `json:"next_retry_at,omitempty" db:"next_retry_at"`
+	ProcessingStartedAt *time.Time `json:"processing_started_at,omitempty" db:"processing_started_at"`
+	SourceIP            *string    `json:"source_ip,omitempty" db:"source_ip"`
+	UserAgent           *string    `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
 }
 
 // ============================================================================
@@ -413,6 +590,8 @@ type DownloadToken struct {
 	RevokedAt     *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
 	RevokedReason *string    `json:"revoked_reason,omitempty" db:"revoked_reason"`
 	RevokedBy     *int64     `json:"revoked_by,omitempty" db:"revoked_by"`
+	FrozenAt      *time.Time `json:"frozen_at,omitempty" db:"frozen_at"`
+	FrozenReason  *string    `json:"frozen_reason,omitempty" db:"frozen_reason"`
 	DownloadCount int        `json:"download_count" db:"download_count"`
 	MaxDownloads  int        `json:"max_downloads" db:"max_downloads"`
 	LastUsedAt    *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
@@ -423,7 +602,7 @@ type DownloadToken struct {
 
 // IsValid checks if the token is valid for use
 func (dt *DownloadToken) IsValid() bool {
-	if dt.IsRevoked {
+	if dt.IsRevoked || dt.FrozenAt != nil {
 		return false
 	}
 	if time.Now().After(dt.ExpiresAt) {
@@ -451,6 +630,9 @@ type Download struct {
 	Country            *string    `json:"country,omitempty" db:"country"`
 	FileURL            *string    `json:"file_url,omitempty" db:"file_url"`
 	FileSizeBytes      *int64     `json:"file_size_bytes,omitempty" db:"file_size_bytes"`
+	BytesServed        int64      `json:"bytes_served" db:"bytes_served"`
+	RangeHeader        *string    `json:"range_header,omitempty" db:"range_header"`
+	Counted            bool       `json:"counted" db:"counted"`
 	DownloadDurationMS *int       `json:"download_duration_ms,omitempty" db:"download_duration_ms"`
 	StartedAt          time.Time  `json:"started_at" db:"started_at"`
 	CompletedAt        *time.Time `json:"completed_at,omitempty" db:"completed_at"`
@@ -459,6 +641,19 @@ type Download struct {
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 }
 
+// DownloadBundleToken authorizes a single ZIP of every file in an order.
+// Each file is still validated and counted against its own DownloadToken.
+type DownloadBundleToken struct {
+	ID            int64      `json:"id" db:"id"`
+	Token         string     `json:"token" db:"token"`
+	OrderID       int64      `json:"order_id" db:"order_id"`
+	CustomerEmail string     `json:"customer_email" db:"customer_email"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	DownloadCount int        `json:"download_count" db:"download_count"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
 // ============================================================================
 // ORDER STATE TRANSITION
 // ============================================================================
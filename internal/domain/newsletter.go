@@ -48,4 +48,12 @@ type NewsletterCampaignRecipient struct {
 	ClickedAt    *time.Time `db:"clicked_at" json:"clicked_at,omitempty"`
 	ErrorMessage *string    `db:"error_message" json:"error_message,omitempty"`
 	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
-}
\ No newline at end of file
+}
+
+// PendingCampaignRecipient is a subscriber a campaign still has to be sent to
+type PendingCampaignRecipient struct {
+	ID           int64   `db:"id" json:"id"`
+	SubscriberID int64   `db:"subscriber_id" json:"subscriber_id"`
+	Email        string  `db:"email" json:"email"`
+	Name         *string `db:"name" json:"name,omitempty"`
+}
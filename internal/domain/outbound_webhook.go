@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ============================================================================
+// OUTBOUND EVENTS
+// ============================================================================
+
+type OutboundEvent string
+
+const (
+	OutboundEventOrderPaid            OutboundEvent = "order.paid"
+	OutboundEventOrderApproved        OutboundEvent = "order.approved"
+	OutboundEventOrderRefunded        OutboundEvent = "order.refunded"
+	OutboundEventNewsletterSubscribed OutboundEvent = "newsletter.subscribed"
+	OutboundEventContactCreated       OutboundEvent = "contact.created"
+	OutboundEventTemplatePublished    OutboundEvent = "template.published"
+	OutboundEventBlogPostPublished    OutboundEvent = "blog_post.published"
+)
+
+// OutboundEvents lists every event a subscription may ask for.
+var OutboundEvents = []OutboundEvent{
+	OutboundEventOrderPaid,
+	OutboundEventOrderApproved,
+	OutboundEventOrderRefunded,
+	OutboundEventNewsletterSubscribed,
+	OutboundEventContactCreated,
+	OutboundEventTemplatePublished,
+	OutboundEventBlogPostPublished,
+}
+
+func IsValidOutboundEvent(event string) bool {
+	for _, e := range OutboundEvents {
+		if string(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// WEBHOOK SUBSCRIPTION
+// ============================================================================
+
+// WebhookSubscription is an external endpoint that receives the listed
+// events. The secret is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID         int64          `json:"id" db:"id"`
+	Name       string         `json:"name" db:"name"`
+	URL        string         `json:"url" db:"url"`
+	Secret     string         `json:"-" db:"secret"`
+	EventTypes pq.StringArray `json:"event_types" db:"event_types"`
+	IsActive   bool           `json:"is_active" db:"is_active"`
+	CreatedBy  *int64         `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// ============================================================================
+// WEBHOOK DELIVERY
+// ============================================================================
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to one subscription. The response
+// fields describe the most recent attempt.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id" db:"id"`
+	SubscriptionID int64                 `json:"subscription_id" db:"subscription_id"`
+	EventID        string                `json:"event_id" db:"event_id"`
+	EventType      string                `json:"event_type" db:"event_type"`
+	Payload        JSONMap               `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	ResponseStatus *int                  `json:"response_status,omitempty" db:"response_status"`
+	ResponseBody   *string               `json:"response_body,omitempty" db:"response_body"`
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// ============================================================================
+// PRICE RULE STATUS
+// ============================================================================
+
+type PriceRuleStatus string
+
+const (
+	PriceRuleStatusScheduled PriceRuleStatus = "scheduled"
+	PriceRuleStatusActive    PriceRuleStatus = "active"
+	PriceRuleStatusEnded     PriceRuleStatus = "ended"
+	PriceRuleStatusCancelled PriceRuleStatus = "cancelled"
+)
+
+// ============================================================================
+// PRICE RULE
+// ============================================================================
+
+// PriceRule is a sale that runs from StartsAt to EndsAt on one template or
+// every template in a category. It either sets a fixed sale price (template
+// rules only) or takes DiscountPercent off each template's list price.
+type PriceRule struct {
+	ID                int64           `json:"id" db:"id"`
+	Name              string          `json:"name" db:"name"`
+	TemplateID        *int64          `json:"template_id,omitempty" db:"template_id"`
+	CategoryID        *int64          `json:"category_id,omitempty" db:"category_id"`
+	SalePriceUSDCents *int64          `json:"sale_price_usd_cents,omitempty" db:"sale_price_usd_cents"`
+	DiscountPercent   *int            `json:"discount_percent,omitempty" db:"discount_percent"`
+	StartsAt          time.Time       `json:"starts_at" db:"starts_at"`
+	EndsAt            time.Time       `json:"ends_at" db:"ends_at"`
+	Status            PriceRuleStatus `json:"status" db:"status"`
+	CreatedBy         *int64          `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Covers returns true if the rule targets the template
+func (r *PriceRule) Covers(t *Template) bool {
+	if r.TemplateID != nil {
+		return *r.TemplateID == t.ID
+	}
+	return r.CategoryID != nil && t.CategoryID != nil && *r.CategoryID == *t.CategoryID
+}
+
+// SalePriceFor returns the rule's sale price for a template it covers. The
+// second result is false when that would not be below the list price.
+func (r *PriceRule) SalePriceFor(t *Template) (int64, bool) {
+	var price int64
+	switch {
+	case r.SalePriceUSDCents != nil:
+		price = *r.SalePriceUSDCents
+	case r.DiscountPercent != nil:
+		price = int64(math.Round(float64(t.PriceUSDCents) * float64(100-*r.DiscountPercent) / 100))
+	default:
+		return 0, false
+	}
+	return price, price < t.PriceUSDCents
+}
+
+// ============================================================================
+// PRICE HISTORY
+// ============================================================================
+
+type PriceChangeSource string
+
+const (
+	PriceChangeSourceInitial   PriceChangeSource = "initial"
+	PriceChangeSourceAdmin     PriceChangeSource = "admin"
+	PriceChangeSourcePriceRule PriceChangeSource = "price_rule"
+)
+
+// TemplatePriceHistory records a template's list and sale price after a
+// change, and what they were before it
+type TemplatePriceHistory struct {
+	ID                        int64             `json:"id" db:"id"`
+	TemplateID                int64             `json:"template_id" db:"template_id"`
+	PriceUSDCents             int64             `json:"price_usd_cents" db:"price_usd_cents"`
+	SalePriceUSDCents         *int64            `json:"sale_price_usd_cents,omitempty" db:"sale_price_usd_cents"`
+	PreviousPriceUSDCents     *int64            `json:"previous_price_usd_cents,omitempty" db:"previous_price_usd_cents"`
+	PreviousSalePriceUSDCents *int64            `json:"previous_sale_price_usd_cents,omitempty" db:"previous_sale_price_usd_cents"`
+	Source                    PriceChangeSource `json:"source" db:"source"`
+	PriceRuleID               *int64            `json:"price_rule_id,omitempty" db:"price_rule_id"`
+	ChangedBy                 *int64            `json:"changed_by,omitempty" db:"changed_by"`
+	CreatedAt                 time.Time         `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// ============================================================================
+// RECONCILIATION RUN
+// ============================================================================
+
+type ReconciliationRunStatus string
+
+const (
+	ReconciliationRunning   ReconciliationRunStatus = "running"
+	ReconciliationCompleted ReconciliationRunStatus = "completed"
+	ReconciliationFailed    ReconciliationRunStatus = "failed"
+)
+
+type ReconciliationRun struct {
+	ID                  int64                   `json:"id" db:"id"`
+	PeriodStart         time.Time               `json:"period_start" db:"period_start"`
+	PeriodEnd           time.Time               `json:"period_end" db:"period_end"`
+	Status              ReconciliationRunStatus `json:"status" db:"status"`
+	GatewayPaymentCount int                     `json:"gateway_payment_count" db:"gateway_payment_count"`
+	LocalPaymentCount   int                     `json:"local_payment_count" db:"local_payment_count"`
+	MatchedCount        int                     `json:"matched_count" db:"matched_count"`
+	MismatchCount       int                     `json:"mismatch_count" db:"mismatch_count"`
+	FeesUpdatedCount    int                     `json:"fees_updated_count" db:"fees_updated_count"`
+	GatewayFeeUSDCents  int64                   `json:"gateway_fee_usd_cents" db:"gateway_fee_usd_cents"`
+	NetAmountUSDCents   int64                   `json:"net_amount_usd_cents" db:"net_amount_usd_cents"`
+	Error               *string                 `json:"error,omitempty" db:"error"`
+	TriggeredBy         *int64                  `json:"triggered_by,omitempty" db:"triggered_by"`
+	StartedAt           time.Time               `json:"started_at" db:"started_at"`
+	CompletedAt         *time.Time              `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// ============================================================================
+// RECONCILIATION ITEM
+// ============================================================================
+
+type ReconciliationIssue string
+
+const (
+	// Captured at the gateway, but our payment record never reached captured
+	ReconciliationPendingLocally ReconciliationIssue = "pending_locally"
+	// Captured in our DB, but the gateway does not report it as captured
+	ReconciliationMissingInGateway ReconciliationIssue = "missing_in_gateway"
+	// Captured at the gateway for an order we have no payment record for
+	ReconciliationUnknownPayment ReconciliationIssue = "unknown_payment"
+	ReconciliationAmountMismatch ReconciliationIssue = "amount_mismatch"
+)
+
+type ReconciliationItem struct {
+	ID                    int64               `json:"id" db:"id"`
+	RunID                 int64               `json:"run_id" db:"run_id"`
+	PaymentID             *int64              `json:"payment_id,omitempty" db:"payment_id"`
+	GatewayPaymentID      *string             `json:"gateway_payment_id,omitempty" db:"gateway_payment_id"`
+	GatewayOrderID        *string             `json:"gateway_order_id,omitempty" db:"gateway_order_id"`
+	Issue                 ReconciliationIssue `json:"issue" db:"issue"`
+	LocalStatus           *string             `json:"local_status,omitempty" db:"local_status"`
+	GatewayStatus         *string             `json:"gateway_status,omitempty" db:"gateway_status"`
+	LocalAmountUSDCents   *int64              `json:"local_amount_usd_cents,omitempty" db:"local_amount_usd_cents"`
+	GatewayAmountUSDCents *int64              `json:"gateway_amount_usd_cents,omitempty" db:"gateway_amount_usd_cents"`
+	Details               *string             `json:"details,omitempty" db:"details"`
+	CreatedAt             time.Time           `json:"created_at" db:"created_at"`
+}
+
+type ReconciliationReport struct {
+	Run   *ReconciliationRun    `json:"run"`
+	Items []*ReconciliationItem `json:"items"`
+}
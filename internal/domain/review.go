@@ -0,0 +1,78 @@
+package domain
+
+import "time"
+
+// ============================================================================
+// REVIEW STATUS
+// ============================================================================
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+func (s ReviewStatus) IsValid() bool {
+	return s == ReviewStatusPending || s == ReviewStatusApproved || s == ReviewStatusRejected
+}
+
+// ============================================================================
+// TEMPLATE REVIEW
+// ============================================================================
+
+// TemplateReview is a rating left by a buyer of the template, matched to an
+// approved order placed with their email. Only approved reviews are shown
+// publicly or counted in the template's rating.
+type TemplateReview struct {
+	ID             int64        `json:"id" db:"id"`
+	TemplateID     int64        `json:"template_id" db:"template_id"`
+	OrderID        int64        `json:"order_id" db:"order_id"`
+	OrderItemID    int64        `json:"order_item_id" db:"order_item_id"`
+	CustomerID     *int64       `json:"customer_id,omitempty" db:"customer_id"`
+	CustomerEmail  string       `json:"customer_email" db:"customer_email"`
+	ReviewerName   string       `json:"reviewer_name" db:"reviewer_name"`
+	Rating         int          `json:"rating" db:"rating"`
+	Title          *string      `json:"title,omitempty" db:"title"`
+	Body           string       `json:"body" db:"body"`
+	Status         ReviewStatus `json:"status" db:"status"`
+	ModerationNote *string      `json:"moderation_note,omitempty" db:"moderation_note"`
+	ModeratedBy    *int64       `json:"moderated_by,omitempty" db:"moderated_by"`
+	ModeratedAt    *time.Time   `json:"moderated_at,omitempty" db:"moderated_at"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// PublicTemplateReview is what shoppers see of a review: no email, order
+// or moderation details.
+type PublicTemplateReview struct {
+	ID               int64     `json:"id"`
+	ReviewerName     string    `json:"reviewer_name"`
+	Rating           int       `json:"rating"`
+	Title            *string   `json:"title,omitempty"`
+	Body             string    `json:"body"`
+	VerifiedPurchase bool      `json:"verified_purchase"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (r *TemplateReview) Public() *PublicTemplateReview {
+	return &PublicTemplateReview{
+		ID:               r.ID,
+		ReviewerName:     r.ReviewerName,
+		Rating:           r.Rating,
+		Title:            r.Title,
+		Body:             r.Body,
+		VerifiedPurchase: true,
+		CreatedAt:        r.CreatedAt,
+	}
+}
+
+// RatingSummary aggregates a template's approved reviews. Distribution is
+// keyed by star rating, "1" to "5", with every key present.
+type RatingSummary struct {
+	TemplateID   int64          `json:"template_id"`
+	Average      float64        `json:"average"`
+	Count        int            `json:"count"`
+	Distribution map[string]int `json:"distribution"`
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ============================================================================
+// RISK SIGNALS
+// ============================================================================
+
+type RiskSignal string
+
+const (
+	RiskSignalEmailVelocity   RiskSignal = "email_velocity"
+	RiskSignalIPVelocity      RiskSignal = "ip_velocity"
+	RiskSignalCardVelocity    RiskSignal = "card_velocity"
+	RiskSignalCountryMismatch RiskSignal = "country_mismatch"
+	RiskSignalDisposableEmail RiskSignal = "disposable_email"
+	RiskSignalFailedPayments  RiskSignal = "failed_payments"
+)
+
+// MaxRiskScore caps the summed signal points.
+const MaxRiskScore = 100
+
+// ============================================================================
+// RISK REASONS
+// ============================================================================
+
+type RiskReason struct {
+	Signal RiskSignal `json:"signal"`
+	Points int        `json:"points"`
+	Detail string     `json:"detail"`
+}
+
+// RiskReasons is stored as a JSONB array on orders.risk_reasons
+type RiskReasons []RiskReason
+
+func (r RiskReasons) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *RiskReasons) Scan(value interface{}) error {
+	var data []byte
+	switch src := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("failed to scan RiskReasons: unexpected type %T", value)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// ============================================================================
+// RISK ASSESSMENT
+// ============================================================================
+
+type RiskAssessment struct {
+	OrderID int64       `json:"order_id"`
+	Score   int         `json:"score"`
+	Reasons RiskReasons `json:"reasons"`
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// ============================================================================
+// SETTING KEYS
+// ============================================================================
+
+const (
+	SettingDownloadExpiryDays  = "downloads.expiry_days"
+	SettingDownloadMaxPerToken = "downloads.max_per_token"
+	SettingDownloadProxy       = "downloads.proxy_enabled"
+	SettingDownloadCountPct    = "downloads.count_threshold_percent"
+	SettingDownloadVersion     = "downloads.version_policy"
+	SettingOrderAutoApprove    = "orders.auto_approve"
+	SettingStoreName           = "store.name"
+	SettingSupportEmail        = "store.support_email"
+	SettingTaxEnabled          = "tax.enabled"
+	SettingTaxRatePercent      = "tax.rate_percent"
+
+	SettingRiskVelocityWindowHours = "risk.velocity_window_hours"
+	SettingRiskVelocityMaxOrders   = "risk.velocity_max_orders"
+	SettingRiskMaxFailedPayments   = "risk.max_failed_payments"
+	SettingRiskDisposableDomains   = "risk.disposable_email_domains"
+)
+
+// Values of SettingDownloadVersion
+const (
+	VersionPolicyLatest    = "latest"
+	VersionPolicyPurchased = "purchased"
+)
+
+// ============================================================================
+// SETTING
+// ============================================================================
+
+type Setting struct {
+	Key         string    `json:"key" db:"key"`
+	Value       JSONValue `json:"value" db:"value"`
+	Schema      JSONMap   `json:"schema" db:"schema"`
+	Description *string   `json:"description,omitempty" db:"description"`
+	Version     int       `json:"version" db:"version"`
+	UpdatedBy   *int64    `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SettingUpdate is a requested change to a single setting.
+// ExpectedVersion of 0 skips the optimistic-lock check.
+type SettingUpdate struct {
+	Key             string    `json:"key"`
+	Value           JSONValue `json:"value"`
+	ExpectedVersion int       `json:"version"`
+}
+
+// ============================================================================
+// SETTING HISTORY
+// ============================================================================
+
+type SettingChange struct {
+	ID         int64     `json:"id" db:"id"`
+	SettingKey string    `json:"setting_key" db:"setting_key"`
+	OldValue   JSONValue `json:"old_value" db:"old_value"`
+	NewValue   JSONValue `json:"new_value" db:"new_value"`
+	Version    int       `json:"version" db:"version"`
+	ChangedBy  *int64    `json:"changed_by,omitempty" db:"changed_by"`
+	ChangedAt  time.Time `json:"changed_at" db:"changed_at"`
+}
@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN APPROVAL RULE HANDLER - Auto-approval rules for paid orders
+// ============================================================================
+
+type ApprovalRuleHandler struct {
+	approvalRuleService *service.ApprovalRuleService
+}
+
+func NewApprovalRuleHandler(approvalRuleService *service.ApprovalRuleService) *ApprovalRuleHandler {
+	return &ApprovalRuleHandler{
+		approvalRuleService: approvalRuleService,
+	}
+}
+
+type ApprovalRuleRequest struct {
+	Name        string                  `json:"name"`
+	Description *string                 `json:"description"`
+	RuleType    domain.ApprovalRuleType `json:"rule_type"`
+	Params      domain.JSONMap          `json:"params"`
+	IsActive    bool                    `json:"is_active"`
+	Priority    int                     `json:"priority"`
+}
+
+// GET /api/v1/admin/approval-rules?active_only=true
+func (h *ApprovalRuleHandler) GetAll(c *fiber.Ctx) error {
+	activeOnly := c.Query("active_only", "false") == "true"
+
+	rules, err := h.approvalRuleService.GetAllRules(c.Context(), activeOnly)
+	if err != nil {
+		logger.Error("Failed to get approval rules", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get approval rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"rules": rules,
+	})
+}
+
+// GET /api/v1/admin/approval-rules/:id
+func (h *ApprovalRuleHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rule ID",
+		})
+	}
+
+	rule, err := h.approvalRuleService.GetRule(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Approval rule not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"rule": rule,
+	})
+}
+
+// POST /api/v1/admin/approval-rules
+func (h *ApprovalRuleHandler) Create(c *fiber.Ctx) error {
+	var req ApprovalRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	rule := &domain.ApprovalRule{
+		Name:        req.Name,
+		Description: req.Description,
+		RuleType:    req.RuleType,
+		Params:      req.Params,
+		IsActive:    req.IsActive,
+		Priority:    req.Priority,
+	}
+
+	if err := h.approvalRuleService.CreateRule(c.Context(), rule, adminID); err != nil {
+		return h.ruleError(c, err, "Failed to create approval rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"rule": rule,
+	})
+}
+
+// PUT /api/v1/admin/approval-rules/:id
+func (h *ApprovalRuleHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rule ID",
+		})
+	}
+
+	var req ApprovalRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	rule := &domain.ApprovalRule{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		RuleType:    req.RuleType,
+		Params:      req.Params,
+		IsActive:    req.IsActive,
+		Priority:    req.Priority,
+	}
+
+	if err := h.approvalRuleService.UpdateRule(c.Context(), rule, adminID); err != nil {
+		return h.ruleError(c, err, "Failed to update approval rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"rule": rule,
+	})
+}
+
+// DELETE /api/v1/admin/approval-rules/:id
+func (h *ApprovalRuleHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rule ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.approvalRuleService.DeleteRule(c.Context(), id, adminID); err != nil {
+		return h.ruleError(c, err, "Failed to delete approval rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Approval rule deleted successfully",
+	})
+}
+
+// POST /api/v1/admin/approval-rules/dry-run/:orderId
+// Evaluates the active rules against an existing order without changing it.
+func (h *ApprovalRuleHandler) DryRun(c *fiber.Ctx) error {
+	orderID, err := strconv.ParseInt(c.Params("orderId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid order ID",
+		})
+	}
+
+	evaluation, err := h.approvalRuleService.DryRun(c.Context(), orderID)
+	if err != nil {
+		return h.ruleError(c, err, "Failed to evaluate approval rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"evaluation": evaluation,
+	})
+}
+
+func (h *ApprovalRuleHandler) ruleError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -47,6 +47,16 @@ func (h *BlogCategoryHandler) GetAll(c *fiber.Ctx) error {
 	return response.Paginated(c, categories, total, params.Page, params.Limit)
 }
 
+// GetTree returns every category, active or not, nested under its parent
+func (h *BlogCategoryHandler) GetTree(c *fiber.Ctx) error {
+	tree, err := h.categoryService.GetCategoryTree(c.Context(), false)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessData(c, tree)
+}
+
 func (h *BlogCategoryHandler) GetByID(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
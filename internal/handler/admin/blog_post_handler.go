@@ -8,6 +8,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/merraki/merraki-backend/internal/domain"
 	"github.com/merraki/merraki-backend/internal/middleware"
+	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
 	"github.com/merraki/merraki-backend/internal/pkg/logger"
 	"github.com/merraki/merraki-backend/internal/pkg/response"
 	"github.com/merraki/merraki-backend/internal/pkg/validator"
@@ -24,38 +25,42 @@ func NewBlogPostHandler(postService *service.BlogPostService) *BlogPostHandler {
 }
 
 type CreateBlogPostRequest struct {
-	Title              string   `json:"title" validate:"required"`
-	Slug               string   `json:"slug"`
-	Excerpt            string   `json:"excerpt"`
-	Content            string   `json:"content" validate:"required"`
-	FeaturedImageURL   string   `json:"featured_image_url"`
-	AuthorID           *int64   `json:"author_id"`
-	CategoryID         *int64   `json:"category_id"`
-	Tags               []string `json:"tags"`
-	MetaTitle          string   `json:"meta_title"`
-	MetaDescription    string   `json:"meta_description"`
-	MetaKeywords       []string `json:"meta_keywords"`
-	Status             string   `json:"status" validate:"required,oneof=draft published archived"`
-	IsFeatured         bool     `json:"is_featured"`
-	ReadingTimeMinutes int      `json:"reading_time_minutes"`
+	Title              string     `json:"title" validate:"required"`
+	Slug               string     `json:"slug"`
+	Excerpt            string     `json:"excerpt"`
+	Content            string     `json:"content" validate:"required"`
+	FeaturedImageURL   string     `json:"featured_image_url"`
+	AuthorID           *int64     `json:"author_id"`
+	CategoryID         *int64     `json:"category_id"`
+	Tags               []string   `json:"tags"`
+	MetaTitle          string     `json:"meta_title"`
+	MetaDescription    string     `json:"meta_description"`
+	MetaKeywords       []string   `json:"meta_keywords"`
+	Status             string     `json:"status" validate:"required,oneof=draft scheduled published archived"`
+	IsFeatured         bool       `json:"is_featured"`
+	ReadingTimeMinutes int        `json:"reading_time_minutes"`
+	PublishedAt        *time.Time `json:"published_at"` // required when scheduling
+	AnnounceOnPublish  bool       `json:"announce_on_publish"`
 }
 
 // ✅ FIX: Add UpdateBlogPostRequest struct
 type UpdateBlogPostRequest struct {
-	Title              string   `json:"title" validate:"required"`
-	Slug               string   `json:"slug"`
-	Excerpt            string   `json:"excerpt"`
-	Content            string   `json:"content" validate:"required"`
-	FeaturedImageURL   string   `json:"featured_image_url"`
-	AuthorID           *int64   `json:"author_id"`
-	CategoryID         *int64   `json:"category_id"`
-	Tags               []string `json:"tags"`
-	MetaTitle          string   `json:"meta_title"`
-	MetaDescription    string   `json:"meta_description"`
-	MetaKeywords       []string `json:"meta_keywords"`
-	Status             string   `json:"status" validate:"required,oneof=draft published archived"`
-	IsFeatured         bool     `json:"is_featured"`
-	ReadingTimeMinutes int      `json:"reading_time_minutes"`
+	Title              string     `json:"title" validate:"required"`
+	Slug               string     `json:"slug"`
+	Excerpt            string     `json:"excerpt"`
+	Content            string     `json:"content" validate:"required"`
+	FeaturedImageURL   string     `json:"featured_image_url"`
+	AuthorID           *int64     `json:"author_id"`
+	CategoryID         *int64     `json:"category_id"`
+	Tags               []string   `json:"tags"`
+	MetaTitle          string     `json:"meta_title"`
+	MetaDescription    string     `json:"meta_description"`
+	MetaKeywords       []string   `json:"meta_keywords"`
+	Status             string     `json:"status" validate:"required,oneof=draft scheduled published archived"`
+	IsFeatured         bool       `json:"is_featured"`
+	ReadingTimeMinutes int        `json:"reading_time_minutes"`
+	PublishedAt        *time.Time `json:"published_at"` // required when scheduling
+	AnnounceOnPublish  bool       `json:"announce_on_publish"`
 }
 
 func (h *BlogPostHandler) GetAll(c *fiber.Ctx) error {
@@ -175,9 +180,12 @@ func (h *BlogPostHandler) Create(c *fiber.Ctx) error {
 	adminID := middleware.GetAdminID(c)
 
 	var publishedAt *time.Time
-	if req.Status == "published" {
+	switch req.Status {
+	case "published":
 		now := time.Now()
 		publishedAt = &now
+	case "scheduled":
+		publishedAt = req.PublishedAt
 	}
 
 	// Ensure arrays are not nil
@@ -233,6 +241,7 @@ func (h *BlogPostHandler) Create(c *fiber.Ctx) error {
 		IsFeatured:         req.IsFeatured,
 		ReadingTimeMinutes: readingTime,
 		PublishedAt:        publishedAt,
+		AnnounceOnPublish:  req.AnnounceOnPublish,
 	}
 
 	if err := h.postService.CreatePost(c.Context(), post, adminID); err != nil {
@@ -322,6 +331,7 @@ func (h *BlogPostHandler) Update(c *fiber.Ctx) error {
 		Status:             req.Status,
 		IsFeatured:         req.IsFeatured,
 		ReadingTimeMinutes: readingTime,
+		AnnounceOnPublish:  req.AnnounceOnPublish,
 	}
 
 	// ✅ Handle published_at
@@ -330,6 +340,8 @@ func (h *BlogPostHandler) Update(c *fiber.Ctx) error {
 		post.PublishedAt = &now
 	} else if req.Status == "published" && existingPost.PublishedAt != nil {
 		post.PublishedAt = existingPost.PublishedAt
+	} else if req.Status == "scheduled" {
+		post.PublishedAt = req.PublishedAt
 	} else if req.Status != "published" {
 		post.PublishedAt = nil
 	}
@@ -363,6 +375,30 @@ func (h *BlogPostHandler) Patch(c *fiber.Ctx) error {
 	return response.Success(c, "Blog post updated successfully", nil)
 }
 
+// UploadFeaturedImage - multipart "image"; replaces featured_image_url with
+// the widest rendition
+func (h *BlogPostHandler) UploadFeaturedImage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return response.Error(c, fiber.NewError(400, "Invalid post ID"))
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		return response.Error(c, apperrors.New("IMAGE_REQUIRED", "Image is required", 400))
+	}
+
+	adminID := middleware.GetAdminID(c)
+
+	post, err := h.postService.UploadFeaturedImage(c.Context(), int64(id), file, adminID)
+	if err != nil {
+		logger.Error("Failed to upload featured image", zap.Error(err))
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, "Featured image uploaded successfully", post)
+}
+
 func (h *BlogPostHandler) Delete(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
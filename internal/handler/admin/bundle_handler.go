@@ -0,0 +1,290 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN BUNDLE HANDLER - Template bundles and their images
+// ============================================================================
+
+type BundleHandler struct {
+	bundleService *service.BundleService
+}
+
+func NewBundleHandler(bundleService *service.BundleService) *BundleHandler {
+	return &BundleHandler{
+		bundleService: bundleService,
+	}
+}
+
+// GET /api/v1/admin/bundles?status=active&template_id=3&search=&page=1&limit=20
+func (h *BundleHandler) GetAll(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := make(map[string]interface{})
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = domain.TemplateStatus(status)
+	}
+
+	if templateID, err := strconv.ParseInt(c.Query("template_id"), 10, 64); err == nil {
+		filters["template_id"] = templateID
+	}
+
+	if search := c.Query("search"); search != "" {
+		filters["search"] = search
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		filters["sort"] = sort
+	}
+
+	bundles, total, err := h.bundleService.GetAll(c.Context(), filters, page, limit)
+	if err != nil {
+		logger.Error("Failed to get bundles", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get bundles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"bundles": bundles,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// GET /api/v1/admin/bundles/:id
+func (h *BundleHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid bundle ID",
+		})
+	}
+
+	bundle, err := h.bundleService.Get(c.Context(), id)
+	if err != nil {
+		return h.bundleError(c, err, "Failed to get bundle")
+	}
+
+	return c.JSON(fiber.Map{
+		"bundle": bundle,
+	})
+}
+
+// POST /api/v1/admin/bundles
+func (h *BundleHandler) Create(c *fiber.Ctx) error {
+	var req service.BundleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	bundle, err := h.bundleService.Create(c.Context(), req, adminID)
+	if err != nil {
+		return h.bundleError(c, err, "Failed to create bundle")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"bundle": bundle,
+	})
+}
+
+// PUT /api/v1/admin/bundles/:id
+func (h *BundleHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid bundle ID",
+		})
+	}
+
+	var req service.BundleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	bundle, err := h.bundleService.Update(c.Context(), id, req, adminID)
+	if err != nil {
+		return h.bundleError(c, err, "Failed to update bundle")
+	}
+
+	return c.JSON(fiber.Map{
+		"bundle": bundle,
+	})
+}
+
+// DELETE /api/v1/admin/bundles/:id
+func (h *BundleHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid bundle ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.bundleService.Delete(c.Context(), id, adminID); err != nil {
+		return h.bundleError(c, err, "Failed to delete bundle")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Bundle deleted successfully",
+	})
+}
+
+// ============================================================================
+// IMAGES
+// ============================================================================
+
+// POST /api/v1/admin/bundles/:id/images
+func (h *BundleHandler) AddImage(c *fiber.Ctx) error {
+	bundleID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid bundle ID",
+		})
+	}
+
+	var req AddImageRequest
+	if err := c.BodyParser(&req); err != nil || req.URL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	image := &domain.BundleImage{
+		BundleID:     bundleID,
+		URL:          req.URL,
+		AltText:      req.AltText,
+		DisplayOrder: req.DisplayOrder,
+		IsPrimary:    req.IsPrimary,
+	}
+
+	if err := h.bundleService.AddImage(c.Context(), image, adminID); err != nil {
+		return h.bundleError(c, err, "Failed to add image")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"image": image,
+	})
+}
+
+// POST /api/v1/admin/bundles/:id/images/upload
+// multipart: image (file), alt_text, display_order, is_primary
+func (h *BundleHandler) UploadImage(c *fiber.Ctx) error {
+	bundleID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid bundle ID",
+		})
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Image is required",
+		})
+	}
+
+	image := &domain.BundleImage{
+		IsPrimary: c.FormValue("is_primary") == "true",
+	}
+	if altText := c.FormValue("alt_text"); altText != "" {
+		image.AltText = &altText
+	}
+	if order := c.FormValue("display_order"); order != "" {
+		if image.DisplayOrder, err = strconv.Atoi(order); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid display_order",
+			})
+		}
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.bundleService.UploadImage(c.Context(), bundleID, file, image, adminID); err != nil {
+		return h.bundleError(c, err, "Failed to upload image")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"image": image,
+	})
+}
+
+// DELETE /api/v1/admin/bundles/images/:id
+func (h *BundleHandler) DeleteImage(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid image ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.bundleService.DeleteImage(c.Context(), id, adminID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Image not found",
+			})
+		}
+		return h.bundleError(c, err, "Failed to delete image")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Image deleted successfully",
+	})
+}
+
+func (h *BundleHandler) bundleError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Bundle not found",
+		})
+	case errors.Is(err, domain.ErrDuplicateEntry):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
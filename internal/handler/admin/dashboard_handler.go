@@ -90,17 +90,3 @@ func (h *DashboardHandler) GlobalSearch(c *fiber.Ctx) error {
 		"results": []interface{}{},
 	})
 }
-
-// GetSettings — placeholder for admin settings.
-// GET /api/v1/admin/dashboard/settings
-func (h *DashboardHandler) GetSettings(c *fiber.Ctx) error {
-	return response.SuccessData(c, fiber.Map{
-		"settings": fiber.Map{},
-	})
-}
-
-// UpdateSettings — placeholder.
-// PUT /api/v1/admin/dashboard/settings
-func (h *DashboardHandler) UpdateSettings(c *fiber.Ctx) error {
-	return response.Success(c, "Settings updated successfully", nil)
-}
\ No newline at end of file
@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN DISPUTE HANDLER - Payment disputes and evidence
+// ============================================================================
+
+type DisputeHandler struct {
+	disputeService *service.DisputeService
+}
+
+func NewDisputeHandler(disputeService *service.DisputeService) *DisputeHandler {
+	return &DisputeHandler{
+		disputeService: disputeService,
+	}
+}
+
+// GET /api/v1/admin/disputes?status=open&order_id=12&page=1&limit=20
+func (h *DisputeHandler) GetAll(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := make(map[string]interface{})
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = domain.DisputeStatus(status)
+	}
+
+	if orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64); err == nil {
+		filters["order_id"] = orderID
+	}
+
+	disputes, total, err := h.disputeService.GetAll(c.Context(), filters, page, limit)
+	if err != nil {
+		logger.Error("Failed to get disputes", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get disputes",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"disputes": disputes,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GET /api/v1/admin/disputes/:id
+func (h *DisputeHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid dispute ID",
+		})
+	}
+
+	dispute, err := h.disputeService.Get(c.Context(), id)
+	if err != nil {
+		return h.disputeError(c, err, "Failed to get dispute")
+	}
+
+	return c.JSON(fiber.Map{
+		"dispute": dispute,
+	})
+}
+
+type AddEvidenceRequest struct {
+	Notes       string  `json:"notes"`
+	DocumentURL *string `json:"document_url"`
+}
+
+// POST /api/v1/admin/disputes/:id/evidence
+func (h *DisputeHandler) AddEvidence(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid dispute ID",
+		})
+	}
+
+	var req AddEvidenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	evidence, err := h.disputeService.AddEvidence(c.Context(), id, adminID, req.Notes, req.DocumentURL)
+	if err != nil {
+		return h.disputeError(c, err, "Failed to add dispute evidence")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"evidence": evidence,
+	})
+}
+
+func (h *DisputeHandler) disputeError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dispute not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -15,16 +15,18 @@ import (
 // ============================================================================
 
 type OrderHandler struct {
-	orderService *service.OrderService
+	orderService   *service.OrderService
+	licenseService *service.LicenseService
 }
 
 type markPaidRequest struct {
 	GatewayOrderID string `json:"gateway_order_id"`
 }
 
-func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+func NewOrderHandler(orderService *service.OrderService, licenseService *service.LicenseService) *OrderHandler {
 	return &OrderHandler{
-		orderService: orderService,
+		orderService:   orderService,
+		licenseService: licenseService,
 	}
 }
 
@@ -32,7 +34,7 @@ func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
 // GET ALL ORDERS
 // ============================================================================
 
-// GET /api/v1/admin/orders?status=pending&page=1&limit=20
+// GET /api/v1/admin/orders?status=pending&page=1&limit=20&min_risk_score=50&sort=risk
 func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
 	// Parse query parameters
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -56,6 +58,14 @@ func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
 		filters["email"] = email
 	}
 
+	if minRisk, err := strconv.Atoi(c.Query("min_risk_score")); err == nil {
+		filters["min_risk_score"] = minRisk
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		filters["sort"] = sort
+	}
+
 	orders, total, err := h.orderService.GetAllOrders(
 		c.Context(),
 		filters,
@@ -101,9 +111,15 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 	// Get state transitions
 	transitions, _ := h.orderService.GetOrderTransitions(c.Context(), id)
 
+	licenses, err := h.licenseService.GetByOrderID(c.Context(), id)
+	if err != nil {
+		logger.Error("Failed to load order licenses", zap.Int64("order_id", id), zap.Error(err))
+	}
+
 	return c.JSON(fiber.Map{
 		"order":       order,
 		"transitions": transitions,
+		"licenses":    licenses,
 	})
 }
 
@@ -201,7 +217,7 @@ func (h *OrderHandler) RejectOrder(c *fiber.Ctx) error {
 // GET ORDERS PENDING REVIEW
 // ============================================================================
 
-// GET /api/v1/admin/orders/pending-review?page=1&limit=20
+// GET /api/v1/admin/orders/pending-review?page=1&limit=20&sort=risk
 func (h *OrderHandler) GetPendingReviewOrders(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
@@ -215,6 +231,11 @@ func (h *OrderHandler) GetPendingReviewOrders(c *fiber.Ctx) error {
 
 	filters := map[string]interface{}{
 		"status": domain.OrderStatusAdminReview,
+		"sort":   c.Query("sort"),
+	}
+
+	if minRisk, err := strconv.Atoi(c.Query("min_risk_score")); err == nil {
+		filters["min_risk_score"] = minRisk
 	}
 
 	orders, total, err := h.orderService.GetAllOrders(
@@ -315,4 +336,37 @@ func (h *OrderHandler) DeleteOrder(c *fiber.Ctx) error {
 		"success": true,
 		"message": "Order deleted successfully",
 	})
-}
\ No newline at end of file
+}
+// ============================================================================
+// REASSESS RISK
+// ============================================================================
+
+// POST /api/v1/admin/orders/:id/risk/reassess
+func (h *OrderHandler) ReassessRisk(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid order ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	assessment, err := h.orderService.ReassessRisk(c.Context(), id, adminID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Order not found",
+			})
+		}
+
+		logger.Error("Failed to reassess order risk", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to reassess order risk",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"risk": assessment,
+	})
+}
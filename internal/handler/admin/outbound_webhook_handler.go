@@ -0,0 +1,256 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN OUTBOUND WEBHOOK HANDLER - Subscriptions and delivery log
+// ============================================================================
+
+type OutboundWebhookHandler struct {
+	webhookService *service.OutboundWebhookService
+}
+
+func NewOutboundWebhookHandler(webhookService *service.OutboundWebhookService) *OutboundWebhookHandler {
+	return &OutboundWebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+type WebhookSubscriptionRequest struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"` // optional; generated on create, kept on update
+	EventTypes []string `json:"event_types"`
+	IsActive   bool     `json:"is_active"`
+}
+
+// GET /api/v1/admin/outbound-webhooks
+func (h *OutboundWebhookHandler) GetAll(c *fiber.Ctx) error {
+	subs, err := h.webhookService.GetAllSubscriptions(c.Context())
+	if err != nil {
+		logger.Error("Failed to get webhook subscriptions", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get webhook subscriptions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"subscriptions": subs,
+		"events":        domain.OutboundEvents,
+	})
+}
+
+// GET /api/v1/admin/outbound-webhooks/:id
+func (h *OutboundWebhookHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid subscription ID",
+		})
+	}
+
+	sub, err := h.webhookService.GetSubscription(c.Context(), id)
+	if err != nil {
+		return h.webhookError(c, err, "Failed to get webhook subscription")
+	}
+
+	return c.JSON(fiber.Map{
+		"subscription": sub,
+	})
+}
+
+// POST /api/v1/admin/outbound-webhooks
+// The signing secret is only returned in this response.
+func (h *OutboundWebhookHandler) Create(c *fiber.Ctx) error {
+	var req WebhookSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	sub := &domain.WebhookSubscription{
+		Name:       req.Name,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		IsActive:   req.IsActive,
+	}
+
+	if err := h.webhookService.CreateSubscription(c.Context(), sub, adminID); err != nil {
+		return h.webhookError(c, err, "Failed to create webhook subscription")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"subscription": sub,
+		"secret":       sub.Secret,
+	})
+}
+
+// PUT /api/v1/admin/outbound-webhooks/:id
+func (h *OutboundWebhookHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid subscription ID",
+		})
+	}
+
+	var req WebhookSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	sub := &domain.WebhookSubscription{
+		ID:         id,
+		Name:       req.Name,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		IsActive:   req.IsActive,
+	}
+
+	if err := h.webhookService.UpdateSubscription(c.Context(), sub, adminID); err != nil {
+		return h.webhookError(c, err, "Failed to update webhook subscription")
+	}
+
+	return c.JSON(fiber.Map{
+		"subscription": sub,
+	})
+}
+
+// DELETE /api/v1/admin/outbound-webhooks/:id
+func (h *OutboundWebhookHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid subscription ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.webhookService.DeleteSubscription(c.Context(), id, adminID); err != nil {
+		return h.webhookError(c, err, "Failed to delete webhook subscription")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Webhook subscription deleted successfully",
+	})
+}
+
+// GET /api/v1/admin/outbound-webhooks/deliveries?subscription_id=1&status=failed&event_type=order.paid&page=1&limit=20
+func (h *OutboundWebhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := make(map[string]interface{})
+
+	if subID, err := strconv.ParseInt(c.Query("subscription_id"), 10, 64); err == nil {
+		filters["subscription_id"] = subID
+	}
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = domain.WebhookDeliveryStatus(status)
+	}
+
+	if eventType := c.Query("event_type"); eventType != "" {
+		filters["event_type"] = eventType
+	}
+
+	deliveries, total, err := h.webhookService.GetDeliveries(c.Context(), filters, page, limit)
+	if err != nil {
+		logger.Error("Failed to get webhook deliveries", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get webhook deliveries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"deliveries": deliveries,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	})
+}
+
+// GET /api/v1/admin/outbound-webhooks/deliveries/:id
+func (h *OutboundWebhookHandler) GetDelivery(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid delivery ID",
+		})
+	}
+
+	delivery, err := h.webhookService.GetDelivery(c.Context(), id)
+	if err != nil {
+		return h.webhookError(c, err, "Failed to get webhook delivery")
+	}
+
+	return c.JSON(fiber.Map{
+		"delivery": delivery,
+	})
+}
+
+// POST /api/v1/admin/outbound-webhooks/deliveries/:id/redeliver
+func (h *OutboundWebhookHandler) Redeliver(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid delivery ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	delivery, err := h.webhookService.Redeliver(c.Context(), id, adminID)
+	if err != nil {
+		return h.webhookError(c, err, "Failed to queue webhook redelivery")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Webhook delivery queued",
+		"delivery": delivery,
+	})
+}
+
+func (h *OutboundWebhookHandler) webhookError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,219 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN PRICE RULE HANDLER - Scheduled sales and template price history
+// ============================================================================
+
+type PriceRuleHandler struct {
+	priceRuleService *service.PriceRuleService
+}
+
+func NewPriceRuleHandler(priceRuleService *service.PriceRuleService) *PriceRuleHandler {
+	return &PriceRuleHandler{
+		priceRuleService: priceRuleService,
+	}
+}
+
+// GET /api/v1/admin/price-rules?status=active&template_id=3&category_id=2&page=1&limit=20
+func (h *PriceRuleHandler) GetAll(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := make(map[string]interface{})
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = domain.PriceRuleStatus(status)
+	}
+
+	if templateID, err := strconv.ParseInt(c.Query("template_id"), 10, 64); err == nil {
+		filters["template_id"] = templateID
+	}
+
+	if categoryID, err := strconv.ParseInt(c.Query("category_id"), 10, 64); err == nil {
+		filters["category_id"] = categoryID
+	}
+
+	rules, total, err := h.priceRuleService.GetAll(c.Context(), filters, page, limit)
+	if err != nil {
+		logger.Error("Failed to get price rules", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get price rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"price_rules": rules,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+// GET /api/v1/admin/price-rules/:id
+func (h *PriceRuleHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid price rule ID",
+		})
+	}
+
+	rule, err := h.priceRuleService.Get(c.Context(), id)
+	if err != nil {
+		return h.priceRuleError(c, err, "Failed to get price rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"price_rule": rule,
+	})
+}
+
+// POST /api/v1/admin/price-rules
+func (h *PriceRuleHandler) Create(c *fiber.Ctx) error {
+	var req service.PriceRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	rule, err := h.priceRuleService.Create(c.Context(), req, adminID)
+	if err != nil {
+		return h.priceRuleError(c, err, "Failed to create price rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"price_rule": rule,
+	})
+}
+
+// PUT /api/v1/admin/price-rules/:id
+func (h *PriceRuleHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid price rule ID",
+		})
+	}
+
+	var req service.PriceRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	rule, err := h.priceRuleService.Update(c.Context(), id, req, adminID)
+	if err != nil {
+		return h.priceRuleError(c, err, "Failed to update price rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"price_rule": rule,
+	})
+}
+
+// POST /api/v1/admin/price-rules/:id/cancel
+func (h *PriceRuleHandler) Cancel(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid price rule ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	rule, err := h.priceRuleService.Cancel(c.Context(), id, adminID)
+	if err != nil {
+		return h.priceRuleError(c, err, "Failed to cancel price rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"price_rule": rule,
+	})
+}
+
+// GET /api/v1/admin/templates/:id/price-history?page=1&limit=50
+func (h *PriceRuleHandler) GetPriceHistory(c *fiber.Ctx) error {
+	templateID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	history, total, err := h.priceRuleService.GetPriceHistory(c.Context(), templateID, page, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Template not found",
+			})
+		}
+		logger.Error("Failed to get price history", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get price history",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"history": history,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+func (h *PriceRuleHandler) priceRuleError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Price rule not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidStateTransition):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
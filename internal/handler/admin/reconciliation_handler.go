@@ -0,0 +1,151 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN RECONCILIATION HANDLER - Gateway reconciliation reports
+// ============================================================================
+
+type ReconciliationHandler struct {
+	reconService *service.ReconciliationService
+}
+
+func NewReconciliationHandler(reconService *service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconService: reconService,
+	}
+}
+
+// GET /api/v1/admin/reconciliation?page=1&limit=20
+func (h *ReconciliationHandler) GetRuns(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	runs, total, err := h.reconService.GetRuns(c.Context(), page, limit)
+	if err != nil {
+		logger.Error("Failed to get reconciliation runs", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get reconciliation runs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"runs":  runs,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GET /api/v1/admin/reconciliation/:id
+func (h *ReconciliationHandler) GetReport(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid run ID",
+		})
+	}
+
+	report, err := h.reconService.GetReport(c.Context(), id)
+	if err != nil {
+		return h.reconError(c, err, "Failed to get reconciliation report")
+	}
+
+	return c.JSON(fiber.Map{
+		"report": report,
+	})
+}
+
+// GET /api/v1/admin/reconciliation/:id/export.csv
+func (h *ReconciliationHandler) ExportCSV(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid run ID",
+		})
+	}
+
+	data, err := h.reconService.ExportCSV(c.Context(), id)
+	if err != nil {
+		return h.reconError(c, err, "Failed to export reconciliation report")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="reconciliation-%d.csv"`, id))
+	return c.Send(data)
+}
+
+type RunReconciliationRequest struct {
+	Date string `json:"date"` // YYYY-MM-DD, defaults to yesterday (UTC)
+}
+
+// POST /api/v1/admin/reconciliation/run
+func (h *ReconciliationHandler) Run(c *fiber.Ctx) error {
+	var req RunReconciliationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	day := time.Now().UTC().AddDate(0, 0, -1)
+	if req.Date != "" {
+		parsed, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid date, expected YYYY-MM-DD",
+			})
+		}
+		day = parsed
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	run, err := h.reconService.Run(c.Context(), day, &adminID)
+	if err != nil {
+		if run != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"error": "Reconciliation failed",
+				"run":   run,
+			})
+		}
+		return h.reconError(c, err, "Failed to run reconciliation")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"run": run,
+	})
+}
+
+func (h *ReconciliationHandler) reconError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Reconciliation run not found",
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,163 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN REVIEW HANDLER - Moderation of template reviews
+// ============================================================================
+
+type ReviewHandler struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// GET /api/v1/admin/reviews?status=pending&template_id=3&rating=1&page=1&limit=20
+func (h *ReviewHandler) GetAll(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := make(map[string]interface{})
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = domain.ReviewStatus(status)
+	}
+
+	if templateID, err := strconv.ParseInt(c.Query("template_id"), 10, 64); err == nil {
+		filters["template_id"] = templateID
+	}
+
+	if rating, err := strconv.Atoi(c.Query("rating")); err == nil {
+		filters["rating"] = rating
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		filters["sort"] = sort
+	}
+
+	reviews, total, err := h.reviewService.GetAll(c.Context(), filters, page, limit)
+	if err != nil {
+		logger.Error("Failed to get reviews", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get reviews",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"reviews": reviews,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// GET /api/v1/admin/reviews/:id
+func (h *ReviewHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid review ID",
+		})
+	}
+
+	review, err := h.reviewService.Get(c.Context(), id)
+	if err != nil {
+		return h.reviewError(c, err, "Failed to get review")
+	}
+
+	return c.JSON(fiber.Map{
+		"review": review,
+	})
+}
+
+type ModerateReviewRequest struct {
+	Status domain.ReviewStatus `json:"status"`
+	Note   string              `json:"note"`
+}
+
+// PUT /api/v1/admin/reviews/:id/moderate
+func (h *ReviewHandler) Moderate(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid review ID",
+		})
+	}
+
+	var req ModerateReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	review, err := h.reviewService.Moderate(c.Context(), id, req.Status, req.Note, adminID)
+	if err != nil {
+		return h.reviewError(c, err, "Failed to moderate review")
+	}
+
+	return c.JSON(fiber.Map{
+		"review": review,
+	})
+}
+
+// DELETE /api/v1/admin/reviews/:id
+func (h *ReviewHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid review ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.reviewService.Delete(c.Context(), id, adminID); err != nil {
+		return h.reviewError(c, err, "Failed to delete review")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Review deleted successfully",
+	})
+}
+
+func (h *ReviewHandler) reviewError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Review not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/response"
+	"github.com/merraki/merraki-backend/internal/service"
+)
+
+// ============================================================================
+// ADMIN SETTINGS HANDLER - Runtime configuration
+// ============================================================================
+
+type SettingsHandler struct {
+	settingsService *service.SettingsService
+}
+
+func NewSettingsHandler(settingsService *service.SettingsService) *SettingsHandler {
+	return &SettingsHandler{settingsService: settingsService}
+}
+
+// GET /api/v1/admin/settings
+func (h *SettingsHandler) GetAll(c *fiber.Ctx) error {
+	settings, err := h.settingsService.GetAll(c.Context())
+	if err != nil {
+		return response.Error(c, err)
+	}
+	return response.SuccessData(c, fiber.Map{
+		"settings": settings,
+	})
+}
+
+// GET /api/v1/admin/settings/:key
+func (h *SettingsHandler) Get(c *fiber.Ctx) error {
+	setting, err := h.settingsService.Get(c.Context(), c.Params("key"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+	return response.SuccessData(c, setting)
+}
+
+type updateSettingsRequest struct {
+	Settings []*domain.SettingUpdate `json:"settings"`
+}
+
+// PUT /api/v1/admin/settings
+// Body: {"settings": [{"key": "downloads.max_per_token", "value": 10, "version": 1}]}
+func (h *SettingsHandler) Update(c *fiber.Ctx) error {
+	var req updateSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.NewError(400, "Invalid request body"))
+	}
+
+	updated, err := h.settingsService.Update(c.Context(), req.Settings, c.Locals("admin_id").(int64))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, "Settings updated successfully", fiber.Map{
+		"settings": updated,
+	})
+}
+
+type updateSettingRequest struct {
+	Value   domain.JSONValue `json:"value"`
+	Version int              `json:"version"`
+}
+
+// PUT /api/v1/admin/settings/:key
+func (h *SettingsHandler) UpdateOne(c *fiber.Ctx) error {
+	var req updateSettingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.NewError(400, "Invalid request body"))
+	}
+
+	updated, err := h.settingsService.Update(c.Context(), []*domain.SettingUpdate{{
+		Key:             c.Params("key"),
+		Value:           req.Value,
+		ExpectedVersion: req.Version,
+	}}, c.Locals("admin_id").(int64))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, "Setting updated successfully", updated[0])
+}
+
+// GET /api/v1/admin/settings/history?key=store.name&page=1&limit=20
+func (h *SettingsHandler) GetHistory(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	params := &domain.PaginationParams{Page: page, Limit: limit}
+	params.Validate()
+
+	key := c.Query("key", c.Params("key"))
+
+	changes, total, err := h.settingsService.GetHistory(c.Context(), key, params.Limit, params.GetOffset())
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Paginated(c, changes, total, params.Page, params.Limit)
+}
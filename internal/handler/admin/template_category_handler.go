@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -45,6 +46,26 @@ func (h *CategoryHandler) GetAllCategories(c *fiber.Ctx) error {
 	})
 }
 
+// ============================================================================
+// GET CATEGORY TREE
+// ============================================================================
+
+// GET /api/v1/admin/categories/tree
+func (h *CategoryHandler) GetCategoryTree(c *fiber.Ctx) error {
+	activeOnly := c.Query("active_only", "false") == "true"
+
+	tree, err := h.categoryService.GetCategoryTree(c.Context(), activeOnly)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get categories",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"categories": tree,
+	})
+}
+
 // ============================================================================
 // GET CATEGORY BY ID
 // ============================================================================
@@ -118,6 +139,12 @@ func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
 			})
 		}
 		
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create category",
 		})
@@ -173,6 +200,18 @@ func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
 			})
 		}
 		
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		if errors.Is(err, domain.ErrVersionConflict) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update category",
 		})
@@ -211,4 +250,98 @@ func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
 		"success": true,
 		"message": "Category deleted successfully",
 	})
+}
+
+// ============================================================================
+// MOVE CATEGORY
+// ============================================================================
+
+type MoveCategoryRequest struct {
+	ParentID *int64 `json:"parent_id"`
+	Position int    `json:"position"`
+}
+
+// PUT /api/v1/admin/categories/:id/move
+func (h *CategoryHandler) MoveCategory(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid category ID",
+		})
+	}
+
+	var req MoveCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.categoryService.MoveCategory(c.Context(), id, req.ParentID, req.Position, adminID); err != nil {
+		return categoryTreeError(c, err, "Failed to move category")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Category moved successfully",
+	})
+}
+
+// ============================================================================
+// REORDER CATEGORIES
+// ============================================================================
+
+type ReorderCategoriesRequest struct {
+	ParentID    *int64  `json:"parent_id"`
+	CategoryIDs []int64 `json:"category_ids"`
+}
+
+// PUT /api/v1/admin/categories/reorder
+func (h *CategoryHandler) ReorderCategories(c *fiber.Ctx) error {
+	var req ReorderCategoriesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if len(req.CategoryIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "category_ids is required",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err := h.categoryService.ReorderCategories(c.Context(), req.ParentID, req.CategoryIDs, adminID); err != nil {
+		return categoryTreeError(c, err, "Failed to reorder categories")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Categories reordered successfully",
+	})
+}
+
+func categoryTreeError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Category not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrVersionConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
 }
\ No newline at end of file
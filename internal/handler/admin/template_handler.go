@@ -1,10 +1,13 @@
 package admin
 
 import (
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/merraki/merraki-backend/internal/domain"
+	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
 	"github.com/merraki/merraki-backend/internal/pkg/logger"
 	"github.com/merraki/merraki-backend/internal/service"
 	"go.uber.org/zap"
@@ -16,16 +19,19 @@ import (
 
 type TemplateHandler struct {
 	templateService *service.TemplateService
-	storageService  *service.StorageService
+	versionService  *service.TemplateVersionService
+	previewService  *service.TemplatePreviewService
 }
 
 func NewTemplateHandler(
 	templateService *service.TemplateService,
-	storageService *service.StorageService,
+	versionService *service.TemplateVersionService,
+	previewService *service.TemplatePreviewService,
 ) *TemplateHandler {
 	return &TemplateHandler{
 		templateService: templateService,
-		storageService:  storageService,
+		versionService:  versionService,
+		previewService:  previewService,
 	}
 }
 
@@ -37,6 +43,22 @@ func stringValue(s *string) string {
 	return *s
 }
 
+// templateWriteError reports client errors from a template write, such as a
+// taken slug or a publish time in the past, with their own status
+func (h *TemplateHandler) templateWriteError(c *fiber.Ctx, err error, message string) error {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) && appErr.Status < fiber.StatusInternalServerError {
+		return c.Status(appErr.Status).JSON(fiber.Map{
+			"error": appErr.Message,
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
 // ============================================================================
 // GET ALL TEMPLATES (ADMIN)
 // ============================================================================
@@ -128,17 +150,20 @@ type CreateTemplateRequest struct {
 	PriceUSDCents     int64                 `json:"price_usd_cents" validate:"required,min=0"`
 	SalePriceUSDCents *int64                `json:"sale_price_usd_cents"`
 	FileURL           *string               `json:"file_url"`
-	FileSizeMB        *float64              `json:"file_size_mb"`
-	FileFormat        *string               `json:"file_format"`
 	PreviewURL        *string               `json:"preview_url"`
 	Status            domain.TemplateStatus `json:"status"`
 	IsFeatured        bool                  `json:"is_featured"`
 	IsBestseller      bool                  `json:"is_bestseller"`
 	IsNew             bool                  `json:"is_new"`
+	WatermarkEnabled  bool                  `json:"watermark_enabled"`
+	LicenseTier       domain.LicenseTier    `json:"license_tier"`
+	LicenseSeats      int                   `json:"license_seats"`
 	MetaTitle         *string               `json:"meta_title"`
 	MetaDescription   *string               `json:"meta_description"`
 	MetaKeywords      []string              `json:"meta_keywords"`
 	CurrentVersion    string                `json:"current_version"`
+	PublishedAt       *time.Time            `json:"published_at"` // required when scheduling
+	AnnounceOnPublish bool                  `json:"announce_on_publish"`
 }
 
 // POST /api/v1/admin/templates
@@ -161,24 +186,24 @@ func (h *TemplateHandler) CreateTemplate(c *fiber.Ctx) error {
 		PriceUSDCents:     req.PriceUSDCents,
 		SalePriceUSDCents: req.SalePriceUSDCents,
 		FileURL:           req.FileURL,
-		FileSizeMB:        req.FileSizeMB,
-		FileFormat:        req.FileFormat,
 		PreviewURL:        req.PreviewURL,
 		Status:            req.Status,
 		IsFeatured:        req.IsFeatured,
 		IsBestseller:      req.IsBestseller,
 		IsNew:             req.IsNew,
+		WatermarkEnabled:  req.WatermarkEnabled,
+		LicenseTier:       req.LicenseTier,
+		LicenseSeats:      req.LicenseSeats,
 		MetaTitle:         req.MetaTitle,
 		MetaDescription:   req.MetaDescription,
 		MetaKeywords:      req.MetaKeywords,
 		CurrentVersion:    req.CurrentVersion,
+		PublishedAt:       req.PublishedAt,
+		AnnounceOnPublish: req.AnnounceOnPublish,
 	}
 
 	if err := h.templateService.CreateTemplate(c.Context(), template, adminID); err != nil {
-		logger.Error("Failed to create template", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create template",
-		})
+		return h.templateWriteError(c, err, "Failed to create template")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -219,24 +244,24 @@ func (h *TemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
 		PriceUSDCents:     req.PriceUSDCents,
 		SalePriceUSDCents: req.SalePriceUSDCents,
 		FileURL:           req.FileURL,
-		FileSizeMB:        req.FileSizeMB,
-		FileFormat:        req.FileFormat,
 		PreviewURL:        req.PreviewURL,
 		Status:            req.Status,
 		IsFeatured:        req.IsFeatured,
 		IsBestseller:      req.IsBestseller,
 		IsNew:             req.IsNew,
+		WatermarkEnabled:  req.WatermarkEnabled,
+		LicenseTier:       req.LicenseTier,
+		LicenseSeats:      req.LicenseSeats,
 		MetaTitle:         req.MetaTitle,
 		MetaDescription:   req.MetaDescription,
 		MetaKeywords:      req.MetaKeywords,
 		CurrentVersion:    req.CurrentVersion,
+		PublishedAt:       req.PublishedAt,
+		AnnounceOnPublish: req.AnnounceOnPublish,
 	}
 
 	if err = h.templateService.UpdateTemplate(c.Context(), template, adminID); err != nil {
-		logger.Error("Failed to update template", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update template",
-		})
+		return h.templateWriteError(c, err, "Failed to update template")
 	}
 
 	return c.JSON(fiber.Map{
@@ -268,10 +293,7 @@ func (h *TemplateHandler) PatchTemplate(c *fiber.Ctx) error {
 	adminID := c.Locals("admin_id").(int64)
 
 	if err = h.templateService.PatchTemplate(c.Context(), id, updates, adminID); err != nil {
-		logger.Error("Failed to patch template", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update template",
-		})
+		return h.templateWriteError(c, err, "Failed to update template")
 	}
 
 	return c.JSON(fiber.Map{
@@ -330,35 +352,43 @@ func (h *TemplateHandler) UploadTemplateFile(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := h.storageService.UploadFile(c.Context(), file, "templates")
+	adminID := c.Locals("admin_id").(int64)
+
+	// Replaces the file of the current version; new versions go through
+	// POST /:id/versions
+	result, err := h.versionService.ReplaceCurrentFile(c.Context(), id, file, adminID)
 	if err != nil {
-		logger.Error("Failed to upload file", zap.Error(err))
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Template not found",
+			})
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, domain.ErrQuarantined) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": "File failed the malware scan and was quarantined",
+			})
+		}
+		logger.Error("Failed to upload template file", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to upload file",
 		})
 	}
 
-	adminID := c.Locals("admin_id").(int64)
 	fileSizeMB := float64(result.Bytes) / (1024 * 1024)
 
-	updates := map[string]interface{}{
-		"file_url":     result.PublicID,
-		"file_size_mb": fileSizeMB,
-		"file_format":  result.Format,
-	}
-
-	if err = h.templateService.PatchTemplate(c.Context(), id, updates, adminID); err != nil {
-		logger.Error("Failed to update template after upload", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update template",
-		})
-	}
-
 	return c.JSON(fiber.Map{
-		"success":   true,
-		"file_url":  result.URL,
-		"public_id": result.PublicID,
-		"size_mb":   fileSizeMB,
+		"success":         true,
+		"file_url":        result.URL,
+		"public_id":       result.PublicID,
+		"size_mb":         fileSizeMB,
+		"format":          result.Format,
+		"checksum_sha256": result.Checksum,
+		"scan_status":     result.ScanStatus,
 	})
 }
 
@@ -412,6 +442,66 @@ func (h *TemplateHandler) AddImage(c *fiber.Ctx) error {
 	})
 }
 
+// ============================================================================
+// UPLOAD IMAGE
+// ============================================================================
+
+// POST /api/v1/admin/templates/:id/images/upload
+// multipart: image (file), alt_text, display_order, is_primary
+func (h *TemplateHandler) UploadImage(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	templateID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Image is required",
+		})
+	}
+
+	image := &domain.TemplateImage{
+		IsPrimary: c.FormValue("is_primary") == "true",
+	}
+	if altText := c.FormValue("alt_text"); altText != "" {
+		image.AltText = &altText
+	}
+	if order := c.FormValue("display_order"); order != "" {
+		if image.DisplayOrder, err = strconv.Atoi(order); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid display_order",
+			})
+		}
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	if err = h.templateService.UploadImage(c.Context(), templateID, file, image, adminID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Template not found",
+			})
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		logger.Error("Failed to upload image", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to upload image",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"image": image,
+	})
+}
+
 // ============================================================================
 // DELETE IMAGE
 // ============================================================================
@@ -429,6 +519,11 @@ func (h *TemplateHandler) DeleteImage(c *fiber.Ctx) error {
 	adminID := c.Locals("admin_id").(int64)
 
 	if err = h.templateService.DeleteImage(c.Context(), id, adminID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Image not found",
+			})
+		}
 		logger.Error("Failed to delete image", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete image",
@@ -441,6 +536,45 @@ func (h *TemplateHandler) DeleteImage(c *fiber.Ctx) error {
 	})
 }
 
+// ============================================================================
+// GENERATE PREVIEWS
+// ============================================================================
+
+// POST /api/v1/admin/templates/:id/previews
+// Re-renders the watermarked preview images of a PDF or XLSX template file
+// in the background, replacing the previous ones.
+func (h *TemplateHandler) GeneratePreviews(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	templateID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	job, err := h.previewService.Enqueue(c.Context(), templateID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Template not found",
+			})
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		logger.Error("Failed to enqueue template previews", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate previews",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"job": job,
+	})
+}
+
 // ============================================================================
 // ADD FEATURE
 // ============================================================================
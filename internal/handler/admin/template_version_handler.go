@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN TEMPLATE VERSION HANDLER - Version history and rollback
+// ============================================================================
+
+type TemplateVersionHandler struct {
+	versionService *service.TemplateVersionService
+}
+
+func NewTemplateVersionHandler(versionService *service.TemplateVersionService) *TemplateVersionHandler {
+	return &TemplateVersionHandler{
+		versionService: versionService,
+	}
+}
+
+// GET /api/v1/admin/templates/:id/versions
+func (h *TemplateVersionHandler) List(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	versions, err := h.versionService.ListVersions(c.Context(), id)
+	if err != nil {
+		return h.versionError(c, err, "Failed to get template versions")
+	}
+
+	return c.JSON(fiber.Map{
+		"versions":      versions,
+		"serves_latest": h.versionService.ServesLatest(c.Context()),
+	})
+}
+
+// POST /api/v1/admin/templates/:id/versions
+// multipart: file, version_number, changelog, notify_buyers=true
+func (h *TemplateVersionHandler) Upload(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "File is required",
+		})
+	}
+
+	notify, _ := strconv.ParseBool(c.FormValue("notify_buyers"))
+	req := service.UploadVersionRequest{
+		VersionNumber: c.FormValue("version_number"),
+		Changelog:     c.FormValue("changelog"),
+		NotifyBuyers:  notify,
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	version, err := h.versionService.UploadVersion(c.Context(), id, file, req, adminID)
+	if errors.Is(err, domain.ErrQuarantined) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "File failed the malware scan and was quarantined",
+			"version": version,
+		})
+	}
+	if err != nil {
+		return h.versionError(c, err, "Failed to upload template version")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"version":       version,
+		"notify_buyers": notify,
+	})
+}
+
+// POST /api/v1/admin/templates/:id/versions/:versionId/rollback
+func (h *TemplateVersionHandler) Rollback(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	versionID, err := strconv.ParseInt(c.Params("versionId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid version ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	version, err := h.versionService.Rollback(c.Context(), id, versionID, adminID)
+	if err != nil {
+		return h.versionError(c, err, "Failed to roll back template version")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"version": version,
+	})
+}
+
+func (h *TemplateVersionHandler) versionError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Template or version not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrQuarantined):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
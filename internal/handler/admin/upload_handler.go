@@ -0,0 +1,179 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN UPLOAD HANDLER - Resumable (tus-style) template version uploads
+// ============================================================================
+//
+// 1. POST   /templates/:id/uploads      {filename, size, version_number, ...}
+// 2. PATCH  /uploads/:uploadId          raw chunk, Upload-Offset header
+//    HEAD   /uploads/:uploadId          Upload-Offset to resume from
+// 3. POST   /uploads/:uploadId/finalize creates the template version
+
+type UploadHandler struct {
+	uploadService *service.ChunkedUploadService
+}
+
+func NewUploadHandler(uploadService *service.ChunkedUploadService) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+	}
+}
+
+// POST /api/v1/admin/templates/:id/uploads
+func (h *UploadHandler) Create(c *fiber.Ctx) error {
+	templateID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	var req service.CreateUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	session, err := h.uploadService.Create(c.Context(), templateID, req, adminID)
+	if err != nil {
+		return h.uploadError(c, err, "Failed to create upload")
+	}
+
+	h.setOffsetHeaders(c, session)
+	c.Location(fmt.Sprintf("/api/v1/admin/uploads/%s", session.ID))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"upload": session,
+	})
+}
+
+// HEAD /api/v1/admin/uploads/:uploadId
+func (h *UploadHandler) Head(c *fiber.Ctx) error {
+	session, err := h.uploadService.Get(c.Context(), c.Params("uploadId"), c.Locals("admin_id").(int64))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	h.setOffsetHeaders(c, session)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// GET /api/v1/admin/uploads/:uploadId
+func (h *UploadHandler) Get(c *fiber.Ctx) error {
+	session, err := h.uploadService.Get(c.Context(), c.Params("uploadId"), c.Locals("admin_id").(int64))
+	if err != nil {
+		return h.uploadError(c, err, "Failed to get upload")
+	}
+
+	h.setOffsetHeaders(c, session)
+	return c.JSON(fiber.Map{
+		"upload": session,
+	})
+}
+
+// PATCH /api/v1/admin/uploads/:uploadId
+func (h *UploadHandler) WriteChunk(c *fiber.Ctx) error {
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Upload-Offset header is required",
+		})
+	}
+
+	id := c.Params("uploadId")
+	adminID := c.Locals("admin_id").(int64)
+
+	session, err := h.uploadService.WriteChunk(c.Context(), id, adminID, offset, c.Body())
+	if errors.Is(err, domain.ErrVersionConflict) {
+		// Tell the client where to resume from
+		if session == nil {
+			session, _ = h.uploadService.Get(c.Context(), id, adminID)
+		}
+		if session != nil {
+			h.setOffsetHeaders(c, session)
+		}
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Upload-Offset does not match the bytes received, or another chunk is being written",
+		})
+	}
+	if err != nil {
+		return h.uploadError(c, err, "Failed to write chunk")
+	}
+
+	h.setOffsetHeaders(c, session)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /api/v1/admin/uploads/:uploadId/finalize
+func (h *UploadHandler) Finalize(c *fiber.Ctx) error {
+	version, err := h.uploadService.Finalize(c.Context(), c.Params("uploadId"), c.Locals("admin_id").(int64))
+	if errors.Is(err, domain.ErrQuarantined) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "File failed the malware scan and was quarantined",
+			"version": version,
+		})
+	}
+	if err != nil {
+		return h.uploadError(c, err, "Failed to finalize upload")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"version": version,
+	})
+}
+
+// DELETE /api/v1/admin/uploads/:uploadId
+func (h *UploadHandler) Abort(c *fiber.Ctx) error {
+	if err := h.uploadService.Abort(c.Context(), c.Params("uploadId"), c.Locals("admin_id").(int64)); err != nil {
+		return h.uploadError(c, err, "Failed to abort upload")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+	})
+}
+
+func (h *UploadHandler) setOffsetHeaders(c *fiber.Ctx, session *domain.UploadSession) {
+	c.Set("Upload-Offset", strconv.FormatInt(session.ReceivedBytes, 10))
+	c.Set("Upload-Length", strconv.FormatInt(session.TotalBytes, 10))
+	c.Set("Cache-Control", "no-store")
+}
+
+func (h *UploadHandler) uploadError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Upload or template not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidStateTransition), errors.Is(err, domain.ErrVersionConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// ADMIN WEBHOOK HANDLER - Payment webhook inbox
+// ============================================================================
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// GET /api/v1/admin/webhooks?event_type=payment.captured&signature_verified=true&processed=false&page=1&limit=20
+func (h *WebhookHandler) GetAll(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := make(map[string]interface{})
+
+	if eventType := c.Query("event_type"); eventType != "" {
+		filters["event_type"] = eventType
+	}
+
+	if verified, err := strconv.ParseBool(c.Query("signature_verified")); err == nil {
+		filters["signature_verified"] = verified
+	}
+
+	if processed, err := strconv.ParseBool(c.Query("processed")); err == nil {
+		filters["processed"] = processed
+	}
+
+	if orderID, err := strconv.ParseInt(c.Query("order_id"), 10, 64); err == nil {
+		filters["order_id"] = orderID
+	}
+
+	webhooks, total, err := h.webhookService.GetAll(c.Context(), filters, page, limit)
+	if err != nil {
+		logger.Error("Failed to get webhooks", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get webhooks",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"webhooks": webhooks,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GET /api/v1/admin/webhooks/:id
+func (h *WebhookHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	webhook, err := h.webhookService.Get(c.Context(), id)
+	if err != nil {
+		return h.webhookError(c, err, "Failed to get webhook")
+	}
+
+	return c.JSON(fiber.Map{
+		"webhook": webhook,
+	})
+}
+
+// POST /api/v1/admin/webhooks/:id/replay
+func (h *WebhookHandler) Replay(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	adminID := c.Locals("admin_id").(int64)
+
+	webhook, err := h.webhookService.Replay(c.Context(), id, adminID)
+	if err != nil {
+		if webhook != nil {
+			// The failure is recorded in processing_error
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"error":   "Webhook replay failed",
+				"webhook": webhook,
+			})
+		}
+		return h.webhookError(c, err, "Failed to replay webhook")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Webhook replayed",
+		"webhook": webhook,
+	})
+}
+
+func (h *WebhookHandler) webhookError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Webhook not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrVersionConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
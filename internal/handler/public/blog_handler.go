@@ -221,6 +221,32 @@ func (h *BlogHandler) GetCategoryBySlug(c *fiber.Ctx) error {
 	return response.SuccessData(c, category)
 }
 
+// GetCategoryTree returns the active categories nested under their parents
+func (h *BlogHandler) GetCategoryTree(c *fiber.Ctx) error {
+	tree, err := h.categoryService.GetCategoryTree(c.Context(), true)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.SuccessData(c, tree)
+}
+
+// GetCategoryBreadcrumbs returns the path from the root category down to slug
+func (h *BlogHandler) GetCategoryBreadcrumbs(c *fiber.Ctx) error {
+	breadcrumbs, err := h.categoryService.GetBreadcrumbs(c.Context(), c.Params("slug"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	for _, category := range breadcrumbs {
+		if !category.IsActive {
+			return response.Error(c, fiber.NewError(404, "Category not found"))
+		}
+	}
+
+	return response.SuccessData(c, breadcrumbs)
+}
+
 func (h *BlogHandler) GetPostsByCategory(c *fiber.Ctx) error {
 	slug := c.Params("slug")
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -0,0 +1,91 @@
+package public
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// BUNDLE HANDLER - Template bundles in the public catalog
+// ============================================================================
+
+type BundleHandler struct {
+	bundleService *service.BundleService
+}
+
+func NewBundleHandler(bundleService *service.BundleService) *BundleHandler {
+	return &BundleHandler{
+		bundleService: bundleService,
+	}
+}
+
+// GET /api/v1/public/bundles?featured=true&search=&sort=price_asc&page=1&limit=12
+func (h *BundleHandler) GetAllBundles(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "12"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 12
+	}
+
+	filters := make(map[string]interface{})
+
+	if c.Query("featured") == "true" {
+		filters["featured"] = true
+	}
+
+	if search := c.Query("search"); search != "" {
+		filters["search"] = search
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		filters["sort"] = sort
+	}
+
+	bundles, total, err := h.bundleService.ListPublic(c.Context(), filters, page, limit)
+	if err != nil {
+		logger.Error("Failed to get bundles", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get bundles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"bundles": bundles,
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GET /api/v1/public/bundles/:slug
+func (h *BundleHandler) GetBundleBySlug(c *fiber.Ctx) error {
+	bundle, err := h.bundleService.GetPublic(c.Context(), c.Params("slug"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Bundle not found",
+			})
+		}
+		logger.Error("Failed to get bundle", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get bundle",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"bundle": bundle,
+	})
+}
@@ -24,15 +24,18 @@ var validate = validator.New()
 type CheckoutHandler struct {
 	orderService   *service.OrderService
 	paymentService *service.PaymentService
+	webhookService *service.WebhookService
 }
 
 func NewCheckoutHandler(
 	orderService *service.OrderService,
 	paymentService *service.PaymentService,
+	webhookService *service.WebhookService,
 ) *CheckoutHandler {
 	return &CheckoutHandler{
 		orderService:   orderService,
 		paymentService: paymentService,
+		webhookService: webhookService,
 	}
 }
 
@@ -49,6 +52,9 @@ type CreateOrderRequest struct {
 
 	// ✅ frontend-generated idempotency key
 	IdempotencyKey string `json:"idempotency_key" validate:"required"`
+
+	// Opt-in to emails when a purchased template gets a new version
+	NotifyUpdates bool `json:"notify_updates"`
 }
 
 func (h *CheckoutHandler) CreateOrder(c *fiber.Ctx) error {
@@ -72,8 +78,10 @@ func (h *CheckoutHandler) CreateOrder(c *fiber.Ctx) error {
 		BillingAddress:    req.BillingAddress,
 		Items:             req.Items,
 		IdempotencyKey:    req.IdempotencyKey,
+		NotifyUpdates:     req.NotifyUpdates,
 		CustomerIP:        c.IP(),
 		CustomerUserAgent: string(c.Request().Header.UserAgent()),
+		CustomerCountry:   c.Get("CF-IPCountry"), // Cloudflare header
 	}
 
 	order, err := h.orderService.CreateOrder(c.Context(), serviceReq)
@@ -191,6 +199,7 @@ func (h *CheckoutHandler) HandleWebhook(c *fiber.Ctx) error {
 		c.Context(),
 		payload,
 		signature,
+		c.Get("X-Razorpay-Event-Id"),
 		c.IP(),
 		string(c.Request().Header.UserAgent()),
 	)
@@ -205,33 +214,19 @@ func (h *CheckoutHandler) HandleWebhook(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "webhook processing failed"})
 	}
 
+	// Redeliveries carry the same event ID; the first copy is already stored
+	if result.Duplicate {
+		logger.Info("duplicate webhook ignored", zap.String("event", result.Event))
+		return c.JSON(fiber.Map{"status": "received"})
+	}
+
 	// ------------------------------------------------------------------
 	// EVENT ROUTING (business layer)
 	// ------------------------------------------------------------------
 
-	switch result.Event {
-
-	case "payment.captured":
-		err := h.orderService.MarkPaymentCaptured(
-			c.Context(),
-			result.GatewayOrderID,
-			result.GatewayPaymentID,
-		)
-		if err != nil {
-			logger.Error("mark captured failed", zap.Error(err))
-		}
-
-	case "payment.failed":
-		err := h.orderService.MarkPaymentFailed(
-			c.Context(),
-			result.GatewayOrderID,
-		)
-		if err != nil {
-			logger.Error("mark failed error", zap.Error(err))
-		}
-
-	default:
-		logger.Warn("unhandled webhook event", zap.String("event", result.Event))
+	// Failures are recorded on the webhook and retried by the sweeper
+	if err := h.webhookService.Process(c.Context(), result.Webhook); err != nil {
+		logger.Error("webhook dispatch failed", zap.String("event", result.Event), zap.Error(err))
 	}
 
 	return c.JSON(fiber.Map{"status": "received"})
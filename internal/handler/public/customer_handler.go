@@ -0,0 +1,218 @@
+package public
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/middleware"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// CUSTOMER HANDLER - Magic-link login & "my account" endpoints
+// ============================================================================
+
+type CustomerHandler struct {
+	customerService *service.CustomerService
+	cfg             *config.Config
+}
+
+func NewCustomerHandler(customerService *service.CustomerService, cfg *config.Config) *CustomerHandler {
+	return &CustomerHandler{
+		customerService: customerService,
+		cfg:             cfg,
+	}
+}
+
+// ============================================================================
+// LOGIN
+// ============================================================================
+
+// POST /api/v1/public/customer/login
+func (h *CustomerHandler) RequestLogin(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := h.customerService.RequestMagicLink(c.Context(), req.Email, c.IP()); err != nil {
+		return h.customerError(c, err, "Failed to send sign-in link")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the address is valid, a sign-in link is on its way",
+	})
+}
+
+// POST /api/v1/public/customer/verify
+func (h *CustomerHandler) VerifyLogin(c *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	session, err := h.customerService.VerifyMagicLink(c.Context(), req.Token, c.IP())
+	if err != nil {
+		return h.customerError(c, err, "Failed to verify sign-in link")
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "customer_access_token",
+		Value:    session.AccessToken,
+		HTTPOnly: true,
+		Secure:   h.cfg.Auth.CookieSecure,
+		SameSite: h.cfg.Auth.CookieSameSite,
+		Path:     "/",
+		MaxAge:   session.ExpiresIn,
+	})
+
+	return c.JSON(session)
+}
+
+// POST /api/v1/customer/logout
+func (h *CustomerHandler) Logout(c *fiber.Ctx) error {
+	c.ClearCookie("customer_access_token")
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out",
+	})
+}
+
+// ============================================================================
+// MY ACCOUNT
+// ============================================================================
+
+// GET /api/v1/customer/me
+func (h *CustomerHandler) GetMe(c *fiber.Ctx) error {
+	customer, err := h.customerService.GetCustomer(c.Context(), middleware.GetCustomerID(c))
+	if err != nil {
+		return h.customerError(c, err, "Failed to get account")
+	}
+
+	return c.JSON(fiber.Map{
+		"customer": customer,
+	})
+}
+
+// GET /api/v1/customer/orders?page=1&limit=10
+func (h *CustomerHandler) GetOrders(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	orders, total, err := h.customerService.ListOrders(c.Context(), middleware.GetCustomerID(c), page, limit)
+	if err != nil {
+		return h.customerError(c, err, "Failed to get orders")
+	}
+
+	return c.JSON(fiber.Map{
+		"orders": orders,
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GET /api/v1/customer/orders/:id/invoice
+func (h *CustomerHandler) GetInvoice(c *fiber.Ctx) error {
+	orderID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid order ID",
+		})
+	}
+
+	order, pdfBytes, err := h.customerService.GetInvoice(c.Context(), middleware.GetCustomerID(c), orderID)
+	if err != nil {
+		return h.customerError(c, err, "Failed to generate invoice")
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="invoice-%s.pdf"`, order.OrderNumber))
+	return c.Send(pdfBytes)
+}
+
+type UpdateNotificationsRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// PUT /api/v1/customer/orders/:id/notifications
+func (h *CustomerHandler) SetUpdateNotifications(c *fiber.Ctx) error {
+	orderID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid order ID",
+		})
+	}
+
+	var req UpdateNotificationsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := h.customerService.SetUpdateNotifications(c.Context(), middleware.GetCustomerID(c), orderID, req.Enabled); err != nil {
+		return h.customerError(c, err, "Failed to update notification preference")
+	}
+
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"notify_updates": req.Enabled,
+	})
+}
+
+// GET /api/v1/customer/downloads
+func (h *CustomerHandler) GetDownloads(c *fiber.Ctx) error {
+	tokens, err := h.customerService.ListDownloads(c.Context(), middleware.GetCustomerID(c))
+	if err != nil {
+		return h.customerError(c, err, "Failed to get downloads")
+	}
+
+	return c.JSON(fiber.Map{
+		"downloads": tokens,
+	})
+}
+
+func (h *CustomerHandler) customerError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
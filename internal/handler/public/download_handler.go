@@ -1,7 +1,14 @@
 package public
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"mime"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/middleware"
 	"github.com/merraki/merraki-backend/internal/pkg/logger"
 	"github.com/merraki/merraki-backend/internal/service"
 	"go.uber.org/zap"
@@ -13,11 +20,13 @@ import (
 
 type DownloadHandler struct {
 	downloadTokenService *service.DownloadTokenService
+	lookupService        *service.OrderLookupService
 }
 
-func NewDownloadHandler(downloadTokenService *service.DownloadTokenService) *DownloadHandler {
+func NewDownloadHandler(downloadTokenService *service.DownloadTokenService, lookupService *service.OrderLookupService) *DownloadHandler {
 	return &DownloadHandler{
 		downloadTokenService: downloadTokenService,
+		lookupService:        lookupService,
 	}
 }
 
@@ -45,6 +54,10 @@ func (h *DownloadHandler) InitiateDownload(c *fiber.Ctx) error {
 		Country:   c.Get("CF-IPCountry"), // Cloudflare header
 	}
 
+	if h.downloadTokenService.ProxyEnabled(c.Context()) {
+		return h.streamDownload(c, serviceReq)
+	}
+
 	// Get download URL
 	response, err := h.downloadTokenService.InitiateDownload(c.Context(), serviceReq)
 	if err != nil {
@@ -62,6 +75,94 @@ func (h *DownloadHandler) InitiateDownload(c *fiber.Ctx) error {
 	return c.Redirect(response.DownloadURL, fiber.StatusTemporaryRedirect)
 }
 
+// streamDownload serves the file through the API, passing Range/If-Range
+// to storage so interrupted transfers can resume.
+func (h *DownloadHandler) streamDownload(c *fiber.Ctx, req *service.InitiateDownloadRequest) error {
+	download, err := h.downloadTokenService.OpenProxyDownload(
+		c.Context(),
+		req,
+		c.Get(fiber.HeaderRange),
+		c.Get(fiber.HeaderIfRange),
+	)
+	if err != nil {
+		logger.Error("Proxied download failed",
+			zap.String("email", req.Email),
+			zap.Error(err),
+		)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid or expired download link",
+		})
+	}
+
+	for key := range download.Header {
+		c.Set(key, download.Header.Get(key))
+	}
+	if c.GetRespHeader(fiber.HeaderContentType) == "" {
+		c.Set(fiber.HeaderContentType, "application/octet-stream")
+	}
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{
+		"filename": download.FileName,
+	}))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	// fasthttp closes the body once the response is written (or the client
+	// goes away), which records the transfer
+	c.Status(download.StatusCode)
+	c.Context().SetBodyStream(download.Body, int(download.ContentLength))
+	return nil
+}
+
+// ============================================================================
+// BUNDLE DOWNLOAD - All files of an order as one ZIP
+// ============================================================================
+
+// GET /api/v1/public/download/bundle?token=xxx&email=xxx
+func (h *DownloadHandler) DownloadBundle(c *fiber.Ctx) error {
+	token := c.Query("token")
+	email := c.Query("email")
+
+	if token == "" || email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token and email are required",
+		})
+	}
+
+	serviceReq := &service.InitiateDownloadRequest{
+		Token:     token,
+		Email:     email,
+		IPAddress: c.IP(),
+		UserAgent: string(c.Request().Header.UserAgent()),
+		Country:   c.Get("CF-IPCountry"),
+	}
+
+	bundle, err := h.downloadTokenService.OpenBundle(c.Context(), serviceReq)
+	if err != nil {
+		logger.Error("Bundle download failed",
+			zap.String("email", email),
+			zap.Error(err),
+		)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid or expired download link",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{
+		"filename": bundle.FileName,
+	}))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	// The ZIP is written after the handler returns, one file at a time
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.downloadTokenService.WriteBundle(context.Background(), bundle, w); err != nil {
+			logger.Error("Bundle stream aborted", zap.String("file", bundle.FileName), zap.Error(err))
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
+
 // ============================================================================
 // GET DOWNLOAD INFO (without redirect)
 // ============================================================================
@@ -106,19 +207,12 @@ func (h *DownloadHandler) GetDownloadInfo(c *fiber.Ctx) error {
 }
 
 // ============================================================================
-// GET DOWNLOADS BY EMAIL
+// GET DOWNLOADS BY EMAIL (lookup session)
 // ============================================================================
 
-// GET /api/v1/downloads/by-email?email=xxx
+// GET /api/v1/public/download/by-email
 func (h *DownloadHandler) GetDownloadsByEmail(c *fiber.Ctx) error {
-	email := c.Query("email")
-	if email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
-	}
-
-	tokens, err := h.downloadTokenService.GetTokensByEmail(c.Context(), email)
+	tokens, err := h.lookupService.GetDownloads(c.Context(), middleware.GetLookupEmail(c))
 	if err != nil {
 		logger.Error("Failed to get downloads", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -129,4 +223,43 @@ func (h *DownloadHandler) GetDownloadsByEmail(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"downloads": tokens,
 	})
-}
\ No newline at end of file
+}
+
+// ============================================================================
+// RESEND DOWNLOAD LINKS (lookup session)
+// ============================================================================
+
+// POST /api/v1/public/download/resend
+func (h *DownloadHandler) ResendDownloadLinks(c *fiber.Ctx) error {
+	var req struct {
+		OrderNumber string `json:"order_number"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.OrderNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Order number is required",
+		})
+	}
+
+	err := h.lookupService.ResendDownloadLinks(c.Context(), middleware.GetLookupEmail(c), req.OrderNumber)
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Order not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		logger.Error("Failed to resend download links", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resend download links",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Download links will be emailed shortly",
+	})
+}
@@ -0,0 +1,60 @@
+package public
+
+import (
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/pkg/storage"
+	"github.com/merraki/merraki-backend/internal/service"
+)
+
+// ============================================================================
+// FILE HANDLER - Serves files stored by the local storage backend
+// ============================================================================
+
+type FileHandler struct {
+	storageService *service.StorageService
+}
+
+func NewFileHandler(storageService *service.StorageService) *FileHandler {
+	return &FileHandler{
+		storageService: storageService,
+	}
+}
+
+// GET /api/v1/public/files/*
+func (h *FileHandler) ServeFile(c *fiber.Ctx) error {
+	local, ok := h.storageService.Local()
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "File not found",
+		})
+	}
+
+	key := c.Params("*")
+	if err := local.Verify(key, c.Query("exp"), c.Query("sig")); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid or expired link",
+		})
+	}
+
+	filePath, err := local.Path(key)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "File not found",
+		})
+	}
+
+	// Private files are downloads; public ones (images) render inline
+	if strings.HasPrefix(key, string(storage.Private)+"/") {
+		c.Attachment(path.Base(key))
+	}
+
+	if err := c.SendFile(filePath); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "File not found",
+		})
+	}
+	return nil
+}
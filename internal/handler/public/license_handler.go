@@ -0,0 +1,62 @@
+package public
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// LICENSE HANDLER - Public license key verification
+// ============================================================================
+
+type LicenseHandler struct {
+	licenseService *service.LicenseService
+}
+
+func NewLicenseHandler(licenseService *service.LicenseService) *LicenseHandler {
+	return &LicenseHandler{
+		licenseService: licenseService,
+	}
+}
+
+// POST /api/v1/public/licenses/verify
+func (h *LicenseHandler) Verify(c *fiber.Ctx) error {
+	var req struct {
+		LicenseKey string `json:"license_key"`
+		Email      string `json:"email"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	result, err := h.licenseService.Verify(c.Context(), req.LicenseKey, req.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		logger.Error("Failed to verify license", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify license",
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// GET /api/v1/public/licenses/public-key
+func (h *LicenseHandler) GetPublicKey(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"algorithm":  "Ed25519",
+		"public_key": h.licenseService.PublicKey(),
+	})
+}
@@ -1,10 +1,15 @@
 package public
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/middleware"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
 	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
 )
 
 // ============================================================================
@@ -12,85 +17,102 @@ import (
 // ============================================================================
 
 type OrderHandler struct {
-	orderService *service.OrderService
+	orderService  *service.OrderService
+	lookupService *service.OrderLookupService
 }
 
-func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+func NewOrderHandler(orderService *service.OrderService, lookupService *service.OrderLookupService) *OrderHandler {
 	return &OrderHandler{
-		orderService: orderService,
+		orderService:  orderService,
+		lookupService: lookupService,
 	}
 }
 
 // ============================================================================
-// LOOKUP ORDER BY NUMBER
+// LOOKUP CODES - Prove ownership of an email before showing its orders
 // ============================================================================
 
-// GET /api/v1/orders/lookup?order_number=xxx&email=xxx
-func (h *OrderHandler) LookupOrder(c *fiber.Ctx) error {
-	orderNumber := c.Query("order_number")
-	email := c.Query("email")
+// POST /api/v1/public/orders/lookup/request-code
+func (h *OrderHandler) RequestLookupCode(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
 
-	if orderNumber == "" || email == "" {
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Order number and email are required",
+			"error": "Invalid request body",
 		})
 	}
 
-	// Get order
-	order, err := h.orderService.GetOrderByNumber(c.Context(), orderNumber)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Order not found",
+	if err := h.lookupService.RequestCode(c.Context(), req.Email); err != nil {
+		return h.lookupError(c, err, "Failed to send verification code")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If we have orders for this email, a verification code is on its way",
+	})
+}
+
+// POST /api/v1/public/orders/lookup/verify
+func (h *OrderHandler) VerifyLookupCode(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
 		})
 	}
 
-	// Verify email matches
-	if order.Order.CustomerEmail != email {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Unauthorized",
+	session, err := h.lookupService.VerifyCode(c.Context(), req.Email, req.Code)
+	if err != nil {
+		return h.lookupError(c, err, "Failed to verify code")
+	}
+
+	return c.JSON(session)
+}
+
+// ============================================================================
+// LOOKUP ORDER BY NUMBER
+// ============================================================================
+
+// GET /api/v1/public/orders/lookup?order_number=xxx (lookup session)
+func (h *OrderHandler) LookupOrder(c *fiber.Ctx) error {
+	orderNumber := c.Query("order_number")
+	if orderNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Order number is required",
 		})
 	}
 
+	order, err := h.lookupService.GetOrderByNumber(c.Context(), middleware.GetLookupEmail(c), orderNumber)
+	if err != nil {
+		return h.lookupError(c, err, "Failed to get order")
+	}
+
 	return c.JSON(fiber.Map{
 		"order": order,
 	})
 }
 
 // ============================================================================
-// GET ORDER BY ID (with email verification)
+// GET ORDER BY ID
 // ============================================================================
 
-// GET /api/v1/orders/:id?email=xxx
+// GET /api/v1/public/orders/:id (lookup session)
 func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	email := c.Query("email")
-
-	if email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
-	}
-
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid order ID",
 		})
 	}
 
-	// Get order
-	order, err := h.orderService.GetOrderByID(c.Context(), id)
+	order, err := h.lookupService.GetOrderByID(c.Context(), middleware.GetLookupEmail(c), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Order not found",
-		})
-	}
-
-	// Verify email matches
-	if order.Order.CustomerEmail != email {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return h.lookupError(c, err, "Failed to get order")
 	}
 
 	return c.JSON(fiber.Map{
@@ -102,15 +124,8 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 // GET ORDERS BY EMAIL
 // ============================================================================
 
-// GET /api/v1/orders/by-email?email=xxx&page=1&limit=10
+// GET /api/v1/public/orders/by-email?page=1&limit=10 (lookup session)
 func (h *OrderHandler) GetOrdersByEmail(c *fiber.Ctx) error {
-	email := c.Query("email")
-	if email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
-	}
-
 	// Parse pagination
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -122,9 +137,9 @@ func (h *OrderHandler) GetOrdersByEmail(c *fiber.Ctx) error {
 		limit = 10
 	}
 
-	orders, total, err := h.orderService.GetOrdersByEmail(
+	orders, total, err := h.lookupService.GetOrders(
 		c.Context(),
-		email,
+		middleware.GetLookupEmail(c),
 		page,
 		limit,
 	)
@@ -140,4 +155,22 @@ func (h *OrderHandler) GetOrdersByEmail(c *fiber.Ctx) error {
 		"page":   page,
 		"limit":  limit,
 	})
-}
\ No newline at end of file
+}
+
+func (h *OrderHandler) lookupError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Order not found",
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
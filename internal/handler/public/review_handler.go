@@ -0,0 +1,129 @@
+package public
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/middleware"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// REVIEW HANDLER - Template reviews for shoppers and signed-in buyers
+// ============================================================================
+
+type ReviewHandler struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// ============================================================================
+// PUBLIC
+// ============================================================================
+
+// GET /api/v1/public/templates/:slug/reviews?sort=newest&page=1&limit=10
+func (h *ReviewHandler) GetTemplateReviews(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	reviews, total, err := h.reviewService.ListApproved(c.Context(), c.Params("slug"), c.Query("sort"), page, limit)
+	if err != nil {
+		return h.reviewError(c, err, "Failed to get reviews")
+	}
+
+	return c.JSON(fiber.Map{
+		"reviews": reviews,
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GET /api/v1/public/templates/:slug/reviews/summary
+func (h *ReviewHandler) GetRatingSummary(c *fiber.Ctx) error {
+	summary, err := h.reviewService.GetRatingSummary(c.Context(), c.Params("slug"))
+	if err != nil {
+		return h.reviewError(c, err, "Failed to get rating summary")
+	}
+
+	return c.JSON(fiber.Map{
+		"summary": summary,
+	})
+}
+
+// ============================================================================
+// MY REVIEWS - Requires a customer access token
+// ============================================================================
+
+// POST /api/v1/customer/reviews
+func (h *ReviewHandler) Submit(c *fiber.Ctx) error {
+	var req service.SubmitReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	review, err := h.reviewService.Submit(c.Context(), middleware.GetCustomerID(c), middleware.GetCustomerEmail(c), req)
+	if err != nil {
+		return h.reviewError(c, err, "Failed to submit review")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"review":  review,
+		"message": "Thanks! Your review will appear once it has been approved",
+	})
+}
+
+// GET /api/v1/customer/reviews
+func (h *ReviewHandler) GetMine(c *fiber.Ctx) error {
+	reviews, err := h.reviewService.ListMine(c.Context(), middleware.GetCustomerEmail(c))
+	if err != nil {
+		return h.reviewError(c, err, "Failed to get reviews")
+	}
+
+	return c.JSON(fiber.Map{
+		"reviews": reviews,
+	})
+}
+
+func (h *ReviewHandler) reviewError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Template not found",
+		})
+	case errors.Is(err, domain.ErrForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Error(message, zap.Error(err))
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
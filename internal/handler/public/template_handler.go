@@ -1,9 +1,12 @@
 package public
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
 	"github.com/merraki/merraki-backend/internal/service"
 )
 
@@ -12,17 +15,20 @@ import (
 // ============================================================================
 
 type TemplateHandler struct {
-	templateService *service.TemplateService
-	categoryService *service.CategoryService
+	templateService       *service.TemplateService
+	categoryService       *service.CategoryService
+	recommendationService *service.RecommendationService
 }
 
 func NewTemplateHandler(
 	templateService *service.TemplateService,
 	categoryService *service.CategoryService,
+	recommendationService *service.RecommendationService,
 ) *TemplateHandler {
 	return &TemplateHandler{
-		templateService: templateService,
-		categoryService: categoryService,
+		templateService:       templateService,
+		categoryService:       categoryService,
+		recommendationService: recommendationService,
 	}
 }
 
@@ -31,6 +37,7 @@ func NewTemplateHandler(
 // ============================================================================
 
 // GET /api/v1/templates?category_id=1&featured=true&search=budget&sort=price_asc&page=1&limit=12
+// sort: price_asc, price_desc, popular, rating, newest
 func (h *TemplateHandler) GetAllTemplates(c *fiber.Ctx) error {
 	// Parse query parameters
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -47,9 +54,20 @@ func (h *TemplateHandler) GetAllTemplates(c *fiber.Ctx) error {
 	filters := make(map[string]interface{})
 	filters["available"] = true
 
+	// A category includes its subcategories unless include_descendants=false
 	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
 		if categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64); err == nil {
-			filters["category_id"] = categoryID
+			if c.Query("include_descendants") == "false" {
+				filters["category_id"] = categoryID
+			} else {
+				categoryIDs, err := h.categoryService.GetSubtreeIDs(c.Context(), categoryID, true)
+				if err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "Failed to get templates",
+					})
+				}
+				filters["category_ids"] = categoryIDs
+			}
 		}
 	}
 
@@ -96,7 +114,7 @@ func (h *TemplateHandler) GetTemplateBySlug(c *fiber.Ctx) error {
 	slug := c.Params("slug")
 
 	template, err := h.templateService.GetTemplateBySlug(c.Context(), slug, true)
-	if err != nil {
+	if err != nil || template.Status == domain.TemplateStatusScheduled {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Template not found",
 		})
@@ -122,7 +140,7 @@ func (h *TemplateHandler) GetTemplateByID(c *fiber.Ctx) error {
 	}
 
 	template, err := h.templateService.GetTemplateByID(c.Context(), id, true)
-	if err != nil {
+	if err != nil || template.Status == domain.TemplateStatusScheduled {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Template not found",
 		})
@@ -205,6 +223,31 @@ func (h *TemplateHandler) GetNewTemplates(c *fiber.Ctx) error {
 	})
 }
 
+// ============================================================================
+// GET RELATED TEMPLATES
+// ============================================================================
+
+// GET /api/v1/templates/:slug/related?limit=6
+func (h *TemplateHandler) GetRelatedTemplates(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "6"))
+
+	templates, err := h.recommendationService.GetRelated(c.Context(), c.Params("slug"), limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Template not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get related templates",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"templates": templates,
+	})
+}
+
 // ============================================================================
 // GET CATEGORIES
 // ============================================================================
@@ -223,6 +266,47 @@ func (h *TemplateHandler) GetCategories(c *fiber.Ctx) error {
 	})
 }
 
+// ============================================================================
+// GET CATEGORY TREE
+// ============================================================================
+
+// GET /api/v1/categories/tree
+func (h *TemplateHandler) GetCategoryTree(c *fiber.Ctx) error {
+	tree, err := h.categoryService.GetCategoryTree(c.Context(), true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get categories",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"categories": tree,
+	})
+}
+
+// ============================================================================
+// GET CATEGORY BREADCRUMBS
+// ============================================================================
+
+// GET /api/v1/categories/:slug/breadcrumbs
+func (h *TemplateHandler) GetCategoryBreadcrumbs(c *fiber.Ctx) error {
+	breadcrumbs, err := h.categoryService.GetBreadcrumbs(c.Context(), c.Params("slug"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Category not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get breadcrumbs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"breadcrumbs": breadcrumbs,
+	})
+}
+
 // ============================================================================
 // GET CATEGORY BY SLUG
 // ============================================================================
@@ -278,7 +362,7 @@ func (h *TemplateHandler) SearchTemplates(c *fiber.Ctx) error {
 // GET TEMPLATES BY CATEGORY
 // ============================================================================
 
-// GET /api/v1/templates/by-category/:slug?page=1&limit=12
+// GET /api/v1/categories/:slug/templates?page=1&limit=12&include_descendants=true
 func (h *TemplateHandler) GetTemplatesByCategory(c *fiber.Ctx) error {
 	slug := c.Params("slug")
 	
@@ -297,10 +381,16 @@ func (h *TemplateHandler) GetTemplatesByCategory(c *fiber.Ctx) error {
 	templates, total, err := h.templateService.GetTemplatesByCategory(
 		c.Context(),
 		slug,
+		c.Query("include_descendants") != "false",
 		limit,
 		offset,
 	)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Category not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get templates",
 		})
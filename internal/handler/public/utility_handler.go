@@ -5,17 +5,20 @@ import (
 	"github.com/merraki/merraki-backend/internal/pkg/response"
 	"github.com/merraki/merraki-backend/internal/repository/postgres"
 	"github.com/merraki/merraki-backend/internal/repository/redis"
+	"github.com/merraki/merraki-backend/internal/service"
 )
 
 type UtilityHandler struct {
-	db    *postgres.Database
-	redis *redis.Client
+	db             *postgres.Database
+	redis          *redis.Client
+	storageService *service.StorageService
 }
 
-func NewUtilityHandler(db *postgres.Database, redis *redis.Client) *UtilityHandler {
+func NewUtilityHandler(db *postgres.Database, redis *redis.Client, storageService *service.StorageService) *UtilityHandler {
 	return &UtilityHandler{
-		db:    db,
-		redis: redis,
+		db:             db,
+		redis:          redis,
+		storageService: storageService,
 	}
 }
 
@@ -39,7 +42,14 @@ func (h *UtilityHandler) Health(c *fiber.Ctx) error {
 		services["redis"] = "healthy"
 	}
 
-	services["storage"] = "healthy" // Placeholder
+	// Check storage (cached, see StorageService.Health)
+	if err := h.storageService.Health(c.Context()); err != nil {
+		status = "unhealthy"
+		services["storage"] = "unhealthy"
+	} else {
+		services["storage"] = "healthy"
+	}
+	services["storage_provider"] = h.storageService.Provider()
 
 	statusCode := fiber.StatusOK
 	if status == "unhealthy" {
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/merraki/merraki-backend/internal/config"
 	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
@@ -42,6 +44,71 @@ func AdminAuth(cfg *config.Config) fiber.Handler {
 	}
 }
 
+// CustomerAuth accepts the customer_access_token cookie or an
+// "Authorization: Bearer" header carrying a customer PASETO.
+func CustomerAuth(cfg *config.Config) fiber.Handler {
+	pasetoMaker, err := jwt.NewPasetoMaker(cfg.Auth.PasetoKey)
+	if err != nil {
+		panic("Failed to initialize PASETO maker: " + err.Error())
+	}
+
+	return func(c *fiber.Ctx) error {
+		token := c.Cookies("customer_access_token")
+		if token == "" {
+			token = strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		}
+		if token == "" {
+			return response.Error(c, apperrors.ErrUnauthorized)
+		}
+
+		claims, err := pasetoMaker.VerifyToken(token)
+		if err != nil {
+			return response.Error(c, apperrors.New("INVALID_TOKEN", err.Error(), 401))
+		}
+
+		if claims.Type != "customer_access" || claims.CustomerID == 0 {
+			return response.Error(c, apperrors.New("INVALID_TOKEN_TYPE", "Invalid token type", 401))
+		}
+
+		c.Locals("customer_id", claims.CustomerID)
+		c.Locals("customer_email", claims.Email)
+
+		return c.Next()
+	}
+}
+
+// OrderLookupAuth accepts the session issued after an emailed order lookup
+// code is verified, as the order_lookup_token cookie or a Bearer header.
+func OrderLookupAuth(cfg *config.Config) fiber.Handler {
+	pasetoMaker, err := jwt.NewPasetoMaker(cfg.Auth.PasetoKey)
+	if err != nil {
+		panic("Failed to initialize PASETO maker: " + err.Error())
+	}
+
+	return func(c *fiber.Ctx) error {
+		token := c.Cookies("order_lookup_token")
+		if token == "" {
+			token = strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		}
+		if token == "" {
+			return response.Error(c, apperrors.ErrUnauthorized)
+		}
+
+		claims, err := pasetoMaker.VerifyToken(token)
+		if err != nil {
+			return response.Error(c, apperrors.New("INVALID_TOKEN", err.Error(), 401))
+		}
+
+		if claims.Type != "order_lookup" || claims.Email == "" {
+			return response.Error(c, apperrors.New("INVALID_TOKEN_TYPE", "Invalid token type", 401))
+		}
+
+		c.Locals("lookup_email", claims.Email)
+
+		return c.Next()
+	}
+}
+
 func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		permissions, ok := c.Locals("admin_permissions").(map[string]interface{})
@@ -76,4 +143,19 @@ func GetAdminEmail(c *fiber.Ctx) string {
 func GetAdminRole(c *fiber.Ctx) string {
 	role, _ := c.Locals("admin_role").(string)
 	return role
-}
\ No newline at end of file
+}
+
+func GetCustomerID(c *fiber.Ctx) int64 {
+	customerID, _ := c.Locals("customer_id").(int64)
+	return customerID
+}
+
+func GetCustomerEmail(c *fiber.Ctx) string {
+	email, _ := c.Locals("customer_email").(string)
+	return email
+}
+
+func GetLookupEmail(c *fiber.Ctx) string {
+	email, _ := c.Locals("lookup_email").(string)
+	return email
+}
@@ -0,0 +1,199 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/HugoSmits86/nativewebp"
+
+	// GIF and WebP are accepted as input; only the first GIF frame is kept
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+)
+
+// ============================================================================
+// IMAGING - Decoding, downscaling and encoding of uploaded images
+// ============================================================================
+//
+// Renditions are JPEG, or PNG when the image has transparency, plus WebP.
+// The WebP encoder is lossless (VP8L): Go has no lossy WebP encoder short of
+// cgo, so a WebP copy only pays off for some images and callers compare
+// sizes before keeping it.
+
+var (
+	// ErrUnsupported is returned for content that is not a JPEG, PNG, GIF or
+	// WebP.
+	ErrUnsupported = errors.New("imaging: unsupported image format")
+	// ErrTooLarge is returned for images with more pixels than allowed.
+	ErrTooLarge = errors.New("imaging: image has too many pixels")
+)
+
+// Decode decodes a JPEG, PNG, GIF or WebP and turns it upright according to its
+// EXIF orientation. Dimensions are checked before the pixels are decoded, so
+// a small file that claims a huge canvas is refused cheaply. Re-encoding the
+// result drops EXIF and any other metadata.
+func Decode(data []byte, maxPixels int) (*image.RGBA, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	return toRGBA(img), nil
+}
+
+// Opaque reports whether img has no transparent pixels.
+func Opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// Flatten composites img over a white background.
+func Flatten(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+// Resize scales img down to width, keeping its aspect ratio. Each output
+// pixel averages the source pixels it covers, which avoids the aliasing of
+// nearest-neighbour or bilinear sampling at large reductions. Images no
+// wider than width are returned unscaled, as an *image.RGBA.
+func Resize(img image.Image, width int) *image.RGBA {
+	b := img.Bounds()
+	src := toRGBA(img)
+	srcW, srcH := b.Dx(), b.Dy()
+	if width <= 0 || width >= srcW {
+		return src
+	}
+	height := int(math.Round(float64(srcH) * float64(width) / float64(srcW)))
+	if height < 1 {
+		height = 1
+	}
+
+	// Horizontal pass into a float buffer, then vertical into the result.
+	// Pixels are premultiplied, so averaging them is correct for alpha too.
+	cols := contributions(srcW, width)
+	tmp := make([]float32, width*srcH*4)
+	for y := 0; y < srcH; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x, c := range cols {
+			var r, g, bl, a float32
+			for i, w := range c.weights {
+				p := row[(c.start+i)*4:]
+				r += w * float32(p[0])
+				g += w * float32(p[1])
+				bl += w * float32(p[2])
+				a += w * float32(p[3])
+			}
+			t := tmp[(y*width+x)*4:]
+			t[0], t[1], t[2], t[3] = r, g, bl, a
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	rows := contributions(srcH, height)
+	for y, c := range rows {
+		out := dst.Pix[y*dst.Stride:]
+		for x := 0; x < width; x++ {
+			var r, g, bl, a float32
+			for i, w := range c.weights {
+				t := tmp[((c.start+i)*width+x)*4:]
+				r += w * t[0]
+				g += w * t[1]
+				bl += w * t[2]
+				a += w * t[3]
+			}
+			p := out[x*4:]
+			p[0], p[1], p[2], p[3] = clamp8(r), clamp8(g), clamp8(bl), clamp8(a)
+		}
+	}
+	return dst
+}
+
+// toRGBA returns img as an *image.RGBA anchored at the origin, converting
+// it only when needed.
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	if rgba, ok := img.(*image.RGBA); ok && b.Min == (image.Point{}) {
+		return rgba
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// contribution is the run of source pixels, and their weights, that make up
+// one output pixel.
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+func contributions(srcLen, dstLen int) []contribution {
+	scale := float64(srcLen) / float64(dstLen)
+	out := make([]contribution, dstLen)
+	for i := range out {
+		lo := float64(i) * scale
+		hi := lo + scale
+		start := int(lo)
+		end := int(math.Ceil(hi))
+		if end > srcLen {
+			end = srcLen
+		}
+
+		weights := make([]float32, end-start)
+		for j := start; j < end; j++ {
+			covered := math.Min(hi, float64(j+1)) - math.Max(lo, float64(j))
+			weights[j-start] = float32(covered / scale)
+		}
+		out[i] = contribution{start: start, weights: weights}
+	}
+	return out
+}
+
+func clamp8(v float32) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// Encode writes img as "jpg" at the given quality, or as "png" or lossless
+// "webp", which ignore quality.
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case "jpg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case "png":
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		return enc.Encode(w, img)
+	case "webp":
+		return nativewebp.Encode(w, img, nil)
+	}
+	return ErrUnsupported
+}
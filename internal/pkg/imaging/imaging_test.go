@@ -0,0 +1,68 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 48, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 48; x++ {
+			a := uint8(255)
+			if x < 8 {
+				a = 0 // a transparent strip, as in a logo
+			}
+			img.Set(x, y, color.NRGBA{R: uint8(x * 5), G: uint8(y * 7), B: 90, A: a})
+		}
+	}
+	return img
+}
+
+func TestWebPRoundTrip(t *testing.T) {
+	src := testImage()
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, src, "webp", 82); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("RIFF")) || string(buf.Bytes()[8:12]) != "WEBP" {
+		t.Fatalf("output is not a WebP file: % x", buf.Bytes()[:12])
+	}
+
+	got, err := Decode(buf.Bytes(), 10_000)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.Bounds() != src.Bounds() {
+		t.Fatalf("decoded bounds %v, want %v", got.Bounds(), src.Bounds())
+	}
+	// Lossless: every pixel survives, transparency included
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 48; x++ {
+			if got.RGBAAt(x, y) != src.RGBAAt(x, y) {
+				t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, got.RGBAAt(x, y), src.RGBAAt(x, y))
+			}
+		}
+	}
+	if Opaque(got) {
+		t.Error("decoded image lost its transparency")
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, testImage(), "webp", 82); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	if _, err := Decode(buf.Bytes(), 100); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+	if _, err := Decode([]byte("RIFF\x00\x00\x00\x00WEBPVP8X"), 10_000); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("truncated WebP: err = %v, want ErrUnsupported", err)
+	}
+}
@@ -11,10 +11,11 @@ import (
 
 type TokenClaims struct {
 	AdminID     int64                  `json:"admin_id,omitempty"`
+	CustomerID  int64                  `json:"customer_id,omitempty"`
 	Email       string                 `json:"email"`
 	Role        string                 `json:"role"`
 	Permissions map[string]interface{} `json:"permissions"`
-	Type        string                 `json:"type"` // "access" or "admin_access"
+	Type        string                 `json:"type"` // "access", "admin_access", "customer_access" or "order_lookup"
 	ExpiresAt   time.Time              `json:"exp"`
 	IssuedAt    time.Time              `json:"iat"`
 }
@@ -82,6 +83,29 @@ func (maker *PasetoMaker) CreateAccessToken(adminID int64, email, role string, p
 	return maker.CreateToken(claims)
 }
 
+func (maker *PasetoMaker) CreateCustomerToken(customerID int64, email string, duration time.Duration) (string, error) {
+	claims := TokenClaims{
+		CustomerID: customerID,
+		Email:      email,
+		Type:       "customer_access",
+		ExpiresAt:  time.Now().Add(duration),
+	}
+
+	return maker.CreateToken(claims)
+}
+
+// CreateLookupToken issues a short session scoped to the orders of one
+// verified email address.
+func (maker *PasetoMaker) CreateLookupToken(email string, duration time.Duration) (string, error) {
+	claims := TokenClaims{
+		Email:     email,
+		Type:      "order_lookup",
+		ExpiresAt: time.Now().Add(duration),
+	}
+
+	return maker.CreateToken(claims)
+}
+
 func HashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(hash[:])
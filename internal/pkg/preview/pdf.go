@@ -0,0 +1,98 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// renderPDF runs pdftoppm over the first pages, written to a scratch
+// directory that is removed afterwards.
+func renderPDF(ctx context.Context, data []byte, opts Options) ([]Page, error) {
+	bin := opts.PDFToPPM
+	if bin == "" {
+		bin = "pdftoppm"
+	}
+	bin, err := exec.LookPath(bin)
+	if err != nil {
+		return nil, ErrNoRenderer
+	}
+	width := opts.Width
+	if width <= 0 {
+		width = 1200
+	}
+
+	dir, err := os.MkdirTemp("", "preview-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.pdf")
+	if err := os.WriteFile(input, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin,
+		"-png",
+		"-f", "1",
+		"-l", strconv.Itoa(opts.MaxPages),
+		"-scale-to-x", strconv.Itoa(width),
+		"-scale-to-y", "-1",
+		input, filepath.Join(dir, "page"),
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftoppm: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Output is page-1.png, page-2.png, ... with the number zero-padded to
+	// the width of the page count, so the names sort in page order
+	files, err := filepath.Glob(filepath.Join(dir, "page-*.png"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	pages := make([]Page, 0, len(files))
+	for i, name := range files {
+		img, err := readPNG(name)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, Page{Image: img, Label: fmt.Sprintf("Page %d", i+1)})
+	}
+	return pages, nil
+}
+
+func readPNG(name string) (*image.RGBA, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	for y := 0; y < rgba.Rect.Dy(); y++ {
+		for x := 0; x < rgba.Rect.Dx(); x++ {
+			rgba.Set(x, y, img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y))
+		}
+	}
+	return rgba, nil
+}
@@ -0,0 +1,126 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/gosimple/unidecode"
+)
+
+// ============================================================================
+// PREVIEW - First pages of PDFs and first sheets of workbooks as images
+// ============================================================================
+//
+// Workbooks are drawn here, in pure Go, as a plain cell grid with a built-in
+// bitmap font: enough to show a buyer the layout and labels of a template,
+// not a faithful rendering of its styles or charts. Go has no PDF
+// rasteriser, so PDF pages are rendered by poppler's pdftoppm when it is
+// installed.
+
+var (
+	// ErrUnsupported is returned for formats Render cannot preview.
+	ErrUnsupported = errors.New("preview: unsupported format")
+	// ErrNoRenderer is returned for PDFs when pdftoppm is not installed.
+	ErrNoRenderer = errors.New("preview: pdftoppm is not installed")
+)
+
+type Options struct {
+	// MaxPages caps how many pages, or sheets, are rendered.
+	MaxPages int
+	// Width is the pixel width PDF pages are rendered at.
+	Width int
+	// PDFToPPM is the pdftoppm binary, looked up in PATH when not absolute.
+	PDFToPPM string
+}
+
+// Page is one rendered page or sheet.
+type Page struct {
+	Image *image.RGBA
+	Label string
+}
+
+// Supports reports whether Render can preview files of format (a file
+// extension without the dot, as sniffed on upload).
+func Supports(format string) bool {
+	switch format {
+	case "pdf", "xlsx", "xlsm":
+		return true
+	}
+	return false
+}
+
+// Render draws the first pages of a PDF or the first visible sheets of a
+// workbook. A workbook with no cell values yields no pages.
+func Render(ctx context.Context, data []byte, format string, opts Options) ([]Page, error) {
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = 1
+	}
+
+	switch format {
+	case "pdf":
+		return renderPDF(ctx, data, opts)
+	case "xlsx", "xlsm":
+		return renderWorkbook(data, opts.MaxPages)
+	}
+	return nil, ErrUnsupported
+}
+
+// Watermark tiles text diagonally across img. It is faint enough to read
+// the page through, and covers all of it so it can't be cropped away.
+func Watermark(img *image.RGBA, text string) {
+	const (
+		scale = 6
+		alpha = 0.16
+		angle = math.Pi / 6
+	)
+	text = ascii(text)
+
+	// One tile holds the text with room around it; alternate rows are
+	// offset by half a tile
+	tileW := textWidth(text, scale) * 3 / 2
+	tileH := glyphHeight * scale * 4
+	tile := image.NewRGBA(image.Rect(0, 0, tileW, tileH))
+	drawText(tile, 0, 0, text, scale, color.RGBA{A: 255})
+
+	ink := [3]float64{90, 90, 90}
+	sin, cos := math.Sincos(angle)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			u := float64(x)*cos - float64(y)*sin
+			v := float64(x)*sin + float64(y)*cos
+			row := math.Floor(v / float64(tileH))
+			u += row * float64(tileW) / 2
+
+			tu := int(u - math.Floor(u/float64(tileW))*float64(tileW))
+			tv := int(v - row*float64(tileH))
+			if tile.Pix[tile.PixOffset(tu%tileW, tv%tileH)+3] == 0 {
+				continue
+			}
+
+			p := img.Pix[img.PixOffset(x, y):]
+			for i := 0; i < 3; i++ {
+				p[i] = uint8(float64(p[i])*(1-alpha) + ink[i]*alpha)
+			}
+		}
+	}
+}
+
+// ascii transliterates s for the bitmap font, e.g. "é" to "e".
+func ascii(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return unidecode.Unidecode(s)
+		}
+	}
+	return s
+}
+
+// trimText collapses whitespace so multi-line values fit on one line.
+func trimText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,424 @@
+package preview
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// The grid is drawn at twice the font size, roughly matching a spreadsheet
+// at 100% zoom.
+const (
+	sheetScale     = 2
+	cellPadding    = 6
+	rowHeight      = glyphHeight*sheetScale + 10
+	rowHeaderWidth = 48
+
+	defaultColChars = 8.43 // Excel's default column width
+	minGridRows     = 20
+	minGridCols     = 6
+	maxGridRows     = 40
+	maxGridCols     = 16
+	maxGridWidth    = 1600
+)
+
+var (
+	gridLine     = color.RGBA{0xda, 0xdc, 0xe0, 0xff}
+	headerFill   = color.RGBA{0xf3, 0xf3, 0xf3, 0xff}
+	headerInk    = color.RGBA{0x5f, 0x63, 0x68, 0xff}
+	cellInk      = color.RGBA{0x20, 0x21, 0x24, 0xff}
+	sheetTabLine = color.RGBA{0x1e, 0x7e, 0x45, 0xff}
+)
+
+type workbookXML struct {
+	Sheets []struct {
+		Name  string `xml:"name,attr"`
+		State string `xml:"state,attr"`
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// richText is a shared or inline string: plain <t>, or runs of <r><t>.
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r richText) String() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var sb strings.Builder
+	for _, run := range r.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+type cellXML struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline richText `xml:"is"`
+}
+
+// sheetGrid is the visible top-left corner of a worksheet.
+type sheetGrid struct {
+	widths map[int]float64 // column widths in characters, by 1-based column
+	hidden map[int]bool
+	cells  map[[2]int]gridCell // by 1-based row and column
+	rows   int
+	cols   int
+}
+
+type gridCell struct {
+	text    string
+	numeric bool
+}
+
+func renderWorkbook(data []byte, maxSheets int) ([]Page, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	parts := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+
+	var workbook workbookXML
+	if err := decodePart(parts, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels relationshipsXML
+	if err := decodePart(parts, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		targets[rel.ID] = partPath("xl", rel.Target)
+	}
+
+	// Workbooks without any text cells have no shared strings part
+	var shared struct {
+		Items []richText `xml:"si"`
+	}
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(parts, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+	strs := make([]string, len(shared.Items))
+	for i, item := range shared.Items {
+		strs[i] = item.String()
+	}
+
+	var pages []Page
+	for _, sheet := range workbook.Sheets {
+		if len(pages) == maxSheets {
+			break
+		}
+		if sheet.State == "hidden" || sheet.State == "veryHidden" {
+			continue
+		}
+		f, ok := parts[targets[sheet.RelID]]
+		if !ok {
+			continue
+		}
+
+		grid, err := readSheet(f, strs)
+		if err != nil {
+			return nil, err
+		}
+		if len(grid.cells) == 0 {
+			continue
+		}
+		pages = append(pages, Page{
+			Image: drawGrid(grid, sheet.Name),
+			Label: sheet.Name,
+		})
+	}
+	return pages, nil
+}
+
+// readSheet streams a worksheet part, stopping at the first row past the
+// visible grid so huge sheets cost no more than small ones.
+func readSheet(f *zip.File, strs []string) (*sheetGrid, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	grid := &sheetGrid{
+		widths: make(map[int]float64),
+		hidden: make(map[int]bool),
+		cells:  make(map[[2]int]gridCell),
+	}
+
+	dec := xml.NewDecoder(rc)
+	row, col := 0, 0
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch se.Name.Local {
+		case "col":
+			readColumn(grid, se)
+
+		case "row":
+			row++
+			if r, err := strconv.Atoi(attr(se, "r")); err == nil {
+				row = r
+			}
+			col = 0
+			if row > maxGridRows {
+				return grid, nil
+			}
+
+		case "c":
+			var c cellXML
+			if err := dec.DecodeElement(&c, &se); err != nil {
+				return nil, err
+			}
+			col++
+			if r, cc, ok := cellRef(c.Ref); ok {
+				row, col = r, cc
+			}
+			if row < 1 || row > maxGridRows || col < 1 || col > maxGridCols {
+				continue
+			}
+
+			text, numeric := cellText(c, strs)
+			if text == "" {
+				continue
+			}
+			grid.cells[[2]int{row, col}] = gridCell{text: text, numeric: numeric}
+			grid.rows = max(grid.rows, row)
+			grid.cols = max(grid.cols, col)
+		}
+	}
+	return grid, nil
+}
+
+func readColumn(grid *sheetGrid, se xml.StartElement) {
+	lo, err1 := strconv.Atoi(attr(se, "min"))
+	hi, err2 := strconv.Atoi(attr(se, "max"))
+	if err1 != nil || err2 != nil {
+		return
+	}
+	// The range comes straight from the file; only the visible grid matters
+	lo, hi = max(lo, 1), min(hi, maxGridCols)
+	width, _ := strconv.ParseFloat(attr(se, "width"), 64)
+	hidden := attr(se, "hidden") == "1" || attr(se, "hidden") == "true"
+	for c := lo; c <= hi; c++ {
+		if width > 0 {
+			grid.widths[c] = width
+		}
+		if hidden {
+			grid.hidden[c] = true
+		}
+	}
+}
+
+// cellText returns what a cell shows, from its cached value for formulas,
+// and whether it is a number (drawn right-aligned).
+func cellText(c cellXML, strs []string) (string, bool) {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(c.Value))
+		if err != nil || i < 0 || i >= len(strs) {
+			return "", false
+		}
+		return trimText(strs[i]), false
+	case "inlineStr":
+		return trimText(c.Inline.String()), false
+	case "b":
+		if c.Value == "1" {
+			return "TRUE", false
+		}
+		return "FALSE", false
+	case "str", "e":
+		return trimText(c.Value), false
+	}
+
+	v := strings.TrimSpace(c.Value)
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v, false
+	}
+	// Number formats live in styles.xml; two decimals reads well for the
+	// money and ratio figures templates are made of
+	if f != math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatFloat(f, 'f', 2, 64), true
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), true
+}
+
+func drawGrid(grid *sheetGrid, name string) *image.RGBA {
+	rows := min(max(grid.rows, minGridRows), maxGridRows)
+	cols := min(max(grid.cols, minGridCols), maxGridCols)
+
+	// Column x positions, skipping hidden columns and stopping once the
+	// image is wide enough
+	type column struct {
+		index, x, width int
+	}
+	var columns []column
+	x := rowHeaderWidth
+	for c := 1; c <= cols && x < maxGridWidth; c++ {
+		if grid.hidden[c] {
+			continue
+		}
+		chars, ok := grid.widths[c]
+		if !ok {
+			chars = defaultColChars
+		}
+		w := int(chars * glyphAdvance * sheetScale)
+		w = min(max(w, 3*glyphAdvance*sheetScale), 40*glyphAdvance*sheetScale)
+		columns = append(columns, column{index: c, x: x, width: w})
+		x += w
+	}
+
+	tabHeight := rowHeight + 8
+	width := x + 1
+	height := rowHeight*(rows+1) + tabHeight + 1
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	// Column and row headers
+	fill(img, image.Rect(0, 0, width, rowHeight), headerFill)
+	fill(img, image.Rect(0, 0, rowHeaderWidth, rowHeight*(rows+1)), headerFill)
+	textY := (rowHeight - glyphHeight*sheetScale) / 2
+	for _, col := range columns {
+		label := columnName(col.index)
+		drawText(img, col.x+(col.width-textWidth(label, sheetScale))/2, textY, label, sheetScale, headerInk)
+	}
+	for r := 1; r <= rows; r++ {
+		label := strconv.Itoa(r)
+		drawText(img, (rowHeaderWidth-textWidth(label, sheetScale))/2, r*rowHeight+textY, label, sheetScale, headerInk)
+	}
+
+	// Grid lines
+	gridBottom := rowHeight * (rows + 1)
+	for r := 0; r <= rows+1; r++ {
+		fill(img, image.Rect(0, r*rowHeight, width, r*rowHeight+1), gridLine)
+	}
+	fill(img, image.Rect(0, 0, 1, gridBottom), gridLine)
+	fill(img, image.Rect(rowHeaderWidth, 0, rowHeaderWidth+1, gridBottom), gridLine)
+	for _, col := range columns {
+		fill(img, image.Rect(col.x+col.width, 0, col.x+col.width+1, gridBottom), gridLine)
+	}
+
+	// Values, clipped to their cell
+	for r := 1; r <= rows; r++ {
+		for _, col := range columns {
+			cell, ok := grid.cells[[2]int{r, col.index}]
+			if !ok {
+				continue
+			}
+			text := clip(ascii(cell.text), col.width-2*cellPadding, sheetScale)
+			tx := col.x + cellPadding
+			if cell.numeric {
+				tx = col.x + col.width - cellPadding - textWidth(text, sheetScale)
+			}
+			drawText(img, tx, r*rowHeight+textY, text, sheetScale, cellInk)
+		}
+	}
+
+	// Sheet tab along the bottom, as in a spreadsheet app
+	fill(img, image.Rect(0, gridBottom+1, width, height), headerFill)
+	tabText := clip(ascii(name), width-rowHeaderWidth-4*cellPadding, sheetScale)
+	tabRight := rowHeaderWidth + textWidth(tabText, sheetScale) + 4*cellPadding
+	fill(img, image.Rect(rowHeaderWidth, gridBottom+1, tabRight, height-4), color.RGBA{0xff, 0xff, 0xff, 0xff})
+	fill(img, image.Rect(rowHeaderWidth, height-7, tabRight, height-4), sheetTabLine)
+	drawText(img, rowHeaderWidth+2*cellPadding, gridBottom+1+(tabHeight-4-glyphHeight*sheetScale)/2, tabText, sheetScale, cellInk)
+
+	return img
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// columnName turns a 1-based column number into its letters: 1 is A, 27 AA.
+func columnName(n int) string {
+	name := ""
+	for n > 0 {
+		n--
+		name = string(rune('A'+n%26)) + name
+		n /= 26
+	}
+	return name
+}
+
+// cellRef splits a reference such as "B12" into row 12 and column 2.
+func cellRef(ref string) (int, int, bool) {
+	col, i := 0, 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	row, err := strconv.Atoi(ref[i:])
+	if i == 0 || err != nil {
+		return 0, 0, false
+	}
+	return row, col, true
+}
+
+func attr(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// partPath resolves a relationship target against the folder of the part
+// that refers to it.
+func partPath(dir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Clean(path.Join(dir, target))
+}
+
+func decodePart(parts map[string]*zip.File, name string, v interface{}) error {
+	f, ok := parts[name]
+	if !ok {
+		return ErrUnsupported
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,172 @@
+package preview
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+// testWorkbook builds a minimal XLSX with one worksheet per entry of sheets,
+// named Sheet1, Sheet2, ... and sharing strs as its shared strings.
+func testWorkbook(t *testing.T, strs []string, sheets ...string) []byte {
+	t.Helper()
+
+	var entries, rels, shared bytes.Buffer
+	parts := map[string]string{}
+	for i, body := range sheets {
+		n := string(rune('1' + i))
+		entries.WriteString(`<sheet name="Sheet` + n + `" sheetId="` + n + `" r:id="rId` + n + `"/>`)
+		rels.WriteString(`<Relationship Id="rId` + n + `" Target="worksheets/sheet` + n + `.xml"/>`)
+		parts["xl/worksheets/sheet"+n+".xml"] = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + body + `</worksheet>`
+	}
+	for _, s := range strs {
+		shared.WriteString("<si><t>")
+		_ = xml.EscapeText(&shared, []byte(s))
+		shared.WriteString("</t></si>")
+	}
+	parts["xl/workbook.xml"] = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + entries.String() + `</sheets></workbook>`
+	parts["xl/_rels/workbook.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + `</Relationships>`
+	parts["xl/sharedStrings.xml"] = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + shared.String() + `</sst>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRenderWorkbook(t *testing.T) {
+	data := testWorkbook(t, []string{"Rent", "Total"},
+		`<sheetData>`+
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1"><v>1200.5</v></c></row>`+
+			`<row r="3"><c r="A3" t="s"><v>1</v></c><c r="B3" t="inlineStr"><is><t>see  below</t></is></c></row>`+
+			`</sheetData>`,
+		`<sheetData/>`, // no values, so no page
+		`<sheetData><row r="1"><c r="A1" t="b"><v>1</v></c></row></sheetData>`,
+	)
+
+	pages, err := Render(context.Background(), data, "xlsx", Options{MaxPages: 5})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if len(pages) != 2 || pages[0].Label != "Sheet1" || pages[1].Label != "Sheet3" {
+		t.Fatalf("got %d pages %+v, want Sheet1 and Sheet3", len(pages), pages)
+	}
+	for _, page := range pages {
+		if b := page.Image.Bounds(); b.Dx() < rowHeaderWidth || b.Dy() < rowHeight*minGridRows {
+			t.Errorf("%s: image is %v", page.Label, b)
+		}
+	}
+
+	pages, err = Render(context.Background(), data, "xlsx", Options{})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if len(pages) != 1 {
+		t.Errorf("default MaxPages rendered %d pages, want 1", len(pages))
+	}
+}
+
+func TestReadSheetCells(t *testing.T) {
+	data := testWorkbook(t, []string{"Label"},
+		`<cols><col min="2" max="3" width="20"/><col min="4" max="4" hidden="1"/></cols>`+
+			`<sheetData>`+
+			`<row r="2"><c r="A2" t="s"><v>0</v></c><c r="B2"><v>3.14159</v></c><c r="Q2"><v>9</v></c></row>`+
+			`<row r="41"><c r="A41"><v>1</v></c></row>`+
+			`</sheetData>`,
+	)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unzip: %v", err)
+	}
+	var sheet *zip.File
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = f
+		}
+	}
+
+	grid, err := readSheet(sheet, []string{"Label"})
+	if err != nil {
+		t.Fatalf("readSheet: %v", err)
+	}
+	if got := grid.cells[[2]int{2, 1}]; got.text != "Label" || got.numeric {
+		t.Errorf("A2 = %+v", got)
+	}
+	if got := grid.cells[[2]int{2, 2}]; got.text != "3.14" || !got.numeric {
+		t.Errorf("B2 = %+v", got)
+	}
+	// Q is past the grid, and row 41 below it
+	if len(grid.cells) != 2 || grid.rows != 2 || grid.cols != 2 {
+		t.Errorf("grid has %d cells, %d rows, %d cols", len(grid.cells), grid.rows, grid.cols)
+	}
+	if grid.widths[2] != 20 || grid.widths[3] != 20 || !grid.hidden[4] {
+		t.Errorf("widths %v, hidden %v", grid.widths, grid.hidden)
+	}
+}
+
+func TestReadColumnRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max string
+		want     int
+	}{
+		{"whole sheet", "1", "16384", maxGridCols},
+		{"hostile range", "-2000000000", "2000000000", maxGridCols},
+		{"past the grid", "20", "30", 0},
+		{"reversed", "5", "3", 0},
+		{"not a number", "A", "3", 0},
+	}
+	for _, tt := range tests {
+		grid := &sheetGrid{widths: map[int]float64{}, hidden: map[int]bool{}}
+		readColumn(grid, xml.StartElement{Attr: []xml.Attr{
+			{Name: xml.Name{Local: "min"}, Value: tt.min},
+			{Name: xml.Name{Local: "max"}, Value: tt.max},
+			{Name: xml.Name{Local: "hidden"}, Value: "1"},
+		}})
+		if len(grid.hidden) != tt.want {
+			t.Errorf("%s: %d columns hidden, want %d", tt.name, len(grid.hidden), tt.want)
+		}
+		for c := range grid.hidden {
+			if c < 1 || c > maxGridCols {
+				t.Errorf("%s: column %d is outside the grid", tt.name, c)
+			}
+		}
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	if _, err := Render(context.Background(), []byte("not a zip"), "xlsx", Options{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("junk workbook: err = %v, want ErrUnsupported", err)
+	}
+	if _, err := Render(context.Background(), testWorkbook(t, nil), "docx", Options{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("docx: err = %v, want ErrUnsupported", err)
+	}
+	if Supports("docx") || !Supports("xlsm") {
+		t.Error("Supports disagrees with Render")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for n, want := range map[int]string{1: "A", 16: "P", 26: "Z", 27: "AA", 703: "AAA"} {
+		if got := columnName(n); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", n, got, want)
+		}
+		if _, col, ok := cellRef(want + "7"); !ok || col != n {
+			t.Errorf("cellRef(%s7) = %d, %v", want, col, ok)
+		}
+	}
+}
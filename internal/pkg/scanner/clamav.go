@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// CLAMAV - clamd INSTREAM over a unix or TCP socket
+// ============================================================================
+
+// clamChunkSize stays well below clamd's default StreamMaxLength chunking.
+const clamChunkSize = 64 * 1024
+
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV connects to clamd at address, given as "unix:///path/to/clamd.sock",
+// "tcp://host:port" or plain "host:port". Every scan opens its own
+// connection and gives up after timeout.
+func NewClamAV(address string, timeout time.Duration) (*ClamAV, error) {
+	network, addr := "tcp", address
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		addr = strings.TrimPrefix(address, "tcp://")
+	}
+	if addr == "" {
+		return nil, errors.New("clamav address is required")
+	}
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	return &ClamAV{network: network, address: addr, timeout: timeout}, nil
+}
+
+func (c *ClamAV) Name() string { return "clamav" }
+
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("clamav: %w", err)
+	}
+
+	// Each chunk is a big-endian length followed by the data; a zero
+	// length ends the stream.
+	buf := make([]byte, 4+clamChunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				// clamd closes the socket when the stream exceeds its
+				// limit; its reply explains why
+				if reply, replyErr := readReply(conn); replyErr == nil {
+					return nil, fmt.Errorf("clamav: %s", reply)
+				}
+				return nil, fmt.Errorf("clamav: %w", err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("clamav: %w", err)
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return nil, fmt.Errorf("clamav: %w", err)
+	}
+	return parseReply(reply)
+}
+
+func (c *ClamAV) dial(ctx context.Context) (net.Conn, error) {
+	d := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamav: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(c.timeout))
+	return conn, nil
+}
+
+// readReply reads one NUL-terminated reply, as sent for z-prefixed commands.
+func readReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && !(errors.Is(err, io.EOF) && len(reply) > 0) {
+		return "", err
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+// parseReply turns "stream: OK" or "stream: <signature> FOUND" into a result.
+func parseReply(reply string) (*Result, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+
+	switch {
+	case verdict == "OK":
+		return &Result{Scanned: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{
+			Scanned:   true,
+			Infected:  true,
+			Signature: strings.TrimSuffix(verdict, " FOUND"),
+		}, nil
+	}
+	return nil, fmt.Errorf("clamav: %s", reply)
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// ============================================================================
+// CLOUDINARY
+// ============================================================================
+
+type CloudinaryConfig struct {
+	CloudName string
+	APIKey    string
+	APISecret string
+	// Folder is the root folder; public objects go under Folder/public.
+	Folder string
+}
+
+type Cloudinary struct {
+	cld *cloudinary.Cloudinary
+	cfg CloudinaryConfig
+}
+
+func NewCloudinary(cfg CloudinaryConfig) (*Cloudinary, error) {
+	cld, err := cloudinary.NewFromParams(cfg.CloudName, cfg.APIKey, cfg.APISecret)
+	if err != nil {
+		return nil, err
+	}
+	return &Cloudinary{cld: cld, cfg: cfg}, nil
+}
+
+func (c *Cloudinary) Provider() string { return "cloudinary" }
+
+// Put uploads public files as ordinary "upload" assets. Private files are
+// uploaded as "authenticated" assets, which Cloudinary refuses to deliver
+// without a signature. Their keys read "private/<resource type>/<public ID>"
+// because the resource type Cloudinary picked is needed to address them.
+func (c *Cloudinary) Put(ctx context.Context, r io.Reader, opts PutOptions) (*Object, error) {
+	private := opts.Visibility != Public
+
+	folder := c.cfg.Folder
+	if !private {
+		folder += "/public"
+	}
+	if opts.Folder != "" {
+		folder += "/" + opts.Folder
+	}
+
+	params := uploader.UploadParams{
+		Folder:       folder,
+		ResourceType: "auto",
+		PublicID:     opts.Name,
+	}
+	if private {
+		// The public ID keeps the extension so the download API knows the
+		// format; the name is chosen the same way the other backends do
+		name := opts.Name
+		if name == "" {
+			prefix, err := randomHex(8)
+			if err != nil {
+				return nil, err
+			}
+			name = prefix + "_" + sanitizeName(opts.Filename)
+		}
+		params.PublicID = name
+		params.Type = api.Authenticated
+	}
+
+	result, err := c.cld.Upload.Upload(ctx, r, params)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error.Message != "" {
+		return nil, errors.New(result.Error.Message)
+	}
+
+	obj := &Object{
+		Key:          result.PublicID,
+		URL:          result.SecureURL,
+		Format:       result.Format,
+		ResourceType: result.ResourceType,
+		Size:         int64(result.Bytes),
+		ModTime:      result.CreatedAt,
+	}
+	if private {
+		// Authenticated URLs are refused without a signature; callers must
+		// ask SignedURL for a link
+		obj.Key = cloudinaryPrivateKey(result.ResourceType, result.PublicID)
+		obj.URL = ""
+	}
+	return obj, nil
+}
+
+func (c *Cloudinary) Delete(ctx context.Context, key string) error {
+	params := uploader.DestroyParams{PublicID: key}
+	if asset, ok := parseCloudinaryKey(key); ok {
+		params = uploader.DestroyParams{
+			PublicID:     asset.publicID,
+			Type:         api.Authenticated,
+			ResourceType: asset.resourceType,
+		}
+	}
+
+	result, err := c.cld.Upload.Destroy(ctx, params)
+	if err != nil {
+		return err
+	}
+	if msg := result.Error.Message; msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// SignedURL returns a download API link that expires after ttl and serves
+// the file as an attachment. Keys from before private files were
+// authenticated are addressed as the raw uploads they were stored as.
+//
+// The URL is built here rather than with the SDK's PrivateDownloadURL,
+// which sends expires_at as a date where the API expects a Unix time.
+func (c *Cloudinary) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	asset, ok := parseCloudinaryKey(key)
+	if !ok {
+		asset = cloudinaryAsset{resourceType: string(api.File), deliveryType: string(api.Upload), publicID: key}
+	}
+
+	params := url.Values{
+		"public_id":  {asset.publicID},
+		"type":       {asset.deliveryType},
+		"attachment": {"true"},
+		"expires_at": {strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)},
+		"timestamp":  {strconv.FormatInt(time.Now().Unix(), 10)},
+	}
+	// Raw public IDs include the extension; other resource types are
+	// converted to the format asked for, which is the original one
+	if asset.resourceType != string(api.File) {
+		params.Set("format", formatOf(asset.publicID))
+	}
+
+	signature, err := api.SignParameters(params, c.cfg.APISecret)
+	if err != nil {
+		return "", err
+	}
+	params.Set("signature", signature)
+	params.Set("api_key", c.cfg.APIKey)
+
+	return fmt.Sprintf("%s/v1_1/%s/%s/download?%s",
+		c.cld.Upload.Config.API.UploadPrefix,
+		c.cfg.CloudName,
+		asset.resourceType,
+		params.Encode(),
+	), nil
+}
+
+func (c *Cloudinary) PublicURL(key string) string {
+	return fmt.Sprintf("https://res.cloudinary.com/%s/raw/upload/%s", c.cfg.CloudName, key)
+}
+
+// Stat looks the public ID up as a raw file first, then as an image (PDFs
+// uploaded with resource type "auto" end up as images). Private keys name
+// their resource type, so they are looked up once.
+func (c *Cloudinary) Stat(ctx context.Context, key string) (*Object, error) {
+	lookups := []admin.AssetParams{
+		{AssetType: api.File, PublicID: key},
+		{AssetType: api.Image, PublicID: key},
+	}
+	asset, private := parseCloudinaryKey(key)
+	if private {
+		lookups = []admin.AssetParams{{
+			AssetType:    api.AssetType(asset.resourceType),
+			DeliveryType: api.Authenticated,
+			PublicID:     asset.publicID,
+		}}
+	}
+
+	for _, params := range lookups {
+		result, err := c.cld.Admin.Asset(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if msg := result.Error.Message; msg != "" {
+			if strings.Contains(strings.ToLower(msg), "not found") {
+				continue
+			}
+			return nil, errors.New(msg)
+		}
+
+		obj := &Object{
+			Key:          result.PublicID,
+			URL:          result.SecureURL,
+			Format:       result.Format,
+			ResourceType: result.ResourceType,
+			Size:         int64(result.Bytes),
+			ModTime:      result.CreatedAt,
+		}
+		if private {
+			obj.Key = key
+			obj.URL = ""
+		}
+		return obj, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (c *Cloudinary) Open(ctx context.Context, key string, header http.Header) (*http.Response, error) {
+	signedURL, err := c.SignedURL(ctx, key, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	return openURL(ctx, signedURL, header)
+}
+
+func (c *Cloudinary) Ping(ctx context.Context) error {
+	result, err := c.cld.Admin.Ping(ctx)
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
+
+// cloudinaryAsset addresses a stored asset. Public IDs on their own start
+// with the configured folder, so keys starting with "private/" are
+// unambiguous.
+type cloudinaryAsset struct {
+	resourceType string
+	deliveryType string
+	publicID     string
+}
+
+func cloudinaryPrivateKey(resourceType, publicID string) string {
+	return string(Private) + "/" + resourceType + "/" + publicID
+}
+
+func parseCloudinaryKey(key string) (cloudinaryAsset, bool) {
+	rest, ok := strings.CutPrefix(key, string(Private)+"/")
+	if !ok {
+		return cloudinaryAsset{}, false
+	}
+	resourceType, publicID, ok := strings.Cut(rest, "/")
+	switch api.AssetType(resourceType) {
+	case api.Image, api.Video, api.File:
+	default:
+		ok = false
+	}
+	if !ok || publicID == "" {
+		return cloudinaryAsset{}, false
+	}
+	return cloudinaryAsset{
+		resourceType: resourceType,
+		deliveryType: api.Authenticated,
+		publicID:     publicID,
+	}, true
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2/api"
+)
+
+func newTestCloudinary(t *testing.T, handler http.HandlerFunc) *Cloudinary {
+	t.Helper()
+	c, err := NewCloudinary(CloudinaryConfig{
+		CloudName: "merraki-test",
+		APIKey:    "123456789",
+		APISecret: "test-secret",
+		Folder:    "merraki",
+	})
+	if err != nil {
+		t.Fatalf("NewCloudinary: %v", err)
+	}
+	if handler != nil {
+		srv := httptest.NewServer(handler)
+		t.Cleanup(srv.Close)
+		c.cld.Upload.Config.API.UploadPrefix = srv.URL
+		c.cld.Admin.Config.API.UploadPrefix = srv.URL
+	}
+	return c
+}
+
+// checkCloudinarySignature verifies a signed request the way Cloudinary does:
+// every parameter except the signature, api_key, file and resource_type.
+func checkCloudinarySignature(t *testing.T, values url.Values) {
+	t.Helper()
+	signed := url.Values{}
+	for k, v := range values {
+		switch k {
+		case "signature", "api_key", "file", "resource_type", "cloud_name":
+			continue
+		}
+		signed[k] = v
+	}
+	want, err := api.SignParameters(signed, "test-secret")
+	if err != nil {
+		t.Fatalf("SignParameters: %v", err)
+	}
+	if got := values.Get("signature"); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+}
+
+func TestCloudinaryPutPrivateIsAuthenticated(t *testing.T) {
+	var form url.Values
+	var uploadPath string
+	c := newTestCloudinary(t, func(w http.ResponseWriter, r *http.Request) {
+		uploadPath = r.URL.Path
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse upload: %v", err)
+		}
+		form = url.Values(r.MultipartForm.Value)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"public_id":     form.Get("folder") + "/" + form.Get("public_id"),
+			"resource_type": "raw",
+			"type":          form.Get("type"),
+			"bytes":         4,
+			"secure_url":    "https://res.cloudinary.com/merraki-test/raw/authenticated/v1/x",
+		})
+	})
+
+	obj, err := c.Put(context.Background(), strings.NewReader("data"), PutOptions{
+		Folder:     "templates",
+		Filename:   "Budget Planner.xlsx",
+		Visibility: Private,
+		Size:       4,
+	})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if uploadPath != "/v1_1/merraki-test/auto/upload" {
+		t.Errorf("uploaded to %s, want the auto upload endpoint", uploadPath)
+	}
+	if got := form.Get("type"); got != "authenticated" {
+		t.Errorf("type = %q, want authenticated", got)
+	}
+	if got := form.Get("public_id"); !strings.HasSuffix(got, "_Budget_Planner.xlsx") {
+		t.Errorf("public_id = %q, want a unique name keeping the extension", got)
+	}
+	checkCloudinarySignature(t, form)
+
+	if !strings.HasPrefix(obj.Key, "private/raw/merraki/templates/") {
+		t.Errorf("key = %q, want private/<resource type>/<public ID>", obj.Key)
+	}
+	if obj.URL != "" {
+		t.Errorf("private object has a URL %q", obj.URL)
+	}
+}
+
+func TestCloudinaryPutPublicIsUpload(t *testing.T) {
+	var form url.Values
+	c := newTestCloudinary(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse upload: %v", err)
+		}
+		form = url.Values(r.MultipartForm.Value)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"public_id":     "merraki/public/images/cover",
+			"resource_type": "image",
+			"secure_url":    "https://res.cloudinary.com/merraki-test/image/upload/v1/merraki/public/images/cover.png",
+		})
+	})
+
+	obj, err := c.Put(context.Background(), strings.NewReader("data"), PutOptions{
+		Folder:     "images",
+		Name:       "cover",
+		Visibility: Public,
+		Size:       4,
+	})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if got := form.Get("type"); got != "" {
+		t.Errorf("public upload sent type %q", got)
+	}
+	if obj.Key != "merraki/public/images/cover" || obj.URL == "" {
+		t.Errorf("object = %+v, want the public ID and its URL", obj)
+	}
+}
+
+func TestCloudinarySignedURL(t *testing.T) {
+	c := newTestCloudinary(t, nil)
+
+	tests := []struct {
+		key          string
+		resourceType string
+		publicID     string
+		deliveryType string
+		format       string
+	}{
+		{"private/raw/merraki/templates/ab12_planner.xlsx", "raw", "merraki/templates/ab12_planner.xlsx", "authenticated", ""},
+		{"private/image/merraki/invoices/ORD-1.pdf", "image", "merraki/invoices/ORD-1.pdf", "authenticated", "pdf"},
+		// Stored before private files were authenticated
+		{"merraki/templates/legacy.pdf", "raw", "merraki/templates/legacy.pdf", "upload", ""},
+	}
+
+	for _, tt := range tests {
+		before := time.Now()
+		raw, err := c.SignedURL(context.Background(), tt.key, 15*time.Minute)
+		if err != nil {
+			t.Fatalf("SignedURL(%s): %v", tt.key, err)
+		}
+
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("parse %s: %v", raw, err)
+		}
+		if u.Host != "api.cloudinary.com" || u.Path != "/v1_1/merraki-test/"+tt.resourceType+"/download" {
+			t.Errorf("%s: URL %s is not the %s download API", tt.key, raw, tt.resourceType)
+		}
+
+		q := u.Query()
+		if q.Get("public_id") != tt.publicID || q.Get("type") != tt.deliveryType ||
+			q.Get("format") != tt.format || q.Get("attachment") != "true" {
+			t.Errorf("%s: query = %v", tt.key, q)
+		}
+		expiresAt, _ := strconv.ParseInt(q.Get("expires_at"), 10, 64)
+		if want := before.Add(15 * time.Minute).Unix(); expiresAt < want || expiresAt > want+1 {
+			t.Errorf("%s: expires_at = %d, want about %d", tt.key, expiresAt, want)
+		}
+		if q.Get("api_key") != "123456789" {
+			t.Errorf("%s: api_key = %q", tt.key, q.Get("api_key"))
+		}
+		checkCloudinarySignature(t, q)
+	}
+}
+
+func TestCloudinaryDeletePrivate(t *testing.T) {
+	var form url.Values
+	var destroyPath string
+	c := newTestCloudinary(t, func(w http.ResponseWriter, r *http.Request) {
+		destroyPath = r.URL.Path
+		// The SDK posts an urlencoded body without a Content-Type
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+
+	if err := c.Delete(context.Background(), "private/raw/merraki/templates/ab12_planner.xlsx"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if destroyPath != "/v1_1/merraki-test/raw/destroy" {
+		t.Errorf("destroyed via %s, want the raw destroy endpoint", destroyPath)
+	}
+	if form.Get("public_id") != "merraki/templates/ab12_planner.xlsx" || form.Get("type") != "authenticated" {
+		t.Errorf("destroy params = %v", form)
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// servePipe runs handler in the background and returns its output as an
+// *http.Response as soon as the status line is written, streaming the body
+// through a pipe instead of buffering it.
+func servePipe(req *http.Request, handler http.HandlerFunc) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	w := &pipeWriter{
+		header: http.Header{},
+		body:   pw,
+		ready:  make(chan struct{}),
+	}
+
+	go func() {
+		defer func() {
+			w.WriteHeader(http.StatusOK)
+			pw.Close()
+		}()
+		handler(w, req)
+	}()
+
+	<-w.ready
+
+	resp := &http.Response{
+		Status:        strconv.Itoa(w.status) + " " + http.StatusText(w.status),
+		StatusCode:    w.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.sent,
+		Body:          pr,
+		ContentLength: -1,
+		Request:       req,
+	}
+	if n, err := strconv.ParseInt(w.sent.Get("Content-Length"), 10, 64); err == nil {
+		resp.ContentLength = n
+	}
+	return resp, nil
+}
+
+type pipeWriter struct {
+	header http.Header
+	sent   http.Header
+	body   *io.PipeWriter
+	status int
+	once   sync.Once
+	ready  chan struct{}
+}
+
+func (w *pipeWriter) Header() http.Header { return w.header }
+
+func (w *pipeWriter) WriteHeader(status int) {
+	w.once.Do(func() {
+		w.status = status
+		// Snapshot: the caller reads these while the handler keeps running
+		w.sent = w.header.Clone()
+		close(w.ready)
+	})
+}
+
+func (w *pipeWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
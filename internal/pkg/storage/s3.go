@@ -0,0 +1,397 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// S3-COMPATIBLE - AWS S3, MinIO, R2, ...
+// ============================================================================
+
+type S3Config struct {
+	// Endpoint is host[:port], e.g. "localhost:9000" for MinIO. Empty means
+	// AWS ("s3.<region>.amazonaws.com").
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// PathStyle addresses buckets as endpoint/bucket/key, which MinIO needs.
+	PathStyle bool
+	// Bucket holds private objects. PublicBucket holds public ones and
+	// should allow anonymous reads; when empty, Bucket is used for both and
+	// the "public/" prefix must be made readable by a bucket policy.
+	Bucket       string
+	PublicBucket string
+	// PublicURL overrides the base URL of public objects, e.g. a CDN.
+	PublicURL string
+}
+
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+}
+
+// s3Client bounds metadata requests; object bodies go through streamClient.
+var s3Client = &http.Client{Timeout: 60 * time.Second}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 storage needs a bucket, access key and secret key")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("s3.%s.amazonaws.com", cfg.Region)
+		cfg.UseSSL = true
+	}
+	if cfg.PublicBucket == "" {
+		cfg.PublicBucket = cfg.Bucket
+	}
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+
+	return &S3{cfg: cfg, client: s3Client}, nil
+}
+
+func (s *S3) Provider() string { return "s3" }
+
+func (s *S3) Put(ctx context.Context, r io.Reader, opts PutOptions) (*Object, error) {
+	key, err := objectKey(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	body, size, cleanup, err := sizedReader(r, opts.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	contentType := contentTypeOf(key, opts.ContentType)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(s.bucketFor(key), key), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, time.Now())
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp)
+	}
+
+	obj := &Object{
+		Key:          key,
+		Format:       formatOf(key),
+		ContentType:  contentType,
+		ResourceType: "raw",
+		Size:         size,
+		ModTime:      time.Now(),
+	}
+	if keyVisibility(key) == Public {
+		obj.URL = s.PublicURL(key)
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(s.bucketFor(key), key))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// SignedURL presigns a GET that downloads the object as an attachment.
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.presign(key, ttl, url.Values{
+		"response-content-disposition": {fmt.Sprintf(`attachment; filename="%s"`, path.Base(key))},
+	})
+}
+
+func (s *S3) PublicURL(key string) string {
+	if s.cfg.PublicURL != "" {
+		return s.cfg.PublicURL + "/" + key
+	}
+	return s.objectURL(s.cfg.PublicBucket, key)
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	resp, err := s.do(ctx, http.MethodHead, s.objectURL(s.bucketFor(key), key))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, s3Error(resp)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &Object{
+		Key:          key,
+		Format:       formatOf(key),
+		ContentType:  resp.Header.Get("Content-Type"),
+		ResourceType: "raw",
+		Size:         resp.ContentLength,
+		ModTime:      modTime,
+	}, nil
+}
+
+func (s *S3) Open(ctx context.Context, key string, header http.Header) (*http.Response, error) {
+	signedURL, err := s.presign(key, 5*time.Minute, nil)
+	if err != nil {
+		return nil, err
+	}
+	return openURL(ctx, signedURL, header)
+}
+
+// Ping checks that the private bucket exists and the credentials work.
+func (s *S3) Ping(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodHead, s.bucketURL(s.cfg.Bucket))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("s3 bucket %q returned status %d", s.cfg.Bucket, resp.StatusCode)
+	}
+	return nil
+}
+
+// ============================================================================
+// ADDRESSING
+// ============================================================================
+
+func (s *S3) bucketFor(key string) string {
+	if keyVisibility(key) == Public {
+		return s.cfg.PublicBucket
+	}
+	return s.cfg.Bucket
+}
+
+func (s *S3) scheme() string {
+	if s.cfg.UseSSL {
+		return "https"
+	}
+	return "http"
+}
+
+func (s *S3) bucketURL(bucket string) string {
+	if s.cfg.PathStyle {
+		return fmt.Sprintf("%s://%s/%s/", s.scheme(), s.cfg.Endpoint, bucket)
+	}
+	return fmt.Sprintf("%s://%s.%s/", s.scheme(), bucket, s.cfg.Endpoint)
+}
+
+func (s *S3) objectURL(bucket, key string) string {
+	return s.bucketURL(bucket) + uriEncode(key, false)
+}
+
+func (s *S3) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, time.Now())
+	return s.client.Do(req)
+}
+
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// ============================================================================
+// SIGNATURE V4
+// ============================================================================
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+)
+
+// sign adds an Authorization header. Bodies are sent as UNSIGNED-PAYLOAD
+// so uploads can stream without hashing the file first.
+func (s *S3) sign(req *http.Request, now time.Time) {
+	s.signPayload(req, now, unsignedPayload)
+}
+
+// signPayload signs req with the given payload hash. Host, Content-Type,
+// Range, Date and every X-Amz-* header are signed.
+func (s *S3) signPayload(req *http.Request, now time.Time, payloadHash string) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	values := map[string]string{"host": req.URL.Host}
+	for name, vals := range req.Header {
+		name = strings.ToLower(name)
+		switch {
+		case name == "content-type", name == "range", name == "date", strings.HasPrefix(name, "x-amz-"):
+			values[name] = strings.Join(vals, ",")
+		}
+	}
+	signed := make([]string, 0, len(values))
+	for name := range values {
+		signed = append(signed, name)
+	}
+	sort.Strings(signed)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signed {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(values[name]) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(now, scope, amzDate, canonical)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.cfg.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// presign returns a query-signed GET URL for key.
+func (s *S3) presign(key string, ttl time.Duration, extra url.Values) (string, error) {
+	return s.presignAt(s.objectURL(s.bucketFor(key), key), time.Now(), ttl, extra)
+}
+
+func (s *S3) presignAt(rawURL string, now time.Time, ttl time.Duration, extra url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	scope := s.scope(now)
+
+	q := url.Values{}
+	for k, v := range extra {
+		q[k] = v
+	}
+	q.Set("X-Amz-Algorithm", sigV4Algorithm)
+	q.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		canonicalPath(u),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	u.RawQuery = canonicalQuery(q) + "&X-Amz-Signature=" + s.signature(now, scope, amzDate, canonical)
+	return u.String(), nil
+}
+
+func (s *S3) scope(now time.Time) string {
+	return fmt.Sprintf("%s/%s/s3/aws4_request", now.Format("20060102"), s.cfg.Region)
+}
+
+func (s *S3) signature(now time.Time, scope, amzDate, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func canonicalPath(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything except unreserved characters, as
+// SigV4 requires. Slashes are kept in object paths.
+func uriEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// ============================================================================
+// CONTENT SNIFFING - File type from magic bytes, not the client's word
+// ============================================================================
+
+// ErrUnknownFileType is returned by Sniff for content it does not recognise.
+var ErrUnknownFileType = errors.New("storage: unrecognised file type")
+
+// FileType is the detected format (a file extension without the dot) and
+// its MIME type.
+type FileType struct {
+	Format      string
+	ContentType string
+}
+
+var (
+	magicPDF  = []byte("%PDF-")
+	magicPNG  = []byte("\x89PNG\r\n\x1a\n")
+	magicJPEG = []byte("\xff\xd8\xff")
+	magicGIF  = [][]byte{[]byte("GIF87a"), []byte("GIF89a")}
+	magicZIP  = [][]byte{[]byte("PK\x03\x04"), []byte("PK\x05\x06")}
+	// OLE2 compound files: legacy .xls, .doc and .ppt
+	magicOLE = []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")
+)
+
+var contentTypes = map[string]string{
+	"pdf":  "application/pdf",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"zip":  "application/zip",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"xlsm": "application/vnd.ms-excel.sheet.macroEnabled.12",
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"xls":  "application/vnd.ms-excel",
+	"doc":  "application/msword",
+	"ppt":  "application/vnd.ms-powerpoint",
+}
+
+// Sniff detects the type of the size bytes in r. filename is only used to
+// tell apart formats that share a container (xlsx/xlsm, xls/doc/ppt).
+func Sniff(r io.ReaderAt, size int64, filename string) (FileType, error) {
+	head := make([]byte, 512)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return FileType{}, err
+	}
+	head = head[:n]
+	ext := Extension(filename)
+
+	format := ""
+	switch {
+	case bytes.HasPrefix(head, magicPDF):
+		format = "pdf"
+	case bytes.HasPrefix(head, magicPNG):
+		format = "png"
+	case bytes.HasPrefix(head, magicJPEG):
+		format = "jpg"
+	case hasAnyPrefix(head, magicGIF):
+		format = "gif"
+	case len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")):
+		format = "webp"
+	case hasAnyPrefix(head, magicZIP):
+		format = sniffZip(r, size, ext)
+	case bytes.HasPrefix(head, magicOLE):
+		// The stream directory would tell these apart; the extension is
+		// good enough once the container itself is known
+		switch ext {
+		case "xls", "doc", "ppt":
+			format = ext
+		}
+	}
+
+	if format == "" {
+		return FileType{}, ErrUnknownFileType
+	}
+	return FileType{Format: format, ContentType: contentTypes[format]}, nil
+}
+
+// sniffZip tells Office Open XML documents from plain archives by their
+// top-level part folders.
+func sniffZip(r io.ReaderAt, size int64, ext string) string {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return ""
+	}
+
+	for _, f := range zr.File {
+		switch {
+		case strings.HasPrefix(f.Name, "xl/"):
+			if ext == "xlsm" {
+				return "xlsm"
+			}
+			return "xlsx"
+		case strings.HasPrefix(f.Name, "word/"):
+			return "docx"
+		case strings.HasPrefix(f.Name, "ppt/"):
+			return "pptx"
+		}
+	}
+	return "zip"
+}
+
+// Extension returns the lower-case extension of filename without the dot,
+// with "jpeg" folded into "jpg".
+func Extension(filename string) string {
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(strings.ReplaceAll(filename, "\\", "/"))), ".")
+	if ext == "jpeg" {
+		return "jpg"
+	}
+	return ext
+}
+
+func hasAnyPrefix(b []byte, prefixes [][]byte) bool {
+	for _, p := range prefixes {
+		if bytes.HasPrefix(b, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+// Package storage abstracts where uploaded files live.
+//
+// Keys are opaque strings returned by Put and stored in the database. The
+// local and S3 backends prefix every key with its visibility ("private/" or
+// "public/"), so private template files and public images never share a
+// folder or bucket. Cloudinary keeps its own public IDs, prefixing only
+// those of private (authenticated) assets with "private/".
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Stat for keys that do not exist.
+var ErrNotFound = errors.New("storage: object not found")
+
+type Visibility string
+
+const (
+	Private Visibility = "private"
+	Public  Visibility = "public"
+)
+
+// Object describes a stored file.
+type Object struct {
+	Key          string
+	URL          string
+	Format       string
+	ContentType  string
+	ResourceType string
+	Size         int64
+	ModTime      time.Time
+}
+
+type PutOptions struct {
+	// Folder groups objects by purpose, e.g. "templates" or "invoices".
+	Folder string
+	// Name fixes the object name so a later Put replaces it. When empty, a
+	// unique name is derived from Filename.
+	Name        string
+	Filename    string
+	ContentType string
+	Visibility  Visibility
+	// Size is the length of the reader, or -1 when unknown.
+	Size int64
+}
+
+type Storage interface {
+	// Provider is the backend name, as set in STORAGE_PROVIDER.
+	Provider() string
+	Put(ctx context.Context, r io.Reader, opts PutOptions) (*Object, error)
+	// Delete removes an object. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited URL that downloads a private object
+	// as an attachment.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// PublicURL returns the permanent URL of a public object.
+	PublicURL(key string) string
+	Stat(ctx context.Context, key string) (*Object, error)
+	// Open fetches an object, honouring Range and If-Range in header. The
+	// caller must close the response body.
+	Open(ctx context.Context, key string, header http.Header) (*http.Response, error)
+	// Ping checks that the backend is reachable and usable.
+	Ping(ctx context.Context) error
+}
+
+// ============================================================================
+// SHARED HELPERS
+// ============================================================================
+
+// objectKey builds "<visibility>/<folder>/<name>" for backends that choose
+// their own keys.
+func objectKey(opts PutOptions) (string, error) {
+	name := opts.Name
+	if name == "" {
+		prefix, err := randomHex(8)
+		if err != nil {
+			return "", err
+		}
+		name = prefix + "_" + path.Base(strings.ReplaceAll(opts.Filename, "\\", "/"))
+	}
+
+	parts := []string{string(visibilityOrDefault(opts.Visibility))}
+	if folder := sanitizePath(opts.Folder); folder != "" {
+		parts = append(parts, folder)
+	}
+	parts = append(parts, sanitizeName(name))
+	return strings.Join(parts, "/"), nil
+}
+
+func visibilityOrDefault(v Visibility) Visibility {
+	if v == Public {
+		return Public
+	}
+	return Private
+}
+
+// keyVisibility reads the visibility back from a key built by objectKey.
+func keyVisibility(key string) Visibility {
+	if strings.HasPrefix(key, string(Public)+"/") {
+		return Public
+	}
+	return Private
+}
+
+// sanitizeName keeps letters, digits, dot, dash and underscore so keys are
+// safe as file names and need no escaping in URLs.
+func sanitizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	out := strings.TrimLeft(sb.String(), ".")
+	if out == "" {
+		return "file"
+	}
+	return out
+}
+
+func sanitizePath(p string) string {
+	var parts []string
+	for _, part := range strings.Split(p, "/") {
+		if part = strings.Trim(part, " ."); part != "" {
+			parts = append(parts, sanitizeName(part))
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// validKey rejects keys that could escape the storage root.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	if path.Clean(key) != key {
+		return false
+	}
+	scope, _, found := strings.Cut(key, "/")
+	return found && (scope == string(Private) || scope == string(Public))
+}
+
+func formatOf(key string) string {
+	return strings.TrimPrefix(strings.ToLower(path.Ext(key)), ".")
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// sizedReader returns r with a known length, spooling it to a temporary
+// file when the length cannot be determined up front. Backends that must
+// send Content-Length use it; cleanup must always be called.
+func sizedReader(r io.Reader, size int64) (io.Reader, int64, func(), error) {
+	noop := func() {}
+	if size >= 0 {
+		return r, size, noop, nil
+	}
+	if l, ok := r.(interface{ Len() int }); ok {
+		return r, int64(l.Len()), noop, nil
+	}
+
+	tmp, err := os.CreateTemp("", "merraki-upload-*")
+	if err != nil {
+		return nil, 0, noop, err
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, noop, err
+	}
+	return tmp, n, cleanup, nil
+}
+
+// streamClient has no overall timeout: large files may take minutes to
+// stream, but the upstream must start answering quickly.
+var streamClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 30 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// openURL fetches rawURL forwarding header, for backends whose signed URLs
+// can be read directly.
+func openURL(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch file: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	return nil, fmt.Errorf("storage returned status %d", resp.StatusCode)
+}
@@ -0,0 +1,363 @@
+package watermark
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	contentTypesPart = "[Content_Types].xml"
+	rootRelsPart     = "_rels/.rels"
+	workbookPart     = "xl/workbook.xml"
+	workbookRelsPart = "xl/_rels/workbook.xml.rels"
+
+	relationshipsNS = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	customPropsNS   = "http://schemas.openxmlformats.org/officeDocument/2006/custom-properties"
+	vtypesNS        = "http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"
+	spreadsheetNS   = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+
+	customPropsRel   = relationshipsNS + "/custom-properties"
+	worksheetRel     = relationshipsNS + "/worksheet"
+	customPropsCType = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+	worksheetCType   = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
+
+	// Format ID Office uses for user-defined document properties
+	customPropsFmtID = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+
+	licenseSheetName = "License"
+)
+
+var (
+	relationshipRe = regexp.MustCompile(`<Relationship\b[^>]*>`)
+	relTypeRe      = regexp.MustCompile(`\bType="([^"]*)"`)
+	relTargetRe    = regexp.MustCompile(`\bTarget="([^"]*)"`)
+	relIDRe        = regexp.MustCompile(`\bId="([^"]*)"`)
+	pidRe          = regexp.MustCompile(`\bpid="(\d+)"`)
+	sheetIDRe      = regexp.MustCompile(`\bsheetId="(\d+)"`)
+	sheetNameRe    = regexp.MustCompile(`<(?:\w+:)?sheet\b[^>]*\bname="([^"]*)"`)
+	sheetsCloseRe  = regexp.MustCompile(`</(\w+:)?sheets>`)
+)
+
+// OOXML stamps an Office Open XML package (XLSX, DOCX, PPTX) by adding
+// custom document properties. Workbooks also get a hidden "License" sheet,
+// which survives copy-and-save in a way document properties may not.
+func OOXML(data []byte, stamp Stamp) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	parts := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+	if parts[contentTypesPart] == nil || parts[rootRelsPart] == nil {
+		return nil, ErrUnsupported
+	}
+
+	changed := make(map[string][]byte)
+	read := func(name string) ([]byte, error) {
+		if b, ok := changed[name]; ok {
+			return b, nil
+		}
+		f := parts[name]
+		if f == nil {
+			return nil, nil
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	contentTypes, err := read(contentTypesPart)
+	if err != nil {
+		return nil, err
+	}
+	rootRels, err := read(rootRelsPart)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Custom document properties
+	customPart := "docProps/custom.xml"
+	if target := relTarget(rootRels, customPropsRel); target != "" {
+		customPart = strings.TrimPrefix(target, "/")
+	} else if rootRels, _, err = addRelationship(rootRels, customPropsRel, customPart); err != nil {
+		return nil, err
+	}
+
+	custom, err := read(customPart)
+	if err != nil {
+		return nil, err
+	}
+	if custom, err = stampCustomProps(custom, stamp); err != nil {
+		return nil, err
+	}
+
+	changed[rootRelsPart] = rootRels
+	changed[customPart] = custom
+	contentTypes = addOverride(contentTypes, "/"+customPart, customPropsCType)
+
+	// 2. Hidden licence sheet for workbooks
+	if parts[workbookPart] != nil && parts[workbookRelsPart] != nil {
+		sheetPart, err := addLicenseSheet(changed, read, stamp)
+		if err != nil {
+			return nil, err
+		}
+		if sheetPart != "" {
+			contentTypes = addOverride(contentTypes, "/"+sheetPart, worksheetCType)
+		}
+	}
+
+	changed[contentTypesPart] = contentTypes
+
+	// 3. Re-pack: untouched parts are copied without recompressing
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+
+	for _, f := range zr.File {
+		b, ok := changed[f.Name]
+		if !ok {
+			if err := zw.Copy(f); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := writePart(zw, f.Name, b); err != nil {
+			return nil, err
+		}
+		delete(changed, f.Name)
+	}
+
+	added := make([]string, 0, len(changed))
+	for name := range changed {
+		added = append(added, name)
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		if err := writePart(zw, name, changed[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func writePart(zw *zip.Writer, name string, b []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// stampCustomProps adds the stamp to an existing custom.xml, or creates
+// one when the document has none.
+func stampCustomProps(existing []byte, stamp Stamp) ([]byte, error) {
+	if existing == nil {
+		var sb strings.Builder
+		sb.WriteString(xml.Header)
+		fmt.Fprintf(&sb, `<Properties xmlns="%s" xmlns:vt="%s">`, customPropsNS, vtypesNS)
+		writeProperties(&sb, stamp, "vt", 2)
+		sb.WriteString(`</Properties>`)
+		return []byte(sb.String()), nil
+	}
+
+	doc := string(existing)
+	end := strings.LastIndex(doc, "</Properties>")
+	if end < 0 {
+		return nil, ErrUnsupported
+	}
+
+	pid := 1
+	for _, m := range pidRe.FindAllStringSubmatch(doc, -1) {
+		if n, _ := strconv.Atoi(m[1]); n > pid {
+			pid = n
+		}
+	}
+
+	prefix := namespacePrefix(doc, vtypesNS)
+	if prefix == "" {
+		root := strings.Index(doc, "<Properties")
+		if root < 0 {
+			return nil, ErrUnsupported
+		}
+		prefix = "wmvt"
+		insertAt := root + len("<Properties")
+		doc = doc[:insertAt] + fmt.Sprintf(` xmlns:%s="%s"`, prefix, vtypesNS) + doc[insertAt:]
+		end = strings.LastIndex(doc, "</Properties>")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(doc[:end])
+	writeProperties(&sb, stamp, prefix, pid+1)
+	sb.WriteString(doc[end:])
+	return []byte(sb.String()), nil
+}
+
+func writeProperties(sb *strings.Builder, stamp Stamp, vt string, pid int) {
+	for i, field := range stamp.fields() {
+		fmt.Fprintf(sb, `<property fmtid="%s" pid="%d" name="%s"><%s:lpwstr>%s</%s:lpwstr></property>`,
+			customPropsFmtID, pid+i, field[0], vt, xmlText(field[1]), vt)
+	}
+}
+
+// addLicenseSheet appends a hidden worksheet listing the stamp to the
+// workbook. It returns the new part name, or "" if the workbook markup is
+// not in a shape it can safely edit.
+func addLicenseSheet(changed map[string][]byte, read func(string) ([]byte, error), stamp Stamp) (string, error) {
+	workbook, err := read(workbookPart)
+	if err != nil {
+		return "", err
+	}
+	workbookRels, err := read(workbookRelsPart)
+	if err != nil {
+		return "", err
+	}
+
+	doc := string(workbook)
+	relPrefix := namespacePrefix(doc, relationshipsNS)
+	closing := sheetsCloseRe.FindStringSubmatchIndex(doc)
+	if relPrefix == "" || closing == nil {
+		return "", nil
+	}
+	elemPrefix := ""
+	if closing[2] >= 0 {
+		elemPrefix = doc[closing[2]:closing[3]]
+	}
+
+	var sheetPart string
+	for n := 1; ; n++ {
+		sheetPart = fmt.Sprintf("xl/worksheets/sheet%d.xml", n)
+		if b, _ := read(sheetPart); b == nil {
+			break
+		}
+	}
+
+	workbookRels, relID, err := addRelationship(workbookRels, worksheetRel, strings.TrimPrefix(sheetPart, "xl/"))
+	if err != nil {
+		return "", err
+	}
+
+	sheetID := 0
+	for _, m := range sheetIDRe.FindAllStringSubmatch(doc, -1) {
+		if n, _ := strconv.Atoi(m[1]); n > sheetID {
+			sheetID = n
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, m := range sheetNameRe.FindAllStringSubmatch(doc, -1) {
+		names[strings.ToLower(m[1])] = true
+	}
+	name := licenseSheetName
+	for n := 2; names[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s%d", licenseSheetName, n)
+	}
+
+	sheet := fmt.Sprintf(`<%ssheet name="%s" sheetId="%d" state="hidden" %s:id="%s"/>`,
+		elemPrefix, name, sheetID+1, relPrefix, relID)
+	doc = doc[:closing[0]] + sheet + doc[closing[0]:]
+
+	changed[workbookPart] = []byte(doc)
+	changed[workbookRelsPart] = workbookRels
+	changed[sheetPart] = licenseSheet(stamp)
+	return sheetPart, nil
+}
+
+func licenseSheet(stamp Stamp) []byte {
+	rows := [][2]string{
+		{"Licensed to", stamp.Name},
+		{"Email", stamp.Email},
+		{"Order", stamp.OrderNumber},
+		{"Issued", stamp.IssuedAt.UTC().Format("2006-01-02 15:04 MST")},
+		{"Notice", "This file is licensed to the purchaser named above and may not be redistributed."},
+	}
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	fmt.Fprintf(&sb, `<worksheet xmlns="%s"><sheetData>`, spreadsheetNS)
+	for i, row := range rows {
+		fmt.Fprintf(&sb,
+			`<row r="%d"><c r="A%d" t="inlineStr"><is><t>%s</t></is></c><c r="B%d" t="inlineStr"><is><t>%s</t></is></c></row>`,
+			i+1, i+1, xmlText(row[0]), i+1, xmlText(row[1]))
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return []byte(sb.String())
+}
+
+// relTarget returns the target of the first relationship of the given type.
+func relTarget(rels []byte, relType string) string {
+	for _, rel := range relationshipRe.FindAll(rels, -1) {
+		if m := relTypeRe.FindSubmatch(rel); m != nil && string(m[1]) == relType {
+			if t := relTargetRe.FindSubmatch(rel); t != nil {
+				return string(t[1])
+			}
+		}
+	}
+	return ""
+}
+
+// addRelationship appends a relationship with an unused Id and returns it.
+func addRelationship(rels []byte, relType, target string) ([]byte, string, error) {
+	doc := string(rels)
+	end := strings.LastIndex(doc, "</Relationships>")
+	if end < 0 {
+		return nil, "", ErrUnsupported
+	}
+
+	used := make(map[string]bool)
+	for _, m := range relIDRe.FindAllStringSubmatch(doc, -1) {
+		used[m[1]] = true
+	}
+	id := "rIdLicense"
+	for n := 2; used[id]; n++ {
+		id = fmt.Sprintf("rIdLicense%d", n)
+	}
+
+	rel := fmt.Sprintf(`<Relationship Id="%s" Type="%s" Target="%s"/>`, id, relType, target)
+	return []byte(doc[:end] + rel + doc[end:]), id, nil
+}
+
+// addOverride registers a part's content type unless it already is.
+func addOverride(contentTypes []byte, partName, contentType string) []byte {
+	doc := string(contentTypes)
+	if strings.Contains(doc, `PartName="`+partName+`"`) {
+		return contentTypes
+	}
+	end := strings.LastIndex(doc, "</Types>")
+	if end < 0 {
+		return contentTypes
+	}
+	override := fmt.Sprintf(`<Override PartName="%s" ContentType="%s"/>`, partName, contentType)
+	return []byte(doc[:end] + override + doc[end:])
+}
+
+// namespacePrefix returns the prefix bound to ns in doc, if any.
+func namespacePrefix(doc, ns string) string {
+	re := regexp.MustCompile(`xmlns:(\w+)="` + regexp.QuoteMeta(ns) + `"`)
+	if m := re.FindStringSubmatch(doc); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func xmlText(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
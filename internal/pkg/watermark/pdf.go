@@ -0,0 +1,218 @@
+package watermark
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var errMalformedPDF = errors.New("watermark: malformed PDF")
+
+var (
+	startXRefRe = regexp.MustCompile(`startxref\s+(\d+)`)
+	objHeaderRe = regexp.MustCompile(`^\s*\d+\s+\d+\s+obj\b`)
+	rootRe      = regexp.MustCompile(`/Root\s+(\d+\s+\d+\s+R)`)
+	infoRe      = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	sizeRe      = regexp.MustCompile(`/Size\s+(\d+)`)
+	idRe        = regexp.MustCompile(`/ID\s*(\[[^\]]*\])`)
+	encryptRe   = regexp.MustCompile(`/Encrypt\b`)
+)
+
+// PDF stamps the document information dictionary. The original bytes are
+// kept as-is and a new Info object is appended as an incremental update,
+// so page content and earlier revisions are never rewritten.
+func PDF(data []byte, stamp Stamp) ([]byte, error) {
+	i := bytes.LastIndex(data, []byte("startxref"))
+	if i < 0 {
+		return nil, errMalformedPDF
+	}
+	m := startXRefRe.FindSubmatch(data[i:])
+	if m == nil {
+		return nil, errMalformedPDF
+	}
+	prev, err := strconv.Atoi(string(m[1]))
+	if err != nil || prev <= 0 || prev >= len(data) {
+		return nil, errMalformedPDF
+	}
+
+	trailer, xrefStream, err := readTrailer(data, prev)
+	if err != nil {
+		return nil, err
+	}
+	if encryptRe.Match(trailer) {
+		return nil, ErrUnsupported
+	}
+
+	root := rootRe.FindSubmatch(trailer)
+	sizeMatch := sizeRe.FindSubmatch(trailer)
+	if root == nil || sizeMatch == nil {
+		return nil, errMalformedPDF
+	}
+	size, err := strconv.Atoi(string(sizeMatch[1]))
+	if err != nil {
+		return nil, errMalformedPDF
+	}
+
+	var id string
+	if m := idRe.FindSubmatch(trailer); m != nil {
+		id = " /ID " + string(m[1])
+	}
+
+	var info strings.Builder
+	if m := infoRe.FindSubmatch(trailer); m != nil {
+		info.Write(existingInfo(data, string(m[1]), string(m[2])))
+	}
+	for _, field := range stamp.fields() {
+		fmt.Fprintf(&info, " /%s %s", field[0], pdfText(field[1]))
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data) + 1024)
+	buf.Write(data)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	infoNum := size
+	infoOffset := buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<<%s >>\nendobj\n", infoNum, info.String())
+
+	xrefOffset := buf.Len()
+	if xrefStream {
+		// Files that use cross-reference streams must be updated with one
+		var rows bytes.Buffer
+		for _, offset := range []int{infoOffset, xrefOffset} {
+			row := make([]byte, 11)
+			row[0] = 1
+			binary.BigEndian.PutUint64(row[1:9], uint64(offset))
+			rows.Write(row)
+		}
+		fmt.Fprintf(&buf,
+			"%d 0 obj\n<< /Type /XRef /Size %d /Index [%d 2] /W [1 8 2] /Root %s /Info %d 0 R /Prev %d%s /Length %d >>\nstream\n",
+			infoNum+1, infoNum+2, infoNum, root[1], infoNum, prev, id, rows.Len(),
+		)
+		buf.Write(rows.Bytes())
+		buf.WriteString("\nendstream\nendobj\n")
+	} else {
+		fmt.Fprintf(&buf,
+			"xref\n%d 1\n%010d 00000 n \ntrailer\n<< /Size %d /Root %s /Info %d 0 R /Prev %d%s >>\n",
+			infoNum, infoOffset, infoNum+1, root[1], infoNum, prev, id,
+		)
+	}
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	return buf.Bytes(), nil
+}
+
+// readTrailer returns the trailer dictionary for the cross-reference
+// section at offset, and whether that section is an xref stream.
+func readTrailer(data []byte, offset int) ([]byte, bool, error) {
+	rest := data[offset:]
+
+	if bytes.HasPrefix(bytes.TrimLeft(rest, " \t\r\n"), []byte("xref")) {
+		i := bytes.Index(rest, []byte("trailer"))
+		if i < 0 {
+			return nil, false, errMalformedPDF
+		}
+		dict, err := readDict(rest[i:])
+		return dict, false, err
+	}
+
+	if !objHeaderRe.Match(rest) {
+		return nil, false, errMalformedPDF
+	}
+	dict, err := readDict(rest)
+	return dict, true, err
+}
+
+// existingInfo returns the body of the current Info dictionary so its
+// entries (title, author, ...) survive the update. Info objects stored in
+// a compressed object stream are not carried over.
+func existingInfo(data []byte, num, gen string) []byte {
+	re := regexp.MustCompile(`(?:^|\s)` + num + `\s+` + gen + `\s+obj\b`)
+	matches := re.FindAllIndex(data, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	rest := data[matches[len(matches)-1][1]:]
+	if !bytes.HasPrefix(bytes.TrimLeft(rest, " \t\r\n"), []byte("<<")) {
+		return nil
+	}
+	dict, err := readDict(rest)
+	if err != nil {
+		return nil
+	}
+	return dict
+}
+
+// readDict returns the contents of the first << ... >> dictionary in b,
+// skipping over nested dictionaries and string literals.
+func readDict(b []byte) ([]byte, error) {
+	start := bytes.Index(b, []byte("<<"))
+	if start < 0 {
+		return nil, errMalformedPDF
+	}
+
+	depth := 0
+	for i := start; i < len(b); i++ {
+		switch b[i] {
+		case '(':
+			i = skipLiteral(b, i)
+		case '<':
+			if i+1 < len(b) && b[i+1] == '<' {
+				depth++
+				i++
+				continue
+			}
+			// Hex string
+			for i < len(b) && b[i] != '>' {
+				i++
+			}
+		case '>':
+			if i+1 < len(b) && b[i+1] == '>' {
+				depth--
+				i++
+				if depth == 0 {
+					return b[start+2 : i-1], nil
+				}
+			}
+		}
+	}
+	return nil, errMalformedPDF
+}
+
+func skipLiteral(b []byte, i int) int {
+	depth := 0
+	for ; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// pdfText encodes s as a UTF-16BE hex string, which readers accept for
+// any text value regardless of the characters in it.
+func pdfText(s string) string {
+	var sb strings.Builder
+	sb.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&sb, "%04X", u)
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+// Package watermark stamps buyer details into delivered files.
+//
+// Stamps are written as document metadata (and, for spreadsheets, a hidden
+// sheet) so the visible content and layout of the file are left untouched.
+package watermark
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnsupported is returned for files that cannot be stamped: unknown
+// formats, encrypted PDFs and archives that are not Office documents.
+var ErrUnsupported = errors.New("watermark: unsupported file")
+
+type Stamp struct {
+	Name        string
+	Email       string
+	OrderNumber string
+	IssuedAt    time.Time
+}
+
+// Notice is the one-line licence statement embedded in the file.
+func (s Stamp) Notice() string {
+	return fmt.Sprintf("Licensed to %s <%s> - Order %s", s.Name, s.Email, s.OrderNumber)
+}
+
+func (s Stamp) fields() [][2]string {
+	return [][2]string{
+		{"LicensedTo", s.Name},
+		{"LicenseeEmail", s.Email},
+		{"OrderNumber", s.OrderNumber},
+		{"LicenseIssued", s.IssuedAt.UTC().Format(time.RFC3339)},
+		{"LicenseNotice", s.Notice()},
+	}
+}
+
+// Apply detects the file type from its content and returns a stamped copy.
+func Apply(data []byte, stamp Stamp) ([]byte, error) {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+
+	switch {
+	case bytes.Contains(head, []byte("%PDF-")):
+		return PDF(data, stamp)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return OOXML(data, stamp)
+	default:
+		return nil, ErrUnsupported
+	}
+}
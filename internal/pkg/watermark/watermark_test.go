@@ -0,0 +1,421 @@
+package watermark
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"rsc.io/pdf"
+)
+
+var testStamp = Stamp{
+	Name:        "Zoë Fernández",
+	Email:       "zoe@example.com",
+	OrderNumber: "ORD-20260314-0042",
+	IssuedAt:    time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC),
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return data
+}
+
+// ============================================================================
+// PDF
+// ============================================================================
+
+func openPDF(t *testing.T, data []byte) *pdf.Reader {
+	t.Helper()
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("stamped PDF does not parse: %v", err)
+	}
+	return r
+}
+
+func checkPDFStamp(t *testing.T, r *pdf.Reader, stamp Stamp) {
+	t.Helper()
+	info := r.Trailer().Key("Info")
+	if info.Kind() != pdf.Dict {
+		t.Fatalf("trailer has no Info dictionary")
+	}
+	for _, field := range stamp.fields() {
+		if got := info.Key(field[0]).Text(); got != field[1] {
+			t.Errorf("Info /%s = %q, want %q", field[0], got, field[1])
+		}
+	}
+}
+
+func TestPDFRoundTrip(t *testing.T) {
+	tests := []struct {
+		fixture string
+		title   string
+	}{
+		{"planner.pdf", "Monthly Budget Planner"},
+		{"tracker-xrefstream.pdf", "Savings Tracker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			original := readFixture(t, tt.fixture)
+			pages := openPDF(t, original).NumPage()
+
+			stamped, err := Apply(original, testStamp)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+
+			// Incremental update: the original revision is left byte for byte
+			if !bytes.HasPrefix(stamped, original) {
+				t.Error("stamped file does not start with the original bytes")
+			}
+
+			r := openPDF(t, stamped)
+			checkPDFStamp(t, r, testStamp)
+			if got := r.Trailer().Key("Info").Key("Title").Text(); got != tt.title {
+				t.Errorf("Title = %q, want the original %q kept", got, tt.title)
+			}
+			if got := r.NumPage(); got != pages {
+				t.Errorf("stamped file has %d pages, want %d", got, pages)
+			}
+			if r.Page(1).V.Key("Type").Name() != "Page" {
+				t.Error("first page no longer resolves")
+			}
+
+			// A second update chains onto the first through /Prev
+			restamp := testStamp
+			restamp.OrderNumber = "ORD-20260315-0007"
+			again, err := Apply(stamped, restamp)
+			if err != nil {
+				t.Fatalf("Apply on a stamped file: %v", err)
+			}
+			r = openPDF(t, again)
+			checkPDFStamp(t, r, restamp)
+			if got := r.NumPage(); got != pages {
+				t.Errorf("restamped file has %d pages, want %d", got, pages)
+			}
+		})
+	}
+}
+
+func TestPDFEncryptedUnsupported(t *testing.T) {
+	original := readFixture(t, "planner.pdf")
+	encrypted := bytes.Replace(original, []byte("trailer\n<<"), []byte("trailer\n<< /Encrypt 99 0 R"), 1)
+	if bytes.Equal(encrypted, original) {
+		t.Fatal("fixture has no trailer to patch")
+	}
+
+	if _, err := Apply(encrypted, testStamp); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestPDFMalformed(t *testing.T) {
+	original := readFixture(t, "planner.pdf")
+	truncated := original[:bytes.LastIndex(original, []byte("startxref"))]
+
+	if _, err := Apply(truncated, testStamp); err == nil {
+		t.Error("stamped a PDF with no startxref")
+	}
+}
+
+// ============================================================================
+// OOXML
+// ============================================================================
+
+type contentTypes struct {
+	Defaults []struct {
+		Extension   string `xml:"Extension,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Default"`
+	Overrides []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+}
+
+type relationships struct {
+	Relationships []struct {
+		ID         string `xml:"Id,attr"`
+		Type       string `xml:"Type,attr"`
+		Target     string `xml:"Target,attr"`
+		TargetMode string `xml:"TargetMode,attr"`
+	} `xml:"Relationship"`
+}
+
+type customProperties struct {
+	Properties []struct {
+		FmtID string `xml:"fmtid,attr"`
+		PID   int    `xml:"pid,attr"`
+		Name  string `xml:"name,attr"`
+		Value string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes lpwstr"`
+	} `xml:"property"`
+}
+
+// openPackage unzips an OPC package and checks that it is still valid:
+// every XML part is well formed, every part has a content type, and every
+// internal relationship points at a part that exists.
+func openPackage(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("stamped package does not unzip: %v", err)
+	}
+
+	parts := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		if _, dup := parts[f.Name]; dup {
+			t.Errorf("part %s appears twice", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		parts[f.Name] = b
+
+		if strings.HasSuffix(f.Name, ".xml") || strings.HasSuffix(f.Name, ".rels") {
+			dec := xml.NewDecoder(bytes.NewReader(b))
+			for {
+				if _, err := dec.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("%s is not well-formed XML: %v", f.Name, err)
+					break
+				}
+			}
+		}
+	}
+
+	var types contentTypes
+	unmarshalPart(t, parts, contentTypesPart, &types)
+	defaults := make(map[string]bool)
+	for _, d := range types.Defaults {
+		defaults[strings.ToLower(d.Extension)] = true
+	}
+	overrides := make(map[string]bool)
+	for _, o := range types.Overrides {
+		if overrides[o.PartName] {
+			t.Errorf("content type override for %s registered twice", o.PartName)
+		}
+		overrides[o.PartName] = true
+		if _, ok := parts[strings.TrimPrefix(o.PartName, "/")]; !ok {
+			t.Errorf("content type override for missing part %s", o.PartName)
+		}
+	}
+	for name := range parts {
+		if name == contentTypesPart {
+			continue
+		}
+		ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+		if !overrides["/"+name] && !defaults[ext] {
+			t.Errorf("part %s has no content type", name)
+		}
+	}
+
+	for name := range parts {
+		if !strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		// word/_rels/document.xml.rels describes word/document.xml
+		base := path.Dir(path.Dir(name))
+		if base == "." {
+			base = ""
+		}
+
+		var rels relationships
+		unmarshalPart(t, parts, name, &rels)
+		ids := make(map[string]bool)
+		for _, rel := range rels.Relationships {
+			if ids[rel.ID] {
+				t.Errorf("%s: relationship Id %s used twice", name, rel.ID)
+			}
+			ids[rel.ID] = true
+			if rel.TargetMode == "External" {
+				continue
+			}
+			target := strings.TrimPrefix(path.Join(base, rel.Target), "/")
+			if strings.HasPrefix(rel.Target, "/") {
+				target = strings.TrimPrefix(rel.Target, "/")
+			}
+			if _, ok := parts[target]; !ok {
+				t.Errorf("%s: relationship %s points at missing part %s", name, rel.ID, target)
+			}
+		}
+	}
+
+	return parts
+}
+
+func unmarshalPart(t *testing.T, parts map[string][]byte, name string, v interface{}) {
+	t.Helper()
+	b, ok := parts[name]
+	if !ok {
+		t.Fatalf("package has no %s", name)
+	}
+	if err := xml.Unmarshal(b, v); err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+}
+
+func checkCustomProps(t *testing.T, parts map[string][]byte, stamp Stamp) map[string]string {
+	t.Helper()
+
+	var rels relationships
+	unmarshalPart(t, parts, rootRelsPart, &rels)
+	customPart := ""
+	for _, rel := range rels.Relationships {
+		if rel.Type == customPropsRel {
+			if customPart != "" {
+				t.Error("root relationships list custom properties twice")
+			}
+			customPart = strings.TrimPrefix(rel.Target, "/")
+		}
+	}
+	if customPart == "" {
+		t.Fatal("no custom properties relationship")
+	}
+
+	var props customProperties
+	unmarshalPart(t, parts, customPart, &props)
+
+	values := make(map[string]string)
+	pids := make(map[int]bool)
+	for _, p := range props.Properties {
+		if p.PID < 2 {
+			t.Errorf("property %s has pid %d; Office reserves pids below 2", p.Name, p.PID)
+		}
+		if pids[p.PID] {
+			t.Errorf("pid %d used twice", p.PID)
+		}
+		pids[p.PID] = true
+		values[p.Name] = p.Value
+	}
+	for _, field := range stamp.fields() {
+		if values[field[0]] != field[1] {
+			t.Errorf("custom property %s = %q, want %q", field[0], values[field[0]], field[1])
+		}
+	}
+	return values
+}
+
+func TestDOCXRoundTrip(t *testing.T) {
+	original := readFixture(t, "letter.docx")
+	before := openPackage(t, original)
+
+	stamped, err := Apply(original, testStamp)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	parts := openPackage(t, stamped)
+	values := checkCustomProps(t, parts, testStamp)
+	if values["TemplateVersion"] != "3.1" {
+		t.Errorf("existing custom property lost: TemplateVersion = %q", values["TemplateVersion"])
+	}
+
+	for _, name := range []string{"word/document.xml", "docProps/core.xml", "docProps/app.xml"} {
+		if !bytes.Equal(parts[name], before[name]) {
+			t.Errorf("%s was modified", name)
+		}
+	}
+	if len(parts) != len(before) {
+		t.Errorf("stamped document has %d parts, want %d", len(parts), len(before))
+	}
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	original := readFixture(t, "budget.xlsx")
+	before := openPackage(t, original)
+
+	stamped, err := Apply(original, testStamp)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	parts := openPackage(t, stamped)
+	checkCustomProps(t, parts, testStamp)
+
+	var workbook struct {
+		Sheets []struct {
+			Name  string `xml:"name,attr"`
+			ID    int    `xml:"sheetId,attr"`
+			State string `xml:"state,attr"`
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	unmarshalPart(t, parts, workbookPart, &workbook)
+	if len(workbook.Sheets) != 2 {
+		t.Fatalf("workbook has %d sheets, want the original plus the licence sheet", len(workbook.Sheets))
+	}
+	if first := workbook.Sheets[0]; first.Name != "Budget" || first.State == "hidden" {
+		t.Errorf("first sheet = %+v, want the visible Budget sheet first", first)
+	}
+
+	license := workbook.Sheets[1]
+	if license.Name != licenseSheetName || license.State != "hidden" || license.ID == workbook.Sheets[0].ID {
+		t.Errorf("licence sheet = %+v, want a hidden %q sheet with its own sheetId", license, licenseSheetName)
+	}
+
+	var rels relationships
+	unmarshalPart(t, parts, workbookRelsPart, &rels)
+	sheetPart := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == license.RelID {
+			sheetPart = path.Join("xl", rel.Target)
+		}
+	}
+	if sheetPart == "" {
+		t.Fatalf("licence sheet relationship %q not found", license.RelID)
+	}
+
+	var sheet struct {
+		Cells []string `xml:"sheetData>row>c>is>t"`
+	}
+	unmarshalPart(t, parts, sheetPart, &sheet)
+	text := strings.Join(sheet.Cells, "\n")
+	for _, want := range []string{testStamp.Name, testStamp.Email, testStamp.OrderNumber} {
+		if !strings.Contains(text, want) {
+			t.Errorf("licence sheet does not contain %q", want)
+		}
+	}
+
+	if !bytes.Equal(parts["xl/worksheets/sheet1.xml"], before["xl/worksheets/sheet1.xml"]) {
+		t.Error("original worksheet was modified")
+	}
+}
+
+func TestUnsupportedFiles(t *testing.T) {
+	var plainZip bytes.Buffer
+	zw := zip.NewWriter(&plainZip)
+	w, _ := zw.Create("readme.txt")
+	_, _ = w.Write([]byte("not an office document"))
+	_ = zw.Close()
+
+	tests := map[string][]byte{
+		"text":      []byte("just some text"),
+		"png":       {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'},
+		"plain zip": plainZip.Bytes(),
+	}
+	for name, data := range tests {
+		if _, err := Apply(data, testStamp); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: err = %v, want ErrUnsupported", name, err)
+		}
+	}
+}
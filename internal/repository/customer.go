@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type CustomerRepository interface {
+	FindOrCreate(ctx context.Context, email string) (*domain.Customer, error)
+	FindByID(ctx context.Context, id int64) (*domain.Customer, error)
+	RecordLogin(ctx context.Context, id int64, ip string) error
+
+	// Magic links
+	CreateLoginToken(ctx context.Context, token *domain.CustomerLoginToken) error
+	ConsumeLoginToken(ctx context.Context, tokenHash string, now time.Time) (*domain.CustomerLoginToken, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type LicenseRepository interface {
+	// Create inserts the license unless the order item already has one and
+	// reports whether it did.
+	Create(ctx context.Context, license *domain.License) (bool, error)
+	FindBySerial(ctx context.Context, serial string) (*domain.LicenseWithTemplate, error)
+	GetByOrderID(ctx context.Context, orderID int64) ([]*domain.LicenseWithTemplate, error)
+	RevokeByOrderID(ctx context.Context, orderID int64, reason string) (int64, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/merraki/merraki-backend/internal/domain"
 )
@@ -17,6 +18,12 @@ type CategoryRepository interface {
 	GetAll(ctx context.Context, activeOnly bool) ([]*domain.Category, error)
 	Update(ctx context.Context, category *domain.Category) error
 	Delete(ctx context.Context, id int64) error
+
+	// Tree
+	GetAncestors(ctx context.Context, id int64) ([]*domain.Category, error)
+	GetSubtreeIDs(ctx context.Context, id int64, activeOnly bool) ([]int64, error)
+	GetTemplateCounts(ctx context.Context, activeOnly bool) (map[int64]int, error)
+	Reorder(ctx context.Context, parentID *int64, ids []int64) error
 }
 
 type TemplateRepository interface {
@@ -31,6 +38,7 @@ type TemplateRepository interface {
 
 	// Images
 	CreateImage(ctx context.Context, image *domain.TemplateImage) error
+	FindImageByID(ctx context.Context, id int64) (*domain.TemplateImage, error)
 	GetImages(ctx context.Context, templateID int64) ([]*domain.TemplateImage, error)
 	DeleteImage(ctx context.Context, id int64) error
 
@@ -49,10 +57,22 @@ type TemplateRepository interface {
 	GetVersions(ctx context.Context, templateID int64) ([]*domain.TemplateVersion, error)
 	GetCurrentVersion(ctx context.Context, templateID int64) (*domain.TemplateVersion, error)
 	SetCurrentVersion(ctx context.Context, templateID int64, versionID int64) error
+	GetVersionByID(ctx context.Context, templateID, versionID int64) (*domain.TemplateVersion, error)
+	GetVersionByNumber(ctx context.Context, templateID int64, versionNumber string) (*domain.TemplateVersion, error)
+	SaveVersionFile(ctx context.Context, version *domain.TemplateVersion) error
+	ActivateVersion(ctx context.Context, templateID, versionID int64) error
+
+	// Related templates
+	RefreshRelated(ctx context.Context, perTemplate int) (int64, error)
+	GetRelated(ctx context.Context, templateID int64, limit int) ([]*domain.RelatedTemplate, error)
+	GetPopularRelated(ctx context.Context, template *domain.Template, excludeIDs []int64, limit int) ([]*domain.RelatedTemplate, error)
+
+	// Scheduled publishing
+	PublishScheduled(ctx context.Context, now time.Time) ([]*domain.Template, error)
 
 	// Extended queries
     Search(ctx context.Context, query string, limit int) ([]*domain.Template, error)
-    GetByCategory(ctx context.Context, categoryID int64, limit, offset int) ([]*domain.Template, int, error)
+    GetByCategories(ctx context.Context, categoryIDs []int64, limit, offset int) ([]*domain.Template, int, error)
     GetByTag(ctx context.Context, tag string, limit, offset int) ([]*domain.Template, int, error)
     GetFeatured(ctx context.Context, limit int) ([]*domain.Template, error)
     GetBestsellers(ctx context.Context, limit int) ([]*domain.Template, error)
@@ -66,6 +86,41 @@ type TemplateRepository interface {
     ReplaceAllTags(ctx context.Context, templateID int64, tags []string) error
 }
 
+type PriceRuleRepository interface {
+	Create(ctx context.Context, rule *domain.PriceRule) error
+	FindByID(ctx context.Context, id int64) (*domain.PriceRule, error)
+	GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.PriceRule, int, error)
+	Update(ctx context.Context, rule *domain.PriceRule) error
+
+	// Scheduling
+	UpdateStatuses(ctx context.Context, now time.Time) error
+	GetActive(ctx context.Context) ([]*domain.PriceRule, error)
+	GetAffectedTemplates(ctx context.Context) ([]*domain.Template, error)
+
+	// History
+	CreateHistory(ctx context.Context, entry *domain.TemplatePriceHistory) error
+	GetHistory(ctx context.Context, templateID int64, limit, offset int) ([]*domain.TemplatePriceHistory, int, error)
+}
+
+type BundleRepository interface {
+	Create(ctx context.Context, bundle *domain.TemplateBundle) error
+	FindByID(ctx context.Context, id int64) (*domain.TemplateBundle, error)
+	FindBySlug(ctx context.Context, slug string) (*domain.TemplateBundle, error)
+	GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.TemplateBundle, int, error)
+	Update(ctx context.Context, bundle *domain.TemplateBundle) error
+	Delete(ctx context.Context, id int64) error
+
+	// Contents
+	GetTemplates(ctx context.Context, bundleID int64) ([]*domain.Template, error)
+	ReplaceTemplates(ctx context.Context, bundleID int64, templateIDs []int64) error
+
+	// Images
+	CreateImage(ctx context.Context, image *domain.BundleImage) error
+	FindImageByID(ctx context.Context, id int64) (*domain.BundleImage, error)
+	GetImages(ctx context.Context, bundleID int64) ([]*domain.BundleImage, error)
+	DeleteImage(ctx context.Context, id int64) error
+}
+
 type OrderRepository interface {
 	// Core CRUD
 	Create(ctx context.Context, order *domain.Order) error
@@ -83,26 +138,70 @@ type OrderRepository interface {
 	UpdateStatus(ctx context.Context, id int64, newStatus domain.OrderStatus, adminID *int64) error
 
 	// Admin actions
-	Approve(ctx context.Context, id int64, adminID int64, notes *string) error
+	Approve(ctx context.Context, id int64, adminID int64, notes *string, downloadsExpiresAt time.Time) error
+	AutoApprove(ctx context.Context, id int64, triggeredBy, reason string, metadata domain.JSONMap, downloadsExpiresAt time.Time) error
+	MoveToReview(ctx context.Context, id int64, triggeredBy, reason string, metadata domain.JSONMap) error
 	Reject(ctx context.Context, id int64, adminID int64, reason string) error
 
 	// Analytics
 	GetRevenueByDateRange(ctx context.Context, startDate, endDate string) (float64, error)
 	GetOrderCountByStatus(ctx context.Context) (map[domain.OrderStatus]int, error)
 
+	// Customer history
+	CountApprovedByEmail(ctx context.Context, email string, excludeOrderID int64) (int, error)
+
+	// Customer accounts
+	ClaimByEmail(ctx context.Context, customerID int64, email string) (int64, error)
+	FindByCustomerID(ctx context.Context, customerID int64, limit, offset int) ([]*domain.Order, int, error)
+
+	// Risk scoring
+	UpdateRisk(ctx context.Context, id int64, score int, reasons domain.RiskReasons) error
+	CountRecentByEmail(ctx context.Context, email string, since time.Time, excludeOrderID int64) (int, error)
+	CountRecentByIP(ctx context.Context, ip string, since time.Time, excludeOrderID int64) (int, error)
+
+	// Update notifications
+	SetNotifyUpdates(ctx context.Context, id int64, enabled bool) error
+	FindUpdateSubscribers(ctx context.Context, templateID int64, versionNumber string) ([]*domain.TemplateUpdateSubscriber, error)
+
 	// MarkasPaid
 	MarkAsPaid(ctx context.Context, id int64, adminID int64, gatewayOrderID string) error
+	MarkPaid(ctx context.Context, id int64, gatewayPaymentID string) error
 
 	// Delete
 	Delete(ctx context.Context, id int64, adminID int64) error
 }
 
+type ApprovalRuleRepository interface {
+	Create(ctx context.Context, rule *domain.ApprovalRule) error
+	FindByID(ctx context.Context, id int64) (*domain.ApprovalRule, error)
+	GetAll(ctx context.Context, activeOnly bool) ([]*domain.ApprovalRule, error)
+	Update(ctx context.Context, rule *domain.ApprovalRule) error
+	Delete(ctx context.Context, id int64) error
+}
+
 type OrderItemRepository interface {
 	Create(ctx context.Context, item *domain.OrderItem) error
 	CreateBatch(ctx context.Context, items []*domain.OrderItem) error
 	GetByOrderID(ctx context.Context, orderID int64) ([]*domain.OrderItem, error)
 	GetByID(ctx context.Context, id int64) (*domain.OrderItem, error)
 	IncrementDownloadCount(ctx context.Context, id int64) error
+	SetWatermarkedFile(ctx context.Context, id int64, fileURL, sourceURL string) error
+
+	// Reviews
+	FindApprovedPurchase(ctx context.Context, templateID int64, email string) (*domain.OrderItem, error)
+}
+
+type TemplateReviewRepository interface {
+	Upsert(ctx context.Context, review *domain.TemplateReview) error
+	FindByID(ctx context.Context, id int64) (*domain.TemplateReview, error)
+	GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.TemplateReview, int, error)
+	GetByEmail(ctx context.Context, email string) ([]*domain.TemplateReview, error)
+	Moderate(ctx context.Context, review *domain.TemplateReview) error
+	Delete(ctx context.Context, id int64) error
+
+	// Ratings
+	GetRatingSummary(ctx context.Context, templateID int64) (*domain.RatingSummary, error)
+	RefreshTemplateRating(ctx context.Context, templateID int64) error
 }
 
 type PaymentRepository interface {
@@ -114,14 +213,35 @@ type PaymentRepository interface {
 	Update(ctx context.Context, payment *domain.Payment) error
 	UpdateStatus(ctx context.Context, id int64, status domain.PaymentStatus) error
 	MarkAsVerified(ctx context.Context, id int64) error
+
+	// Risk scoring
+	CountRecentOrdersByCardLast4(ctx context.Context, last4 string, since time.Time, excludeOrderID int64) (int, error)
+	CountRecentFailedByEmail(ctx context.Context, email string, since time.Time) (int, error)
+
+	// Reconciliation
+	GetCapturedBetween(ctx context.Context, from, to time.Time) ([]*domain.Payment, error)
+	UpdateSettlement(ctx context.Context, id int64, feeCents, netCents int64, settlementID *string, settledAt *time.Time) error
+}
+
+type PaymentDisputeRepository interface {
+	Upsert(ctx context.Context, dispute *domain.PaymentDispute) error
+	FindByID(ctx context.Context, id int64) (*domain.PaymentDispute, error)
+	FindByGatewayDisputeID(ctx context.Context, gatewayDisputeID string) (*domain.PaymentDispute, error)
+	GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.PaymentDispute, int, error)
+	GetByOrderID(ctx context.Context, orderID int64) ([]*domain.PaymentDispute, error)
+	AddEvidence(ctx context.Context, evidence *domain.DisputeEvidence) error
+	GetEvidence(ctx context.Context, disputeID int64) ([]*domain.DisputeEvidence, error)
 }
 
 type PaymentWebhookRepository interface {
 	Create(ctx context.Context, webhook *domain.PaymentWebhook) error
+	CreateUnique(ctx context.Context, webhook *domain.PaymentWebhook) (bool, error)
 	FindByID(ctx context.Context, id int64) (*domain.PaymentWebhook, error)
-	GetUnprocessed(ctx context.Context, limit int) ([]*domain.PaymentWebhook, error)
+	GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.PaymentWebhook, int, error)
+	ClaimUnprocessed(ctx context.Context, limit int, lease time.Duration) ([]*domain.PaymentWebhook, error)
+	Claim(ctx context.Context, id int64, lease time.Duration) (bool, error)
 	MarkAsProcessed(ctx context.Context, id int64) error
-	IncrementRetryCount(ctx context.Context, id int64, errorMsg string) error
+	IncrementRetryCount(ctx context.Context, id int64, errorMsg string, nextRetryAt *time.Time) error
 }
 
 type DownloadTokenRepository interface {
@@ -130,8 +250,21 @@ type DownloadTokenRepository interface {
 	GetByOrderID(ctx context.Context, orderID int64) ([]*domain.DownloadToken, error)
 	GetByEmail(ctx context.Context, email string) ([]*domain.DownloadToken, error)
 	IncrementDownloadCount(ctx context.Context, id int64) error
+	ReserveDownload(ctx context.Context, id int64) (bool, error)
+	ReleaseDownload(ctx context.Context, id int64) error
 	Revoke(ctx context.Context, id int64, adminID int64, reason string) error
 	CleanupExpired(ctx context.Context) (int64, error)
+
+	// Disputes
+	FreezeByOrderID(ctx context.Context, orderID int64, reason string) (int64, error)
+	UnfreezeByOrderID(ctx context.Context, orderID int64) (int64, error)
+	RevokeByOrderID(ctx context.Context, orderID int64, reason string) (int64, error)
+
+	// Bundles
+	CreateBundle(ctx context.Context, bundle *domain.DownloadBundleToken) (bool, error)
+	FindBundleByToken(ctx context.Context, token string) (*domain.DownloadBundleToken, error)
+	FindBundleByOrderID(ctx context.Context, orderID int64) (*domain.DownloadBundleToken, error)
+	MarkBundleUsed(ctx context.Context, id int64) error
 }
 
 type DownloadRepository interface {
@@ -140,6 +273,10 @@ type DownloadRepository interface {
 	GetByOrderID(ctx context.Context, orderID int64) ([]*domain.Download, error)
 	MarkAsCompleted(ctx context.Context, id int64, durationMS int) error
 	MarkAsFailed(ctx context.Context, id int64, errorMsg string) error
+
+	// Proxied transfers
+	RecordTransfer(ctx context.Context, download *domain.Download) error
+	MarkCountedIfDelivered(ctx context.Context, tokenID int64, thresholdBytes int64) (bool, error)
 }
 
 type OrderStateTransitionRepository interface {
@@ -163,6 +300,7 @@ type BackgroundJobRepository interface {
 	MarkAsFailed(ctx context.Context, id int64, errorMsg string) error
 	IncrementRetryCount(ctx context.Context, id int64) error
 	GetJobsByType(ctx context.Context, jobType string, limit int) ([]*domain.BackgroundJob, error)
+	CreateUnique(ctx context.Context, job *domain.BackgroundJob) (bool, error)
 }
 
 type CircuitBreakerRepository interface {
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type NewsletterCampaignRepository interface {
+	FindOrCreate(ctx context.Context, campaign *domain.NewsletterCampaign) error
+	AddRecipients(ctx context.Context, campaignID int64) (int, error)
+	GetPendingRecipients(ctx context.Context, campaignID int64) ([]*domain.PendingCampaignRecipient, error)
+	MarkRecipientSent(ctx context.Context, id int64) error
+	MarkRecipientFailed(ctx context.Context, id int64, errorMsg string) error
+	Finish(ctx context.Context, campaignID int64) error
+}
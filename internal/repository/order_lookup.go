@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type OrderLookupCodeStore interface {
+	SaveCode(ctx context.Context, email, codeHash string, ttl time.Duration) error
+	// RecordAttempt counts a verification attempt against the email and
+	// returns the current code with that attempt included. The count spans
+	// every code sent to the email and lasts window from the first attempt.
+	RecordAttempt(ctx context.Context, email string, window time.Duration) (*domain.OrderLookupCode, error)
+	CountAttempts(ctx context.Context, email string) (int, error)
+	ClearAttempts(ctx context.Context, email string) error
+	DeleteCode(ctx context.Context, email string) error
+
+	// AcquireCooldown returns false while a previous call for key is still cooling down
+	AcquireCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, sub *domain.WebhookSubscription) error
+	FindByID(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	GetActiveForEvent(ctx context.Context, event string) ([]*domain.WebhookSubscription, error)
+	Update(ctx context.Context, sub *domain.WebhookSubscription) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.WebhookDelivery) error
+	FindByID(ctx context.Context, id int64) (*domain.WebhookDelivery, error)
+	GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.WebhookDelivery, int, error)
+	RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error
+	ResetForRedelivery(ctx context.Context, id int64) error
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type ApprovalRuleRepository struct {
+	db *sqlx.DB
+}
+
+func NewApprovalRuleRepository(db *sqlx.DB) *ApprovalRuleRepository {
+	return &ApprovalRuleRepository{db: db}
+}
+
+func (r *ApprovalRuleRepository) Create(ctx context.Context, rule *domain.ApprovalRule) error {
+	query := `
+		INSERT INTO approval_rules (
+			name, description, rule_type, params, is_active, priority, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx, query,
+		rule.Name, rule.Description, rule.RuleType, rule.Params,
+		rule.IsActive, rule.Priority, rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (r *ApprovalRuleRepository) FindByID(ctx context.Context, id int64) (*domain.ApprovalRule, error) {
+	var rule domain.ApprovalRule
+	query := `SELECT * FROM approval_rules WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &rule, query, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &rule, err
+}
+
+func (r *ApprovalRuleRepository) GetAll(ctx context.Context, activeOnly bool) ([]*domain.ApprovalRule, error) {
+	var rules []*domain.ApprovalRule
+	query := `SELECT * FROM approval_rules`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+	query += ` ORDER BY priority, id`
+
+	err := r.db.SelectContext(ctx, &rules, query)
+	return rules, err
+}
+
+func (r *ApprovalRuleRepository) Update(ctx context.Context, rule *domain.ApprovalRule) error {
+	query := `
+		UPDATE approval_rules SET
+			name = $1, description = $2, rule_type = $3, params = $4,
+			is_active = $5, priority = $6
+		WHERE id = $7
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		rule.Name, rule.Description, rule.RuleType, rule.Params,
+		rule.IsActive, rule.Priority, rule.ID,
+	).Scan(&rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (r *ApprovalRuleRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM approval_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
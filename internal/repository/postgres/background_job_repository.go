@@ -32,6 +32,28 @@ func (r *BackgroundJobRepository) Create(ctx context.Context, job *domain.Backgr
 	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
 }
 
+// CreateUnique enqueues the job unless one with the same job_id already
+// exists. It reports whether a new job was created.
+func (r *BackgroundJobRepository) CreateUnique(ctx context.Context, job *domain.BackgroundJob) (bool, error) {
+	query := `
+		INSERT INTO background_jobs (
+			job_type, job_id, payload, status, max_retries, scheduled_at, priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (job_id) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		job.JobType, job.JobID, job.Payload, job.Status,
+		job.MaxRetries, job.ScheduledAt, job.Priority,
+	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func (r *BackgroundJobRepository) FindByID(ctx context.Context, id int64) (*domain.BackgroundJob, error) {
 	var job domain.BackgroundJob
 	query := `SELECT * FROM background_jobs WHERE id = $1`
@@ -49,6 +71,7 @@ func (r *BackgroundJobRepository) GetPending(ctx context.Context, limit int) ([]
 		SELECT * FROM background_jobs 
 		WHERE status IN ('pending', 'retrying')
 		AND scheduled_at <= CURRENT_TIMESTAMP
+		AND (next_retry_at IS NULL OR next_retry_at <= CURRENT_TIMESTAMP)
 		AND (locked_at IS NULL OR lock_expires_at < CURRENT_TIMESTAMP)
 		ORDER BY priority DESC, created_at ASC
 		LIMIT $1
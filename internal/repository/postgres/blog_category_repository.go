@@ -78,7 +78,19 @@ func (r *BlogCategoryRepository) GetAll(ctx context.Context, activeOnly bool, li
 	return categories, total, nil
 }
 
+// Update saves the category. A new parent_id is checked against the locked
+// ancestor chain in the same transaction, see checkAncestry.
 func (r *BlogCategoryRepository) Update(ctx context.Context, category *domain.BlogCategory) error {
+	tx, err := r.db.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkAncestry(ctx, tx, "blog_categories", []int64{category.ID}, category.ParentID); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE blog_categories 
 		SET name = $1, slug = $2, description = $3, parent_id = $4,
@@ -86,11 +98,15 @@ func (r *BlogCategoryRepository) Update(ctx context.Context, category *domain.Bl
 		WHERE id = $7
 		RETURNING updated_at`
 
-	return r.db.DB.QueryRowContext(
+	if err := tx.QueryRowContext(
 		ctx, query,
 		category.Name, category.Slug, category.Description,
 		category.ParentID, category.DisplayOrder, category.IsActive, category.ID,
-	).Scan(&category.UpdatedAt)
+	).Scan(&category.UpdatedAt); err != nil {
+		return treeWriteError(err)
+	}
+
+	return treeWriteError(tx.Commit())
 }
 
 func (r *BlogCategoryRepository) Delete(ctx context.Context, id int64) error {
@@ -108,4 +124,76 @@ func (r *BlogCategoryRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM blog_categories WHERE id = $1`
 	_, err := r.db.DB.ExecContext(ctx, query, id)
 	return err
-}
\ No newline at end of file
+}
+
+// ============================================================================
+// TREE
+// ============================================================================
+
+// ListAll returns every category in display order, unpaginated, for building
+// the tree
+func (r *BlogCategoryRepository) ListAll(ctx context.Context, activeOnly bool) ([]*domain.BlogCategory, error) {
+	var categories []*domain.BlogCategory
+
+	query := `SELECT * FROM blog_categories`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+	query += ` ORDER BY display_order ASC, name ASC`
+
+	err := r.db.DB.SelectContext(ctx, &categories, query)
+	return categories, err
+}
+
+// GetAncestors returns the category and its ancestors, root first. The path
+// check stops the walk if bad data has formed a loop.
+func (r *BlogCategoryRepository) GetAncestors(ctx context.Context, id int64) ([]*domain.BlogCategory, error) {
+	var categories []*domain.BlogCategory
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT c.*, 0 AS depth, ARRAY[c.id] AS path
+			FROM blog_categories c
+			WHERE c.id = $1
+
+			UNION ALL
+
+			SELECT p.*, a.depth + 1, a.path || p.id
+			FROM blog_categories p
+			JOIN ancestors a ON p.id = a.parent_id
+			WHERE p.id <> ALL(a.path)
+		)
+		SELECT id, name, slug, description, parent_id, display_order, is_active,
+			created_at, updated_at
+		FROM ancestors
+		ORDER BY depth DESC
+	`
+
+	err := r.db.DB.SelectContext(ctx, &categories, query, id)
+	return categories, err
+}
+
+// GetPostCounts returns the number of published posts filed directly under
+// each category, keyed by category ID
+func (r *BlogCategoryRepository) GetPostCounts(ctx context.Context) (map[int64]int, error) {
+	var rows []struct {
+		CategoryID int64 `db:"category_id"`
+		Count      int   `db:"count"`
+	}
+
+	query := `
+		SELECT category_id, COUNT(*) AS count
+		FROM blog_posts
+		WHERE category_id IS NOT NULL AND status = 'published'
+		GROUP BY category_id
+	`
+
+	if err := r.db.DB.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/merraki/merraki-backend/internal/domain"
@@ -23,8 +24,8 @@ func (r *BlogPostRepository) Create(ctx context.Context, post *domain.BlogPost)
 		INSERT INTO blog_posts 
 		(title, slug, excerpt, content, featured_image_url, author_id, category_id,
 		 tags, meta_title, meta_description, meta_keywords, status, is_featured,
-		 reading_time_minutes, published_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		 reading_time_minutes, published_at, announce_on_publish)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id, views_count, created_at, updated_at`
 
 	err := r.db.DB.QueryRowContext(
@@ -32,7 +33,7 @@ func (r *BlogPostRepository) Create(ctx context.Context, post *domain.BlogPost)
 		post.Title, post.Slug, post.Excerpt, post.Content, post.FeaturedImageURL,
 		post.AuthorID, post.CategoryID, pq.Array(post.Tags), post.MetaTitle,
 		post.MetaDescription, pq.Array(post.MetaKeywords), post.Status,
-		post.IsFeatured, post.ReadingTimeMinutes, post.PublishedAt,
+		post.IsFeatured, post.ReadingTimeMinutes, post.PublishedAt, post.AnnounceOnPublish,
 	).Scan(&post.ID, &post.ViewsCount, &post.CreatedAt, &post.UpdatedAt)
 
 	return err
@@ -267,6 +268,9 @@ func (r *BlogPostRepository) GetAllWithRelations(ctx context.Context, filters ma
 			pq.Array(&post.MetaKeywords), &post.Status, &post.IsFeatured,
 			&post.ViewsCount, &post.ReadingTimeMinutes, &post.PublishedAt,
 			&post.CreatedAt, &post.UpdatedAt,
+			&post.FeaturedImageWidth, &post.FeaturedImageHeight,
+			&post.FeaturedImageBlurHash, &post.FeaturedImageRenditions,
+			&post.AnnounceOnPublish,
 			// Author fields
 			&author.ID, &author.Name, &author.Slug, &author.Email,
 			&author.Bio, &author.AvatarURL,
@@ -297,8 +301,11 @@ func (r *BlogPostRepository) Update(ctx context.Context, post *domain.BlogPost)
 		    featured_image_url = $5, author_id = $6, category_id = $7,
 		    tags = $8, meta_title = $9, meta_description = $10,
 		    meta_keywords = $11, status = $12, is_featured = $13,
-		    reading_time_minutes = $14, published_at = $15, updated_at = NOW()
-		WHERE id = $16
+		    reading_time_minutes = $14, published_at = $15,
+		    featured_image_width = $16, featured_image_height = $17,
+		    featured_image_blurhash = $18, featured_image_renditions = $19,
+		    announce_on_publish = $20, updated_at = NOW()
+		WHERE id = $21
 		RETURNING updated_at`
 
 	err := r.db.DB.QueryRowContext(
@@ -306,7 +313,27 @@ func (r *BlogPostRepository) Update(ctx context.Context, post *domain.BlogPost)
 		post.Title, post.Slug, post.Excerpt, post.Content, post.FeaturedImageURL,
 		post.AuthorID, post.CategoryID, pq.Array(post.Tags), post.MetaTitle,
 		post.MetaDescription, pq.Array(post.MetaKeywords), post.Status,
-		post.IsFeatured, post.ReadingTimeMinutes, post.PublishedAt, post.ID,
+		post.IsFeatured, post.ReadingTimeMinutes, post.PublishedAt,
+		post.FeaturedImageWidth, post.FeaturedImageHeight,
+		post.FeaturedImageBlurHash, post.FeaturedImageRenditions,
+		post.AnnounceOnPublish, post.ID,
+	).Scan(&post.UpdatedAt)
+
+	return err
+}
+
+// SetFeaturedImage replaces the featured image and its renditions.
+func (r *BlogPostRepository) SetFeaturedImage(ctx context.Context, post *domain.BlogPost) error {
+	query := `
+		UPDATE blog_posts
+		SET featured_image_url = $1, featured_image_width = $2, featured_image_height = $3,
+		    featured_image_blurhash = $4, featured_image_renditions = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING updated_at`
+
+	err := r.db.DB.QueryRowContext(ctx, query,
+		post.FeaturedImageURL, post.FeaturedImageWidth, post.FeaturedImageHeight,
+		post.FeaturedImageBlurHash, post.FeaturedImageRenditions, post.ID,
 	).Scan(&post.UpdatedAt)
 
 	return err
@@ -349,6 +376,19 @@ func (r *BlogPostRepository) IncrementViews(ctx context.Context, id int64) error
 	return err
 }
 
+// PublishScheduled publishes every scheduled post whose publish time has
+// passed and returns the posts it published.
+func (r *BlogPostRepository) PublishScheduled(ctx context.Context, now time.Time) ([]*domain.BlogPost, error) {
+	var posts []*domain.BlogPost
+	query := `
+		UPDATE blog_posts SET status = 'published', updated_at = NOW()
+		WHERE status = 'scheduled' AND published_at <= $1
+		RETURNING *`
+
+	err := r.db.DB.SelectContext(ctx, &posts, query, now)
+	return posts, err
+}
+
 func (r *BlogPostRepository) Search(ctx context.Context, searchTerm string, limit int) ([]*domain.BlogPost, error) {
 	var posts []*domain.BlogPost
 	query := `
@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type BundleRepository struct {
+	db *sqlx.DB
+}
+
+func NewBundleRepository(db *sqlx.DB) *BundleRepository {
+	return &BundleRepository{db: db}
+}
+
+func (r *BundleRepository) Create(ctx context.Context, bundle *domain.TemplateBundle) error {
+	query := `
+		INSERT INTO template_bundles (
+			name, slug, tagline, description,
+			price_usd_cents, sale_price_usd_cents,
+			status, is_featured,
+			meta_title, meta_description, published_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query,
+		bundle.Name, bundle.Slug, bundle.Tagline, bundle.Description,
+		bundle.PriceUSDCents, bundle.SalePriceUSDCents,
+		bundle.Status, bundle.IsFeatured,
+		bundle.MetaTitle, bundle.MetaDescription, bundle.PublishedAt,
+	).Scan(&bundle.ID, &bundle.CreatedAt, &bundle.UpdatedAt)
+}
+
+func (r *BundleRepository) FindByID(ctx context.Context, id int64) (*domain.TemplateBundle, error) {
+	var bundle domain.TemplateBundle
+	err := r.db.GetContext(ctx, &bundle, `SELECT * FROM template_bundles WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (r *BundleRepository) FindBySlug(ctx context.Context, slug string) (*domain.TemplateBundle, error) {
+	var bundle domain.TemplateBundle
+	err := r.db.GetContext(ctx, &bundle, `SELECT * FROM template_bundles WHERE slug = $1`, slug)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (r *BundleRepository) GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.TemplateBundle, int, error) {
+	var bundles []*domain.TemplateBundle
+	var total int
+
+	whereClauses := []string{"1=1"}
+	args := []interface{}{}
+	argPos := 1
+
+	if status, ok := filters["status"].(domain.TemplateStatus); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, status)
+		argPos++
+	}
+
+	if featured, ok := filters["featured"].(bool); ok && featured {
+		whereClauses = append(whereClauses, "is_featured = TRUE")
+	}
+
+	// Every template in the bundle can be bought, so checkout will accept it
+	if purchasable, ok := filters["purchasable"].(bool); ok && purchasable {
+		whereClauses = append(whereClauses, `NOT EXISTS (
+			SELECT 1 FROM template_bundle_items bi
+			JOIN templates t ON t.id = bi.template_id
+			WHERE bi.bundle_id = template_bundles.id AND t.status <> 'active'
+		)`)
+	}
+
+	if templateID, ok := filters["template_id"].(int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"id IN (SELECT bundle_id FROM template_bundle_items WHERE template_id = $%d)", argPos,
+		))
+		args = append(args, templateID)
+		argPos++
+	}
+
+	if search, ok := filters["search"].(string); ok && search != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("(name ILIKE $%d OR description ILIKE $%d)", argPos, argPos))
+		args = append(args, "%"+search+"%")
+		argPos++
+	}
+
+	whereClause := strings.Join(whereClauses, " AND ")
+
+	err := r.db.GetContext(ctx, &total, fmt.Sprintf("SELECT COUNT(*) FROM template_bundles WHERE %s", whereClause), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orderBy := "created_at DESC"
+	if sortBy, ok := filters["sort"].(string); ok {
+		switch sortBy {
+		case "price_asc":
+			orderBy = "COALESCE(sale_price_usd_cents, price_usd_cents) ASC"
+		case "price_desc":
+			orderBy = "COALESCE(sale_price_usd_cents, price_usd_cents) DESC"
+		case "name":
+			orderBy = "name ASC"
+		}
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT * FROM template_bundles
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, whereClause, orderBy, argPos, argPos+1)
+
+	err = r.db.SelectContext(ctx, &bundles, query, args...)
+	return bundles, total, err
+}
+
+func (r *BundleRepository) Update(ctx context.Context, bundle *domain.TemplateBundle) error {
+	query := `
+		UPDATE template_bundles SET
+			name = $1, slug = $2, tagline = $3, description = $4,
+			price_usd_cents = $5, sale_price_usd_cents = $6,
+			status = $7, is_featured = $8,
+			meta_title = $9, meta_description = $10, published_at = $11,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $12
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(
+		ctx, query,
+		bundle.Name, bundle.Slug, bundle.Tagline, bundle.Description,
+		bundle.PriceUSDCents, bundle.SalePriceUSDCents,
+		bundle.Status, bundle.IsFeatured,
+		bundle.MetaTitle, bundle.MetaDescription, bundle.PublishedAt,
+		bundle.ID,
+	).Scan(&bundle.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (r *BundleRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM template_bundles WHERE id = $1", id)
+	return err
+}
+
+// ============================================================================
+// Contents
+// ============================================================================
+
+func (r *BundleRepository) GetTemplates(ctx context.Context, bundleID int64) ([]*domain.Template, error) {
+	var templates []*domain.Template
+	err := r.db.SelectContext(ctx, &templates, `
+		SELECT t.* FROM templates t
+		JOIN template_bundle_items bi ON bi.template_id = t.id
+		WHERE bi.bundle_id = $1
+		ORDER BY bi.display_order, t.id
+	`, bundleID)
+	return templates, err
+}
+
+// ReplaceTemplates sets the bundle's contents to templateIDs, in that order
+func (r *BundleRepository) ReplaceTemplates(ctx context.Context, bundleID int64, templateIDs []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM template_bundle_items WHERE bundle_id = $1", bundleID); err != nil {
+		return err
+	}
+
+	for i, templateID := range templateIDs {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO template_bundle_items (bundle_id, template_id, display_order) VALUES ($1, $2, $3)",
+			bundleID, templateID, i,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ============================================================================
+// Images
+// ============================================================================
+
+func (r *BundleRepository) CreateImage(ctx context.Context, image *domain.BundleImage) error {
+	query := `
+		INSERT INTO template_bundle_images
+			(bundle_id, url, alt_text, display_order, is_primary, width, height, blurhash, renditions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		image.BundleID, image.URL, image.AltText, image.DisplayOrder, image.IsPrimary,
+		image.Width, image.Height, image.BlurHash, image.Renditions,
+	).Scan(&image.ID, &image.CreatedAt)
+}
+
+func (r *BundleRepository) FindImageByID(ctx context.Context, id int64) (*domain.BundleImage, error) {
+	var image domain.BundleImage
+	err := r.db.GetContext(ctx, &image, `SELECT * FROM template_bundle_images WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *BundleRepository) GetImages(ctx context.Context, bundleID int64) ([]*domain.BundleImage, error) {
+	var images []*domain.BundleImage
+	err := r.db.SelectContext(ctx, &images,
+		`SELECT * FROM template_bundle_images WHERE bundle_id = $1 ORDER BY display_order, id`, bundleID,
+	)
+	return images, err
+}
+
+func (r *BundleRepository) DeleteImage(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM template_bundle_images WHERE id = $1", id)
+	return err
+}
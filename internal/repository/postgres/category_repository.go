@@ -3,8 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/merraki/merraki-backend/internal/domain"
 )
 
@@ -73,7 +77,19 @@ func (r *CategoryRepository) GetAll(ctx context.Context, activeOnly bool) ([]*do
 	return categories, err
 }
 
+// Update saves the category. A new parent_id is checked against the locked
+// ancestor chain in the same transaction, see checkAncestry.
 func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkAncestry(ctx, tx, "categories", []int64{category.ID}, category.ParentID); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE categories SET
 			name = $1,
@@ -87,7 +103,7 @@ func (r *CategoryRepository) Update(ctx context.Context, category *domain.Catego
 		WHERE id = $9
 	`
 
-	result, err := r.db.ExecContext(
+	result, err := tx.ExecContext(
 		ctx, query,
 		category.Name,
 		category.Slug,
@@ -100,7 +116,7 @@ func (r *CategoryRepository) Update(ctx context.Context, category *domain.Catego
 		category.ID,
 	)
 	if err != nil {
-		return err
+		return treeWriteError(err)
 	}
 
 	rows, err := result.RowsAffected()
@@ -111,7 +127,7 @@ func (r *CategoryRepository) Update(ctx context.Context, category *domain.Catego
 		return domain.ErrNotFound
 	}
 
-	return nil
+	return treeWriteError(tx.Commit())
 }
 
 func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
@@ -130,4 +146,181 @@ func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ============================================================================
+// TREE
+// ============================================================================
+
+// GetAncestors returns the category and its ancestors, root first. The path
+// check stops the walk if bad data has formed a loop.
+func (r *CategoryRepository) GetAncestors(ctx context.Context, id int64) ([]*domain.Category, error) {
+	var categories []*domain.Category
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT c.*, 0 AS depth, ARRAY[c.id] AS path
+			FROM categories c
+			WHERE c.id = $1
+
+			UNION ALL
+
+			SELECT p.*, a.depth + 1, a.path || p.id
+			FROM categories p
+			JOIN ancestors a ON p.id = a.parent_id
+			WHERE p.id <> ALL(a.path)
+		)
+		SELECT id, name, slug, description, parent_id, display_order, is_active,
+			meta_title, meta_description, created_at, updated_at
+		FROM ancestors
+		ORDER BY depth DESC
+	`
+
+	err := r.db.SelectContext(ctx, &categories, query, id)
+	return categories, err
+}
+
+// GetSubtreeIDs returns the category's ID followed by those of every category
+// below it. With activeOnly, inactive subcategories and everything under them
+// are left out.
+func (r *CategoryRepository) GetSubtreeIDs(ctx context.Context, id int64, activeOnly bool) ([]int64, error) {
+	var ids []int64
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+
+			UNION
+
+			SELECT c.id
+			FROM categories c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.is_active OR NOT $2::boolean
+		)
+		SELECT id FROM subtree
+	`
+
+	err := r.db.SelectContext(ctx, &ids, query, id, activeOnly)
+	return ids, err
+}
+
+// GetTemplateCounts returns the number of templates filed directly under
+// each category, keyed by category ID
+func (r *CategoryRepository) GetTemplateCounts(ctx context.Context, activeOnly bool) (map[int64]int, error) {
+	var rows []struct {
+		CategoryID int64 `db:"category_id"`
+		Count      int   `db:"count"`
+	}
+
+	query := `SELECT category_id, COUNT(*) AS count FROM templates WHERE category_id IS NOT NULL`
+	if activeOnly {
+		query += ` AND status = 'active'`
+	}
+	query += ` GROUP BY category_id`
+
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// Reorder files ids under parentID in the given order, renumbering their
+// display_order from zero. The move is checked against the locked ancestor
+// chain in the same transaction, see checkAncestry.
+func (r *CategoryRepository) Reorder(ctx context.Context, parentID *int64, ids []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkAncestry(ctx, tx, "categories", ids, parentID); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE categories SET parent_id = $1, display_order = $2 WHERE id = $3`,
+			parentID, i, id,
+		)
+		if err != nil {
+			return treeWriteError(err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrNotFound
+		}
+	}
+
+	return treeWriteError(tx.Commit())
+}
+
+// checkAncestry locks the categories in ids and then each ancestor of
+// parentID with SELECT ... FOR UPDATE, walking up from parentID. It rejects
+// a parentID that does not exist or that lies in the subtree of one of ids.
+//
+// Call it in the transaction that writes the new parent_id. Two moves that
+// would form a loop between them (A under B, B under A) each need a lock
+// the other holds. Postgres then aborts one of them, so both cannot commit.
+// The aborted move comes back from treeWriteError as ErrVersionConflict.
+// table is "categories" or "blog_categories".
+func checkAncestry(ctx context.Context, tx *sqlx.Tx, table string, ids []int64, parentID *int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var locked []int64
+	if err := tx.SelectContext(ctx, &locked,
+		`SELECT id FROM `+table+` WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(ids),
+	); err != nil {
+		return treeWriteError(err)
+	}
+
+	moved := make(map[int64]bool, len(locked))
+	for _, id := range locked {
+		moved[id] = true
+	}
+	for _, id := range ids {
+		if !moved[id] {
+			return domain.ErrNotFound
+		}
+	}
+
+	seen := make(map[int64]bool)
+	for current := parentID; current != nil && !seen[*current]; {
+		if moved[*current] {
+			return fmt.Errorf("%w: a category cannot be moved under itself or its own subcategory", domain.ErrInvalidInput)
+		}
+		seen[*current] = true
+
+		var next *int64
+		err := tx.GetContext(ctx, &next, `SELECT parent_id FROM `+table+` WHERE id = $1 FOR UPDATE`, *current)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: parent category not found", domain.ErrInvalidInput)
+		}
+		if err != nil {
+			return treeWriteError(err)
+		}
+		current = next
+	}
+
+	return nil
+}
+
+// treeWriteError reports a deadlock between concurrent category moves as a
+// version conflict, which the caller can retry. sqlx runs on the pgx stdlib
+// driver, so server errors arrive as *pgconn.PgError.
+func treeWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "40P01" {
+		return fmt.Errorf("%w: the category tree was changed concurrently, retry", domain.ErrVersionConflict)
+	}
+	return err
+}
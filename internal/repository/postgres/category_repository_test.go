@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+func TestTreeWriteError(t *testing.T) {
+	deadlock := &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+	if err := treeWriteError(deadlock); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("deadlock: err = %v, want ErrVersionConflict", err)
+	}
+
+	// database/sql hands the driver's error back wrapped at times
+	if err := treeWriteError(fmt.Errorf("commit: %w", deadlock)); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("wrapped deadlock: err = %v, want ErrVersionConflict", err)
+	}
+
+	other := &pgconn.PgError{Code: "23505", Message: "duplicate key"}
+	if err := treeWriteError(other); err != other {
+		t.Errorf("unique violation: err = %v, want it unchanged", err)
+	}
+
+	if err := treeWriteError(nil); err != nil {
+		t.Errorf("nil: err = %v", err)
+	}
+}
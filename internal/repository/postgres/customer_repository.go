@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type CustomerRepository struct {
+	db *sqlx.DB
+}
+
+func NewCustomerRepository(db *sqlx.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// FindOrCreate returns the customer for an email, creating it on first use.
+// The no-op update makes RETURNING yield the existing row on conflict.
+func (r *CustomerRepository) FindOrCreate(ctx context.Context, email string) (*domain.Customer, error) {
+	var customer domain.Customer
+	query := `
+		INSERT INTO customers (email) VALUES ($1)
+		ON CONFLICT (LOWER(email)) DO UPDATE SET email = customers.email
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, &customer, query, email)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *CustomerRepository) FindByID(ctx context.Context, id int64) (*domain.Customer, error) {
+	var customer domain.Customer
+	err := r.db.GetContext(ctx, &customer, `SELECT * FROM customers WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &customer, err
+}
+
+func (r *CustomerRepository) RecordLogin(ctx context.Context, id int64, ip string) error {
+	query := `
+		UPDATE customers SET
+			email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP),
+			last_login_at = CURRENT_TIMESTAMP,
+			last_login_ip = $1
+		WHERE id = $2
+	`
+	result, err := r.db.ExecContext(ctx, query, ip, id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// Magic links
+// ============================================================================
+
+func (r *CustomerRepository) CreateLoginToken(ctx context.Context, token *domain.CustomerLoginToken) error {
+	query := `
+		INSERT INTO customer_login_tokens (customer_id, token_hash, expires_at, requested_ip)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query,
+		token.CustomerID, token.TokenHash, token.ExpiresAt, token.RequestedIP,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// ConsumeLoginToken marks an unused, unexpired token as used in a single
+// statement so the same link cannot be redeemed twice.
+func (r *CustomerRepository) ConsumeLoginToken(ctx context.Context, tokenHash string, now time.Time) (*domain.CustomerLoginToken, error) {
+	var token domain.CustomerLoginToken
+	query := `
+		UPDATE customer_login_tokens SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, &token, query, tokenHash, now)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
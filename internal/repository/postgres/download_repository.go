@@ -20,8 +20,8 @@ func (r *DownloadRepository) Create(ctx context.Context, download *domain.Downlo
 		INSERT INTO downloads (
 			token_id, order_id, order_item_id, template_id,
 			customer_email, ip_address, user_agent, country,
-			file_url, file_size_bytes
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			file_url, file_size_bytes, range_header, counted
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, started_at, created_at
 	`
 
@@ -29,7 +29,7 @@ func (r *DownloadRepository) Create(ctx context.Context, download *domain.Downlo
 		ctx, query,
 		download.TokenID, download.OrderID, download.OrderItemID, download.TemplateID,
 		download.CustomerEmail, download.IPAddress, download.UserAgent, download.Country,
-		download.FileURL, download.FileSizeBytes,
+		download.FileURL, download.FileSizeBytes, download.RangeHeader, download.Counted,
 	).Scan(&download.ID, &download.StartedAt, &download.CreatedAt)
 }
 
@@ -65,4 +65,45 @@ func (r *DownloadRepository) MarkAsFailed(ctx context.Context, id int64, errorMs
 	`
 	_, err := r.db.ExecContext(ctx, query, errorMsg, id)
 	return err
-}
\ No newline at end of file
+}
+
+// RecordTransfer stores the outcome of a proxied transfer.
+func (r *DownloadRepository) RecordTransfer(ctx context.Context, download *domain.Download) error {
+	query := `
+		UPDATE downloads SET
+			bytes_served = $1,
+			file_size_bytes = $2,
+			download_duration_ms = $3,
+			completed_at = $4,
+			failed = $5,
+			error_message = $6,
+			counted = counted OR $7
+		WHERE id = $8
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		download.BytesServed, download.FileSizeBytes, download.DownloadDurationMS,
+		download.CompletedAt, download.Failed, download.ErrorMessage, download.Counted, download.ID,
+	)
+	return err
+}
+
+// MarkCountedIfDelivered charges the token once its uncounted transfers
+// (a full download or several resumed ranges) add up to thresholdBytes.
+// It reports whether a download should be counted.
+func (r *DownloadRepository) MarkCountedIfDelivered(ctx context.Context, tokenID int64, thresholdBytes int64) (bool, error) {
+	query := `
+		UPDATE downloads SET counted = true
+		WHERE token_id = $1 AND counted = false
+		AND (
+			SELECT COALESCE(SUM(bytes_served), 0) FROM downloads
+			WHERE token_id = $1 AND counted = false
+		) >= $2
+	`
+	result, err := r.db.ExecContext(ctx, query, tokenID, thresholdBytes)
+	if err != nil {
+		return false, err
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
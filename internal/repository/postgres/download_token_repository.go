@@ -55,8 +55,9 @@ func (r *DownloadTokenRepository) GetByEmail(ctx context.Context, email string)
 	var tokens []*domain.DownloadToken
 	query := `
 		SELECT * FROM download_tokens 
-		WHERE customer_email = $1 
+		WHERE LOWER(customer_email) = LOWER($1)
 		AND is_revoked = false 
+		AND frozen_at IS NULL
 		AND expires_at > CURRENT_TIMESTAMP
 		ORDER BY created_at DESC
 	`
@@ -74,6 +75,36 @@ func (r *DownloadTokenRepository) IncrementDownloadCount(ctx context.Context, id
 	return err
 }
 
+// ReserveDownload takes one of the token's remaining downloads in a single
+// conditional update, so concurrent requests cannot all pass a limit check
+// made before any of them is counted. It reports false when none is left.
+func (r *DownloadTokenRepository) ReserveDownload(ctx context.Context, id int64) (bool, error) {
+	query := `
+		UPDATE download_tokens
+		SET download_count = download_count + 1, last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND download_count < max_downloads
+	`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ReleaseDownload gives back a download taken by ReserveDownload that did
+// not end up being counted.
+func (r *DownloadTokenRepository) ReleaseDownload(ctx context.Context, id int64) error {
+	query := `
+		UPDATE download_tokens
+		SET download_count = GREATEST(download_count - 1, 0)
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
 func (r *DownloadTokenRepository) Revoke(ctx context.Context, id int64, adminID int64, reason string) error {
 	query := `
 		UPDATE download_tokens 
@@ -84,6 +115,48 @@ func (r *DownloadTokenRepository) Revoke(ctx context.Context, id int64, adminID
 	return err
 }
 
+// FreezeByOrderID suspends every active token on the order without revoking it.
+func (r *DownloadTokenRepository) FreezeByOrderID(ctx context.Context, orderID int64, reason string) (int64, error) {
+	query := `
+		UPDATE download_tokens
+		SET frozen_at = CURRENT_TIMESTAMP, frozen_reason = $1
+		WHERE order_id = $2 AND is_revoked = false AND frozen_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, reason, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *DownloadTokenRepository) UnfreezeByOrderID(ctx context.Context, orderID int64) (int64, error) {
+	query := `
+		UPDATE download_tokens
+		SET frozen_at = NULL, frozen_reason = NULL
+		WHERE order_id = $1 AND frozen_at IS NOT NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RevokeByOrderID permanently revokes every token on the order. revoked_by is
+// left empty because the caller is the system, not an admin.
+func (r *DownloadTokenRepository) RevokeByOrderID(ctx context.Context, orderID int64, reason string) (int64, error) {
+	query := `
+		UPDATE download_tokens
+		SET is_revoked = true, revoked_at = CURRENT_TIMESTAMP, revoked_reason = $1
+		WHERE order_id = $2 AND is_revoked = false
+	`
+	result, err := r.db.ExecContext(ctx, query, reason, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (r *DownloadTokenRepository) CleanupExpired(ctx context.Context) (int64, error) {
 	// Optionally delete expired tokens (or just leave them for audit)
 	query := `DELETE FROM download_tokens WHERE expires_at < CURRENT_TIMESTAMP AND created_at < $1`
@@ -96,4 +169,59 @@ func (r *DownloadTokenRepository) CleanupExpired(ctx context.Context) (int64, er
 	}
 
 	return result.RowsAffected()
-}
\ No newline at end of file
+}
+
+// ============================================================================
+// Bundles
+// ============================================================================
+
+// CreateBundle inserts the order's bundle token; it returns false if the
+// order already has one.
+func (r *DownloadTokenRepository) CreateBundle(ctx context.Context, bundle *domain.DownloadBundleToken) (bool, error) {
+	query := `
+		INSERT INTO download_bundle_tokens (token, order_id, customer_email, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (order_id) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		bundle.Token, bundle.OrderID, bundle.CustomerEmail, bundle.ExpiresAt,
+	).Scan(&bundle.ID, &bundle.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *DownloadTokenRepository) FindBundleByToken(ctx context.Context, token string) (*domain.DownloadBundleToken, error) {
+	var bundle domain.DownloadBundleToken
+	err := r.db.GetContext(ctx, &bundle, `SELECT * FROM download_bundle_tokens WHERE token = $1`, token)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &bundle, err
+}
+
+func (r *DownloadTokenRepository) FindBundleByOrderID(ctx context.Context, orderID int64) (*domain.DownloadBundleToken, error) {
+	var bundle domain.DownloadBundleToken
+	err := r.db.GetContext(ctx, &bundle, `SELECT * FROM download_bundle_tokens WHERE order_id = $1`, orderID)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &bundle, err
+}
+
+func (r *DownloadTokenRepository) MarkBundleUsed(ctx context.Context, id int64) error {
+	query := `
+		UPDATE download_bundle_tokens
+		SET download_count = download_count + 1, last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
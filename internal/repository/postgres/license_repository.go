@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type LicenseRepository struct {
+	db *sqlx.DB
+}
+
+func NewLicenseRepository(db *sqlx.DB) *LicenseRepository {
+	return &LicenseRepository{db: db}
+}
+
+const licenseWithTemplateSelect = `
+	SELECT l.*, t.name AS template_name, t.slug AS template_slug
+	FROM licenses l
+	JOIN templates t ON t.id = l.template_id
+`
+
+func (r *LicenseRepository) Create(ctx context.Context, license *domain.License) (bool, error) {
+	query := `
+		INSERT INTO licenses (
+			serial, license_key, order_id, order_item_id, template_id,
+			customer_email, customer_name, tier, seats, issued_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (order_item_id) DO NOTHING
+		RETURNING id, status, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		license.Serial, license.LicenseKey, license.OrderID, license.OrderItemID, license.TemplateID,
+		license.CustomerEmail, license.CustomerName, license.Tier, license.Seats, license.IssuedAt,
+	).Scan(&license.ID, &license.Status, &license.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *LicenseRepository) FindBySerial(ctx context.Context, serial string) (*domain.LicenseWithTemplate, error) {
+	var license domain.LicenseWithTemplate
+	err := r.db.GetContext(ctx, &license, licenseWithTemplateSelect+` WHERE l.serial = $1`, serial)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &license, nil
+}
+
+func (r *LicenseRepository) GetByOrderID(ctx context.Context, orderID int64) ([]*domain.LicenseWithTemplate, error) {
+	licenses := []*domain.LicenseWithTemplate{}
+	err := r.db.SelectContext(ctx, &licenses, licenseWithTemplateSelect+` WHERE l.order_id = $1 ORDER BY l.id`, orderID)
+	return licenses, err
+}
+
+func (r *LicenseRepository) RevokeByOrderID(ctx context.Context, orderID int64, reason string) (int64, error) {
+	query := `
+		UPDATE licenses
+		SET status = 'revoked', revoked_at = CURRENT_TIMESTAMP, revoke_reason = $1
+		WHERE order_id = $2 AND status = 'active'
+	`
+	result, err := r.db.ExecContext(ctx, query, reason, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
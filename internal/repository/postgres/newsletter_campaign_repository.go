@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type NewsletterCampaignRepository struct {
+	db *sqlx.DB
+}
+
+func NewNewsletterCampaignRepository(db *sqlx.DB) *NewsletterCampaignRepository {
+	return &NewsletterCampaignRepository{db: db}
+}
+
+// FindOrCreate inserts the campaign, or loads the one that already has its
+// slug so a retried send carries on where it stopped
+func (r *NewsletterCampaignRepository) FindOrCreate(ctx context.Context, campaign *domain.NewsletterCampaign) error {
+	query := `
+		INSERT INTO newsletter_campaigns (
+			subject, slug, content, plain_text, from_name, from_email,
+			preview_text, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (slug) DO NOTHING
+		RETURNING *
+	`
+	err := r.db.QueryRowxContext(
+		ctx, query,
+		campaign.Subject, campaign.Slug, campaign.Content, campaign.PlainText,
+		campaign.FromName, campaign.FromEmail, campaign.PreviewText,
+		campaign.Status, campaign.CreatedBy,
+	).StructScan(campaign)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	return r.db.GetContext(ctx, campaign, `SELECT * FROM newsletter_campaigns WHERE slug = $1`, campaign.Slug)
+}
+
+// AddRecipients queues every active subscriber not yet on the campaign and
+// returns the campaign's recipient count
+func (r *NewsletterCampaignRepository) AddRecipients(ctx context.Context, campaignID int64) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO newsletter_campaign_recipients (campaign_id, subscriber_id)
+		SELECT $1, id FROM newsletter_subscribers WHERE status = 'active'
+		ON CONFLICT (campaign_id, subscriber_id) DO NOTHING
+	`, campaignID); err != nil {
+		return 0, err
+	}
+
+	var total int
+	if err := tx.GetContext(ctx, &total, `
+		UPDATE newsletter_campaigns SET
+			status = 'sending',
+			total_recipients = (SELECT COUNT(*) FROM newsletter_campaign_recipients WHERE campaign_id = $1)
+		WHERE id = $1
+		RETURNING total_recipients
+	`, campaignID); err != nil {
+		return 0, err
+	}
+
+	return total, tx.Commit()
+}
+
+func (r *NewsletterCampaignRepository) GetPendingRecipients(ctx context.Context, campaignID int64) ([]*domain.PendingCampaignRecipient, error) {
+	var recipients []*domain.PendingCampaignRecipient
+	err := r.db.SelectContext(ctx, &recipients, `
+		SELECT cr.id, cr.subscriber_id, s.email, s.name
+		FROM newsletter_campaign_recipients cr
+		JOIN newsletter_subscribers s ON s.id = cr.subscriber_id
+		WHERE cr.campaign_id = $1 AND cr.status = 'pending' AND s.status = 'active'
+		ORDER BY cr.id
+	`, campaignID)
+	return recipients, err
+}
+
+func (r *NewsletterCampaignRepository) MarkRecipientSent(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE newsletter_campaign_recipients SET status = 'sent', sent_at = NOW(), error_message = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (r *NewsletterCampaignRepository) MarkRecipientFailed(ctx context.Context, id int64, errorMsg string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE newsletter_campaign_recipients SET status = 'failed', error_message = $2
+		WHERE id = $1
+	`, id, errorMsg)
+	return err
+}
+
+// Finish marks the campaign sent and totals its recipients' outcomes
+func (r *NewsletterCampaignRepository) Finish(ctx context.Context, campaignID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE newsletter_campaigns SET
+			status = 'sent',
+			sent_at = NOW(),
+			total_sent = (SELECT COUNT(*) FROM newsletter_campaign_recipients WHERE campaign_id = $1 AND status = 'sent'),
+			total_failed = (SELECT COUNT(*) FROM newsletter_campaign_recipients WHERE campaign_id = $1 AND status = 'failed')
+		WHERE id = $1
+	`, campaignID)
+	return err
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/merraki/merraki-backend/internal/domain"
@@ -20,8 +21,9 @@ func (r *OrderItemRepository) Create(ctx context.Context, item *domain.OrderItem
 		INSERT INTO order_items (
 			order_id, template_id, template_name, template_slug, template_version,
 			price_usd_cents,
-			file_url, file_format, file_size_mb
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			file_url, file_format, file_size_mb,
+			bundle_id, bundle_name
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`
 	return r.db.QueryRowContext(
@@ -29,6 +31,7 @@ func (r *OrderItemRepository) Create(ctx context.Context, item *domain.OrderItem
 		item.OrderID, item.TemplateID, item.TemplateName, item.TemplateSlug, item.TemplateVersion,
 		item.PriceUSDCents,
 		item.FileURL, item.FileFormat, item.FileSizeMB,
+		item.BundleID, item.BundleName,
 	).Scan(&item.ID, &item.CreatedAt)
 }
 
@@ -43,8 +46,9 @@ func (r *OrderItemRepository) CreateBatch(ctx context.Context, items []*domain.O
 		INSERT INTO order_items (
 			order_id, template_id, template_name, template_slug, template_version,
 			price_usd_cents,
-			file_url, file_format, file_size_mb
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			file_url, file_format, file_size_mb,
+			bundle_id, bundle_name
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`
 
@@ -54,6 +58,7 @@ func (r *OrderItemRepository) CreateBatch(ctx context.Context, items []*domain.O
 			item.OrderID, item.TemplateID, item.TemplateName, item.TemplateSlug, item.TemplateVersion,
 			item.PriceUSDCents,
 			item.FileURL, item.FileFormat, item.FileSizeMB,
+			item.BundleID, item.BundleName,
 		).Scan(&item.ID, &item.CreatedAt); err != nil {
 			return err
 		}
@@ -84,4 +89,35 @@ func (r *OrderItemRepository) IncrementDownloadCount(ctx context.Context, id int
 		WHERE id = $1
 	`, id)
 	return err
-}
\ No newline at end of file
+}
+
+func (r *OrderItemRepository) SetWatermarkedFile(ctx context.Context, id int64, fileURL, sourceURL string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE order_items
+		SET watermarked_file_url = $1, watermark_source_url = $2, watermarked_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, fileURL, sourceURL, id)
+	return err
+}
+
+// FindApprovedPurchase returns the most recent item for the template in an
+// approved order placed with email, or domain.ErrNotFound.
+func (r *OrderItemRepository) FindApprovedPurchase(ctx context.Context, templateID int64, email string) (*domain.OrderItem, error) {
+	var item domain.OrderItem
+	err := r.db.GetContext(ctx, &item, `
+		SELECT oi.* FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE oi.template_id = $1
+		  AND LOWER(o.customer_email) = LOWER($2)
+		  AND o.status = 'approved'
+		ORDER BY o.created_at DESC
+		LIMIT 1
+	`, templateID, email)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -28,11 +28,11 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error
 			billing_address_line1, billing_address_line2,
 			billing_city, billing_state, billing_country, billing_postal_code,
 			subtotal_usd_cents, tax_amount_usd_cents, discount_amount_usd_cents, total_amount_usd_cents,
-			payment_gateway, status, idempotency_key, metadata
+			payment_gateway, status, idempotency_key, metadata, notify_updates
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
 			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
-			$21, $22, $23, $24
+			$21, $22, $23, $24, $25
 		) RETURNING id, created_at, updated_at
 	`
 
@@ -44,7 +44,7 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error
 		order.BillingAddressLine1, order.BillingAddressLine2,
 		order.BillingCity, order.BillingState, order.BillingCountry, order.BillingPostalCode,
 		order.SubtotalUSDCents, order.TaxAmountUSDCents, order.DiscountAmountUSDCents, order.TotalAmountUSDCents,
-		order.PaymentGateway, order.Status, order.IdempotencyKey, order.Metadata,
+		order.PaymentGateway, order.Status, order.IdempotencyKey, order.Metadata, order.NotifyUpdates,
 	).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
 }
 
@@ -80,14 +80,14 @@ func (r *OrderRepository) FindByEmail(ctx context.Context, email string, limit,
 	var total int
 
 	if err := r.db.GetContext(ctx, &total,
-		`SELECT COUNT(*) FROM orders WHERE customer_email = $1`, email,
+		`SELECT COUNT(*) FROM orders WHERE LOWER(customer_email) = LOWER($1)`, email,
 	); err != nil {
 		return nil, 0, err
 	}
 
 	err := r.db.SelectContext(ctx, &orders, `
 		SELECT * FROM orders
-		WHERE customer_email = $1
+		WHERE LOWER(customer_email) = LOWER($1)
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`, email, limit, offset)
@@ -120,6 +120,12 @@ func (r *OrderRepository) GetAll(ctx context.Context, filters map[string]interfa
 		argPos++
 	}
 
+	if minRisk, ok := filters["min_risk_score"].(int); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("risk_score >= $%d", argPos))
+		args = append(args, minRisk)
+		argPos++
+	}
+
 	if startDate, ok := filters["start_date"].(string); ok && startDate != "" {
 		whereClauses = append(whereClauses, fmt.Sprintf("created_at >= $%d", argPos))
 		args = append(args, startDate)
@@ -140,13 +146,18 @@ func (r *OrderRepository) GetAll(ctx context.Context, filters map[string]interfa
 		return nil, 0, err
 	}
 
+	orderBy := "created_at DESC"
+	if sort, _ := filters["sort"].(string); sort == "risk" {
+		orderBy = "risk_score DESC NULLS LAST, created_at DESC"
+	}
+
 	args = append(args, limit, offset)
 	query := fmt.Sprintf(`
 		SELECT * FROM orders
 		WHERE %s
-		ORDER BY created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argPos, argPos+1)
+	`, whereClause, orderBy, argPos, argPos+1)
 
 	err := r.db.SelectContext(ctx, &orders, query, args...)
 	return orders, total, err
@@ -242,7 +253,7 @@ func (r *OrderRepository) UpdateStatus(ctx context.Context, id int64, newStatus
 	return tx.Commit()
 }
 
-func (r *OrderRepository) Approve(ctx context.Context, id int64, adminID int64, notes *string) error {
+func (r *OrderRepository) Approve(ctx context.Context, id int64, adminID int64, notes *string, downloadsExpiresAt time.Time) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
@@ -250,7 +261,6 @@ func (r *OrderRepository) Approve(ctx context.Context, id int64, adminID int64,
 	defer tx.Rollback()
 
 	now := time.Now()
-	expiresAt := now.AddDate(0, 0, 30)
 
 	result, err := tx.ExecContext(ctx, `
 		UPDATE orders SET
@@ -262,7 +272,7 @@ func (r *OrderRepository) Approve(ctx context.Context, id int64, adminID int64,
 			downloads_expires_at = $5,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $6 AND status IN ('paid', 'admin_review')
-	`, domain.OrderStatusApproved, adminID, now, notes, expiresAt, id)
+	`, domain.OrderStatusApproved, adminID, now, notes, downloadsExpiresAt, id)
 	if err != nil {
 		return err
 	}
@@ -283,6 +293,75 @@ func (r *OrderRepository) Approve(ctx context.Context, id int64, adminID int64,
 	return tx.Commit()
 }
 
+// AutoApprove approves a paid order without an admin reviewer.
+func (r *OrderRepository) AutoApprove(ctx context.Context, id int64, triggeredBy, reason string, metadata domain.JSONMap, downloadsExpiresAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE orders SET
+			status = $1,
+			admin_reviewed_at = CURRENT_TIMESTAMP,
+			downloads_enabled = true,
+			downloads_expires_at = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status IN ('paid', 'admin_review')
+	`, domain.OrderStatusApproved, downloadsExpiresAt, id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrInvalidStateTransition
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO order_state_transitions (order_id, to_status, triggered_by, reason, metadata)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, domain.OrderStatusApproved, triggeredBy, reason, metadata)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MoveToReview sends a paid order to the admin review queue.
+func (r *OrderRepository) MoveToReview(ctx context.Context, id int64, triggeredBy, reason string, metadata domain.JSONMap) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = 'paid'
+	`, domain.OrderStatusAdminReview, id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrInvalidStateTransition
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO order_state_transitions (order_id, from_status, to_status, triggered_by, reason, metadata)
+		VALUES ($1, 'paid', $2, $3, $4, $5)
+	`, id, domain.OrderStatusAdminReview, triggeredBy, reason, metadata)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *OrderRepository) Reject(ctx context.Context, id int64, adminID int64, reason string) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -329,7 +408,7 @@ func (r *OrderRepository) MarkAsPaid(ctx context.Context, id int64, adminID int6
 
 	result, err := tx.ExecContext(ctx, `
 		UPDATE orders
-		SET status = $1, gateway_order_id = $2, updated_at = CURRENT_TIMESTAMP
+		SET status = $1, paid_at = CURRENT_TIMESTAMP, gateway_order_id = $2, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $3 AND status = 'pending'
 	`, domain.OrderStatusPaid, gatewayOrderID, id)
 	if err != nil {
@@ -352,6 +431,26 @@ func (r *OrderRepository) MarkAsPaid(ctx context.Context, id int64, adminID int6
 	return tx.Commit()
 }
 
+// MarkPaid records a captured payment on an order awaiting one. status and
+// paid_at are written together, as check_status_timestamps requires; the
+// trigger logs the transition.
+func (r *OrderRepository) MarkPaid(ctx context.Context, id int64, gatewayPaymentID string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE orders
+		SET status = $1, paid_at = CURRENT_TIMESTAMP, gateway_payment_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status IN ('pending', 'payment_initiated', 'payment_processing')
+	`, domain.OrderStatusPaid, gatewayPaymentID, id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrInvalidStateTransition
+	}
+	return nil
+}
+
 // FIX 2: Added adminID int64 param to match interface; cascade delete via SQL
 func (r *OrderRepository) Delete(ctx context.Context, id int64, adminID int64) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
@@ -395,6 +494,147 @@ func (r *OrderRepository) Delete(ctx context.Context, id int64, adminID int64) e
 // Analytics
 // ============================================================================
 
+func (r *OrderRepository) CountApprovedByEmail(ctx context.Context, email string, excludeOrderID int64) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM orders
+		WHERE LOWER(customer_email) = LOWER($1) AND status = 'approved' AND id <> $2
+	`
+	err := r.db.GetContext(ctx, &count, query, email, excludeOrderID)
+	return count, err
+}
+
+// ============================================================================
+// Customer accounts
+// ============================================================================
+
+// ClaimByEmail attaches unclaimed guest orders placed with the customer's
+// (verified) email to their account and returns how many were claimed.
+func (r *OrderRepository) ClaimByEmail(ctx context.Context, customerID int64, email string) (int64, error) {
+	query := `
+		UPDATE orders SET customer_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE customer_id IS NULL AND LOWER(customer_email) = LOWER($2)
+	`
+	result, err := r.db.ExecContext(ctx, query, customerID, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *OrderRepository) FindByCustomerID(ctx context.Context, customerID int64, limit, offset int) ([]*domain.Order, int, error) {
+	var orders []*domain.Order
+	var total int
+
+	if err := r.db.GetContext(ctx, &total,
+		`SELECT COUNT(*) FROM orders WHERE customer_id = $1`, customerID,
+	); err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.SelectContext(ctx, &orders, `
+		SELECT * FROM orders
+		WHERE customer_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, customerID, limit, offset)
+	return orders, total, err
+}
+
+// ============================================================================
+// Update notifications
+// ============================================================================
+
+func (r *OrderRepository) SetNotifyUpdates(ctx context.Context, id int64, enabled bool) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE orders SET notify_updates = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+		enabled, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// FindUpdateSubscribers returns one row per buyer email who opted in and
+// owns the template through an approved order, skipping buyers who already
+// purchased versionNumber. The most recent order wins for each email.
+func (r *OrderRepository) FindUpdateSubscribers(ctx context.Context, templateID int64, versionNumber string) ([]*domain.TemplateUpdateSubscriber, error) {
+	var subscribers []*domain.TemplateUpdateSubscriber
+	query := `
+		SELECT DISTINCT ON (LOWER(o.customer_email))
+			o.id AS order_id, o.order_number, o.customer_email, o.customer_name,
+			oi.template_version AS purchased_version
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.id
+		WHERE oi.template_id = $1
+		  AND o.status = 'approved'
+		  AND o.notify_updates = true
+		  AND oi.template_version IS DISTINCT FROM $2
+		ORDER BY LOWER(o.customer_email), o.created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &subscribers, query, templateID, versionNumber)
+	return subscribers, err
+}
+
+// ============================================================================
+// Risk scoring
+// ============================================================================
+
+func (r *OrderRepository) UpdateRisk(ctx context.Context, id int64, score int, reasons domain.RiskReasons) error {
+	query := `
+		UPDATE orders SET
+			risk_score = $1,
+			risk_reasons = $2,
+			risk_evaluated_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+	result, err := r.db.ExecContext(ctx, query, score, reasons, id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CountRecentByEmail counts orders from the same email that reached payment
+// since the given time.
+func (r *OrderRepository) CountRecentByEmail(ctx context.Context, email string, since time.Time, excludeOrderID int64) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM orders
+		WHERE LOWER(customer_email) = LOWER($1)
+		  AND created_at >= $2 AND id <> $3
+		  AND status IN ('paid', 'admin_review', 'approved', 'rejected', 'refunded')
+	`
+	err := r.db.GetContext(ctx, &count, query, email, since, excludeOrderID)
+	return count, err
+}
+
+// CountRecentByIP counts orders from the same IP that reached payment since
+// the given time.
+func (r *OrderRepository) CountRecentByIP(ctx context.Context, ip string, since time.Time, excludeOrderID int64) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM orders
+		WHERE customer_ip = $1
+		  AND created_at >= $2 AND id <> $3
+		  AND status IN ('paid', 'admin_review', 'approved', 'rejected', 'refunded')
+	`
+	err := r.db.GetContext(ctx, &count, query, ip, since, excludeOrderID)
+	return count, err
+}
+
 func (r *OrderRepository) GetRevenueByDateRange(ctx context.Context, startDate, endDate string) (float64, error) {
 	var revenue int64
 	err := r.db.GetContext(ctx, &revenue, `
@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+// ============================================================================
+// Subscriptions
+// ============================================================================
+
+type WebhookSubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookSubscriptionRepository(db *sqlx.DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: db}
+}
+
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, sub *domain.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (
+			name, url, secret, event_types, is_active, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx, query,
+		sub.Name, sub.URL, sub.Secret, sub.EventTypes, sub.IsActive, sub.CreatedBy,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+func (r *WebhookSubscriptionRepository) FindByID(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	err := r.db.GetContext(ctx, &sub, `SELECT * FROM webhook_subscriptions WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &sub, err
+}
+
+func (r *WebhookSubscriptionRepository) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	var subs []*domain.WebhookSubscription
+	err := r.db.SelectContext(ctx, &subs, `SELECT * FROM webhook_subscriptions ORDER BY id`)
+	return subs, err
+}
+
+func (r *WebhookSubscriptionRepository) GetActiveForEvent(ctx context.Context, event string) ([]*domain.WebhookSubscription, error) {
+	var subs []*domain.WebhookSubscription
+	query := `
+		SELECT * FROM webhook_subscriptions
+		WHERE is_active = true AND $1 = ANY(event_types)
+		ORDER BY id
+	`
+	err := r.db.SelectContext(ctx, &subs, query, event)
+	return subs, err
+}
+
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, sub *domain.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions SET
+			name = $1, url = $2, secret = $3, event_types = $4, is_active = $5
+		WHERE id = $6
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		sub.Name, sub.URL, sub.Secret, sub.EventTypes, sub.IsActive, sub.ID,
+	).Scan(&sub.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// Deliveries
+// ============================================================================
+
+type WebhookDeliveryRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookDeliveryRepository(db *sqlx.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			subscription_id, event_id, event_type, payload, status
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx, query,
+		delivery.SubscriptionID, delivery.EventID, delivery.EventType,
+		delivery.Payload, delivery.Status,
+	).Scan(&delivery.ID, &delivery.CreatedAt, &delivery.UpdatedAt)
+}
+
+func (r *WebhookDeliveryRepository) FindByID(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.GetContext(ctx, &delivery, `SELECT * FROM webhook_deliveries WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &delivery, err
+}
+
+func (r *WebhookDeliveryRepository) GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.WebhookDelivery, int, error) {
+	var deliveries []*domain.WebhookDelivery
+	var total int
+
+	whereClauses := []string{"1=1"}
+	args := []interface{}{}
+	argPos := 1
+
+	if subID, ok := filters["subscription_id"].(int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("subscription_id = $%d", argPos))
+		args = append(args, subID)
+		argPos++
+	}
+
+	if status, ok := filters["status"].(domain.WebhookDeliveryStatus); ok && status != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, status)
+		argPos++
+	}
+
+	if eventType, ok := filters["event_type"].(string); ok && eventType != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("event_type = $%d", argPos))
+		args = append(args, eventType)
+		argPos++
+	}
+
+	whereClause := strings.Join(whereClauses, " AND ")
+
+	if err := r.db.GetContext(ctx, &total,
+		fmt.Sprintf("SELECT COUNT(*) FROM webhook_deliveries WHERE %s", whereClause), args...,
+	); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT * FROM webhook_deliveries
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &deliveries, query, args...)
+	return deliveries, total, err
+}
+
+// RecordAttempt stores the outcome of one delivery attempt and bumps the
+// attempt counter.
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries SET
+			status = $1,
+			attempts = attempts + 1,
+			response_status = $2, response_body = $3, last_error = $4,
+			last_attempt_at = CURRENT_TIMESTAMP,
+			delivered_at = $5
+		WHERE id = $6
+		RETURNING attempts, last_attempt_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx, query,
+		delivery.Status,
+		delivery.ResponseStatus, delivery.ResponseBody, delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	).Scan(&delivery.Attempts, &delivery.LastAttemptAt)
+}
+
+func (r *WebhookDeliveryRepository) ResetForRedelivery(ctx context.Context, id int64) error {
+	query := `UPDATE webhook_deliveries SET status = 'pending' WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type PaymentDisputeRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentDisputeRepository(db *sqlx.DB) *PaymentDisputeRepository {
+	return &PaymentDisputeRepository{db: db}
+}
+
+// Upsert inserts the dispute or refreshes it from the latest gateway event,
+// keyed on gateway_dispute_id.
+func (r *PaymentDisputeRepository) Upsert(ctx context.Context, dispute *domain.PaymentDispute) error {
+	query := `
+		INSERT INTO payment_disputes (
+			payment_id, order_id, gateway_dispute_id,
+			reason_code, reason_description,
+			amount_minor, amount_deducted_minor, currency,
+			phase, status, respond_by, resolved_at, gateway_payload
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (gateway_dispute_id) DO UPDATE SET
+			reason_code = EXCLUDED.reason_code,
+			reason_description = EXCLUDED.reason_description,
+			amount_minor = EXCLUDED.amount_minor,
+			amount_deducted_minor = EXCLUDED.amount_deducted_minor,
+			currency = EXCLUDED.currency,
+			phase = EXCLUDED.phase,
+			status = EXCLUDED.status,
+			respond_by = EXCLUDED.respond_by,
+			resolved_at = COALESCE(payment_disputes.resolved_at, EXCLUDED.resolved_at),
+			gateway_payload = EXCLUDED.gateway_payload
+		RETURNING id, resolved_at, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx, query,
+		dispute.PaymentID, dispute.OrderID, dispute.GatewayDisputeID,
+		dispute.ReasonCode, dispute.ReasonDescription,
+		dispute.AmountMinor, dispute.AmountDeductedMinor, dispute.Currency,
+		dispute.Phase, dispute.Status, dispute.RespondBy, dispute.ResolvedAt, dispute.GatewayPayload,
+	).Scan(&dispute.ID, &dispute.ResolvedAt, &dispute.CreatedAt, &dispute.UpdatedAt)
+}
+
+func (r *PaymentDisputeRepository) FindByID(ctx context.Context, id int64) (*domain.PaymentDispute, error) {
+	var dispute domain.PaymentDispute
+	err := r.db.GetContext(ctx, &dispute, `SELECT * FROM payment_disputes WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &dispute, err
+}
+
+func (r *PaymentDisputeRepository) FindByGatewayDisputeID(ctx context.Context, gatewayDisputeID string) (*domain.PaymentDispute, error) {
+	var dispute domain.PaymentDispute
+	err := r.db.GetContext(ctx, &dispute,
+		`SELECT * FROM payment_disputes WHERE gateway_dispute_id = $1`, gatewayDisputeID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &dispute, err
+}
+
+func (r *PaymentDisputeRepository) GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.PaymentDispute, int, error) {
+	var disputes []*domain.PaymentDispute
+	var total int
+
+	whereClauses := []string{"1=1"}
+	args := []interface{}{}
+	argPos := 1
+
+	if status, ok := filters["status"].(domain.DisputeStatus); ok && status != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, status)
+		argPos++
+	}
+
+	if orderID, ok := filters["order_id"].(int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("order_id = $%d", argPos))
+		args = append(args, orderID)
+		argPos++
+	}
+
+	whereClause := strings.Join(whereClauses, " AND ")
+
+	if err := r.db.GetContext(ctx, &total,
+		fmt.Sprintf("SELECT COUNT(*) FROM payment_disputes WHERE %s", whereClause), args...,
+	); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT * FROM payment_disputes
+		WHERE %s
+		ORDER BY respond_by ASC NULLS LAST, created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &disputes, query, args...)
+	return disputes, total, err
+}
+
+func (r *PaymentDisputeRepository) GetByOrderID(ctx context.Context, orderID int64) ([]*domain.PaymentDispute, error) {
+	var disputes []*domain.PaymentDispute
+	err := r.db.SelectContext(ctx, &disputes,
+		`SELECT * FROM payment_disputes WHERE order_id = $1 ORDER BY created_at DESC`, orderID,
+	)
+	return disputes, err
+}
+
+// ============================================================================
+// Evidence
+// ============================================================================
+
+func (r *PaymentDisputeRepository) AddEvidence(ctx context.Context, evidence *domain.DisputeEvidence) error {
+	query := `
+		INSERT INTO dispute_evidence (dispute_id, notes, document_url, added_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query,
+		evidence.DisputeID, evidence.Notes, evidence.DocumentURL, evidence.AddedBy,
+	).Scan(&evidence.ID, &evidence.CreatedAt)
+}
+
+func (r *PaymentDisputeRepository) GetEvidence(ctx context.Context, disputeID int64) ([]*domain.DisputeEvidence, error) {
+	var evidence []*domain.DisputeEvidence
+	err := r.db.SelectContext(ctx, &evidence,
+		`SELECT * FROM dispute_evidence WHERE dispute_id = $1 ORDER BY created_at`, disputeID,
+	)
+	return evidence, err
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/merraki/merraki-backend/internal/domain"
@@ -146,4 +147,62 @@ func (r *PaymentRepository) MarkAsVerified(ctx context.Context, id int64) error
 		WHERE id = $1
 	`, id)
 	return err
-}
\ No newline at end of file
+}
+// ============================================================================
+// Risk scoring
+// ============================================================================
+
+// CountRecentOrdersByCardLast4 counts other orders paid with a card ending in
+// the same four digits since the given time.
+func (r *PaymentRepository) CountRecentOrdersByCardLast4(ctx context.Context, last4 string, since time.Time, excludeOrderID int64) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(DISTINCT order_id) FROM payments
+		WHERE card_last4 = $1 AND created_at >= $2 AND order_id <> $3
+	`
+	err := r.db.GetContext(ctx, &count, query, last4, since, excludeOrderID)
+	return count, err
+}
+
+// CountRecentFailedByEmail counts failed payments on orders placed by the
+// same email since the given time.
+func (r *PaymentRepository) CountRecentFailedByEmail(ctx context.Context, email string, since time.Time) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM payments p
+		JOIN orders o ON o.id = p.order_id
+		WHERE LOWER(o.customer_email) = LOWER($1)
+		  AND p.status = 'failed' AND p.created_at >= $2
+	`
+	err := r.db.GetContext(ctx, &count, query, email, since)
+	return count, err
+}
+
+// ============================================================================
+// Reconciliation
+// ============================================================================
+
+func (r *PaymentRepository) GetCapturedBetween(ctx context.Context, from, to time.Time) ([]*domain.Payment, error) {
+	var payments []*domain.Payment
+	query := `
+		SELECT * FROM payments
+		WHERE status = 'captured' AND captured_at >= $1 AND captured_at < $2
+		ORDER BY captured_at
+	`
+	err := r.db.SelectContext(ctx, &payments, query, from, to)
+	return payments, err
+}
+
+func (r *PaymentRepository) UpdateSettlement(ctx context.Context, id int64, feeCents, netCents int64, settlementID *string, settledAt *time.Time) error {
+	query := `
+		UPDATE payments SET
+			gateway_fee_usd_cents = $1,
+			net_amount_usd_cents = $2,
+			settlement_id = COALESCE($3, settlement_id),
+			settled_at = COALESCE($4, settled_at),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+	_, err := r.db.ExecContext(ctx, query, feeCents, netCents, settlementID, settledAt, id)
+	return err
+}
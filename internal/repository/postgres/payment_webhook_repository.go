@@ -3,6 +3,9 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/merraki/merraki-backend/internal/domain"
@@ -36,6 +39,33 @@ func (r *PaymentWebhookRepository) Create(ctx context.Context, webhook *domain.P
 	).Scan(&webhook.ID, &webhook.CreatedAt)
 }
 
+// CreateUnique stores the webhook unless one with the same webhook_id was
+// already received. It reports whether the webhook is new.
+func (r *PaymentWebhookRepository) CreateUnique(ctx context.Context, webhook *domain.PaymentWebhook) (bool, error) {
+	query := `
+		INSERT INTO payment_webhooks (
+			webhook_id, event_type, order_id, payment_id,
+			gateway_order_id, gateway_payment_id,
+			payload, signature, signature_verified,
+			source_ip, user_agent, max_retries
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (webhook_id) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		webhook.WebhookID, webhook.EventType, webhook.OrderID, webhook.PaymentID,
+		webhook.GatewayOrderID, webhook.GatewayPaymentID,
+		webhook.Payload, webhook.Signature, webhook.SignatureVerified,
+		webhook.SourceIP, webhook.UserAgent, webhook.MaxRetries,
+	).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func (r *PaymentWebhookRepository) FindByID(ctx context.Context, id int64) (*domain.PaymentWebhook, error) {
 	var webhook domain.PaymentWebhook
 	query := `SELECT * FROM payment_webhooks WHERE id = $1`
@@ -47,34 +77,128 @@ func (r *PaymentWebhookRepository) FindByID(ctx context.Context, id int64) (*dom
 	return &webhook, err
 }
 
-func (r *PaymentWebhookRepository) GetUnprocessed(ctx context.Context, limit int) ([]*domain.PaymentWebhook, error) {
+func (r *PaymentWebhookRepository) GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.PaymentWebhook, int, error) {
+	var webhooks []*domain.PaymentWebhook
+	var total int
+
+	whereClauses := []string{"1=1"}
+	args := []interface{}{}
+	argPos := 1
+
+	if eventType, ok := filters["event_type"].(string); ok && eventType != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("event_type = $%d", argPos))
+		args = append(args, eventType)
+		argPos++
+	}
+
+	if verified, ok := filters["signature_verified"].(bool); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("signature_verified = $%d", argPos))
+		args = append(args, verified)
+		argPos++
+	}
+
+	if processed, ok := filters["processed"].(bool); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("processed = $%d", argPos))
+		args = append(args, processed)
+		argPos++
+	}
+
+	if orderID, ok := filters["order_id"].(int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("order_id = $%d", argPos))
+		args = append(args, orderID)
+		argPos++
+	}
+
+	whereClause := strings.Join(whereClauses, " AND ")
+
+	if err := r.db.GetContext(ctx, &total,
+		fmt.Sprintf("SELECT COUNT(*) FROM payment_webhooks WHERE %s", whereClause), args...,
+	); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT * FROM payment_webhooks
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &webhooks, query, args...)
+	return webhooks, total, err
+}
+
+// ClaimUnprocessed claims verified webhooks that are due for another attempt
+// and returns them. Webhooks that were never attempted get a few minutes'
+// grace so the sweeper does not race the request that received them.
+//
+// Claiming stamps processing_started_at in the same statement that picks the
+// rows, skipping rows another sweeper is claiming at that moment, so each
+// webhook goes to one replica. The claim is released when the attempt is
+// recorded, or lapses after lease if that replica dies mid-dispatch.
+func (r *PaymentWebhookRepository) ClaimUnprocessed(ctx context.Context, limit int, lease time.Duration) ([]*domain.PaymentWebhook, error) {
 	var webhooks []*domain.PaymentWebhook
 	query := `
-		SELECT * FROM payment_webhooks 
-		WHERE processed = false AND retry_count < max_retries
-		ORDER BY created_at ASC 
-		LIMIT $1
+		UPDATE payment_webhooks
+		SET processing_started_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM payment_webhooks
+			WHERE processed = false
+			  AND signature_verified = true
+			  AND retry_count < max_retries
+			  AND COALESCE(next_retry_at, created_at + INTERVAL '5 minutes') <= CURRENT_TIMESTAMP
+			  AND (processing_started_at IS NULL
+			       OR processing_started_at <= CURRENT_TIMESTAMP - $2 * INTERVAL '1 second')
+			ORDER BY created_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
 	`
-	err := r.db.SelectContext(ctx, &webhooks, query, limit)
+	err := r.db.SelectContext(ctx, &webhooks, query, limit, int64(lease.Seconds()))
 	return webhooks, err
 }
 
+// Claim takes the processing claim on one webhook, as ClaimUnprocessed does
+// for a batch, whatever its retry state. It reports false while another
+// claim on it is live.
+func (r *PaymentWebhookRepository) Claim(ctx context.Context, id int64, lease time.Duration) (bool, error) {
+	query := `
+		UPDATE payment_webhooks
+		SET processing_started_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND (processing_started_at IS NULL
+		       OR processing_started_at <= CURRENT_TIMESTAMP - $2 * INTERVAL '1 second')
+	`
+	result, err := r.db.ExecContext(ctx, query, id, int64(lease.Seconds()))
+	if err != nil {
+		return false, err
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
 func (r *PaymentWebhookRepository) MarkAsProcessed(ctx context.Context, id int64) error {
 	query := `
 		UPDATE payment_webhooks 
-		SET processed = true, processed_at = CURRENT_TIMESTAMP 
+		SET processed = true, processed_at = CURRENT_TIMESTAMP,
+		    processing_error = NULL, next_retry_at = NULL,
+		    processing_started_at = NULL
 		WHERE id = $1
 	`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *PaymentWebhookRepository) IncrementRetryCount(ctx context.Context, id int64, errorMsg string) error {
+func (r *PaymentWebhookRepository) IncrementRetryCount(ctx context.Context, id int64, errorMsg string, nextRetryAt *time.Time) error {
 	query := `
 		UPDATE payment_webhooks 
-		SET retry_count = retry_count + 1, processing_error = $1 
-		WHERE id = $2
+		SET retry_count = retry_count + 1, processing_error = $1, next_retry_at = $2,
+		    processing_started_at = NULL
+		WHERE id = $3
 	`
-	_, err := r.db.ExecContext(ctx, query, errorMsg, id)
+	_, err := r.db.ExecContext(ctx, query, errorMsg, nextRetryAt, id)
 	return err
 }
\ No newline at end of file
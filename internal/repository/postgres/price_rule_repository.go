@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type PriceRuleRepository struct {
+	db *sqlx.DB
+}
+
+func NewPriceRuleRepository(db *sqlx.DB) *PriceRuleRepository {
+	return &PriceRuleRepository{db: db}
+}
+
+func (r *PriceRuleRepository) Create(ctx context.Context, rule *domain.PriceRule) error {
+	query := `
+		INSERT INTO template_price_rules (
+			name, template_id, category_id,
+			sale_price_usd_cents, discount_percent,
+			starts_at, ends_at, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query,
+		rule.Name, rule.TemplateID, rule.CategoryID,
+		rule.SalePriceUSDCents, rule.DiscountPercent,
+		rule.StartsAt, rule.EndsAt, rule.Status, rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (r *PriceRuleRepository) FindByID(ctx context.Context, id int64) (*domain.PriceRule, error) {
+	var rule domain.PriceRule
+	err := r.db.GetContext(ctx, &rule, `SELECT * FROM template_price_rules WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *PriceRuleRepository) GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.PriceRule, int, error) {
+	var rules []*domain.PriceRule
+	var total int
+
+	whereClauses := []string{"1=1"}
+	args := []interface{}{}
+	argPos := 1
+
+	if status, ok := filters["status"].(domain.PriceRuleStatus); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, status)
+		argPos++
+	}
+
+	if templateID, ok := filters["template_id"].(int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("template_id = $%d", argPos))
+		args = append(args, templateID)
+		argPos++
+	}
+
+	if categoryID, ok := filters["category_id"].(int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("category_id = $%d", argPos))
+		args = append(args, categoryID)
+		argPos++
+	}
+
+	whereClause := strings.Join(whereClauses, " AND ")
+
+	err := r.db.GetContext(ctx, &total, fmt.Sprintf("SELECT COUNT(*) FROM template_price_rules WHERE %s", whereClause), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT * FROM template_price_rules
+		WHERE %s
+		ORDER BY starts_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+
+	err = r.db.SelectContext(ctx, &rules, query, args...)
+	return rules, total, err
+}
+
+func (r *PriceRuleRepository) Update(ctx context.Context, rule *domain.PriceRule) error {
+	query := `
+		UPDATE template_price_rules SET
+			name = $1, template_id = $2, category_id = $3,
+			sale_price_usd_cents = $4, discount_percent = $5,
+			starts_at = $6, ends_at = $7, status = $8
+		WHERE id = $9
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(
+		ctx, query,
+		rule.Name, rule.TemplateID, rule.CategoryID,
+		rule.SalePriceUSDCents, rule.DiscountPercent,
+		rule.StartsAt, rule.EndsAt, rule.Status,
+		rule.ID,
+	).Scan(&rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+// ============================================================================
+// Scheduling
+// ============================================================================
+
+// UpdateStatuses ends rules whose window has passed and activates those
+// whose window has begun
+func (r *PriceRuleRepository) UpdateStatuses(ctx context.Context, now time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE template_price_rules SET status = 'ended'
+		WHERE status IN ('scheduled', 'active') AND ends_at <= $1
+	`, now); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE template_price_rules SET status = 'active'
+		WHERE status = 'scheduled' AND starts_at <= $1 AND ends_at > $1
+	`, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PriceRuleRepository) GetActive(ctx context.Context) ([]*domain.PriceRule, error) {
+	var rules []*domain.PriceRule
+	err := r.db.SelectContext(ctx, &rules,
+		`SELECT * FROM template_price_rules WHERE status = 'active' ORDER BY id`,
+	)
+	return rules, err
+}
+
+// GetAffectedTemplates returns the templates an active rule covers, and
+// those still priced by a rule that may no longer be active
+func (r *PriceRuleRepository) GetAffectedTemplates(ctx context.Context) ([]*domain.Template, error) {
+	var templates []*domain.Template
+	err := r.db.SelectContext(ctx, &templates, `
+		SELECT t.* FROM templates t
+		WHERE t.sale_rule_id IS NOT NULL
+		   OR EXISTS (
+			SELECT 1 FROM template_price_rules pr
+			WHERE pr.status = 'active'
+			  AND (pr.template_id = t.id OR pr.category_id = t.category_id)
+		   )
+		ORDER BY t.id
+	`)
+	return templates, err
+}
+
+// ============================================================================
+// History
+// ============================================================================
+
+func (r *PriceRuleRepository) CreateHistory(ctx context.Context, entry *domain.TemplatePriceHistory) error {
+	query := `
+		INSERT INTO template_price_history (
+			template_id, price_usd_cents, sale_price_usd_cents,
+			previous_price_usd_cents, previous_sale_price_usd_cents,
+			source, price_rule_id, changed_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query,
+		entry.TemplateID, entry.PriceUSDCents, entry.SalePriceUSDCents,
+		entry.PreviousPriceUSDCents, entry.PreviousSalePriceUSDCents,
+		entry.Source, entry.PriceRuleID, entry.ChangedBy,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (r *PriceRuleRepository) GetHistory(ctx context.Context, templateID int64, limit, offset int) ([]*domain.TemplatePriceHistory, int, error) {
+	var entries []*domain.TemplatePriceHistory
+	var total int
+
+	err := r.db.GetContext(ctx, &total,
+		`SELECT COUNT(*) FROM template_price_history WHERE template_id = $1`, templateID,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.db.SelectContext(ctx, &entries, `
+		SELECT * FROM template_price_history
+		WHERE template_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, templateID, limit, offset)
+	return entries, total, err
+}
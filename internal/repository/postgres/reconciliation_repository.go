@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type ReconciliationRepository struct {
+	db *sqlx.DB
+}
+
+func NewReconciliationRepository(db *sqlx.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+func (r *ReconciliationRepository) CreateRun(ctx context.Context, run *domain.ReconciliationRun) error {
+	query := `
+		INSERT INTO reconciliation_runs (period_start, period_end, status, triggered_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, started_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query,
+		run.PeriodStart, run.PeriodEnd, run.Status, run.TriggeredBy,
+	).Scan(&run.ID, &run.StartedAt)
+}
+
+func (r *ReconciliationRepository) CompleteRun(ctx context.Context, run *domain.ReconciliationRun) error {
+	query := `
+		UPDATE reconciliation_runs SET
+			status = $1,
+			gateway_payment_count = $2, local_payment_count = $3,
+			matched_count = $4, mismatch_count = $5, fees_updated_count = $6,
+			gateway_fee_usd_cents = $7, net_amount_usd_cents = $8,
+			error = $9,
+			completed_at = CURRENT_TIMESTAMP
+		WHERE id = $10
+		RETURNING completed_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query,
+		run.Status,
+		run.GatewayPaymentCount, run.LocalPaymentCount,
+		run.MatchedCount, run.MismatchCount, run.FeesUpdatedCount,
+		run.GatewayFeeUSDCents, run.NetAmountUSDCents,
+		run.Error,
+		run.ID,
+	).Scan(&run.CompletedAt)
+}
+
+func (r *ReconciliationRepository) FindRunByID(ctx context.Context, id int64) (*domain.ReconciliationRun, error) {
+	var run domain.ReconciliationRun
+	err := r.db.GetContext(ctx, &run, `SELECT * FROM reconciliation_runs WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &run, err
+}
+
+func (r *ReconciliationRepository) GetRuns(ctx context.Context, limit, offset int) ([]*domain.ReconciliationRun, int, error) {
+	var runs []*domain.ReconciliationRun
+	var total int
+
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM reconciliation_runs`); err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.SelectContext(ctx, &runs, `
+		SELECT * FROM reconciliation_runs
+		ORDER BY period_start DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	return runs, total, err
+}
+
+func (r *ReconciliationRepository) AddItems(ctx context.Context, items []*domain.ReconciliationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO reconciliation_items (
+			run_id, payment_id, gateway_payment_id, gateway_order_id, issue,
+			local_status, gateway_status, local_amount_usd_cents, gateway_amount_usd_cents, details
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+
+	for _, item := range items {
+		if err := tx.QueryRowContext(
+			ctx, query,
+			item.RunID, item.PaymentID, item.GatewayPaymentID, item.GatewayOrderID, item.Issue,
+			item.LocalStatus, item.GatewayStatus, item.LocalAmountUSDCents, item.GatewayAmountUSDCents, item.Details,
+		).Scan(&item.ID, &item.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ReconciliationRepository) GetItems(ctx context.Context, runID int64) ([]*domain.ReconciliationItem, error) {
+	var items []*domain.ReconciliationItem
+	err := r.db.SelectContext(ctx, &items,
+		`SELECT * FROM reconciliation_items WHERE run_id = $1 ORDER BY issue, id`, runID,
+	)
+	return items, err
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type SettingsRepository struct {
+	db *sqlx.DB
+}
+
+func NewSettingsRepository(db *sqlx.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+func (r *SettingsRepository) GetAll(ctx context.Context) ([]*domain.Setting, error) {
+	var settings []*domain.Setting
+	query := `SELECT * FROM settings ORDER BY key`
+	err := r.db.SelectContext(ctx, &settings, query)
+	return settings, err
+}
+
+func (r *SettingsRepository) FindByKey(ctx context.Context, key string) (*domain.Setting, error) {
+	var setting domain.Setting
+	query := `SELECT * FROM settings WHERE key = $1`
+
+	err := r.db.GetContext(ctx, &setting, query, key)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &setting, err
+}
+
+// UpdateMany applies all updates atomically and records one history row per
+// changed key. Unknown keys return ErrNotFound; a stale ExpectedVersion
+// returns ErrVersionConflict and nothing is written.
+func (r *SettingsRepository) UpdateMany(ctx context.Context, updates []*domain.SettingUpdate, changedBy int64) ([]*domain.Setting, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var changedByPtr *int64
+	if changedBy > 0 {
+		changedByPtr = &changedBy
+	}
+
+	updated := make([]*domain.Setting, 0, len(updates))
+	for _, u := range updates {
+		var current domain.Setting
+		err := tx.GetContext(ctx, &current, `SELECT * FROM settings WHERE key = $1 FOR UPDATE`, u.Key)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("setting %s: %w", u.Key, domain.ErrNotFound)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if u.ExpectedVersion > 0 && u.ExpectedVersion != current.Version {
+			return nil, fmt.Errorf("setting %s: %w", u.Key, domain.ErrVersionConflict)
+		}
+
+		var setting domain.Setting
+		err = tx.GetContext(ctx, &setting, `
+			UPDATE settings
+			SET value = $1, version = version + 1, updated_by = $2
+			WHERE key = $3
+			RETURNING *
+		`, u.Value, changedByPtr, u.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO settings_history (setting_key, old_value, new_value, version, changed_by)
+			VALUES ($1, $2, $3, $4, $5)
+		`, u.Key, current.Value, setting.Value, setting.Version, changedByPtr)
+		if err != nil {
+			return nil, err
+		}
+
+		updated = append(updated, &setting)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *SettingsRepository) GetHistory(ctx context.Context, key string, limit, offset int) ([]*domain.SettingChange, int, error) {
+	var changes []*domain.SettingChange
+
+	query := `SELECT * FROM settings_history WHERE 1=1`
+	countQuery := `SELECT COUNT(*) FROM settings_history WHERE 1=1`
+	args := []interface{}{}
+	argCount := 1
+
+	if key != "" {
+		query += fmt.Sprintf(" AND setting_key = $%d", argCount)
+		countQuery += fmt.Sprintf(" AND setting_key = $%d", argCount)
+		args = append(args, key)
+		argCount++
+	}
+
+	query += " ORDER BY changed_at DESC, id DESC"
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	if err := r.db.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	return changes, total, nil
+}
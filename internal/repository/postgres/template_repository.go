@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -26,12 +27,14 @@ func (r *TemplateRepository) Create(ctx context.Context, template *domain.Templa
 			price_usd_cents, sale_price_usd_cents,
 			file_url, file_size_mb, file_format, preview_url,
 			status,
-			is_featured, is_bestseller, is_new,
+			is_featured, is_bestseller, is_new, watermark_enabled,
+			license_tier, license_seats,
 			meta_title, meta_description, meta_keywords,
-			current_version, published_at
+			current_version, published_at, announce_on_publish
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22, $23, $24
 		) RETURNING id, created_at, updated_at
 	`
 
@@ -41,9 +44,10 @@ func (r *TemplateRepository) Create(ctx context.Context, template *domain.Templa
 		template.PriceUSDCents, template.SalePriceUSDCents,
 		template.FileURL, template.FileSizeMB, template.FileFormat, template.PreviewURL,
 		template.Status,
-		template.IsFeatured, template.IsBestseller, template.IsNew,
+		template.IsFeatured, template.IsBestseller, template.IsNew, template.WatermarkEnabled,
+		template.LicenseTier, template.LicenseSeats,
 		template.MetaTitle, template.MetaDescription, pq.Array(template.MetaKeywords),
-		template.CurrentVersion, template.PublishedAt,
+		template.CurrentVersion, template.PublishedAt, template.AnnounceOnPublish,
 	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
 }
 
@@ -88,12 +92,23 @@ func (r *TemplateRepository) GetAll(ctx context.Context, filters map[string]inte
 		argPos++
 	}
 
+	if categoryIDs, ok := filters["category_ids"].([]int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("category_id = ANY($%d)", argPos))
+		args = append(args, pq.Array(categoryIDs))
+		argPos++
+	}
+
 	if status, ok := filters["status"].(domain.TemplateStatus); ok {
 		whereClauses = append(whereClauses, fmt.Sprintf("status = $%d", argPos))
 		args = append(args, status)
 		argPos++
 	}
 
+	// Public listings: scheduled, draft and archived templates stay hidden
+	if available, ok := filters["available"].(bool); ok && available {
+		whereClauses = append(whereClauses, "status = 'active'")
+	}
+
 	if featured, ok := filters["featured"].(bool); ok && featured {
 		whereClauses = append(whereClauses, fmt.Sprintf("is_featured = $%d", argPos))
 		args = append(args, true)
@@ -124,6 +139,8 @@ func (r *TemplateRepository) GetAll(ctx context.Context, filters map[string]inte
 			orderBy = "price_usd_cents DESC"
 		case "popular":
 			orderBy = "downloads_count DESC"
+		case "rating":
+			orderBy = "rating_average DESC, rating_count DESC"
 		case "newest":
 			orderBy = "created_at DESC"
 		}
@@ -175,11 +192,12 @@ func (r *TemplateRepository) Update(ctx context.Context, template *domain.Templa
 			price_usd_cents = $6, sale_price_usd_cents = $7,
 			file_url = $8, file_size_mb = $9, file_format = $10, preview_url = $11,
 			status = $12,
-			is_featured = $13, is_bestseller = $14, is_new = $15,
-			meta_title = $16, meta_description = $17, meta_keywords = $18,
-			current_version = $19, published_at = $20,
+			is_featured = $13, is_bestseller = $14, is_new = $15, watermark_enabled = $16,
+			license_tier = $17, license_seats = $18,
+			meta_title = $19, meta_description = $20, meta_keywords = $21,
+			current_version = $22, published_at = $23, announce_on_publish = $24,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $21
+		WHERE id = $25
 	`
 
 	_, err := r.db.ExecContext(
@@ -188,9 +206,10 @@ func (r *TemplateRepository) Update(ctx context.Context, template *domain.Templa
 		template.PriceUSDCents, template.SalePriceUSDCents,
 		template.FileURL, template.FileSizeMB, template.FileFormat, template.PreviewURL,
 		template.Status,
-		template.IsFeatured, template.IsBestseller, template.IsNew,
+		template.IsFeatured, template.IsBestseller, template.IsNew, template.WatermarkEnabled,
+		template.LicenseTier, template.LicenseSeats,
 		template.MetaTitle, template.MetaDescription, pq.Array(template.MetaKeywords),
-		template.CurrentVersion, template.PublishedAt,
+		template.CurrentVersion, template.PublishedAt, template.AnnounceOnPublish,
 		template.ID,
 	)
 	return err
@@ -227,6 +246,19 @@ func (r *TemplateRepository) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
+// PublishScheduled makes every scheduled template whose publish time has
+// passed active, and returns them. Each template is returned once: a second
+// call finds nothing left to publish.
+func (r *TemplateRepository) PublishScheduled(ctx context.Context, now time.Time) ([]*domain.Template, error) {
+	var templates []*domain.Template
+	err := r.db.SelectContext(ctx, &templates, `
+		UPDATE templates SET status = 'active', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'scheduled' AND published_at <= $1
+		RETURNING *
+	`, now)
+	return templates, err
+}
+
 func (r *TemplateRepository) Search(ctx context.Context, query string, limit int) ([]*domain.Template, error) {
 	var templates []*domain.Template
 	searchQuery := `
@@ -246,22 +278,23 @@ func (r *TemplateRepository) Search(ctx context.Context, query string, limit int
 	return templates, err
 }
 
-func (r *TemplateRepository) GetByCategory(ctx context.Context, categoryID int64, limit, offset int) ([]*domain.Template, int, error) {
+// GetByCategories returns active templates filed under any of categoryIDs
+func (r *TemplateRepository) GetByCategories(ctx context.Context, categoryIDs []int64, limit, offset int) ([]*domain.Template, int, error) {
 	var templates []*domain.Template
 	var total int
 
 	if err := r.db.GetContext(ctx, &total,
-		`SELECT COUNT(*) FROM templates WHERE category_id = $1 AND status = 'active'`, categoryID,
+		`SELECT COUNT(*) FROM templates WHERE category_id = ANY($1) AND status = 'active'`, pq.Array(categoryIDs),
 	); err != nil {
 		return nil, 0, err
 	}
 
 	err := r.db.SelectContext(ctx, &templates, `
 		SELECT * FROM templates
-		WHERE category_id = $1 AND status = 'active'
+		WHERE category_id = ANY($1) AND status = 'active'
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
-	`, categoryID, limit, offset)
+	`, pq.Array(categoryIDs), limit, offset)
 	return templates, total, err
 }
 
@@ -338,15 +371,29 @@ func (r *TemplateRepository) IncrementViews(ctx context.Context, id int64) error
 
 func (r *TemplateRepository) CreateImage(ctx context.Context, image *domain.TemplateImage) error {
 	query := `
-		INSERT INTO template_images (template_id, url, alt_text, display_order, is_primary)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO template_images
+			(template_id, url, alt_text, display_order, is_primary, is_preview, width, height, blurhash, renditions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at
 	`
 	return r.db.QueryRowContext(ctx, query,
-		image.TemplateID, image.URL, image.AltText, image.DisplayOrder, image.IsPrimary,
+		image.TemplateID, image.URL, image.AltText, image.DisplayOrder, image.IsPrimary, image.IsPreview,
+		image.Width, image.Height, image.BlurHash, image.Renditions,
 	).Scan(&image.ID, &image.CreatedAt)
 }
 
+func (r *TemplateRepository) FindImageByID(ctx context.Context, id int64) (*domain.TemplateImage, error) {
+	var image domain.TemplateImage
+	err := r.db.GetContext(ctx, &image, `SELECT * FROM template_images WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
 func (r *TemplateRepository) GetImages(ctx context.Context, templateID int64) ([]*domain.TemplateImage, error) {
 	var images []*domain.TemplateImage
 	err := r.db.SelectContext(ctx, &images,
@@ -439,13 +486,16 @@ func (r *TemplateRepository) ReplaceAllTags(ctx context.Context, templateID int6
 
 func (r *TemplateRepository) CreateVersion(ctx context.Context, version *domain.TemplateVersion) error {
 	query := `
-		INSERT INTO template_versions (template_id, version_number, file_url, file_size_mb, changelog, is_current, uploaded_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO template_versions (
+			template_id, version_number, file_url, file_size_mb, changelog, is_current, uploaded_by,
+			checksum_sha256, content_type, scan_status, scan_signature
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`
 	return r.db.QueryRowContext(ctx, query,
 		version.TemplateID, version.VersionNumber, version.FileURL, version.FileSizeMB,
 		version.Changelog, version.IsCurrent, version.UploadedBy,
+		version.ChecksumSHA256, version.ContentType, scanStatusOrDefault(version.ScanStatus), version.ScanSignature,
 	).Scan(&version.ID, &version.CreatedAt)
 }
 
@@ -489,4 +539,208 @@ func (r *TemplateRepository) SetCurrentVersion(ctx context.Context, templateID i
 	}
 
 	return tx.Commit()
-}
\ No newline at end of file
+}
+func (r *TemplateRepository) GetVersionByID(ctx context.Context, templateID, versionID int64) (*domain.TemplateVersion, error) {
+	var version domain.TemplateVersion
+	err := r.db.GetContext(ctx, &version,
+		`SELECT * FROM template_versions WHERE id = $1 AND template_id = $2`, versionID, templateID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &version, err
+}
+
+func (r *TemplateRepository) GetVersionByNumber(ctx context.Context, templateID int64, versionNumber string) (*domain.TemplateVersion, error) {
+	var version domain.TemplateVersion
+	err := r.db.GetContext(ctx, &version,
+		`SELECT * FROM template_versions WHERE template_id = $1 AND version_number = $2`, templateID, versionNumber,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &version, err
+}
+
+// SaveVersionFile replaces the file of an existing version, or records it
+// as a new one, without touching which version is current.
+func (r *TemplateRepository) SaveVersionFile(ctx context.Context, version *domain.TemplateVersion) error {
+	query := `
+		INSERT INTO template_versions (
+			template_id, version_number, file_url, file_size_mb, changelog, is_current, uploaded_by,
+			checksum_sha256, content_type, scan_status, scan_signature
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (template_id, version_number) DO UPDATE SET
+			file_url = EXCLUDED.file_url,
+			file_size_mb = EXCLUDED.file_size_mb,
+			changelog = COALESCE(EXCLUDED.changelog, template_versions.changelog),
+			uploaded_by = EXCLUDED.uploaded_by,
+			checksum_sha256 = EXCLUDED.checksum_sha256,
+			content_type = EXCLUDED.content_type,
+			scan_status = EXCLUDED.scan_status,
+			scan_signature = EXCLUDED.scan_signature
+		RETURNING id, is_current, created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		version.TemplateID, version.VersionNumber, version.FileURL, version.FileSizeMB,
+		version.Changelog, version.IsCurrent, version.UploadedBy,
+		version.ChecksumSHA256, version.ContentType, scanStatusOrDefault(version.ScanStatus), version.ScanSignature,
+	).Scan(&version.ID, &version.IsCurrent, &version.CreatedAt)
+}
+
+// ActivateVersion makes a version current and points the template's file,
+// size and version number at it in one transaction. Quarantined versions
+// are treated as missing.
+func (r *TemplateRepository) ActivateVersion(ctx context.Context, templateID, versionID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE templates t SET
+			file_url = v.file_url,
+			file_size_mb = v.file_size_mb,
+			current_version = v.version_number,
+			updated_at = CURRENT_TIMESTAMP
+		FROM template_versions v
+		WHERE v.id = $2 AND v.template_id = t.id AND t.id = $1
+		  AND v.scan_status <> 'quarantined'
+	`, templateID, versionID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		"UPDATE template_versions SET is_current = (id = $2) WHERE template_id = $1", templateID, versionID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanStatusOrDefault(status domain.ScanStatus) domain.ScanStatus {
+	if status == "" {
+		return domain.ScanStatusUnscanned
+	}
+	return status
+}
+
+// ============================================================================
+// Related templates
+// ============================================================================
+
+// RefreshRelated rebuilds template_relations, keeping the perTemplate best
+// matches of each active template. A co-purchase in an approved order weighs
+// most, then each shared tag, then a shared category.
+func (r *TemplateRepository) RefreshRelated(ctx context.Context, perTemplate int) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM template_relations"); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		WITH active AS (
+			SELECT id, category_id FROM templates WHERE status = 'active'
+		),
+		bought AS (
+			SELECT a.template_id, b.template_id AS related_id, COUNT(DISTINCT a.order_id) AS co_purchases
+			FROM order_items a
+			JOIN order_items b ON b.order_id = a.order_id AND b.template_id <> a.template_id
+			JOIN orders o ON o.id = a.order_id AND o.status = 'approved'
+			GROUP BY a.template_id, b.template_id
+		),
+		tagged AS (
+			SELECT a.template_id, b.template_id AS related_id, COUNT(*) AS shared_tags
+			FROM template_tags a
+			JOIN template_tags b ON b.tag = a.tag AND b.template_id <> a.template_id
+			GROUP BY a.template_id, b.template_id
+		),
+		categorised AS (
+			SELECT a.id AS template_id, b.id AS related_id
+			FROM active a
+			JOIN active b ON b.category_id = a.category_id AND b.id <> a.id
+		),
+		pairs AS (
+			SELECT template_id, related_id FROM bought
+			UNION SELECT template_id, related_id FROM tagged
+			UNION SELECT template_id, related_id FROM categorised
+		),
+		scored AS (
+			SELECT
+				p.template_id, p.related_id,
+				COALESCE(b.co_purchases, 0) AS co_purchases,
+				COALESCE(t.shared_tags, 0) AS shared_tags,
+				c.template_id IS NOT NULL AS same_category
+			FROM pairs p
+			JOIN active ta ON ta.id = p.template_id
+			JOIN active tr ON tr.id = p.related_id
+			LEFT JOIN bought b ON b.template_id = p.template_id AND b.related_id = p.related_id
+			LEFT JOIN tagged t ON t.template_id = p.template_id AND t.related_id = p.related_id
+			LEFT JOIN categorised c ON c.template_id = p.template_id AND c.related_id = p.related_id
+		),
+		ranked AS (
+			SELECT *,
+				co_purchases * 5.0 + shared_tags * 2.0 + CASE WHEN same_category THEN 1.0 ELSE 0 END AS score
+			FROM scored
+		)
+		INSERT INTO template_relations (template_id, related_template_id, score, co_purchases, shared_tags, same_category)
+		SELECT template_id, related_id, score, co_purchases, shared_tags, same_category
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY template_id ORDER BY score DESC, related_id DESC) AS rank
+			FROM ranked
+		) best
+		WHERE rank <= $1
+	`, perTemplate)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows, tx.Commit()
+}
+
+// GetRelated returns the precomputed matches of a template that are still
+// active, best first.
+func (r *TemplateRepository) GetRelated(ctx context.Context, templateID int64, limit int) ([]*domain.RelatedTemplate, error) {
+	var related []*domain.RelatedTemplate
+	err := r.db.SelectContext(ctx, &related, `
+		SELECT t.*, tr.score, tr.co_purchases, tr.shared_tags, tr.same_category
+		FROM template_relations tr
+		JOIN templates t ON t.id = tr.related_template_id
+		WHERE tr.template_id = $1 AND t.status = 'active'
+		ORDER BY tr.score DESC, t.id DESC
+		LIMIT $2
+	`, templateID, limit)
+	return related, err
+}
+
+// GetPopularRelated is the live fallback for templates without precomputed
+// matches: active templates in the same category first, then the rest, each
+// by downloads.
+func (r *TemplateRepository) GetPopularRelated(ctx context.Context, template *domain.Template, excludeIDs []int64, limit int) ([]*domain.RelatedTemplate, error) {
+	if excludeIDs == nil {
+		excludeIDs = []int64{} // a NULL array would exclude everything
+	}
+
+	var related []*domain.RelatedTemplate
+	err := r.db.SelectContext(ctx, &related, `
+		SELECT t.*, 0::float8 AS score, 0 AS co_purchases, 0 AS shared_tags,
+			COALESCE(t.category_id = $2, false) AS same_category
+		FROM templates t
+		WHERE t.status = 'active' AND t.id <> $1 AND NOT (t.id = ANY($3))
+		ORDER BY COALESCE(t.category_id = $2, false) DESC, t.downloads_count DESC, t.id DESC
+		LIMIT $4
+	`, template.ID, template.CategoryID, pq.Array(excludeIDs), limit)
+	return related, err
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type TemplateReviewRepository struct {
+	db *sqlx.DB
+}
+
+func NewTemplateReviewRepository(db *sqlx.DB) *TemplateReviewRepository {
+	return &TemplateReviewRepository{db: db}
+}
+
+// Upsert creates the buyer's review of a template, or replaces their earlier
+// one. A replaced review goes back to moderation.
+func (r *TemplateReviewRepository) Upsert(ctx context.Context, review *domain.TemplateReview) error {
+	query := `
+		INSERT INTO template_reviews (
+			template_id, order_id, order_item_id, customer_id, customer_email,
+			reviewer_name, rating, title, body, status
+		) VALUES ($1, $2, $3, $4, LOWER($5), $6, $7, $8, $9, $10)
+		ON CONFLICT (template_id, customer_email) DO UPDATE SET
+			order_id = EXCLUDED.order_id,
+			order_item_id = EXCLUDED.order_item_id,
+			customer_id = COALESCE(EXCLUDED.customer_id, template_reviews.customer_id),
+			reviewer_name = EXCLUDED.reviewer_name,
+			rating = EXCLUDED.rating,
+			title = EXCLUDED.title,
+			body = EXCLUDED.body,
+			status = EXCLUDED.status,
+			moderation_note = NULL,
+			moderated_by = NULL,
+			moderated_at = NULL
+		RETURNING id, customer_email, created_at, updated_at
+	`
+
+	review.ModerationNote = nil
+	review.ModeratedBy = nil
+	review.ModeratedAt = nil
+
+	return r.db.QueryRowContext(
+		ctx, query,
+		review.TemplateID, review.OrderID, review.OrderItemID, review.CustomerID, review.CustomerEmail,
+		review.ReviewerName, review.Rating, review.Title, review.Body, review.Status,
+	).Scan(&review.ID, &review.CustomerEmail, &review.CreatedAt, &review.UpdatedAt)
+}
+
+func (r *TemplateReviewRepository) FindByID(ctx context.Context, id int64) (*domain.TemplateReview, error) {
+	var review domain.TemplateReview
+	err := r.db.GetContext(ctx, &review, `SELECT * FROM template_reviews WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return &review, err
+}
+
+func (r *TemplateReviewRepository) GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.TemplateReview, int, error) {
+	var reviews []*domain.TemplateReview
+	var total int
+
+	whereClauses := []string{"1=1"}
+	args := []interface{}{}
+	argPos := 1
+
+	if status, ok := filters["status"].(domain.ReviewStatus); ok && status != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, status)
+		argPos++
+	}
+
+	if templateID, ok := filters["template_id"].(int64); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("template_id = $%d", argPos))
+		args = append(args, templateID)
+		argPos++
+	}
+
+	if rating, ok := filters["rating"].(int); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("rating = $%d", argPos))
+		args = append(args, rating)
+		argPos++
+	}
+
+	whereClause := strings.Join(whereClauses, " AND ")
+
+	if err := r.db.GetContext(ctx, &total,
+		fmt.Sprintf("SELECT COUNT(*) FROM template_reviews WHERE %s", whereClause), args...,
+	); err != nil {
+		return nil, 0, err
+	}
+
+	orderBy := "created_at DESC"
+	if sortBy, ok := filters["sort"].(string); ok {
+		switch sortBy {
+		case "oldest":
+			orderBy = "created_at ASC"
+		case "rating_desc":
+			orderBy = "rating DESC, created_at DESC"
+		case "rating_asc":
+			orderBy = "rating ASC, created_at DESC"
+		}
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT * FROM template_reviews
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, whereClause, orderBy, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &reviews, query, args...)
+	return reviews, total, err
+}
+
+func (r *TemplateReviewRepository) GetByEmail(ctx context.Context, email string) ([]*domain.TemplateReview, error) {
+	var reviews []*domain.TemplateReview
+	err := r.db.SelectContext(ctx, &reviews,
+		`SELECT * FROM template_reviews WHERE customer_email = LOWER($1) ORDER BY created_at DESC`, email,
+	)
+	return reviews, err
+}
+
+// Moderate records an admin's decision on a review.
+func (r *TemplateReviewRepository) Moderate(ctx context.Context, review *domain.TemplateReview) error {
+	query := `
+		UPDATE template_reviews
+		SET status = $1, moderation_note = $2, moderated_by = $3, moderated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING moderated_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		review.Status, review.ModerationNote, review.ModeratedBy, review.ID,
+	).Scan(&review.ModeratedAt, &review.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (r *TemplateReviewRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM template_reviews WHERE id = $1`, id)
+	return err
+}
+
+// ============================================================================
+// Ratings
+// ============================================================================
+
+func (r *TemplateReviewRepository) GetRatingSummary(ctx context.Context, templateID int64) (*domain.RatingSummary, error) {
+	var rows []struct {
+		Rating int `db:"rating"`
+		Count  int `db:"count"`
+	}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT rating, COUNT(*) AS count FROM template_reviews
+		WHERE template_id = $1 AND status = 'approved'
+		GROUP BY rating
+	`, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.RatingSummary{
+		TemplateID:   templateID,
+		Distribution: make(map[string]int, 5),
+	}
+	for star := 1; star <= 5; star++ {
+		summary.Distribution[strconv.Itoa(star)] = 0
+	}
+
+	sum := 0
+	for _, row := range rows {
+		summary.Distribution[strconv.Itoa(row.Rating)] = row.Count
+		summary.Count += row.Count
+		sum += row.Rating * row.Count
+	}
+	if summary.Count > 0 {
+		// Rounded like the templates.rating_average column
+		summary.Average = math.Round(float64(sum)/float64(summary.Count)*100) / 100
+	}
+	return summary, nil
+}
+
+// RefreshTemplateRating recomputes the denormalized rating of a template
+// from its approved reviews.
+func (r *TemplateReviewRepository) RefreshTemplateRating(ctx context.Context, templateID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE templates t SET
+			rating_average = COALESCE(s.average, 0),
+			rating_count = s.count
+		FROM (
+			SELECT ROUND(AVG(rating), 2) AS average, COUNT(*) AS count
+			FROM template_reviews
+			WHERE template_id = $1 AND status = 'approved'
+		) s
+		WHERE t.id = $1
+	`, templateID)
+	return err
+}
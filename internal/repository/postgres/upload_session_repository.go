@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type UploadSessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewUploadSessionRepository(db *sqlx.DB) *UploadSessionRepository {
+	return &UploadSessionRepository{db: db}
+}
+
+func (r *UploadSessionRepository) Create(ctx context.Context, session *domain.UploadSession) error {
+	query := `
+		INSERT INTO upload_sessions (
+			id, template_id, admin_id, filename, total_bytes,
+			version_number, changelog, notify_buyers, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING received_bytes, status, created_at, updated_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		session.ID, session.TemplateID, session.AdminID, session.Filename, session.TotalBytes,
+		session.VersionNumber, session.Changelog, session.NotifyBuyers, session.ExpiresAt,
+	).Scan(&session.ReceivedBytes, &session.Status, &session.CreatedAt, &session.UpdatedAt)
+}
+
+func (r *UploadSessionRepository) FindByID(ctx context.Context, id string) (*domain.UploadSession, error) {
+	var session domain.UploadSession
+	err := r.db.GetContext(ctx, &session,
+		`SELECT * FROM upload_sessions WHERE id = $1 AND expires_at > CURRENT_TIMESTAMP`, id,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *UploadSessionRepository) Lock(ctx context.Context, id string, status domain.UploadSessionStatus, offset int64, lockFor time.Duration) (*domain.UploadSession, error) {
+	var session domain.UploadSession
+	err := r.db.GetContext(ctx, &session, `
+		UPDATE upload_sessions
+		SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $4), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $2 AND received_bytes = $3
+		  AND expires_at > CURRENT_TIMESTAMP
+		  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+		RETURNING *
+	`, id, status, offset, lockFor.Seconds())
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrVersionConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *UploadSessionRepository) Advance(ctx context.Context, id string, receivedBytes int64, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE upload_sessions
+		SET received_bytes = $2, expires_at = $3, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, receivedBytes, expiresAt)
+	return err
+}
+
+func (r *UploadSessionRepository) Finish(ctx context.Context, id string, status domain.UploadSessionStatus, versionID *int64, failureReason *string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE upload_sessions
+		SET status = $2, version_id = $3, failure_reason = $4, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, status, versionID, failureReason)
+	return err
+}
+
+func (r *UploadSessionRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM upload_sessions
+		WHERE id = $1
+		  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+	`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *UploadSessionRepository) DeleteExpired(ctx context.Context) ([]string, error) {
+	ids := []string{}
+	err := r.db.SelectContext(ctx, &ids, `
+		DELETE FROM upload_sessions
+		WHERE expires_at <= CURRENT_TIMESTAMP
+		  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+		RETURNING id
+	`)
+	return ids, err
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type ReconciliationRepository interface {
+	CreateRun(ctx context.Context, run *domain.ReconciliationRun) error
+	CompleteRun(ctx context.Context, run *domain.ReconciliationRun) error
+	FindRunByID(ctx context.Context, id int64) (*domain.ReconciliationRun, error)
+	GetRuns(ctx context.Context, limit, offset int) ([]*domain.ReconciliationRun, int, error)
+	AddItems(ctx context.Context, items []*domain.ReconciliationItem) error
+	GetItems(ctx context.Context, runID int64) ([]*domain.ReconciliationItem, error)
+}
@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	orderLookupCodePrefix     = "order_lookup:code:"
+	orderLookupAttemptsPrefix = "order_lookup:attempts:"
+	orderLookupCooldownPrefix = "order_lookup:cooldown:"
+)
+
+// recordAttemptScript counts an attempt and reads the code in one step, so
+// parallel guesses each see their own attempt number. It only counts while
+// a code exists. Attempts are kept per email, apart from the code, so
+// sending a new code does not reset them; the count expires window after
+// the first attempt.
+var recordAttemptScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return false
+end
+local attempts = redis.call("INCR", KEYS[2])
+if redis.call("PTTL", KEYS[2]) < 0 then
+	redis.call("PEXPIRE", KEYS[2], ARGV[1])
+end
+return {redis.call("HGET", KEYS[1], "hash"), attempts}
+`)
+
+type OrderLookupStore struct {
+	client *Client
+}
+
+func NewOrderLookupStore(client *Client) *OrderLookupStore {
+	return &OrderLookupStore{client: client}
+}
+
+// SaveCode replaces any previous code for the email. Attempts already
+// counted against the email are kept.
+func (s *OrderLookupStore) SaveCode(ctx context.Context, email, codeHash string, ttl time.Duration) error {
+	key := orderLookupCodePrefix + email
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "hash", codeHash)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *OrderLookupStore) RecordAttempt(ctx context.Context, email string, window time.Duration) (*domain.OrderLookupCode, error) {
+	keys := []string{orderLookupCodePrefix + email, orderLookupAttemptsPrefix + email}
+	result, err := recordAttemptScript.Run(ctx, s.client, keys, window.Milliseconds()).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash, _ := result[0].(string)
+	attempts, _ := result[1].(int64)
+	return &domain.OrderLookupCode{
+		CodeHash: hash,
+		Attempts: int(attempts),
+	}, nil
+}
+
+func (s *OrderLookupStore) CountAttempts(ctx context.Context, email string) (int, error) {
+	attempts, err := s.client.Get(ctx, orderLookupAttemptsPrefix+email).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return attempts, err
+}
+
+func (s *OrderLookupStore) ClearAttempts(ctx context.Context, email string) error {
+	return s.client.Del(ctx, orderLookupAttemptsPrefix+email).Err()
+}
+
+func (s *OrderLookupStore) DeleteCode(ctx context.Context, email string) error {
+	return s.client.Del(ctx, orderLookupCodePrefix+email).Err()
+}
+
+func (s *OrderLookupStore) AcquireCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, orderLookupCooldownPrefix+key, 1, ttl).Result()
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLookupStore(t *testing.T) (*OrderLookupStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewOrderLookupStore(&Client{client}), server
+}
+
+func TestOrderLookupAttemptsSurviveResend(t *testing.T) {
+	store, server := newTestLookupStore(t)
+	ctx := context.Background()
+	email := "buyer@example.com"
+
+	if _, err := store.RecordAttempt(ctx, email, time.Hour); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("attempt without a code: err = %v, want ErrNotFound", err)
+	}
+	if n, _ := store.CountAttempts(ctx, email); n != 0 {
+		t.Fatalf("attempt without a code was counted: %d", n)
+	}
+
+	if err := store.SaveCode(ctx, email, "first", 10*time.Minute); err != nil {
+		t.Fatalf("SaveCode: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		code, err := store.RecordAttempt(ctx, email, time.Hour)
+		if err != nil {
+			t.Fatalf("RecordAttempt: %v", err)
+		}
+		if code.CodeHash != "first" || code.Attempts != i {
+			t.Fatalf("attempt %d: got %+v", i, code)
+		}
+	}
+
+	// A resent code replaces the hash but keeps the count
+	if err := store.SaveCode(ctx, email, "second", 10*time.Minute); err != nil {
+		t.Fatalf("SaveCode: %v", err)
+	}
+	code, err := store.RecordAttempt(ctx, email, time.Hour)
+	if err != nil {
+		t.Fatalf("RecordAttempt: %v", err)
+	}
+	if code.CodeHash != "second" || code.Attempts != 4 {
+		t.Fatalf("after resend: got %+v, want hash second and 4 attempts", code)
+	}
+
+	// The count outlives the code and lasts the window from the first attempt
+	if ttl := server.TTL(orderLookupAttemptsPrefix + email); ttl != time.Hour {
+		t.Errorf("attempts TTL = %v, want 1h", ttl)
+	}
+	server.FastForward(30 * time.Minute)
+	if n, _ := store.CountAttempts(ctx, email); n != 4 {
+		t.Errorf("after the code expired: %d attempts, want 4", n)
+	}
+	server.FastForward(31 * time.Minute)
+	if n, _ := store.CountAttempts(ctx, email); n != 0 {
+		t.Errorf("after the window: %d attempts, want 0", n)
+	}
+}
+
+func TestOrderLookupClearAttempts(t *testing.T) {
+	store, _ := newTestLookupStore(t)
+	ctx := context.Background()
+	email := "buyer@example.com"
+
+	if err := store.SaveCode(ctx, email, "hash", 10*time.Minute); err != nil {
+		t.Fatalf("SaveCode: %v", err)
+	}
+	if _, err := store.RecordAttempt(ctx, email, time.Hour); err != nil {
+		t.Fatalf("RecordAttempt: %v", err)
+	}
+
+	// Burning the code must not clear the count; only a success does
+	if err := store.DeleteCode(ctx, email); err != nil {
+		t.Fatalf("DeleteCode: %v", err)
+	}
+	if n, _ := store.CountAttempts(ctx, email); n != 1 {
+		t.Errorf("after DeleteCode: %d attempts, want 1", n)
+	}
+
+	if err := store.ClearAttempts(ctx, email); err != nil {
+		t.Fatalf("ClearAttempts: %v", err)
+	}
+	if n, _ := store.CountAttempts(ctx, email); n != 0 {
+		t.Errorf("after ClearAttempts: %d attempts, want 0", n)
+	}
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type SettingsRepository interface {
+	GetAll(ctx context.Context) ([]*domain.Setting, error)
+	FindByKey(ctx context.Context, key string) (*domain.Setting, error)
+	UpdateMany(ctx context.Context, updates []*domain.SettingUpdate, changedBy int64) ([]*domain.Setting, error)
+	GetHistory(ctx context.Context, key string, limit, offset int) ([]*domain.SettingChange, int, error)
+}
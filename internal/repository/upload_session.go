@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *domain.UploadSession) error
+	// FindByID returns domain.ErrNotFound for unknown and expired sessions.
+	FindByID(ctx context.Context, id string) (*domain.UploadSession, error)
+
+	// Lock claims the session for one request until lockFor elapses. It
+	// fails with domain.ErrVersionConflict when the session is not in
+	// status, not at offset received bytes, or already locked.
+	Lock(ctx context.Context, id string, status domain.UploadSessionStatus, offset int64, lockFor time.Duration) (*domain.UploadSession, error)
+	// Advance records received bytes, extends the expiry and unlocks.
+	Advance(ctx context.Context, id string, receivedBytes int64, expiresAt time.Time) error
+	// Finish sets the final status and unlocks.
+	Finish(ctx context.Context, id string, status domain.UploadSessionStatus, versionID *int64, failureReason *string) error
+	// Delete removes the session. It fails with domain.ErrVersionConflict
+	// while the session is locked.
+	Delete(ctx context.Context, id string) error
+
+	// DeleteExpired removes sessions past their expiry and returns their IDs.
+	DeleteExpired(ctx context.Context) ([]string, error)
+}
@@ -8,17 +8,28 @@ import (
 )
 
 type AdminHandlers struct {
-	Auth         *adminHandlers.AuthHandler
-	Dashboard    *adminHandlers.DashboardHandler
-	Order        *adminHandlers.OrderHandler
-	Template     *adminHandlers.TemplateHandler
-	Category     *adminHandlers.CategoryHandler
-	BlogPost     *adminHandlers.BlogPostHandler
-	BlogAuthor   *adminHandlers.BlogAuthorHandler
-	BlogCategory *adminHandlers.BlogCategoryHandler
-	Newsletter   *adminHandlers.NewsletterHandler
-	Contact      *adminHandlers.ContactHandler
-	AdminUser    *adminHandlers.AdminUserHandler
+	Auth            *adminHandlers.AuthHandler
+	Dashboard       *adminHandlers.DashboardHandler
+	Order           *adminHandlers.OrderHandler
+	Template        *adminHandlers.TemplateHandler
+	TemplateVersion *adminHandlers.TemplateVersionHandler
+	Upload          *adminHandlers.UploadHandler
+	Category        *adminHandlers.CategoryHandler
+	BlogPost        *adminHandlers.BlogPostHandler
+	BlogAuthor      *adminHandlers.BlogAuthorHandler
+	BlogCategory    *adminHandlers.BlogCategoryHandler
+	Newsletter      *adminHandlers.NewsletterHandler
+	Contact         *adminHandlers.ContactHandler
+	AdminUser       *adminHandlers.AdminUserHandler
+	Settings        *adminHandlers.SettingsHandler
+	ApprovalRule    *adminHandlers.ApprovalRuleHandler
+	Dispute         *adminHandlers.DisputeHandler
+	Reconciliation  *adminHandlers.ReconciliationHandler
+	Webhook         *adminHandlers.WebhookHandler
+	OutboundWebhook *adminHandlers.OutboundWebhookHandler
+	Review          *adminHandlers.ReviewHandler
+	Bundle          *adminHandlers.BundleHandler
+	PriceRule       *adminHandlers.PriceRuleHandler
 }
 
 func SetupAdminRoutes(api fiber.Router, h *AdminHandlers, cfg *config.Config) {
@@ -31,7 +42,16 @@ func SetupAdminRoutes(api fiber.Router, h *AdminHandlers, cfg *config.Config) {
 	setupDashboardRoutes(protected, h)
 	setupBlogRoutes(protected, h)
 	setupOrderRoutes(protected, h)
+	setupApprovalRuleRoutes(protected, h)
+	setupDisputeRoutes(protected, h)
+	setupReconciliationRoutes(protected, h)
+	setupWebhookRoutes(protected, h)
+	setupOutboundWebhookRoutes(protected, h)
 	setupTemplateRoutes(protected, h)
+	setupBundleRoutes(protected, h)
+	setupPriceRuleRoutes(protected, h)
+	setupReviewRoutes(protected, h)
+	setupUploadRoutes(protected, h)
 	setupCategoryRoutes(protected, h)
 	setupContactRoutes(protected, h)
 	setupAdminUserRoutes(protected, h)
@@ -88,6 +108,7 @@ func setupBlogRoutes(protected fiber.Router, h *AdminHandlers) {
 	posts.Post("/", h.BlogPost.Create)
 	posts.Put("/:id", h.BlogPost.Update)
 	posts.Patch("/:id", h.BlogPost.Patch)
+	posts.Post("/:id/featured-image", h.BlogPost.UploadFeaturedImage)
 	posts.Delete("/:id", h.BlogPost.Delete)
 
 	// Authors — static paths MUST come before /:id
@@ -102,6 +123,7 @@ func setupBlogRoutes(protected fiber.Router, h *AdminHandlers) {
 	// Categories — static paths MUST come before /:id
 	categories := blog.Group("/categories")
 	categories.Get("/", h.BlogCategory.GetAll)
+	categories.Get("/tree", h.BlogCategory.GetTree)
 	categories.Get("/slug/:slug", h.BlogCategory.GetBySlug) // FIX: moved before /:id
 	categories.Get("/:id", h.BlogCategory.GetByID)
 	categories.Post("/", h.BlogCategory.Create)
@@ -121,10 +143,73 @@ func setupOrderRoutes(protected fiber.Router, h *AdminHandlers) {
 	o.Post("/:id/approve", h.Order.ApproveOrder)
 	o.Post("/:id/reject", h.Order.RejectOrder)
 	o.Post("/:id/mark-paid", h.Order.MarkOrderAsPaid)
+	o.Post("/:id/risk/reassess", h.Order.ReassessRisk)
 
 	o.Delete("/:id", h.Order.DeleteOrder)
 }
 
+/* ================= APPROVAL RULES ================= */
+
+func setupApprovalRuleRoutes(protected fiber.Router, h *AdminHandlers) {
+	r := protected.Group("/approval-rules")
+
+	r.Get("/", h.ApprovalRule.GetAll)
+	r.Post("/dry-run/:orderId", h.ApprovalRule.DryRun) // static before /:id ✅
+	r.Get("/:id", h.ApprovalRule.GetByID)
+	r.Post("/", h.ApprovalRule.Create)
+	r.Put("/:id", h.ApprovalRule.Update)
+	r.Delete("/:id", h.ApprovalRule.Delete)
+}
+
+/* ================= DISPUTES ================= */
+
+func setupDisputeRoutes(protected fiber.Router, h *AdminHandlers) {
+	d := protected.Group("/disputes")
+
+	d.Get("/", h.Dispute.GetAll)
+	d.Get("/:id", h.Dispute.GetByID)
+	d.Post("/:id/evidence", h.Dispute.AddEvidence)
+}
+
+/* ================= RECONCILIATION ================= */
+
+func setupReconciliationRoutes(protected fiber.Router, h *AdminHandlers) {
+	r := protected.Group("/reconciliation")
+
+	r.Get("/", h.Reconciliation.GetRuns)
+	r.Post("/run", h.Reconciliation.Run) // static before /:id ✅
+	r.Get("/:id", h.Reconciliation.GetReport)
+	r.Get("/:id/export.csv", h.Reconciliation.ExportCSV)
+}
+
+/* ================= WEBHOOKS ================= */
+
+func setupWebhookRoutes(protected fiber.Router, h *AdminHandlers) {
+	w := protected.Group("/webhooks")
+
+	w.Get("/", h.Webhook.GetAll)
+	w.Get("/:id", h.Webhook.GetByID)
+	w.Post("/:id/replay", h.Webhook.Replay)
+}
+
+/* ================= OUTBOUND WEBHOOKS ================= */
+
+func setupOutboundWebhookRoutes(protected fiber.Router, h *AdminHandlers) {
+	o := protected.Group("/outbound-webhooks")
+
+	o.Get("/", h.OutboundWebhook.GetAll)
+	o.Post("/", h.OutboundWebhook.Create)
+
+	// Delivery log (static before /:id ✅)
+	o.Get("/deliveries", h.OutboundWebhook.GetDeliveries)
+	o.Get("/deliveries/:id", h.OutboundWebhook.GetDelivery)
+	o.Post("/deliveries/:id/redeliver", h.OutboundWebhook.Redeliver)
+
+	o.Get("/:id", h.OutboundWebhook.GetByID)
+	o.Put("/:id", h.OutboundWebhook.Update)
+	o.Delete("/:id", h.OutboundWebhook.Delete)
+}
+
 /* ================= TEMPLATES ================= */
 
 func setupTemplateRoutes(protected fiber.Router, h *AdminHandlers) {
@@ -140,14 +225,76 @@ func setupTemplateRoutes(protected fiber.Router, h *AdminHandlers) {
 
 	t.Post("/:id/upload-file", h.Template.UploadTemplateFile)
 
+	t.Get("/:id/versions", h.TemplateVersion.List)
+	t.Post("/:id/versions", h.TemplateVersion.Upload)
+	t.Post("/:id/versions/:versionId/rollback", h.TemplateVersion.Rollback)
+
+	// Resumable uploads of new versions; chunks go to /uploads/:uploadId
+	t.Post("/:id/uploads", h.Upload.Create)
+
 	// Sub-resource routes — not affected by /:id conflict ✅
 	t.Post("/:id/images", h.Template.AddImage)
+	t.Post("/:id/images/upload", h.Template.UploadImage)
 	t.Delete("/images/:id", h.Template.DeleteImage)
+	t.Post("/:id/previews", h.Template.GeneratePreviews)
 
 	t.Post("/:id/features", h.Template.AddFeature)
 	t.Delete("/features/:id", h.Template.DeleteFeature)
 
 	t.Put("/:id/tags", h.Template.UpdateTags)
+
+	t.Get("/:id/price-history", h.PriceRule.GetPriceHistory)
+}
+
+/* ================= BUNDLES ================= */
+
+func setupBundleRoutes(protected fiber.Router, h *AdminHandlers) {
+	b := protected.Group("/bundles")
+
+	b.Get("/", h.Bundle.GetAll)
+	b.Get("/:id", h.Bundle.GetByID)
+	b.Post("/", h.Bundle.Create)
+	b.Put("/:id", h.Bundle.Update)
+	b.Delete("/:id", h.Bundle.Delete)
+
+	b.Post("/:id/images", h.Bundle.AddImage)
+	b.Post("/:id/images/upload", h.Bundle.UploadImage)
+	b.Delete("/images/:id", h.Bundle.DeleteImage)
+}
+
+/* ================= PRICE RULES ================= */
+
+func setupPriceRuleRoutes(protected fiber.Router, h *AdminHandlers) {
+	p := protected.Group("/price-rules")
+
+	p.Get("/", h.PriceRule.GetAll)
+	p.Get("/:id", h.PriceRule.GetByID)
+	p.Post("/", h.PriceRule.Create)
+	p.Put("/:id", h.PriceRule.Update)
+	p.Post("/:id/cancel", h.PriceRule.Cancel)
+}
+
+/* ================= REVIEWS ================= */
+
+func setupReviewRoutes(protected fiber.Router, h *AdminHandlers) {
+	r := protected.Group("/reviews")
+
+	r.Get("/", h.Review.GetAll)
+	r.Get("/:id", h.Review.GetByID)
+	r.Put("/:id/moderate", h.Review.Moderate)
+	r.Delete("/:id", h.Review.Delete)
+}
+
+/* ================= UPLOADS ================= */
+
+func setupUploadRoutes(protected fiber.Router, h *AdminHandlers) {
+	u := protected.Group("/uploads")
+
+	u.Head("/:uploadId", h.Upload.Head)
+	u.Get("/:uploadId", h.Upload.Get)
+	u.Patch("/:uploadId", h.Upload.WriteChunk)
+	u.Post("/:uploadId/finalize", h.Upload.Finalize)
+	u.Delete("/:uploadId", h.Upload.Abort)
 }
 
 /* ================= CATEGORIES ================= */
@@ -156,9 +303,12 @@ func setupCategoryRoutes(protected fiber.Router, h *AdminHandlers) {
 	c := protected.Group("/categories")
 
 	c.Get("/", h.Category.GetAllCategories)
+	c.Get("/tree", h.Category.GetCategoryTree)      // static before /:id ✅
+	c.Put("/reorder", h.Category.ReorderCategories) // static before /:id ✅
 	c.Get("/:id", h.Category.GetCategoryByID)
 	c.Post("/", h.Category.CreateCategory)
 	c.Put("/:id", h.Category.UpdateCategory)
+	c.Put("/:id/move", h.Category.MoveCategory)
 	c.Delete("/:id", h.Category.DeleteCategory)
 }
 
@@ -193,6 +343,10 @@ func setupGlobalRoutes(protected fiber.Router, h *AdminHandlers) {
 	protected.Get("/search", h.Dashboard.GlobalSearch)
 
 	settings := protected.Group("/settings")
-	settings.Get("/", h.Dashboard.GetSettings)
-	settings.Put("/", h.Dashboard.UpdateSettings)
+	settings.Get("/", h.Settings.GetAll)
+	settings.Put("/", h.Settings.Update)
+	settings.Get("/history", h.Settings.GetHistory)
+	settings.Get("/:key", h.Settings.Get)
+	settings.Put("/:key", h.Settings.UpdateOne)
+	settings.Get("/:key/history", h.Settings.GetHistory)
 }
\ No newline at end of file
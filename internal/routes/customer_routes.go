@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/config"
+	publicHandlers "github.com/merraki/merraki-backend/internal/handler/public"
+	"github.com/merraki/merraki-backend/internal/middleware"
+)
+
+// ============================================================================
+// SETUP CUSTOMER ROUTES
+// ============================================================================
+
+func SetupCustomerRoutes(api fiber.Router, h *publicHandlers.CustomerHandler, reviews *publicHandlers.ReviewHandler, cfg *config.Config) {
+	// ========================================================================
+	// LOGIN - Magic links (public, tightly rate limited)
+	// ========================================================================
+	login := api.Group("/public/customer", middleware.RateLimit(5, 15*time.Minute))
+	{
+		login.Post("/login", h.RequestLogin)
+		login.Post("/verify", h.VerifyLogin)
+	}
+
+	// ========================================================================
+	// MY ACCOUNT - Requires a customer access token
+	// ========================================================================
+	customer := api.Group("/customer", middleware.CustomerAuth(cfg))
+	{
+		customer.Get("/me", h.GetMe)
+		customer.Get("/orders", h.GetOrders)
+		customer.Get("/orders/:id/invoice", h.GetInvoice)
+		customer.Put("/orders/:id/notifications", h.SetUpdateNotifications)
+		customer.Get("/downloads", h.GetDownloads)
+		customer.Get("/reviews", reviews.GetMine)
+		customer.Post("/reviews", middleware.RateLimit(10, time.Hour), reviews.Submit)
+		customer.Post("/logout", h.Logout)
+	}
+}
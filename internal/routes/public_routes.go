@@ -1,8 +1,12 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/merraki/merraki-backend/internal/config"
 	publicHandlers "github.com/merraki/merraki-backend/internal/handler/public"
+	"github.com/merraki/merraki-backend/internal/middleware"
 )
 
 // ============================================================================
@@ -18,15 +22,24 @@ type PublicHandlers struct {
 	Newsletter *publicHandlers.NewsletterHandler
 	Contact    *publicHandlers.ContactHandler
 	Utility    *publicHandlers.UtilityHandler
+	Customer   *publicHandlers.CustomerHandler
+	License    *publicHandlers.LicenseHandler
+	File       *publicHandlers.FileHandler
+	Review     *publicHandlers.ReviewHandler
+	Bundle     *publicHandlers.BundleHandler
 }
 
 // ============================================================================
 // SETUP PUBLIC ROUTES
 // ============================================================================
 
-func SetupPublicRoutes(api fiber.Router, handlers *PublicHandlers) {
+func SetupPublicRoutes(api fiber.Router, handlers *PublicHandlers, cfg *config.Config) {
 	public := api.Group("/public")
 
+	// Guest order data needs a session from a verified lookup code
+	lookupAuth := middleware.OrderLookupAuth(cfg)
+	lookupCodeLimit := middleware.RateLimit(5, 15*time.Minute)
+
 	// ========================================================================
 	// TEMPLATES - Product catalog
 	// ========================================================================
@@ -41,6 +54,18 @@ func SetupPublicRoutes(api fiber.Router, handlers *PublicHandlers) {
 		templates.Get("/by-id/:id", handlers.Template.GetTemplateByID)
 		// parameterized last
 		templates.Get("/:slug", handlers.Template.GetTemplateBySlug)
+		templates.Get("/:slug/reviews", handlers.Review.GetTemplateReviews)
+		templates.Get("/:slug/reviews/summary", handlers.Review.GetRatingSummary)
+		templates.Get("/:slug/related", handlers.Template.GetRelatedTemplates)
+	}
+
+	// ========================================================================
+	// BUNDLES - Packs of templates sold together
+	// ========================================================================
+	bundles := public.Group("/bundles")
+	{
+		bundles.Get("/", handlers.Bundle.GetAllBundles)
+		bundles.Get("/:slug", handlers.Bundle.GetBundleBySlug)
 	}
 
 	// ========================================================================
@@ -49,7 +74,9 @@ func SetupPublicRoutes(api fiber.Router, handlers *PublicHandlers) {
 	categories := public.Group("/categories")
 	{
 		categories.Get("/", handlers.Template.GetCategories)
+		categories.Get("/tree", handlers.Template.GetCategoryTree) // static before /:slug
 		categories.Get("/:slug", handlers.Template.GetCategoryBySlug)
+		categories.Get("/:slug/breadcrumbs", handlers.Template.GetCategoryBreadcrumbs)
 		categories.Get("/:slug/templates", handlers.Template.GetTemplatesByCategory)
 	}
 
@@ -85,10 +112,12 @@ func SetupPublicRoutes(api fiber.Router, handlers *PublicHandlers) {
 	orders := public.Group("/orders")
 	{
 		// FIX: static paths before /:id
-		orders.Get("/lookup", handlers.Order.LookupOrder)
-		orders.Get("/by-email", handlers.Order.GetOrdersByEmail)
+		orders.Post("/lookup/request-code", lookupCodeLimit, handlers.Order.RequestLookupCode)
+		orders.Post("/lookup/verify", lookupCodeLimit, handlers.Order.VerifyLookupCode)
+		orders.Get("/lookup", lookupAuth, handlers.Order.LookupOrder)
+		orders.Get("/by-email", lookupAuth, handlers.Order.GetOrdersByEmail)
 		// parameterized last
-		orders.Get("/:id", handlers.Order.GetOrderByID)
+		orders.Get("/:id", lookupAuth, handlers.Order.GetOrderByID)
 	}
 
 	// ========================================================================
@@ -97,11 +126,27 @@ func SetupPublicRoutes(api fiber.Router, handlers *PublicHandlers) {
 	download := public.Group("/download")
 	{
 		// FIX: static paths before parameterized (by-email before any future /:id)
-		download.Get("/by-email", handlers.Download.GetDownloadsByEmail)
+		download.Get("/by-email", lookupAuth, handlers.Download.GetDownloadsByEmail)
+		download.Post("/resend", lookupAuth, handlers.Download.ResendDownloadLinks)
+		download.Get("/bundle", handlers.Download.DownloadBundle)
 		download.Post("/info", handlers.Download.GetDownloadInfo)
 		download.Get("/", handlers.Download.InitiateDownload)
 	}
 
+	// ========================================================================
+	// FILES - Signed links of the local storage backend
+	// ========================================================================
+	public.Get("/files/*", handlers.File.ServeFile)
+
+	// ========================================================================
+	// LICENSES
+	// ========================================================================
+	licenses := public.Group("/licenses")
+	{
+		licenses.Post("/verify", middleware.RateLimit(30, time.Minute), handlers.License.Verify)
+		licenses.Get("/public-key", handlers.License.GetPublicKey)
+	}
+
 	// ========================================================================
 	// BLOG
 	// ========================================================================
@@ -113,7 +158,9 @@ func SetupPublicRoutes(api fiber.Router, handlers *PublicHandlers) {
 		blog.Get("/authors", handlers.Blog.GetAllAuthors)
 		blog.Get("/authors/:slug", handlers.Blog.GetAuthorBySlug)
 		blog.Get("/categories", handlers.Blog.GetAllCategories)
+		blog.Get("/categories/tree", handlers.Blog.GetCategoryTree) // static before /:slug
 		blog.Get("/categories/:slug", handlers.Blog.GetCategoryBySlug)
+		blog.Get("/categories/:slug/breadcrumbs", handlers.Blog.GetCategoryBreadcrumbs)
 	}
 
 	// ========================================================================
@@ -77,7 +77,10 @@ func SetupRoutes(
 	api := app.Group("/api/v1")
 
 	// Setup public routes
-	SetupPublicRoutes(api, publicHandlers)
+	SetupPublicRoutes(api, publicHandlers, cfg)
+
+	// Setup customer account routes
+	SetupCustomerRoutes(api, publicHandlers.Customer, publicHandlers.Review, cfg)
 
 	// Setup admin routes
 	SetupAdminRoutes(api, adminHandlers, cfg)
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// APPROVAL RULE SERVICE - Auto-approval of paid orders
+// ============================================================================
+
+type ApprovalRuleService struct {
+	ruleRepo        repository.ApprovalRuleRepository
+	orderRepo       repository.OrderRepository
+	settingsService *SettingsService
+	activityLogRepo repository.ActivityLogRepository
+}
+
+func NewApprovalRuleService(
+	ruleRepo repository.ApprovalRuleRepository,
+	orderRepo repository.OrderRepository,
+	settingsService *SettingsService,
+	activityLogRepo repository.ActivityLogRepository,
+) *ApprovalRuleService {
+	return &ApprovalRuleService{
+		ruleRepo:        ruleRepo,
+		orderRepo:       orderRepo,
+		settingsService: settingsService,
+		activityLogRepo: activityLogRepo,
+	}
+}
+
+// ============================================================================
+// RULE CRUD
+// ============================================================================
+
+func (s *ApprovalRuleService) CreateRule(ctx context.Context, rule *domain.ApprovalRule, createdBy int64) error {
+	if err := validateApprovalRule(rule); err != nil {
+		return err
+	}
+
+	rule.CreatedBy = &createdBy
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "create_approval_rule", rule.ID, createdBy, map[string]interface{}{
+		"name":      rule.Name,
+		"rule_type": rule.RuleType,
+		"params":    rule.Params,
+	})
+
+	logger.Info("Approval rule created",
+		zap.Int64("id", rule.ID),
+		zap.String("rule_type", string(rule.RuleType)),
+	)
+
+	return nil
+}
+
+func (s *ApprovalRuleService) GetRule(ctx context.Context, id int64) (*domain.ApprovalRule, error) {
+	return s.ruleRepo.FindByID(ctx, id)
+}
+
+func (s *ApprovalRuleService) GetAllRules(ctx context.Context, activeOnly bool) ([]*domain.ApprovalRule, error) {
+	return s.ruleRepo.GetAll(ctx, activeOnly)
+}
+
+func (s *ApprovalRuleService) UpdateRule(ctx context.Context, rule *domain.ApprovalRule, updatedBy int64) error {
+	if _, err := s.ruleRepo.FindByID(ctx, rule.ID); err != nil {
+		return err
+	}
+
+	if err := validateApprovalRule(rule); err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "update_approval_rule", rule.ID, updatedBy, map[string]interface{}{
+		"name":      rule.Name,
+		"is_active": rule.IsActive,
+		"params":    rule.Params,
+	})
+
+	return nil
+}
+
+func (s *ApprovalRuleService) DeleteRule(ctx context.Context, id int64, deletedBy int64) error {
+	rule, err := s.ruleRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "delete_approval_rule", id, deletedBy, map[string]interface{}{
+		"name": rule.Name,
+	})
+
+	return nil
+}
+
+// ============================================================================
+// EVALUATION
+// ============================================================================
+
+// Evaluate runs every active rule against the order. The order is approved
+// only when auto-approval is enabled in settings and no rule fails; with no
+// active rules every paid order is approved.
+func (s *ApprovalRuleService) Evaluate(ctx context.Context, order *domain.Order) (*domain.ApprovalEvaluation, error) {
+	rules, err := s.ruleRepo.GetAll(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation := &domain.ApprovalEvaluation{
+		OrderID: order.ID,
+		Enabled: s.settingsService.GetBool(ctx, domain.SettingOrderAutoApprove, false),
+		Results: make([]*domain.ApprovalRuleResult, 0, len(rules)),
+		Reasons: []string{},
+	}
+
+	if !evaluation.Enabled {
+		evaluation.Reasons = append(evaluation.Reasons, "Auto-approval is disabled")
+	}
+
+	allPassed := true
+	for _, rule := range rules {
+		result := s.evaluateRule(ctx, rule, order)
+		evaluation.Results = append(evaluation.Results, result)
+		if !result.Passed {
+			allPassed = false
+			evaluation.Reasons = append(evaluation.Reasons, fmt.Sprintf("%s: %s", rule.Name, result.Reason))
+		}
+	}
+
+	evaluation.Approved = evaluation.Enabled && allPassed
+	return evaluation, nil
+}
+
+// DryRun evaluates the rules against an existing order without changing it.
+func (s *ApprovalRuleService) DryRun(ctx context.Context, orderID int64) (*domain.ApprovalEvaluation, error) {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	return s.Evaluate(ctx, order)
+}
+
+func (s *ApprovalRuleService) evaluateRule(ctx context.Context, rule *domain.ApprovalRule, order *domain.Order) *domain.ApprovalRuleResult {
+	result := &domain.ApprovalRuleResult{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		RuleType: rule.RuleType,
+	}
+
+	switch rule.RuleType {
+	case domain.ApprovalRuleMaxTotal:
+		maxCents, _ := paramFloat(rule.Params, "max_total_usd_cents")
+		result.Passed = float64(order.TotalAmountUSDCents) <= maxCents
+		result.Reason = fmt.Sprintf("order total $%.2f, limit $%.2f",
+			domain.CentsToUSD(order.TotalAmountUSDCents), maxCents/100)
+
+	case domain.ApprovalRuleReturningCustomer:
+		minOrders := 1
+		if v, ok := paramFloat(rule.Params, "min_approved_orders"); ok && v > 0 {
+			minOrders = int(v)
+		}
+		count, err := s.orderRepo.CountApprovedByEmail(ctx, order.CustomerEmail, order.ID)
+		if err != nil {
+			result.Reason = "could not load customer history"
+			return result
+		}
+		result.Passed = count >= minOrders
+		result.Reason = fmt.Sprintf("%d previously approved order(s), %d required", count, minOrders)
+
+	case domain.ApprovalRuleCountryAllowlist:
+		country := strings.ToUpper(strings.TrimSpace(order.BillingCountry))
+		for _, allowed := range paramStrings(rule.Params, "countries") {
+			if strings.EqualFold(allowed, country) {
+				result.Passed = true
+				break
+			}
+		}
+		if country == "" {
+			country = "unknown"
+		}
+		if result.Passed {
+			result.Reason = fmt.Sprintf("billing country %s is allowed", country)
+		} else {
+			result.Reason = fmt.Sprintf("billing country %s is not in the allow-list", country)
+		}
+
+	case domain.ApprovalRuleNoRiskFlags:
+		if order.RiskScore == nil {
+			result.Reason = "order has not been risk scored"
+			return result
+		}
+		score := *order.RiskScore
+		if maxScore, ok := paramFloat(rule.Params, "max_score"); ok {
+			result.Passed = float64(score) <= maxScore
+			result.Reason = fmt.Sprintf("risk score %d, limit %d", score, int(maxScore))
+		} else {
+			result.Passed = len(order.RiskReasons) == 0
+			if result.Passed {
+				result.Reason = "no risk flags"
+			} else {
+				signals := make([]string, len(order.RiskReasons))
+				for i, reason := range order.RiskReasons {
+					signals[i] = string(reason.Signal)
+				}
+				result.Reason = fmt.Sprintf("risk flags raised: %s", strings.Join(signals, ", "))
+			}
+		}
+
+	default:
+		result.Reason = fmt.Sprintf("unknown rule type %q", rule.RuleType)
+	}
+
+	return result
+}
+
+// ============================================================================
+// VALIDATION
+// ============================================================================
+
+func validateApprovalRule(rule *domain.ApprovalRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	if rule.Params == nil {
+		rule.Params = make(domain.JSONMap)
+	}
+
+	switch rule.RuleType {
+	case domain.ApprovalRuleMaxTotal:
+		if v, ok := paramFloat(rule.Params, "max_total_usd_cents"); !ok || v <= 0 {
+			return fmt.Errorf("%w: max_total_usd_cents must be a positive number", domain.ErrInvalidInput)
+		}
+	case domain.ApprovalRuleReturningCustomer:
+		if v, ok := paramFloat(rule.Params, "min_approved_orders"); ok && v < 1 {
+			return fmt.Errorf("%w: min_approved_orders must be at least 1", domain.ErrInvalidInput)
+		}
+	case domain.ApprovalRuleCountryAllowlist:
+		countries := paramStrings(rule.Params, "countries")
+		if len(countries) == 0 {
+			return fmt.Errorf("%w: countries must list at least one country code", domain.ErrInvalidInput)
+		}
+		normalized := make([]interface{}, len(countries))
+		for i, c := range countries {
+			normalized[i] = strings.ToUpper(strings.TrimSpace(c))
+		}
+		rule.Params["countries"] = normalized
+	case domain.ApprovalRuleNoRiskFlags:
+		if v, ok := paramFloat(rule.Params, "max_score"); ok && (v < 0 || v > domain.MaxRiskScore) {
+			return fmt.Errorf("%w: max_score must be between 0 and %d", domain.ErrInvalidInput, domain.MaxRiskScore)
+		}
+	default:
+		return fmt.Errorf("%w: unknown rule type %q", domain.ErrInvalidInput, rule.RuleType)
+	}
+
+	return nil
+}
+
+func paramFloat(params domain.JSONMap, key string) (float64, bool) {
+	switch v := params[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func paramStrings(params domain.JSONMap, key string) []string {
+	var out []string
+	switch v := params[key].(type) {
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok && str != "" {
+				out = append(out, str)
+			}
+		}
+	case []string:
+		out = v
+	}
+	return out
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *ApprovalRuleService) logActivity(ctx context.Context, action string, entityID int64, adminID int64, metadata map[string]interface{}) {
+	if s.activityLogRepo == nil {
+		return
+	}
+
+	var adminIDPtr *int64
+	if adminID > 0 {
+		adminIDPtr = &adminID
+	}
+
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		Action:     action,
+		EntityType: strPtr("approval_rule"),
+		EntityID:   &entityID,
+		AdminID:    adminIDPtr,
+		Details:    domain.JSONMap(metadata),
+	})
+}
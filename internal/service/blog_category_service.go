@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/gosimple/slug"
 	"github.com/merraki/merraki-backend/internal/domain"
@@ -41,6 +42,10 @@ func (s *BlogCategoryService) CreateCategory(ctx context.Context, category *doma
 		return apperrors.New("SLUG_EXISTS", "Category with this slug already exists", 409)
 	}
 
+	if err := s.checkParent(ctx, 0, category.ParentID); err != nil {
+		return err
+	}
+
 	if err := s.categoryRepo.Create(ctx, category); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to create category", 500)
 	}
@@ -108,7 +113,19 @@ func (s *BlogCategoryService) UpdateCategory(ctx context.Context, category *doma
 		}
 	}
 
+	if err := s.checkParent(ctx, category.ID, category.ParentID); err != nil {
+		return err
+	}
+
 	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			return apperrors.Wrap(err, "INVALID_PARENT", "Invalid parent category", 400)
+		case errors.Is(err, domain.ErrVersionConflict):
+			return apperrors.Wrap(err, "CONFLICT", "Category tree changed, please retry", 409)
+		case errors.Is(err, domain.ErrNotFound):
+			return apperrors.ErrNotFound
+		}
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to update category", 500)
 	}
 
@@ -146,4 +163,105 @@ func (s *BlogCategoryService) DeleteCategory(ctx context.Context, id, deletedBy
 	})
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ============================================================================
+// TREE
+// ============================================================================
+
+// GetCategoryTree returns categories nested under their parents, siblings in
+// display order, with published post counts for each node. With activeOnly,
+// an inactive category hides its whole subtree.
+func (s *BlogCategoryService) GetCategoryTree(ctx context.Context, activeOnly bool) ([]*domain.BlogCategoryNode, error) {
+	categories, err := s.categoryRepo.ListAll(ctx, activeOnly)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to list categories", 500)
+	}
+
+	counts, err := s.categoryRepo.GetPostCounts(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to count posts", 500)
+	}
+
+	return buildBlogCategoryTree(categories, counts), nil
+}
+
+// GetBreadcrumbs returns the path from the root category down to slug
+func (s *BlogCategoryService) GetBreadcrumbs(ctx context.Context, slug string) ([]*domain.BlogCategory, error) {
+	category, err := s.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := s.categoryRepo.GetAncestors(ctx, category.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to load breadcrumbs", 500)
+	}
+	return ancestors, nil
+}
+
+// checkParent rejects a parent that is missing, or that would put category
+// id inside its own subtree. id is 0 for a category not yet created. The
+// repository repeats the check on locked rows when it writes parent_id.
+func (s *BlogCategoryService) checkParent(ctx context.Context, id int64, parentID *int64) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == id {
+		return apperrors.New("INVALID_PARENT", "A category cannot be its own parent", 400)
+	}
+
+	ancestors, err := s.categoryRepo.GetAncestors(ctx, *parentID)
+	if err != nil {
+		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to check parent category", 500)
+	}
+	if len(ancestors) == 0 {
+		return apperrors.New("INVALID_PARENT", "Parent category not found", 400)
+	}
+
+	for _, ancestor := range ancestors {
+		if id != 0 && ancestor.ID == id {
+			return apperrors.New("INVALID_PARENT", "A category cannot be moved under its own subcategory", 400)
+		}
+	}
+	return nil
+}
+
+// buildBlogCategoryTree nests categories, which arrive in display order,
+// under their parents. A category whose parent is not in the list is dropped
+// along with its subtree, as in buildCategoryTree.
+func buildBlogCategoryTree(categories []*domain.BlogCategory, counts map[int64]int) []*domain.BlogCategoryNode {
+	nodes := make(map[int64]*domain.BlogCategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &domain.BlogCategoryNode{
+			BlogCategory: category,
+			PostCount:    counts[category.ID],
+			Children:     []*domain.BlogCategoryNode{},
+		}
+	}
+
+	roots := []*domain.BlogCategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := nodes[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	for _, root := range roots {
+		sumPostCounts(root)
+	}
+	return roots
+}
+
+func sumPostCounts(node *domain.BlogCategoryNode) int {
+	node.TotalPostCount = node.PostCount
+	for _, child := range node.Children {
+		node.TotalPostCount += sumPostCounts(child)
+	}
+	return node.TotalPostCount
+}
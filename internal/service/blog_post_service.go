@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"mime/multipart"
 	"strings"
 	"time"
 
@@ -10,7 +12,10 @@ import (
 	"github.com/lib/pq"
 	"github.com/merraki/merraki-backend/internal/domain"
 	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
 	"github.com/merraki/merraki-backend/internal/repository/postgres"
+	"go.uber.org/zap"
 )
 
 type BlogPostService struct {
@@ -18,6 +23,14 @@ type BlogPostService struct {
 	authorRepo   *postgres.BlogAuthorRepository
 	categoryRepo *postgres.BlogCategoryRepository
 	logRepo      *postgres.ActivityLogRepository
+	imageService *ImageService
+	jobRepo      repository.BackgroundJobRepository
+}
+
+// featuredImageColumns describe an uploaded featured image and are only set
+// by UploadFeaturedImage.
+var featuredImageColumns = []string{
+	"featured_image_width", "featured_image_height", "featured_image_blurhash", "featured_image_renditions",
 }
 
 func NewBlogPostService(
@@ -25,12 +38,16 @@ func NewBlogPostService(
 	authorRepo *postgres.BlogAuthorRepository,
 	categoryRepo *postgres.BlogCategoryRepository,
 	logRepo *postgres.ActivityLogRepository,
+	imageService *ImageService,
+	jobRepo repository.BackgroundJobRepository,
 ) *BlogPostService {
 	return &BlogPostService{
 		postRepo:     postRepo,
 		authorRepo:   authorRepo,
 		categoryRepo: categoryRepo,
 		logRepo:      logRepo,
+		imageService: imageService,
+		jobRepo:      jobRepo,
 	}
 }
 
@@ -85,6 +102,12 @@ func (s *BlogPostService) CreatePost(ctx context.Context, post *domain.BlogPost,
 		post.PublishedAt = &now
 	}
 
+	if post.Status == "scheduled" {
+		if err := validatePublishAt(post.PublishedAt); err != nil {
+			return err
+		}
+	}
+
 	// Ensure arrays are initialized
 	if post.Tags == nil {
 		post.Tags = pq.StringArray{}
@@ -96,6 +119,7 @@ func (s *BlogPostService) CreatePost(ctx context.Context, post *domain.BlogPost,
 	if err := s.postRepo.Create(ctx, post); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to create post", 500)
 	}
+	s.schedulePublishing(ctx, post.ID, post.Status, post.PublishedAt)
 
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &createdBy,
@@ -218,9 +242,34 @@ func (s *BlogPostService) UpdatePost(ctx context.Context, post *domain.BlogPost,
 		post.PublishedAt = &now
 	}
 
+	// A scheduled post keeps its publish time unless a new one is given
+	if post.Status == "scheduled" {
+		if post.PublishedAt == nil && existing.Status == "scheduled" {
+			post.PublishedAt = existing.PublishedAt
+		}
+		if existing.Status != "scheduled" || !equalTimePtr(existing.PublishedAt, post.PublishedAt) {
+			if err := validatePublishAt(post.PublishedAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Renditions belong to the uploaded image; a new URL leaves them stale
+	imageReplaced := derefStr(post.FeaturedImageURL) != derefStr(existing.FeaturedImageURL)
+	if !imageReplaced {
+		post.FeaturedImageWidth = existing.FeaturedImageWidth
+		post.FeaturedImageHeight = existing.FeaturedImageHeight
+		post.FeaturedImageBlurHash = existing.FeaturedImageBlurHash
+		post.FeaturedImageRenditions = existing.FeaturedImageRenditions
+	}
+
 	if err := s.postRepo.Update(ctx, post); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to update post", 500)
 	}
+	s.schedulePublishing(ctx, post.ID, post.Status, post.PublishedAt)
+	if imageReplaced {
+		s.imageService.DeleteRenditions(ctx, existing.FeaturedImageRenditions)
+	}
 
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &updatedBy,
@@ -247,16 +296,48 @@ func (s *BlogPostService) PatchPost(ctx context.Context, id int64, updates map[s
 		return apperrors.ErrNotFound
 	}
 
+	status := patchedStatus(updates, existing.Status)
+	publishAt, err := patchedPublishAt(updates, existing.PublishedAt)
+	if err != nil {
+		return err
+	}
+
 	// Handle status change to published
-	if status, ok := updates["status"].(string); ok && status == "published" && existing.Status != "published" {
+	if status == "published" && existing.Status != "published" {
 		if _, hasPublishedAt := updates["published_at"]; !hasPublishedAt {
-			updates["published_at"] = time.Now()
+			now := time.Now()
+			updates["published_at"] = now
+			publishAt = &now
+		}
+	}
+
+	if status == "scheduled" && (existing.Status != "scheduled" || !equalTimePtr(existing.PublishedAt, publishAt)) {
+		if err := validatePublishAt(publishAt); err != nil {
+			return err
+		}
+	}
+
+	for _, column := range featuredImageColumns {
+		delete(updates, column)
+	}
+	imageReplaced := false
+	if url, ok := updates["featured_image_url"]; ok {
+		newURL, _ := url.(string)
+		if imageReplaced = newURL != derefStr(existing.FeaturedImageURL); imageReplaced {
+			updates["featured_image_width"] = nil
+			updates["featured_image_height"] = nil
+			updates["featured_image_blurhash"] = nil
+			updates["featured_image_renditions"] = domain.ImageRenditions{}
 		}
 	}
 
 	if err := s.postRepo.Patch(ctx, id, updates); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to patch post", 500)
 	}
+	s.schedulePublishing(ctx, id, status, publishAt)
+	if imageReplaced {
+		s.imageService.DeleteRenditions(ctx, existing.FeaturedImageRenditions)
+	}
 
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &updatedBy,
@@ -269,6 +350,21 @@ func (s *BlogPostService) PatchPost(ctx context.Context, id int64, updates map[s
 	return nil
 }
 
+// schedulePublishing queues the job that will publish a scheduled post;
+// if that fails the hourly run still picks it up
+func (s *BlogPostService) schedulePublishing(ctx context.Context, id int64, status string, publishAt *time.Time) {
+	if status != "scheduled" || publishAt == nil || s.jobRepo == nil {
+		return
+	}
+
+	if err := enqueuePublishing(ctx, s.jobRepo, *publishAt); err != nil {
+		logger.Error("Failed to schedule blog post publishing",
+			zap.Int64("post_id", id),
+			zap.Error(err),
+		)
+	}
+}
+
 func (s *BlogPostService) DeletePost(ctx context.Context, id, deletedBy int64) error {
 	post, err := s.postRepo.FindByID(ctx, id)
 	if err != nil {
@@ -285,6 +381,7 @@ func (s *BlogPostService) DeletePost(ctx context.Context, id, deletedBy int64) e
 	if err := s.postRepo.Delete(ctx, id); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to delete post", 500)
 	}
+	s.imageService.DeleteRenditions(ctx, post.FeaturedImageRenditions)
 
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &deletedBy,
@@ -297,6 +394,51 @@ func (s *BlogPostService) DeletePost(ctx context.Context, id, deletedBy int64) e
 	return nil
 }
 
+// UploadFeaturedImage processes an uploaded image into renditions and makes
+// it the post's featured image, replacing any earlier one.
+func (s *BlogPostService) UploadFeaturedImage(ctx context.Context, id int64, file *multipart.FileHeader, updatedBy int64) (*domain.BlogPost, error) {
+	post, err := s.GetPostByID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := s.imageService.Process(ctx, file, "blog")
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return nil, apperrors.New("INVALID_IMAGE", strings.TrimPrefix(err.Error(), domain.ErrInvalidInput.Error()+": "), 400)
+		}
+		return nil, err
+	}
+
+	previous := post.FeaturedImageRenditions
+	url := processed.URL()
+	post.FeaturedImageURL = &url
+	post.FeaturedImageWidth = &processed.Width
+	post.FeaturedImageHeight = &processed.Height
+	post.FeaturedImageBlurHash = &processed.BlurHash
+	post.FeaturedImageRenditions = processed.Renditions
+
+	if err := s.postRepo.SetFeaturedImage(ctx, post); err != nil {
+		s.imageService.DeleteRenditions(ctx, processed.Renditions)
+		return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to update post", 500)
+	}
+	s.imageService.DeleteRenditions(ctx, previous)
+
+	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
+		AdminID:    &updatedBy,
+		Action:     "upload_blog_featured_image",
+		EntityType: strPtr("blog_post"),
+		EntityID:   &id,
+		Details: domain.JSONMap{
+			"width":      processed.Width,
+			"height":     processed.Height,
+			"renditions": len(processed.Renditions),
+		},
+	})
+
+	return post, nil
+}
+
 func (s *BlogPostService) SearchPosts(ctx context.Context, query string, limit int) ([]*domain.BlogPost, error) {
 	return s.postRepo.Search(ctx, query, limit)
 }
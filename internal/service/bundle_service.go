@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/repository"
+)
+
+// ============================================================================
+// BUNDLE SERVICE - Curated packs of templates sold at one price
+// ============================================================================
+//
+// A bundle only references its templates; files, versions and prices stay
+// on the templates themselves. Checkout expands a bundle into one order item
+// per template (see OrderService.CreateOrder).
+
+const (
+	minBundleTemplates = 2
+	maxBundleTemplates = 50
+)
+
+type BundleService struct {
+	bundleRepo      repository.BundleRepository
+	templateRepo    repository.TemplateRepository
+	activityLogRepo repository.ActivityLogRepository
+	imageService    *ImageService
+}
+
+func NewBundleService(
+	bundleRepo repository.BundleRepository,
+	templateRepo repository.TemplateRepository,
+	activityLogRepo repository.ActivityLogRepository,
+	imageService *ImageService,
+) *BundleService {
+	return &BundleService{
+		bundleRepo:      bundleRepo,
+		templateRepo:    templateRepo,
+		activityLogRepo: activityLogRepo,
+		imageService:    imageService,
+	}
+}
+
+type BundleRequest struct {
+	Name              string                `json:"name"`
+	Slug              string                `json:"slug"`
+	Tagline           *string               `json:"tagline"`
+	Description       string                `json:"description"`
+	PriceUSDCents     int64                 `json:"price_usd_cents"`
+	SalePriceUSDCents *int64                `json:"sale_price_usd_cents"`
+	Status            domain.TemplateStatus `json:"status"`
+	IsFeatured        bool                  `json:"is_featured"`
+	MetaTitle         *string               `json:"meta_title"`
+	MetaDescription   *string               `json:"meta_description"`
+	TemplateIDs       []int64               `json:"template_ids"`
+}
+
+// ============================================================================
+// PUBLIC
+// ============================================================================
+
+// ListPublic returns a page of active bundles whose templates can all be
+// bought
+func (s *BundleService) ListPublic(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.BundleWithTemplates, int, error) {
+	filters["status"] = domain.TemplateStatusActive
+	filters["purchasable"] = true
+
+	bundles, total, err := s.bundleRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*domain.BundleWithTemplates, len(bundles))
+	for i, bundle := range bundles {
+		if result[i], err = s.withTemplates(ctx, bundle); err != nil {
+			return nil, 0, err
+		}
+	}
+	return result, total, nil
+}
+
+func (s *BundleService) GetPublic(ctx context.Context, slug string) (*domain.BundleWithTemplates, error) {
+	bundle, err := s.bundleRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !bundle.IsAvailable() {
+		return nil, domain.ErrNotFound
+	}
+
+	result, err := s.withTemplates(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range result.Templates {
+		if !template.IsAvailable() {
+			return nil, domain.ErrNotFound
+		}
+	}
+	return result, nil
+}
+
+// ============================================================================
+// ADMIN
+// ============================================================================
+
+func (s *BundleService) GetAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.TemplateBundle, int, error) {
+	return s.bundleRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+}
+
+func (s *BundleService) Get(ctx context.Context, id int64) (*domain.BundleWithTemplates, error) {
+	bundle, err := s.bundleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withTemplates(ctx, bundle)
+}
+
+func (s *BundleService) Create(ctx context.Context, req BundleRequest, adminID int64) (*domain.BundleWithTemplates, error) {
+	bundle := &domain.TemplateBundle{}
+	if err := s.apply(ctx, bundle, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.bundleRepo.Create(ctx, bundle); err != nil {
+		return nil, err
+	}
+	if err := s.bundleRepo.ReplaceTemplates(ctx, bundle.ID, req.TemplateIDs); err != nil {
+		_ = s.bundleRepo.Delete(ctx, bundle.ID)
+		return nil, err
+	}
+
+	s.logActivity(ctx, "create_bundle", bundle.ID, adminID, domain.JSONMap{
+		"name":         bundle.Name,
+		"template_ids": req.TemplateIDs,
+	})
+
+	return s.withTemplates(ctx, bundle)
+}
+
+// Update replaces the bundle's details and contents. Orders already placed
+// keep the templates and prices they were sold with.
+func (s *BundleService) Update(ctx context.Context, id int64, req BundleRequest, adminID int64) (*domain.BundleWithTemplates, error) {
+	bundle, err := s.bundleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, bundle, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.bundleRepo.Update(ctx, bundle); err != nil {
+		return nil, err
+	}
+	if err := s.bundleRepo.ReplaceTemplates(ctx, bundle.ID, req.TemplateIDs); err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, "update_bundle", bundle.ID, adminID, domain.JSONMap{
+		"name":         bundle.Name,
+		"status":       bundle.Status,
+		"template_ids": req.TemplateIDs,
+	})
+
+	return s.withTemplates(ctx, bundle)
+}
+
+func (s *BundleService) Delete(ctx context.Context, id, adminID int64) error {
+	bundle, err := s.bundleRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	images, err := s.bundleRepo.GetImages(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.bundleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	for _, image := range images {
+		s.imageService.DeleteRenditions(ctx, image.Renditions)
+	}
+
+	s.logActivity(ctx, "delete_bundle", id, adminID, domain.JSONMap{
+		"name": bundle.Name,
+	})
+	return nil
+}
+
+// ============================================================================
+// ADMIN - Images
+// ============================================================================
+
+func (s *BundleService) AddImage(ctx context.Context, image *domain.BundleImage, adminID int64) error {
+	if _, err := s.bundleRepo.FindByID(ctx, image.BundleID); err != nil {
+		return err
+	}
+	if err := s.bundleRepo.CreateImage(ctx, image); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "add_bundle_image", image.BundleID, adminID, domain.JSONMap{
+		"image_id": image.ID,
+	})
+	return nil
+}
+
+// UploadImage processes an uploaded gallery image into renditions and adds
+// it to the bundle, as TemplateService.UploadImage does for templates
+func (s *BundleService) UploadImage(ctx context.Context, bundleID int64, file *multipart.FileHeader, image *domain.BundleImage, adminID int64) error {
+	if _, err := s.bundleRepo.FindByID(ctx, bundleID); err != nil {
+		return err
+	}
+
+	processed, err := s.imageService.Process(ctx, file, "images")
+	if err != nil {
+		return err
+	}
+
+	image.BundleID = bundleID
+	image.URL = processed.URL()
+	image.Width = &processed.Width
+	image.Height = &processed.Height
+	image.BlurHash = &processed.BlurHash
+	image.Renditions = processed.Renditions
+
+	if err := s.bundleRepo.CreateImage(ctx, image); err != nil {
+		s.imageService.DeleteRenditions(ctx, processed.Renditions)
+		return err
+	}
+
+	s.logActivity(ctx, "add_bundle_image", bundleID, adminID, domain.JSONMap{
+		"image_id":   image.ID,
+		"width":      processed.Width,
+		"height":     processed.Height,
+		"renditions": len(processed.Renditions),
+	})
+	return nil
+}
+
+func (s *BundleService) DeleteImage(ctx context.Context, id, adminID int64) error {
+	image, err := s.bundleRepo.FindImageByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.bundleRepo.DeleteImage(ctx, id); err != nil {
+		return err
+	}
+	s.imageService.DeleteRenditions(ctx, image.Renditions)
+
+	s.logActivity(ctx, "delete_bundle_image", image.BundleID, adminID, domain.JSONMap{
+		"image_id": id,
+	})
+	return nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// apply validates req and copies it onto bundle
+func (s *BundleService) apply(ctx context.Context, bundle *domain.TemplateBundle, req BundleRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	if req.PriceUSDCents <= 0 {
+		return fmt.Errorf("%w: price_usd_cents must be positive", domain.ErrInvalidInput)
+	}
+	if req.SalePriceUSDCents != nil && (*req.SalePriceUSDCents < 0 || *req.SalePriceUSDCents >= req.PriceUSDCents) {
+		return fmt.Errorf("%w: sale_price_usd_cents must be below price_usd_cents", domain.ErrInvalidInput)
+	}
+
+	if req.Status == "" {
+		req.Status = domain.TemplateStatusDraft
+	}
+	switch req.Status {
+	case domain.TemplateStatusDraft, domain.TemplateStatusActive, domain.TemplateStatusArchived:
+	default:
+		return fmt.Errorf("%w: status must be draft, active or archived", domain.ErrInvalidInput)
+	}
+
+	req.Slug = strings.TrimSpace(req.Slug)
+	if req.Slug == "" {
+		req.Slug = slug.Make(req.Name)
+	}
+	existing, err := s.bundleRepo.FindBySlug(ctx, req.Slug)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if existing != nil && existing.ID != bundle.ID {
+		return fmt.Errorf("%w: a bundle with slug %q already exists", domain.ErrDuplicateEntry, req.Slug)
+	}
+
+	if err := s.checkTemplates(ctx, req.TemplateIDs, req.Status); err != nil {
+		return err
+	}
+
+	if req.Status == domain.TemplateStatusActive && bundle.PublishedAt == nil {
+		now := time.Now()
+		bundle.PublishedAt = &now
+	}
+
+	bundle.Name = req.Name
+	bundle.Slug = req.Slug
+	bundle.Tagline = req.Tagline
+	bundle.Description = req.Description
+	bundle.PriceUSDCents = req.PriceUSDCents
+	bundle.SalePriceUSDCents = req.SalePriceUSDCents
+	bundle.Status = req.Status
+	bundle.IsFeatured = req.IsFeatured
+	bundle.MetaTitle = req.MetaTitle
+	bundle.MetaDescription = req.MetaDescription
+	return nil
+}
+
+// checkTemplates requires distinct, existing templates, all of them active
+// when the bundle is
+func (s *BundleService) checkTemplates(ctx context.Context, templateIDs []int64, status domain.TemplateStatus) error {
+	if len(templateIDs) < minBundleTemplates || len(templateIDs) > maxBundleTemplates {
+		return fmt.Errorf("%w: a bundle needs between %d and %d templates", domain.ErrInvalidInput, minBundleTemplates, maxBundleTemplates)
+	}
+
+	seen := make(map[int64]bool, len(templateIDs))
+	for _, id := range templateIDs {
+		if seen[id] {
+			return fmt.Errorf("%w: template %d is listed twice", domain.ErrInvalidInput, id)
+		}
+		seen[id] = true
+
+		template, err := s.templateRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if template == nil {
+			return fmt.Errorf("%w: template %d not found", domain.ErrInvalidInput, id)
+		}
+		if status == domain.TemplateStatusActive && !template.IsAvailable() {
+			return fmt.Errorf("%w: template %d is not active", domain.ErrInvalidInput, id)
+		}
+	}
+	return nil
+}
+
+func (s *BundleService) withTemplates(ctx context.Context, bundle *domain.TemplateBundle) (*domain.BundleWithTemplates, error) {
+	templates, err := s.bundleRepo.GetTemplates(ctx, bundle.ID)
+	if err != nil {
+		return nil, err
+	}
+	images, err := s.bundleRepo.GetImages(ctx, bundle.ID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewBundleWithTemplates(bundle, templates, images), nil
+}
+
+func (s *BundleService) logActivity(ctx context.Context, action string, bundleID, adminID int64, details domain.JSONMap) {
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		AdminID:    &adminID,
+		Action:     action,
+		EntityType: strPtr("template_bundle"),
+		EntityID:   &bundleID,
+		Details:    details,
+	})
+}
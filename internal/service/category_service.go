@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/gosimple/slug"
 	"github.com/merraki/merraki-backend/internal/domain"
@@ -40,6 +42,10 @@ func (s *CategoryService) CreateCategory(ctx context.Context, category *domain.C
 		return domain.ErrDuplicateEntry
 	}
 
+	if err := s.checkParent(ctx, 0, category.ParentID); err != nil {
+		return err
+	}
+
 	// Create category
 	if err := s.categoryRepo.Create(ctx, category); err != nil {
 		return err
@@ -92,6 +98,10 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, category *domain.C
 		}
 	}
 
+	if err := s.checkParent(ctx, category.ID, category.ParentID); err != nil {
+		return err
+	}
+
 	// Update
 	if err := s.categoryRepo.Update(ctx, category); err != nil {
 		return err
@@ -138,6 +148,237 @@ func (s *CategoryService) DeleteCategory(ctx context.Context, id int64, deletedB
 	return nil
 }
 
+// ============================================================================
+// TREE
+// ============================================================================
+
+// GetCategoryTree returns categories nested under their parents, siblings in
+// display order, with template counts for each node. With activeOnly, an
+// inactive category hides its whole subtree and only active templates are
+// counted.
+func (s *CategoryService) GetCategoryTree(ctx context.Context, activeOnly bool) ([]*domain.CategoryNode, error) {
+	categories, err := s.categoryRepo.GetAll(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.categoryRepo.GetTemplateCounts(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCategoryTree(categories, counts), nil
+}
+
+// GetBreadcrumbs returns the path from the root category down to slug
+func (s *CategoryService) GetBreadcrumbs(ctx context.Context, slug string) ([]*domain.Category, error) {
+	category, err := s.categoryRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.categoryRepo.GetAncestors(ctx, category.ID)
+}
+
+// GetSubtreeIDs returns the category's ID and those of all its descendants
+func (s *CategoryService) GetSubtreeIDs(ctx context.Context, id int64, activeOnly bool) ([]int64, error) {
+	return s.categoryRepo.GetSubtreeIDs(ctx, id, activeOnly)
+}
+
+// MoveCategory files a category under parentID (nil for the top level) at
+// position among its new siblings. Positions past the end append.
+func (s *CategoryService) MoveCategory(ctx context.Context, id int64, parentID *int64, position int, movedBy int64) error {
+	category, err := s.categoryRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkParent(ctx, id, parentID); err != nil {
+		return err
+	}
+
+	siblings, err := s.children(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(siblings)+1)
+	for _, sibling := range siblings {
+		if sibling.ID != id {
+			ids = append(ids, sibling.ID)
+		}
+	}
+	if position < 0 {
+		position = 0
+	}
+	if position > len(ids) {
+		position = len(ids)
+	}
+	ids = append(ids[:position], append([]int64{id}, ids[position:]...)...)
+
+	if err := s.categoryRepo.Reorder(ctx, parentID, ids); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "move_category", id, movedBy, map[string]interface{}{
+		"name":          category.Name,
+		"old_parent_id": category.ParentID,
+		"parent_id":     parentID,
+		"position":      position,
+	})
+
+	logger.Info("Category moved",
+		zap.Int64("id", id),
+		zap.Int("position", position),
+	)
+
+	return nil
+}
+
+// ReorderCategories sets the order of parentID's children. Children missing
+// from ids keep their relative order after the listed ones.
+func (s *CategoryService) ReorderCategories(ctx context.Context, parentID *int64, ids []int64, reorderedBy int64) error {
+	children, err := s.children(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[int64]bool, len(ids))
+	isChild := make(map[int64]bool, len(children))
+	for _, child := range children {
+		isChild[child.ID] = true
+	}
+	for _, id := range ids {
+		if !isChild[id] {
+			return fmt.Errorf("%w: category %d is not a child of the given parent", domain.ErrInvalidInput, id)
+		}
+		if listed[id] {
+			return fmt.Errorf("%w: category %d is listed more than once", domain.ErrInvalidInput, id)
+		}
+		listed[id] = true
+	}
+
+	ordered := append([]int64{}, ids...)
+	for _, child := range children {
+		if !listed[child.ID] {
+			ordered = append(ordered, child.ID)
+		}
+	}
+
+	if err := s.categoryRepo.Reorder(ctx, parentID, ordered); err != nil {
+		return err
+	}
+
+	var entityID int64
+	if parentID != nil {
+		entityID = *parentID
+	}
+	s.logActivity(ctx, "reorder_categories", entityID, reorderedBy, map[string]interface{}{
+		"parent_id": parentID,
+		"order":     ordered,
+	})
+
+	return nil
+}
+
+// checkParent rejects a parent that is missing, or that would put category
+// id inside its own subtree. id is 0 for a category not yet created. It
+// only gives an early, readable error: the repository repeats the check on
+// locked rows in the transaction that writes parent_id, which is what stops
+// two concurrent moves from forming a loop.
+func (s *CategoryService) checkParent(ctx context.Context, id int64, parentID *int64) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == id {
+		return fmt.Errorf("%w: a category cannot be its own parent", domain.ErrInvalidInput)
+	}
+
+	if _, err := s.categoryRepo.FindByID(ctx, *parentID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: parent category not found", domain.ErrInvalidInput)
+		}
+		return err
+	}
+
+	if id == 0 {
+		return nil
+	}
+
+	subtree, err := s.categoryRepo.GetSubtreeIDs(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	for _, descendantID := range subtree {
+		if descendantID == *parentID {
+			return fmt.Errorf("%w: a category cannot be moved under its own subcategory", domain.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// children returns the categories directly under parentID in display order
+func (s *CategoryService) children(ctx context.Context, parentID *int64) ([]*domain.Category, error) {
+	categories, err := s.categoryRepo.GetAll(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*domain.Category
+	for _, category := range categories {
+		if sameParent(category.ParentID, parentID) {
+			children = append(children, category)
+		}
+	}
+	return children, nil
+}
+
+func sameParent(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// buildCategoryTree nests categories, which arrive in display order, under
+// their parents. A category whose parent is not in the list is dropped along
+// with its subtree, which is how inactive branches disappear from the
+// storefront tree; that also leaves out any categories caught in a loop.
+func buildCategoryTree(categories []*domain.Category, counts map[int64]int) []*domain.CategoryNode {
+	nodes := make(map[int64]*domain.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &domain.CategoryNode{
+			Category:      category,
+			TemplateCount: counts[category.ID],
+			Children:      []*domain.CategoryNode{},
+		}
+	}
+
+	roots := []*domain.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := nodes[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	for _, root := range roots {
+		sumTemplateCounts(root)
+	}
+	return roots
+}
+
+func sumTemplateCounts(node *domain.CategoryNode) int {
+	node.TotalTemplateCount = node.TemplateCount
+	for _, child := range node.Children {
+		node.TotalTemplateCount += sumTemplateCounts(child)
+	}
+	return node.TotalTemplateCount
+}
+
 func (s *CategoryService) logActivity(ctx context.Context, action string, entityID int64, adminID int64, metadata map[string]interface{}) {
 	if s.activityLogRepo == nil {
 		return
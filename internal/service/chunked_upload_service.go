@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/crypto"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// CHUNKED UPLOAD SERVICE - Resumable uploads of new template versions
+// ============================================================================
+
+// A chunk is written in well under chunkLockTTL; finalizing scans and
+// uploads the whole file, which can take much longer.
+const (
+	chunkLockTTL    = 2 * time.Minute
+	finalizeLockTTL = 30 * time.Minute
+)
+
+// errStagingIncomplete means the staged file is shorter than the session
+// says it should be, which happens when a chunk lands on a replica that
+// doesn't see the staging volume the earlier chunks were written to.
+var errStagingIncomplete = errors.New("staged upload is missing earlier chunks; UPLOAD_STAGING_DIR must be shared by every API replica")
+
+type ChunkedUploadService struct {
+	sessionRepo    repository.UploadSessionRepository
+	versionService *TemplateVersionService
+	storageService *StorageService
+	stagingDir     string
+	sessionTTL     time.Duration
+	maxChunkBytes  int64
+}
+
+func NewChunkedUploadService(
+	sessionRepo repository.UploadSessionRepository,
+	versionService *TemplateVersionService,
+	storageService *StorageService,
+	cfg *config.Config,
+) (*ChunkedUploadService, error) {
+	if cfg.IsProduction() && !cfg.Upload.StagingShared {
+		return nil, errors.New("UPLOAD_STAGING_DIR must be a volume shared by every API replica and the worker; " +
+			"mount one and set UPLOAD_STAGING_SHARED=true")
+	}
+
+	dir, err := filepath.Abs(cfg.Upload.StagingDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create upload staging dir: %w", err)
+	}
+	if err := checkStagingWritable(dir); err != nil {
+		return nil, fmt.Errorf("upload staging dir is not writable: %w", err)
+	}
+
+	return &ChunkedUploadService{
+		sessionRepo:    sessionRepo,
+		versionService: versionService,
+		storageService: storageService,
+		stagingDir:     dir,
+		sessionTTL:     cfg.Upload.SessionTTL,
+		maxChunkBytes:  int64(cfg.Upload.MaxChunkMB) * 1024 * 1024,
+	}, nil
+}
+
+type CreateUploadRequest struct {
+	Filename      string `json:"filename"`
+	Size          int64  `json:"size"`
+	VersionNumber string `json:"version_number"`
+	Changelog     string `json:"changelog"`
+	NotifyBuyers  bool   `json:"notify_buyers"`
+}
+
+// ============================================================================
+// SESSION LIFECYCLE
+// ============================================================================
+
+// Create opens a session for a new version of the template. Size, file type
+// and version number are checked up front so a doomed upload fails before
+// any bytes are sent.
+func (s *ChunkedUploadService) Create(ctx context.Context, templateID int64, req CreateUploadRequest, adminID int64) (*domain.UploadSession, error) {
+	filename := path.Base(strings.ReplaceAll(strings.TrimSpace(req.Filename), "\\", "/"))
+	if filename == "" || filename == "." || filename == "/" || len(filename) > 255 {
+		return nil, fmt.Errorf("%w: filename is required", domain.ErrInvalidInput)
+	}
+	versionNumber := strings.TrimSpace(req.VersionNumber)
+
+	if err := s.storageService.ValidateUpload("templates", filename, req.Size); err != nil {
+		return nil, err
+	}
+	if err := s.versionService.CheckNewVersion(ctx, templateID, versionNumber); err != nil {
+		return nil, err
+	}
+
+	id, err := crypto.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &domain.UploadSession{
+		ID:            id,
+		TemplateID:    templateID,
+		AdminID:       adminID,
+		Filename:      filename,
+		TotalBytes:    req.Size,
+		VersionNumber: versionNumber,
+		Changelog:     nullableStr(strings.TrimSpace(req.Changelog)),
+		NotifyBuyers:  req.NotifyBuyers,
+		ExpiresAt:     time.Now().Add(s.sessionTTL),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Get returns a session of adminID. Other admins' sessions are not found.
+func (s *ChunkedUploadService) Get(ctx context.Context, id string, adminID int64) (*domain.UploadSession, error) {
+	session, err := s.sessionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.AdminID != adminID {
+		return nil, domain.ErrNotFound
+	}
+	return session, nil
+}
+
+// WriteChunk appends chunk at offset, which must equal the bytes received
+// so far. A mismatch returns domain.ErrVersionConflict; the client should
+// read the session and resume from its ReceivedBytes.
+func (s *ChunkedUploadService) WriteChunk(ctx context.Context, id string, adminID int64, offset int64, chunk []byte) (*domain.UploadSession, error) {
+	if len(chunk) == 0 {
+		return nil, fmt.Errorf("%w: chunk is empty", domain.ErrInvalidInput)
+	}
+	if int64(len(chunk)) > s.maxChunkBytes {
+		return nil, fmt.Errorf("%w: chunks must be at most %d MB", domain.ErrInvalidInput, s.maxChunkBytes/(1024*1024))
+	}
+
+	session, err := s.Get(ctx, id, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.UploadSessionUploading {
+		return nil, fmt.Errorf("%w: upload is %s", domain.ErrInvalidStateTransition, session.Status)
+	}
+	if offset != session.ReceivedBytes {
+		return session, domain.ErrVersionConflict
+	}
+	if offset+int64(len(chunk)) > session.TotalBytes {
+		return nil, fmt.Errorf("%w: chunk runs past the declared size of %d bytes", domain.ErrInvalidInput, session.TotalBytes)
+	}
+
+	// Another request may have written the same offset in the meantime
+	session, err = s.sessionRepo.Lock(ctx, id, domain.UploadSessionUploading, offset, chunkLockTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	written, writeErr := s.writeAt(id, offset, chunk)
+	session.ReceivedBytes = offset + written
+	session.ExpiresAt = time.Now().Add(s.sessionTTL)
+	if err := s.sessionRepo.Advance(ctx, id, session.ReceivedBytes, session.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, fmt.Errorf("failed to write chunk: %w", writeErr)
+	}
+	return session, nil
+}
+
+// Finalize uploads the assembled file as a new template version. Rejected
+// or quarantined files end the session; storage errors leave it open so
+// finalizing can be retried.
+func (s *ChunkedUploadService) Finalize(ctx context.Context, id string, adminID int64) (*domain.TemplateVersion, error) {
+	session, err := s.Get(ctx, id, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.UploadSessionUploading {
+		return nil, fmt.Errorf("%w: upload is %s", domain.ErrInvalidStateTransition, session.Status)
+	}
+	if session.ReceivedBytes != session.TotalBytes {
+		return nil, fmt.Errorf("%w: upload is incomplete, %d of %d bytes received",
+			domain.ErrInvalidInput, session.ReceivedBytes, session.TotalBytes)
+	}
+
+	session, err = s.sessionRepo.Lock(ctx, id, domain.UploadSessionUploading, session.TotalBytes, finalizeLockTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkStaged(id, session.TotalBytes); err != nil {
+		s.unlock(ctx, session)
+		return nil, err
+	}
+
+	version, err := s.versionService.UploadStagedVersion(ctx, session.TemplateID, s.partPath(id), session.Filename,
+		UploadVersionRequest{
+			VersionNumber: session.VersionNumber,
+			Changelog:     derefStr(session.Changelog),
+			NotifyBuyers:  session.NotifyBuyers,
+		}, adminID)
+
+	switch {
+	case err == nil:
+		s.finish(ctx, session, domain.UploadSessionCompleted, &version.ID, "")
+		return version, nil
+
+	case errors.Is(err, domain.ErrQuarantined):
+		var versionID *int64
+		if version != nil {
+			versionID = &version.ID
+		}
+		s.finish(ctx, session, domain.UploadSessionFailed, versionID, err.Error())
+		return version, err
+
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrNotFound):
+		s.finish(ctx, session, domain.UploadSessionFailed, nil, err.Error())
+		return nil, err
+	}
+
+	// Unlock for a retry
+	s.unlock(ctx, session)
+	return nil, err
+}
+
+// Abort discards a session and whatever it has received. A session that is
+// locked by a chunk write or finalize can't be aborted until that is done.
+func (s *ChunkedUploadService) Abort(ctx context.Context, id string, adminID int64) error {
+	if _, err := s.Get(ctx, id, adminID); err != nil {
+		return err
+	}
+	err := s.sessionRepo.Delete(ctx, id)
+	if errors.Is(err, domain.ErrVersionConflict) {
+		return fmt.Errorf("%w: a chunk is being written or the upload is being finalized", domain.ErrVersionConflict)
+	}
+	if err != nil {
+		return err
+	}
+	s.removePart(id)
+	return nil
+}
+
+// ============================================================================
+// CLEANUP - Called from the worker
+// ============================================================================
+
+// CleanupExpired deletes abandoned and long-finished sessions with their
+// staged files, plus staged files no session refers to any more.
+func (s *ChunkedUploadService) CleanupExpired(ctx context.Context) (int, error) {
+	ids, err := s.sessionRepo.DeleteExpired(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.removePart(id)
+	}
+
+	// Sessions are extended on every chunk, so a part file this old has no
+	// live session
+	entries, err := os.ReadDir(s.stagingDir)
+	if err != nil {
+		return len(ids), err
+	}
+	cutoff := time.Now().Add(-s.sessionTTL - finalizeLockTTL)
+	orphans := 0
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || !strings.HasSuffix(entry.Name(), ".part") {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(filepath.Join(s.stagingDir, entry.Name())); err == nil {
+				orphans++
+			}
+		}
+	}
+
+	if len(ids) > 0 || orphans > 0 {
+		logger.Info("Expired upload sessions cleaned up",
+			zap.Int("sessions", len(ids)),
+			zap.Int("orphaned_files", orphans),
+		)
+	}
+	return len(ids), nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *ChunkedUploadService) partPath(id string) string {
+	return filepath.Join(s.stagingDir, id+".part")
+}
+
+// checkStagingWritable writes and removes a probe file, so a read-only or
+// missing mount fails at startup rather than on the first chunk.
+func checkStagingWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_, err = f.WriteString("ok")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// checkStaged verifies the staged file holds the size the session recorded.
+func (s *ChunkedUploadService) checkStaged(id string, size int64) error {
+	info, err := os.Stat(s.partPath(id))
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.Size() < size) {
+		return errStagingIncomplete
+	}
+	return err
+}
+
+// writeAt writes chunk at offset and returns how much of it was written.
+// Anything past offset is discarded first, left over from a write that
+// failed part way. A file shorter than offset is refused rather than
+// padded with zeros.
+func (s *ChunkedUploadService) writeAt(id string, offset int64, chunk []byte) (int64, error) {
+	f, err := os.OpenFile(s.partPath(id), os.O_WRONLY|os.O_CREATE, 0o640)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() < offset {
+		return 0, errStagingIncomplete
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		return 0, err
+	}
+	n, err := f.WriteAt(chunk, offset)
+	if err == nil {
+		err = f.Sync()
+	}
+	return int64(n), err
+}
+
+func (s *ChunkedUploadService) finish(ctx context.Context, session *domain.UploadSession, status domain.UploadSessionStatus, versionID *int64, reason string) {
+	s.removePart(session.ID)
+
+	session.Status = status
+	session.VersionID = versionID
+	session.FailureReason = nullableStr(reason)
+	if err := s.sessionRepo.Finish(ctx, session.ID, status, versionID, session.FailureReason); err != nil {
+		logger.Error("Failed to update upload session",
+			zap.String("upload_id", session.ID),
+			zap.String("status", string(status)),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *ChunkedUploadService) unlock(ctx context.Context, session *domain.UploadSession) {
+	if err := s.sessionRepo.Advance(ctx, session.ID, session.ReceivedBytes, session.ExpiresAt); err != nil {
+		logger.Error("Failed to unlock upload session", zap.String("upload_id", session.ID), zap.Error(err))
+	}
+}
+
+func (s *ChunkedUploadService) removePart(id string) {
+	if err := os.Remove(s.partPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("Failed to remove staged upload", zap.String("upload_id", id), zap.Error(err))
+	}
+}
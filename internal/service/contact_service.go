@@ -13,17 +13,20 @@ type ContactService struct {
 	contactRepo *postgres.ContactRepository
 	logRepo     *postgres.ActivityLogRepository
 	emailSvc    *EmailService
+	webhooks    *OutboundWebhookService
 }
 
 func NewContactService(
 	contactRepo *postgres.ContactRepository,
 	logRepo *postgres.ActivityLogRepository,
 	emailSvc *EmailService,
+	webhooks *OutboundWebhookService,
 ) *ContactService {
 	return &ContactService{
 		contactRepo: contactRepo,
 		logRepo:     logRepo,
 		emailSvc:    emailSvc,
+		webhooks:    webhooks,
 	}
 }
 
@@ -53,6 +56,14 @@ func (s *ContactService) CreateContact(ctx context.Context, req *CreateContactRe
 
 	// TODO: Send notification to admin
 
+	s.webhooks.Publish(ctx, domain.OutboundEventContactCreated, map[string]interface{}{
+		"contact_id": contact.ID,
+		"name":       contact.Name,
+		"email":      contact.Email,
+		"subject":    contact.Subject,
+		"message":    contact.Message,
+	})
+
 	return contact, nil
 }
 
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/crypto"
+	"github.com/merraki/merraki-backend/internal/pkg/jwt"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// CUSTOMER SERVICE - Passwordless accounts for buyers
+// ============================================================================
+
+type CustomerService struct {
+	customerRepo    repository.CustomerRepository
+	orderRepo       repository.OrderRepository
+	orderItemRepo   repository.OrderItemRepository
+	downloadService *DownloadTokenService
+	emailService    *EmailService
+	pdfService      *PDFService
+	pasetoMaker     *jwt.PasetoMaker
+	cfg             *config.Config
+}
+
+func NewCustomerService(
+	customerRepo repository.CustomerRepository,
+	orderRepo repository.OrderRepository,
+	orderItemRepo repository.OrderItemRepository,
+	downloadService *DownloadTokenService,
+	emailService *EmailService,
+	pdfService *PDFService,
+	cfg *config.Config,
+) (*CustomerService, error) {
+	pasetoMaker, err := jwt.NewPasetoMaker(cfg.Auth.PasetoKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomerService{
+		customerRepo:    customerRepo,
+		orderRepo:       orderRepo,
+		orderItemRepo:   orderItemRepo,
+		downloadService: downloadService,
+		emailService:    emailService,
+		pdfService:      pdfService,
+		pasetoMaker:     pasetoMaker,
+		cfg:             cfg,
+	}, nil
+}
+
+// CustomerSession is returned after a magic link is redeemed.
+type CustomerSession struct {
+	AccessToken   string           `json:"access_token"`
+	ExpiresIn     int              `json:"expires_in"`
+	Customer      *domain.Customer `json:"customer"`
+	ClaimedOrders int64            `json:"claimed_orders"`
+}
+
+// ============================================================================
+// MAGIC LINK LOGIN
+// ============================================================================
+
+// RequestMagicLink emails a single-use sign-in link. Every well-formed
+// address gets the same response so callers cannot probe which emails
+// have bought something.
+func (s *CustomerService) RequestMagicLink(ctx context.Context, email, ip string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || !strings.Contains(email, "@") {
+		return fmt.Errorf("%w: a valid email is required", domain.ErrInvalidInput)
+	}
+
+	customer, err := s.customerRepo.FindOrCreate(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to load customer: %w", err)
+	}
+
+	rawToken, err := crypto.GenerateRandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate login token: %w", err)
+	}
+
+	token := &domain.CustomerLoginToken{
+		CustomerID:  customer.ID,
+		TokenHash:   jwt.HashToken(rawToken),
+		ExpiresAt:   time.Now().Add(s.cfg.Auth.MagicLinkExpires),
+		RequestedIP: nullableStr(ip),
+	}
+	if err := s.customerRepo.CreateLoginToken(ctx, token); err != nil {
+		return fmt.Errorf("failed to store login token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/account/verify?token=%s", s.cfg.Frontend.URL, url.QueryEscape(rawToken))
+	if err := s.emailService.SendCustomerMagicLink(ctx, customer.Email, link, s.cfg.Auth.MagicLinkExpires); err != nil {
+		return err
+	}
+
+	logger.Info("Customer magic link sent", zap.Int64("customer_id", customer.ID))
+	return nil
+}
+
+// VerifyMagicLink redeems a link, marks the email as verified, attaches any
+// guest orders placed with it and issues a customer access token.
+func (s *CustomerService) VerifyMagicLink(ctx context.Context, rawToken, ip string) (*CustomerSession, error) {
+	if rawToken == "" {
+		return nil, fmt.Errorf("%w: token is required", domain.ErrInvalidInput)
+	}
+
+	token, err := s.customerRepo.ConsumeLoginToken(ctx, jwt.HashToken(rawToken), time.Now())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: link is invalid, expired or already used", domain.ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	if err := s.customerRepo.RecordLogin(ctx, token.CustomerID, ip); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.customerRepo.FindByID(ctx, token.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.claimGuestOrders(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.pasetoMaker.CreateCustomerToken(customer.ID, customer.Email, s.cfg.Auth.CustomerTokenExpires)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomerSession{
+		AccessToken:   accessToken,
+		ExpiresIn:     int(s.cfg.Auth.CustomerTokenExpires.Seconds()),
+		Customer:      customer,
+		ClaimedOrders: claimed,
+	}, nil
+}
+
+// claimGuestOrders only runs for verified customers; an unverified email
+// proves nothing about who placed the orders.
+func (s *CustomerService) claimGuestOrders(ctx context.Context, customer *domain.Customer) (int64, error) {
+	if customer.EmailVerifiedAt == nil {
+		return 0, nil
+	}
+
+	claimed, err := s.orderRepo.ClaimByEmail(ctx, customer.ID, customer.Email)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim guest orders: %w", err)
+	}
+	if claimed > 0 {
+		logger.Info("Guest orders claimed",
+			zap.Int64("customer_id", customer.ID),
+			zap.Int64("orders", claimed),
+		)
+	}
+	return claimed, nil
+}
+
+// ============================================================================
+// CUSTOMER-SCOPED DATA
+// ============================================================================
+
+func (s *CustomerService) GetCustomer(ctx context.Context, customerID int64) (*domain.Customer, error) {
+	return s.customerRepo.FindByID(ctx, customerID)
+}
+
+// ListOrders also claims guest orders placed since the last login.
+func (s *CustomerService) ListOrders(ctx context.Context, customerID int64, page, limit int) ([]*domain.Order, int, error) {
+	customer, err := s.customerRepo.FindByID(ctx, customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := s.claimGuestOrders(ctx, customer); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	return s.orderRepo.FindByCustomerID(ctx, customerID, limit, offset)
+}
+
+func (s *CustomerService) ListDownloads(ctx context.Context, customerID int64) ([]*domain.DownloadToken, error) {
+	customer, err := s.customerRepo.FindByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.downloadService.GetTokensByEmail(ctx, customer.Email)
+}
+
+// GetInvoice renders the invoice PDF for one of the customer's paid orders.
+func (s *CustomerService) GetInvoice(ctx context.Context, customerID, orderID int64) (*domain.Order, []byte, error) {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Someone else's order looks the same as a missing one
+	if order.CustomerID == nil || *order.CustomerID != customerID {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	switch order.Status {
+	case domain.OrderStatusPaid, domain.OrderStatusAdminReview, domain.OrderStatusApproved, domain.OrderStatusRefunded:
+	default:
+		return nil, nil, fmt.Errorf("%w: no invoice for a %s order", domain.ErrInvalidInput, order.Status)
+	}
+
+	items, err := s.orderItemRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdfBytes, err := s.pdfService.GenerateOrderInvoice(ctx, order, items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, pdfBytes, nil
+}
+
+// SetUpdateNotifications turns "new version available" emails on or off
+// for one of the customer's orders.
+func (s *CustomerService) SetUpdateNotifications(ctx context.Context, customerID, orderID int64, enabled bool) error {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order.CustomerID == nil || *order.CustomerID != customerID {
+		return domain.ErrNotFound
+	}
+	return s.orderRepo.SetNotifyUpdates(ctx, orderID, enabled)
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// DISPUTE SERVICE - Chargebacks from payment.dispute.* webhooks
+// ============================================================================
+
+type DisputeService struct {
+	disputeRepo     repository.PaymentDisputeRepository
+	paymentRepo     repository.PaymentRepository
+	orderRepo       repository.OrderRepository
+	tokenRepo       repository.DownloadTokenRepository
+	licenseService  *LicenseService
+	jobRepo         repository.BackgroundJobRepository
+	activityLogRepo repository.ActivityLogRepository
+	webhooks        *OutboundWebhookService
+}
+
+func NewDisputeService(
+	disputeRepo repository.PaymentDisputeRepository,
+	paymentRepo repository.PaymentRepository,
+	orderRepo repository.OrderRepository,
+	tokenRepo repository.DownloadTokenRepository,
+	licenseService *LicenseService,
+	jobRepo repository.BackgroundJobRepository,
+	activityLogRepo repository.ActivityLogRepository,
+	webhooks *OutboundWebhookService,
+) *DisputeService {
+	return &DisputeService{
+		disputeRepo:     disputeRepo,
+		paymentRepo:     paymentRepo,
+		orderRepo:       orderRepo,
+		tokenRepo:       tokenRepo,
+		licenseService:  licenseService,
+		jobRepo:         jobRepo,
+		activityLogRepo: activityLogRepo,
+		webhooks:        webhooks,
+	}
+}
+
+// IsDisputeEvent reports whether a webhook event belongs to this service.
+func IsDisputeEvent(event string) bool {
+	return strings.HasPrefix(event, "payment.dispute.")
+}
+
+// ============================================================================
+// WEBHOOK HANDLING
+// ============================================================================
+
+// HandleWebhookEvent applies a payment.dispute.* event. payload is the
+// "payload" object of the Razorpay webhook body:
+//
+//	"payload": {
+//	  "payment": { "entity": { "id": "pay_xxx", ... } },
+//	  "dispute": { "entity": { "id": "disp_xxx", "payment_id": "pay_xxx",
+//	                           "amount": 1000, "status": "open", "respond_by": 1700000000, ... } }
+//	}
+func (s *DisputeService) HandleWebhookEvent(ctx context.Context, event string, payload map[string]interface{}) error {
+	entity := webhookEntity(payload, "dispute")
+	if entity == nil {
+		return fmt.Errorf("dispute webhook missing dispute entity")
+	}
+
+	gatewayDisputeID, _ := entity["id"].(string)
+	if gatewayDisputeID == "" {
+		return fmt.Errorf("dispute webhook missing dispute ID")
+	}
+
+	gatewayPaymentID, _ := entity["payment_id"].(string)
+	if gatewayPaymentID == "" {
+		if paymentEntity := webhookEntity(payload, "payment"); paymentEntity != nil {
+			gatewayPaymentID, _ = paymentEntity["id"].(string)
+		}
+	}
+
+	payment, err := s.paymentRepo.FindByGatewayPaymentID(ctx, gatewayPaymentID)
+	if err != nil {
+		return fmt.Errorf("payment not found for dispute %s: %w", gatewayDisputeID, err)
+	}
+
+	previous, err := s.disputeRepo.FindByGatewayDisputeID(ctx, gatewayDisputeID)
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+
+	dispute := disputeFromEntity(entity, event)
+	dispute.PaymentID = payment.ID
+	dispute.OrderID = payment.OrderID
+	dispute.GatewayDisputeID = gatewayDisputeID
+
+	// Amounts are only meaningful with their currency; never assume USD
+	if dispute.Currency == "" && previous != nil {
+		dispute.Currency = previous.Currency
+	}
+	if dispute.Currency == "" {
+		return fmt.Errorf("dispute %s has no currency", gatewayDisputeID)
+	}
+
+	// Duplicate delivery of an already-applied outcome
+	if previous != nil && previous.Status == dispute.Status && previous.Status.IsResolved() {
+		return nil
+	}
+
+	if dispute.Status.IsResolved() {
+		now := time.Now()
+		dispute.ResolvedAt = &now
+	}
+
+	if err := s.disputeRepo.Upsert(ctx, dispute); err != nil {
+		return err
+	}
+
+	switch dispute.Status {
+	case domain.DisputeStatusWon:
+		err = s.applyWon(ctx, dispute, payment)
+	case domain.DisputeStatusLost, domain.DisputeStatusClosed:
+		err = s.applyLost(ctx, dispute, payment)
+	default:
+		err = s.applyOpen(ctx, dispute, payment, previous == nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Payment dispute updated",
+		zap.String("dispute_id", gatewayDisputeID),
+		zap.String("event", event),
+		zap.String("status", string(dispute.Status)),
+		zap.Int64("order_id", dispute.OrderID),
+	)
+
+	return nil
+}
+
+// applyOpen marks the payment disputed and freezes downloads until the
+// gateway decides.
+func (s *DisputeService) applyOpen(ctx context.Context, dispute *domain.PaymentDispute, payment *domain.Payment, isNew bool) error {
+	if payment.Status != domain.PaymentStatusDisputed {
+		if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, domain.PaymentStatusDisputed); err != nil {
+			return err
+		}
+	}
+
+	frozen, err := s.tokenRepo.FreezeByOrderID(ctx, dispute.OrderID, "Payment disputed: "+dispute.GatewayDisputeID)
+	if err != nil {
+		return err
+	}
+
+	if isNew {
+		s.logActivity(ctx, "dispute_opened", dispute, 0, map[string]interface{}{
+			"gateway_dispute_id": dispute.GatewayDisputeID,
+			"amount_minor":       dispute.AmountMinor,
+			"currency":           dispute.Currency,
+			"tokens_frozen":      frozen,
+		})
+		s.notifyAdmins(ctx, dispute)
+	}
+
+	return nil
+}
+
+// applyWon restores the payment and the customer's downloads.
+func (s *DisputeService) applyWon(ctx context.Context, dispute *domain.PaymentDispute, payment *domain.Payment) error {
+	payment.Status = domain.PaymentStatusCaptured
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		return err
+	}
+
+	unfrozen, err := s.tokenRepo.UnfreezeByOrderID(ctx, dispute.OrderID)
+	if err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "dispute_won", dispute, 0, map[string]interface{}{
+		"gateway_dispute_id": dispute.GatewayDisputeID,
+		"tokens_unfrozen":    unfrozen,
+	})
+	s.notifyAdmins(ctx, dispute)
+
+	return nil
+}
+
+// applyLost treats the chargeback as a refund: the payment and order move to
+// refunded and every download token and license is revoked.
+func (s *DisputeService) applyLost(ctx context.Context, dispute *domain.PaymentDispute, payment *domain.Payment) error {
+	now := time.Now()
+	payment.Status = domain.PaymentStatusRefunded
+	payment.RefundedAt = &now
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		return err
+	}
+
+	revoked, err := s.tokenRepo.RevokeByOrderID(ctx, dispute.OrderID, "Payment dispute lost: "+dispute.GatewayDisputeID)
+	if err != nil {
+		return err
+	}
+
+	licensesRevoked, err := s.licenseService.RevokeForOrder(ctx, dispute.OrderID, "Payment dispute lost: "+dispute.GatewayDisputeID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.orderRepo.UpdateStatus(ctx, dispute.OrderID, domain.OrderStatusRefunded, nil); err != nil {
+		if err != domain.ErrInvalidStateTransition {
+			return err
+		}
+		logger.Warn("Order cannot move to refunded after lost dispute",
+			zap.Int64("order_id", dispute.OrderID),
+		)
+	} else if order, err := s.orderRepo.FindByID(ctx, dispute.OrderID); err == nil {
+		s.webhooks.PublishOrder(ctx, domain.OutboundEventOrderRefunded, order)
+	}
+
+	s.logActivity(ctx, "dispute_lost", dispute, 0, map[string]interface{}{
+		"gateway_dispute_id": dispute.GatewayDisputeID,
+		"amount_deducted":    dispute.AmountDeductedMinor,
+		"currency":           dispute.Currency,
+		"tokens_revoked":     revoked,
+		"licenses_revoked":   licensesRevoked,
+	})
+	s.notifyAdmins(ctx, dispute)
+
+	return nil
+}
+
+// ============================================================================
+// ADMIN
+// ============================================================================
+
+func (s *DisputeService) GetAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.PaymentDispute, int, error) {
+	return s.disputeRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+}
+
+func (s *DisputeService) Get(ctx context.Context, id int64) (*domain.DisputeWithEvidence, error) {
+	dispute, err := s.disputeRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := s.disputeRepo.GetEvidence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if evidence == nil {
+		evidence = []*domain.DisputeEvidence{}
+	}
+
+	return &domain.DisputeWithEvidence{
+		PaymentDispute: *dispute,
+		Evidence:       evidence,
+	}, nil
+}
+
+func (s *DisputeService) AddEvidence(ctx context.Context, disputeID int64, adminID int64, notes string, documentURL *string) (*domain.DisputeEvidence, error) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return nil, fmt.Errorf("%w: notes are required", domain.ErrInvalidInput)
+	}
+
+	dispute, err := s.disputeRepo.FindByID(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status.IsResolved() {
+		return nil, fmt.Errorf("%w: dispute is already %s", domain.ErrInvalidInput, dispute.Status)
+	}
+
+	evidence := &domain.DisputeEvidence{
+		DisputeID:   disputeID,
+		Notes:       notes,
+		DocumentURL: documentURL,
+		AddedBy:     &adminID,
+	}
+	if err := s.disputeRepo.AddEvidence(ctx, evidence); err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, "dispute_evidence_added", dispute, adminID, map[string]interface{}{
+		"evidence_id": evidence.ID,
+	})
+
+	return evidence, nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func disputeFromEntity(entity map[string]interface{}, event string) *domain.PaymentDispute {
+	dispute := &domain.PaymentDispute{
+		GatewayPayload: domain.JSONMap(entity),
+	}
+
+	if v, ok := entity["reason_code"].(string); ok && v != "" {
+		dispute.ReasonCode = &v
+	}
+	if v, ok := entity["reason_description"].(string); ok && v != "" {
+		dispute.ReasonDescription = &v
+	}
+	if v, ok := entity["phase"].(string); ok && v != "" {
+		dispute.Phase = &v
+	}
+	// Razorpay reports amounts in the smallest unit of the dispute's currency
+	if v, ok := entity["currency"].(string); ok && v != "" {
+		dispute.Currency = strings.ToUpper(v)
+	}
+	if v, ok := entity["amount"].(float64); ok {
+		dispute.AmountMinor = int64(v)
+	}
+	if v, ok := entity["amount_deducted"].(float64); ok {
+		dispute.AmountDeductedMinor = int64(v)
+	}
+	if v, ok := entity["respond_by"].(float64); ok && v > 0 {
+		respondBy := time.Unix(int64(v), 0)
+		dispute.RespondBy = &respondBy
+	}
+
+	// Prefer the entity status; fall back to the event suffix
+	// (payment.dispute.created -> open, .won, .lost, .closed, .under_review).
+	status, _ := entity["status"].(string)
+	if status == "" {
+		status = strings.TrimPrefix(event, "payment.dispute.")
+	}
+	switch domain.DisputeStatus(status) {
+	case domain.DisputeStatusWon, domain.DisputeStatusLost, domain.DisputeStatusClosed, domain.DisputeStatusUnderReview:
+		dispute.Status = domain.DisputeStatus(status)
+	default:
+		dispute.Status = domain.DisputeStatusOpen
+	}
+
+	return dispute
+}
+
+// webhookEntity returns payload[name]["entity"] from a Razorpay webhook.
+func webhookEntity(payload map[string]interface{}, name string) map[string]interface{} {
+	wrapper, _ := payload[name].(map[string]interface{})
+	if wrapper == nil {
+		return nil
+	}
+	entity, _ := wrapper["entity"].(map[string]interface{})
+	return entity
+}
+
+func (s *DisputeService) notifyAdmins(ctx context.Context, dispute *domain.PaymentDispute) {
+	payload := map[string]interface{}{
+		"order_id":           dispute.OrderID,
+		"dispute_id":         dispute.ID,
+		"gateway_dispute_id": dispute.GatewayDisputeID,
+		"status":             string(dispute.Status),
+		"amount_minor":       dispute.AmountMinor,
+		"currency":           dispute.Currency,
+		"reason":             derefStr(dispute.ReasonDescription),
+	}
+	if dispute.RespondBy != nil {
+		payload["respond_by"] = dispute.RespondBy.Format(time.RFC3339)
+	}
+
+	if err := s.jobRepo.Create(ctx, &domain.BackgroundJob{
+		JobType:     "send_admin_dispute_notification",
+		Payload:     domain.JSONMap(payload),
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+	}); err != nil {
+		logger.Error("Failed to enqueue dispute notification",
+			zap.String("dispute_id", dispute.GatewayDisputeID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *DisputeService) logActivity(ctx context.Context, action string, dispute *domain.PaymentDispute, adminID int64, metadata map[string]interface{}) {
+	if s.activityLogRepo == nil {
+		return
+	}
+
+	var adminIDPtr *int64
+	if adminID > 0 {
+		adminIDPtr = &adminID
+	}
+
+	metadata["order_id"] = dispute.OrderID
+
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		Action:     action,
+		EntityType: strPtr("payment_dispute"),
+		EntityID:   &dispute.ID,
+		AdminID:    adminIDPtr,
+		Details:    domain.JSONMap(metadata),
+	})
+}
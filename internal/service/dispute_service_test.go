@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+)
+
+func TestDisputeFromEntityKeepsCurrency(t *testing.T) {
+	// Shape of a Razorpay dispute entity: amounts in paise
+	entity := map[string]interface{}{
+		"id":              "disp_0000000000000",
+		"currency":        "inr",
+		"amount":          float64(1000000),
+		"amount_deducted": float64(250000),
+		"phase":           "chargeback",
+		"status":          "open",
+	}
+
+	dispute := disputeFromEntity(entity, "payment.dispute.created")
+	if dispute.Currency != "INR" || dispute.AmountMinor != 1000000 || dispute.AmountDeductedMinor != 250000 {
+		t.Errorf("dispute = %+v", dispute)
+	}
+	if got := domain.FormatMinorAmount(dispute.AmountMinor, dispute.Currency); got != "10000.00 INR" {
+		t.Errorf("formatted amount = %q", got)
+	}
+
+	// Missing currency is left for HandleWebhookEvent to resolve, not assumed USD
+	delete(entity, "currency")
+	if dispute := disputeFromEntity(entity, "payment.dispute.created"); dispute.Currency != "" {
+		t.Errorf("currency without one in the payload = %q", dispute.Currency)
+	}
+
+	if got := domain.FormatMinorAmount(1999, "USD"); got != "$19.99" {
+		t.Errorf("USD amount = %q", got)
+	}
+}
@@ -1,15 +1,24 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/merraki/merraki-backend/internal/domain"
 	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/pkg/watermark"
 	"github.com/merraki/merraki-backend/internal/repository"
 	"go.uber.org/zap"
 )
@@ -19,12 +28,13 @@ import (
 // ============================================================================
 
 type DownloadTokenService struct {
-	tokenRepo      repository.DownloadTokenRepository
-	downloadRepo   repository.DownloadRepository
-	orderRepo      repository.OrderRepository
-	orderItemRepo  repository.OrderItemRepository
-	templateRepo   repository.TemplateRepository
-	storageService *StorageService
+	tokenRepo       repository.DownloadTokenRepository
+	downloadRepo    repository.DownloadRepository
+	orderRepo       repository.OrderRepository
+	orderItemRepo   repository.OrderItemRepository
+	templateRepo    repository.TemplateRepository
+	storageService  *StorageService
+	settingsService *SettingsService
 }
 
 func NewDownloadTokenService(
@@ -34,14 +44,16 @@ func NewDownloadTokenService(
 	orderItemRepo repository.OrderItemRepository,
 	templateRepo repository.TemplateRepository,
 	storageService *StorageService,
+	settingsService *SettingsService,
 ) *DownloadTokenService {
 	return &DownloadTokenService{
-		tokenRepo:      tokenRepo,
-		downloadRepo:   downloadRepo,
-		orderRepo:      orderRepo,
-		orderItemRepo:  orderItemRepo,
-		templateRepo:   templateRepo,
-		storageService: storageService,
+		tokenRepo:       tokenRepo,
+		downloadRepo:    downloadRepo,
+		orderRepo:       orderRepo,
+		orderItemRepo:   orderItemRepo,
+		templateRepo:    templateRepo,
+		storageService:  storageService,
+		settingsService: settingsService,
 	}
 }
 
@@ -71,6 +83,9 @@ func (s *DownloadTokenService) GenerateTokensForOrder(ctx context.Context, order
 		return err
 	}
 
+	expiryDays := s.settingsService.GetInt(ctx, domain.SettingDownloadExpiryDays, 30)
+	maxDownloads := s.settingsService.GetInt(ctx, domain.SettingDownloadMaxPerToken, 5)
+
 	// 4. Generate token for each item
 	for _, item := range items {
 		// Check if token already exists
@@ -95,7 +110,7 @@ func (s *DownloadTokenService) GenerateTokensForOrder(ctx context.Context, order
 		token := s.generateSecureToken()
 
 		// Set expiration (use order's download expiry)
-		expiresAt := time.Now().AddDate(0, 0, 30) // Default 30 days
+		expiresAt := time.Now().AddDate(0, 0, expiryDays)
 		if order.DownloadsExpiresAt != nil {
 			expiresAt = *order.DownloadsExpiresAt
 		}
@@ -107,7 +122,7 @@ func (s *DownloadTokenService) GenerateTokensForOrder(ctx context.Context, order
 			TemplateID:    item.TemplateID,
 			CustomerEmail: order.CustomerEmail,
 			ExpiresAt:     expiresAt,
-			MaxDownloads:  5, // Allow 5 downloads per token
+			MaxDownloads:  maxDownloads,
 		}
 
 		if err := s.tokenRepo.Create(ctx, downloadToken); err != nil {
@@ -126,9 +141,37 @@ func (s *DownloadTokenService) GenerateTokensForOrder(ctx context.Context, order
 		)
 	}
 
+	// 5. One ZIP link for orders with several items
+	if len(items) > 1 {
+		s.createBundleToken(ctx, order, expiryDays)
+	}
+
 	return nil
 }
 
+func (s *DownloadTokenService) createBundleToken(ctx context.Context, order *domain.Order, expiryDays int) {
+	expiresAt := time.Now().AddDate(0, 0, expiryDays)
+	if order.DownloadsExpiresAt != nil {
+		expiresAt = *order.DownloadsExpiresAt
+	}
+
+	bundle := &domain.DownloadBundleToken{
+		Token:         s.generateSecureToken(),
+		OrderID:       order.ID,
+		CustomerEmail: order.CustomerEmail,
+		ExpiresAt:     expiresAt,
+	}
+
+	created, err := s.tokenRepo.CreateBundle(ctx, bundle)
+	if err != nil {
+		logger.Error("Failed to create bundle token", zap.Int64("order_id", order.ID), zap.Error(err))
+		return
+	}
+	if created {
+		logger.Info("Bundle token generated", zap.Int64("order_id", order.ID))
+	}
+}
+
 // ============================================================================
 // VALIDATE TOKEN & INITIATE DOWNLOAD
 // ============================================================================
@@ -149,38 +192,26 @@ type DownloadResponse struct {
 }
 
 func (s *DownloadTokenService) InitiateDownload(ctx context.Context, req *InitiateDownloadRequest) (*DownloadResponse, error) {
-	// 1. Find token
-	token, err := s.tokenRepo.FindByToken(ctx, req.Token)
+	// 1. Find and validate token, load template
+	token, template, err := s.resolveDownload(ctx, req)
 	if err != nil {
-		logger.Warn("Invalid download token",
-			zap.String("token", req.Token[:16]+"..."),
-		)
-		return nil, domain.ErrNotFound
-	}
-
-	// 2. Validate token
-	if err := s.validateToken(token, req.Email); err != nil {
 		return nil, err
 	}
 
-	// 3. Get order item (for file details)
+	// 2. Get order item (for file details)
 	item, err := s.orderItemRepo.GetByID(ctx, token.OrderItemID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. Get template (for current file URL)
-	template, err := s.templateRepo.FindByID(ctx, token.TemplateID)
-	if err != nil {
+	if err := s.reserveDownload(ctx, token, req.Email); err != nil {
 		return nil, err
 	}
 
-	// Ensure file exists
-	if template.FileURL == nil || *template.FileURL == "" {
-		return nil, fmt.Errorf("file not available")
-	}
+	// 3. Resolve the file to serve (buyer-stamped copy if enabled)
+	fileURL := s.deliverableFile(ctx, token, template, item)
 
-	// 5. Create download log
+	// 4. Create download log
 	download := &domain.Download{
 		TokenID:       token.ID,
 		OrderID:       token.OrderID,
@@ -190,7 +221,8 @@ func (s *DownloadTokenService) InitiateDownload(ctx context.Context, req *Initia
 		IPAddress:     &req.IPAddress,
 		UserAgent:     &req.UserAgent,
 		Country:       &req.Country,
-		FileURL:       template.FileURL,
+		FileURL:       &fileURL,
+		Counted:       true, // redirects are charged up front
 	}
 
 	if err := s.downloadRepo.Create(ctx, download); err != nil {
@@ -198,11 +230,7 @@ func (s *DownloadTokenService) InitiateDownload(ctx context.Context, req *Initia
 		// Continue - logging is not critical
 	}
 
-	// 6. Increment download count
-	if err := s.tokenRepo.IncrementDownloadCount(ctx, token.ID); err != nil {
-		logger.Error("Failed to increment token download count", zap.Error(err))
-	}
-
+	// 5. Increment download count (the token was charged by reserveDownload)
 	if err := s.orderItemRepo.IncrementDownloadCount(ctx, item.ID); err != nil {
 		logger.Error("Failed to increment item download count", zap.Error(err))
 	}
@@ -211,24 +239,18 @@ func (s *DownloadTokenService) InitiateDownload(ctx context.Context, req *Initia
 		logger.Error("Failed to increment template download count", zap.Error(err))
 	}
 
-	// 7. Generate signed URL (short-lived)
-	signedURL, err := s.storageService.GenerateSignedDownloadURL(ctx, *template.FileURL, 15*time.Minute)
+	// 6. Generate signed URL (short-lived)
+	signedURL, err := s.storageService.GenerateSignedDownloadURL(ctx, fileURL, 15*time.Minute)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate download URL: %w", err)
 	}
 
-	// 8. Prepare response
-	fileName := fmt.Sprintf("%s_%s.%s", template.Slug, template.CurrentVersion, getFileExtension(*template.FileFormat))
-	fileSize := int64(0)
-	if template.FileSizeMB != nil {
-		fileSize = int64(*template.FileSizeMB * 1024 * 1024)
-	}
-
+	// 7. Prepare response
 	response := &DownloadResponse{
 		DownloadURL: signedURL,
 		ExpiresAt:   time.Now().Add(15 * time.Minute),
-		FileName:    fileName,
-		FileSize:    fileSize,
+		FileName:    downloadFileName(template),
+		FileSize:    templateFileSize(template),
 	}
 
 	logger.Info("Download initiated",
@@ -241,10 +263,698 @@ func (s *DownloadTokenService) InitiateDownload(ctx context.Context, req *Initia
 	return response, nil
 }
 
+// ============================================================================
+// PROXY DOWNLOAD - Stream the file through the API (Range aware)
+// ============================================================================
+
+// proxiedHeaders are copied from the storage response to the client.
+var proxiedHeaders = []string{
+	"Content-Type",
+	"Content-Range",
+	"Accept-Ranges",
+	"ETag",
+	"Last-Modified",
+}
+
+type ProxyDownload struct {
+	StatusCode    int
+	Header        http.Header
+	FileName      string
+	ContentLength int64
+	// Body records the transfer when it is closed.
+	Body io.ReadCloser
+}
+
+// ProxyEnabled reports whether downloads should be streamed instead of
+// redirected to a signed storage URL.
+func (s *DownloadTokenService) ProxyEnabled(ctx context.Context) bool {
+	return s.settingsService.GetBool(ctx, domain.SettingDownloadProxy, false)
+}
+
+// OpenProxyDownload validates the token and opens the stored file,
+// forwarding Range/If-Range. A download is reserved against the token while
+// the stream is open; it is kept only if enough bytes are actually served,
+// and given back otherwise.
+func (s *DownloadTokenService) OpenProxyDownload(ctx context.Context, req *InitiateDownloadRequest, rangeHeader, ifRange string) (*ProxyDownload, error) {
+	token, template, err := s.resolveDownload(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reserveDownload(ctx, token, req.Email); err != nil {
+		return nil, err
+	}
+
+	fileURL := s.deliverableFile(ctx, token, template, nil)
+
+	download := &domain.Download{
+		TokenID:       token.ID,
+		OrderID:       token.OrderID,
+		OrderItemID:   token.OrderItemID,
+		TemplateID:    token.TemplateID,
+		CustomerEmail: req.Email,
+		IPAddress:     &req.IPAddress,
+		UserAgent:     &req.UserAgent,
+		Country:       &req.Country,
+		FileURL:       &fileURL,
+		RangeHeader:   nullableStr(rangeHeader),
+	}
+	if err := s.downloadRepo.Create(ctx, download); err != nil {
+		s.releaseDownload(ctx, token)
+		return nil, fmt.Errorf("failed to create download log: %w", err)
+	}
+
+	upstreamHeader := http.Header{}
+	if rangeHeader != "" {
+		upstreamHeader.Set("Range", rangeHeader)
+		if ifRange != "" {
+			upstreamHeader.Set("If-Range", ifRange)
+		}
+	}
+
+	// The body is read after the handler returns, so the fetch must not be
+	// tied to the request context.
+	resp, err := s.storageService.OpenFile(context.WithoutCancel(ctx), fileURL, upstreamHeader)
+	if err != nil {
+		_ = s.downloadRepo.MarkAsFailed(ctx, download.ID, err.Error())
+		s.releaseDownload(ctx, token)
+		return nil, err
+	}
+
+	header := http.Header{}
+	for _, key := range proxiedHeaders {
+		if v := resp.Header.Get(key); v != "" {
+			header.Set(key, v)
+		}
+	}
+
+	fileSize := totalFileSize(resp)
+	if fileSize <= 0 {
+		fileSize = templateFileSize(template)
+	}
+
+	started := time.Now()
+	body := &meteredBody{
+		ReadCloser: resp.Body,
+		onClose: func(served int64, eof bool, readErr error) {
+			s.finishProxyDownload(download, token, template, resp, fileSize, served, eof, readErr, started)
+		},
+	}
+
+	logger.Info("Proxied download started",
+		zap.Int64("download_id", download.ID),
+		zap.Int64("template_id", template.ID),
+		zap.Int("status", resp.StatusCode),
+		zap.String("range", rangeHeader),
+	)
+
+	return &ProxyDownload{
+		StatusCode:    resp.StatusCode,
+		Header:        header,
+		FileName:      downloadFileName(template),
+		ContentLength: resp.ContentLength,
+		Body:          body,
+	}, nil
+}
+
+// finishProxyDownload records what was served and settles the download
+// reserved when the stream opened: it is kept once the uncounted transfers
+// for the token reach the configured share of the file, and released
+// otherwise. If the transfer cannot be recorded the reservation is kept.
+func (s *DownloadTokenService) finishProxyDownload(
+	download *domain.Download,
+	token *domain.DownloadToken,
+	template *domain.Template,
+	resp *http.Response,
+	fileSize, served int64,
+	eof bool,
+	readErr error,
+	started time.Time,
+) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	durationMS := int(time.Since(started).Milliseconds())
+	completed := eof && (resp.ContentLength < 0 || served >= resp.ContentLength)
+
+	download.BytesServed = served
+	download.DownloadDurationMS = &durationMS
+	if fileSize > 0 {
+		download.FileSizeBytes = &fileSize
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		download.Failed = true
+		download.ErrorMessage = strPtr("requested range not satisfiable")
+	case completed:
+		now := time.Now()
+		download.CompletedAt = &now
+	default:
+		msg := "transfer interrupted"
+		if readErr != nil {
+			msg = readErr.Error()
+		}
+		download.Failed = true
+		download.ErrorMessage = &msg
+	}
+
+	if err := s.downloadRepo.RecordTransfer(ctx, download); err != nil {
+		logger.Error("Failed to record download transfer",
+			zap.Int64("download_id", download.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// Without a known size only a complete transfer can be counted
+	var threshold int64
+	switch {
+	case fileSize > 0:
+		pct := int64(s.settingsService.GetInt(ctx, domain.SettingDownloadCountPct, 80))
+		threshold = (fileSize*pct + 99) / 100
+	case completed:
+		threshold = 0
+	default:
+		s.releaseDownload(ctx, token)
+		return
+	}
+
+	counted, err := s.downloadRepo.MarkCountedIfDelivered(ctx, token.ID, threshold)
+	if err != nil {
+		logger.Error("Failed to evaluate download count", zap.Int64("token_id", token.ID), zap.Error(err))
+		return
+	}
+	if !counted {
+		s.releaseDownload(ctx, token)
+		return
+	}
+
+	s.chargeDownload(ctx, token)
+
+	logger.Info("Proxied download counted",
+		zap.Int64("token_id", token.ID),
+		zap.Int64("download_id", download.ID),
+		zap.Int64("bytes_served", served),
+		zap.Int64("file_size", fileSize),
+	)
+}
+
+// reserveDownload takes one of the token's remaining downloads. The limit
+// check in validateToken reads a count that parallel requests have not yet
+// raised; this conditional increment is what actually enforces it.
+func (s *DownloadTokenService) reserveDownload(ctx context.Context, token *domain.DownloadToken, email string) error {
+	reserved, err := s.tokenRepo.ReserveDownload(ctx, token.ID)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		logger.Warn("Download limit exceeded",
+			zap.String("token", token.Token[:16]+"..."),
+			zap.String("email", email),
+			zap.Int("max", token.MaxDownloads),
+		)
+		return fmt.Errorf("download limit exceeded")
+	}
+	return nil
+}
+
+// releaseDownload gives back a reservation that was not counted
+func (s *DownloadTokenService) releaseDownload(ctx context.Context, token *domain.DownloadToken) {
+	if err := s.tokenRepo.ReleaseDownload(ctx, token.ID); err != nil {
+		logger.Error("Failed to release download reservation", zap.Int64("token_id", token.ID), zap.Error(err))
+	}
+}
+
+// chargeDownload counts a delivered download against its order item and
+// the template. The token itself was charged by reserveDownload.
+func (s *DownloadTokenService) chargeDownload(ctx context.Context, token *domain.DownloadToken) {
+	if err := s.orderItemRepo.IncrementDownloadCount(ctx, token.OrderItemID); err != nil {
+		logger.Error("Failed to increment item download count", zap.Error(err))
+	}
+	if err := s.templateRepo.IncrementDownloads(ctx, token.TemplateID); err != nil {
+		logger.Error("Failed to increment template download count", zap.Error(err))
+	}
+}
+
+// meteredBody counts bytes read from storage (and so written to the
+// client) and reports once when the response body is closed.
+type meteredBody struct {
+	io.ReadCloser
+	served  int64
+	eof     bool
+	readErr error
+	once    sync.Once
+	onClose func(served int64, eof bool, readErr error)
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.served += int64(n)
+	if err == io.EOF {
+		b.eof = true
+	} else if err != nil {
+		b.readErr = err
+	}
+	return n, err
+}
+
+func (b *meteredBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.onClose(b.served, b.eof, b.readErr)
+	})
+	return err
+}
+
+// ============================================================================
+// BUNDLE DOWNLOAD - Every file of an order in one streamed ZIP
+// ============================================================================
+
+type BundleDownload struct {
+	FileName string
+
+	bundle  *domain.DownloadBundleToken
+	order   *domain.Order
+	entries []bundleEntry
+	skipped []string
+	req     *InitiateDownloadRequest
+}
+
+type bundleEntry struct {
+	token    *domain.DownloadToken
+	template *domain.Template
+}
+
+// GetBundleToken returns the order's bundle token, or nil for single-item
+// orders and orders approved before bundles existed.
+func (s *DownloadTokenService) GetBundleToken(ctx context.Context, orderID int64) (*domain.DownloadBundleToken, error) {
+	bundle, err := s.tokenRepo.FindBundleByOrderID(ctx, orderID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return bundle, err
+}
+
+// OpenBundle authorizes a bundle download and works out which files go in
+// it. Files whose own token is revoked, frozen, expired or used up are
+// left out and listed in the README instead.
+func (s *DownloadTokenService) OpenBundle(ctx context.Context, req *InitiateDownloadRequest) (*BundleDownload, error) {
+	bundle, err := s.tokenRepo.FindBundleByToken(ctx, req.Token)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if bundle.CustomerEmail != req.Email {
+		logger.Warn("Email mismatch for bundle token",
+			zap.Int64("order_id", bundle.OrderID),
+			zap.String("provided_email", req.Email),
+		)
+		return nil, fmt.Errorf("invalid email for this token")
+	}
+	if time.Now().After(bundle.ExpiresAt) {
+		return nil, fmt.Errorf("token has expired")
+	}
+
+	order, err := s.orderRepo.FindByID(ctx, bundle.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusApproved || !order.DownloadsEnabled {
+		return nil, fmt.Errorf("downloads not enabled for this order")
+	}
+
+	tokens, err := s.tokenRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &BundleDownload{
+		FileName: fmt.Sprintf("%s.zip", order.OrderNumber),
+		bundle:   bundle,
+		order:    order,
+		req:      req,
+	}
+
+	for _, token := range tokens {
+		template, err := s.templateRepo.FindByID(ctx, token.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.validateToken(token, req.Email); err != nil {
+			b.skipped = append(b.skipped, fmt.Sprintf("%s (%s)", template.Name, err))
+			continue
+		}
+		template = s.versionForToken(ctx, token, template)
+		if template.FileURL == nil || *template.FileURL == "" {
+			b.skipped = append(b.skipped, fmt.Sprintf("%s (file not available)", template.Name))
+			continue
+		}
+
+		b.entries = append(b.entries, bundleEntry{token: token, template: template})
+	}
+
+	if len(b.entries) == 0 {
+		return nil, fmt.Errorf("no files available for download")
+	}
+
+	return b, nil
+}
+
+// WriteBundle streams the ZIP to w one file at a time, straight from
+// storage. Files are stored uncompressed: office formats are already
+// zipped, and it keeps CPU out of the hot path. Each file that is written
+// in full is counted against its own token.
+func (s *DownloadTokenService) WriteBundle(ctx context.Context, b *BundleDownload, w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	readme, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     "README.txt",
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(readme, s.bundleReadme(ctx, b)); err != nil {
+		return err
+	}
+
+	names := make(map[string]int)
+	for _, entry := range b.entries {
+		name := downloadFileName(entry.template)
+		if n := names[name]; n > 0 {
+			name = fmt.Sprintf("%d_%s", n, name)
+		}
+		names[downloadFileName(entry.template)]++
+
+		if err := s.writeBundleEntry(ctx, b, entry, name, zw); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.MarkBundleUsed(ctx, b.bundle.ID); err != nil {
+		logger.Error("Failed to mark bundle used", zap.Int64("bundle_id", b.bundle.ID), zap.Error(err))
+	}
+
+	logger.Info("Bundle download completed",
+		zap.Int64("order_id", b.order.ID),
+		zap.Int("files", len(b.entries)),
+	)
+	return nil
+}
+
+func (s *DownloadTokenService) writeBundleEntry(ctx context.Context, b *BundleDownload, entry bundleEntry, name string, zw *zip.Writer) error {
+	// The archive is already streaming, so a file whose last download was
+	// taken by a parallel request is left out rather than failing the rest
+	if err := s.reserveDownload(ctx, entry.token, b.req.Email); err != nil {
+		logger.Warn("Bundle file skipped",
+			zap.Int64("token_id", entry.token.ID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	fileURL := s.deliverableFile(ctx, entry.token, entry.template, nil)
+
+	download := &domain.Download{
+		TokenID:       entry.token.ID,
+		OrderID:       entry.token.OrderID,
+		OrderItemID:   entry.token.OrderItemID,
+		TemplateID:    entry.token.TemplateID,
+		CustomerEmail: b.req.Email,
+		IPAddress:     &b.req.IPAddress,
+		UserAgent:     &b.req.UserAgent,
+		Country:       &b.req.Country,
+		FileURL:       &fileURL,
+	}
+	if err := s.downloadRepo.Create(ctx, download); err != nil {
+		s.releaseDownload(ctx, entry.token)
+		return fmt.Errorf("failed to create download log: %w", err)
+	}
+
+	started := time.Now()
+	resp, err := s.storageService.OpenFile(ctx, fileURL, nil)
+	if err != nil {
+		_ = s.downloadRepo.MarkAsFailed(ctx, download.ID, err.Error())
+		s.releaseDownload(ctx, entry.token)
+		return err
+	}
+	defer resp.Body.Close()
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		s.releaseDownload(ctx, entry.token)
+		return err
+	}
+
+	served, copyErr := io.Copy(fw, resp.Body)
+
+	durationMS := int(time.Since(started).Milliseconds())
+	download.BytesServed = served
+	download.DownloadDurationMS = &durationMS
+	download.FileSizeBytes = &served
+	if copyErr != nil {
+		msg := copyErr.Error()
+		download.Failed = true
+		download.ErrorMessage = &msg
+	} else {
+		now := time.Now()
+		download.CompletedAt = &now
+		download.Counted = true
+	}
+
+	if err := s.downloadRepo.RecordTransfer(ctx, download); err != nil {
+		logger.Error("Failed to record bundle transfer", zap.Int64("download_id", download.ID), zap.Error(err))
+	}
+	if copyErr != nil {
+		s.releaseDownload(ctx, entry.token)
+		return copyErr
+	}
+
+	s.chargeDownload(ctx, entry.token)
+	return nil
+}
+
+func (s *DownloadTokenService) bundleReadme(ctx context.Context, b *BundleDownload) string {
+	storeName := s.settingsService.GetString(ctx, domain.SettingStoreName, "Merraki Solutions")
+	supportEmail := s.settingsService.GetString(ctx, domain.SettingSupportEmail, "info@merrakisolutions.com")
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s - Order %s\r\n", storeName, b.order.OrderNumber)
+	fmt.Fprintf(&sb, "Licensed to: %s <%s>\r\n", b.order.CustomerName, b.order.CustomerEmail)
+	fmt.Fprintf(&sb, "Downloaded: %s\r\n\r\n", time.Now().UTC().Format("January 2, 2006 15:04 MST"))
+
+	sb.WriteString("FILES\r\n")
+	for _, entry := range b.entries {
+		fmt.Fprintf(&sb, "  - %s (version %s)\r\n", entry.template.Name, entry.template.CurrentVersion)
+	}
+	if len(b.skipped) > 0 {
+		sb.WriteString("\r\nNOT INCLUDED\r\n")
+		for _, skipped := range b.skipped {
+			fmt.Fprintf(&sb, "  - %s\r\n", skipped)
+		}
+	}
+
+	sb.WriteString("\r\nLICENSE\r\n")
+	sb.WriteString("These templates are licensed to the purchaser named above for use in their own\r\n")
+	sb.WriteString("personal and business projects. You may edit them freely. You may not resell,\r\n")
+	sb.WriteString("redistribute or share the original files, alone or as part of another product.\r\n")
+	fmt.Fprintf(&sb, "\r\nQuestions? Contact %s\r\n", supportEmail)
+
+	return sb.String()
+}
+
+// ============================================================================
+// WATERMARKING - Per-buyer stamped copies of the deliverable
+// ============================================================================
+
+// maxWatermarkBytes caps the files stamped in memory; larger files are
+// served unstamped.
+const maxWatermarkBytes = 100 << 20
+
+// deliverableFile returns the storage ID of the file to serve for a token.
+// For templates with watermarking enabled that is a copy stamped with the
+// buyer's details, built on first download and cached on the order item
+// until the template file changes. If stamping fails the original file is
+// served so a buyer is never locked out of a paid download.
+func (s *DownloadTokenService) deliverableFile(ctx context.Context, token *domain.DownloadToken, template *domain.Template, item *domain.OrderItem) string {
+	source := *template.FileURL
+	if !template.WatermarkEnabled {
+		return source
+	}
+
+	if item == nil {
+		var err error
+		if item, err = s.orderItemRepo.GetByID(ctx, token.OrderItemID); err != nil {
+			logger.Error("Failed to load order item for watermark",
+				zap.Int64("order_item_id", token.OrderItemID),
+				zap.Error(err),
+			)
+			return source
+		}
+	}
+
+	if item.WatermarkedFileURL != nil && item.WatermarkSourceURL != nil && *item.WatermarkSourceURL == source {
+		return *item.WatermarkedFileURL
+	}
+
+	fileURL, err := s.watermarkFile(ctx, token, template, item)
+	if err != nil {
+		logger.Warn("Serving unwatermarked file",
+			zap.Int64("order_item_id", item.ID),
+			zap.Int64("template_id", template.ID),
+			zap.Error(err),
+		)
+		return source
+	}
+	return fileURL
+}
+
+func (s *DownloadTokenService) watermarkFile(ctx context.Context, token *domain.DownloadToken, template *domain.Template, item *domain.OrderItem) (string, error) {
+	source := *template.FileURL
+
+	order, err := s.orderRepo.FindByID(ctx, token.OrderID)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.storageService.OpenFile(ctx, source, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWatermarkBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxWatermarkBytes {
+		return "", fmt.Errorf("file too large to watermark")
+	}
+
+	stamped, err := watermark.Apply(data, watermark.Stamp{
+		Name:        order.CustomerName,
+		Email:       order.CustomerEmail,
+		OrderNumber: order.OrderNumber,
+		IssuedAt:    time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The source hash keeps copies of different template files apart, so a
+	// cached CDN response for an older copy is never served.
+	sum := sha256.Sum256([]byte(source))
+	filename := fmt.Sprintf("%s_%d_%s_%s", order.OrderNumber, item.ID, hex.EncodeToString(sum[:4]), downloadFileName(template))
+
+	result, err := s.storageService.UploadFromReader(ctx, bytes.NewReader(stamped), filename, "watermarked")
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.orderItemRepo.SetWatermarkedFile(ctx, item.ID, result.PublicID, source); err != nil {
+		logger.Error("Failed to cache watermarked file", zap.Int64("order_item_id", item.ID), zap.Error(err))
+	}
+
+	logger.Info("Watermarked file created",
+		zap.String("order_number", order.OrderNumber),
+		zap.Int64("order_item_id", item.ID),
+		zap.Int64("template_id", template.ID),
+	)
+
+	return result.PublicID, nil
+}
+
 // ============================================================================
 // TOKEN VALIDATION
 // ============================================================================
 
+// resolveDownload finds and validates a token and loads the template whose
+// file it unlocks.
+func (s *DownloadTokenService) resolveDownload(ctx context.Context, req *InitiateDownloadRequest) (*domain.DownloadToken, *domain.Template, error) {
+	token, err := s.tokenRepo.FindByToken(ctx, req.Token)
+	if err != nil {
+		logger.Warn("Invalid download token",
+			zap.String("token", req.Token[:16]+"..."),
+		)
+		return nil, nil, domain.ErrNotFound
+	}
+
+	if err := s.validateToken(token, req.Email); err != nil {
+		return nil, nil, err
+	}
+
+	// Get template (for current file URL)
+	template, err := s.templateRepo.FindByID(ctx, token.TemplateID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template = s.versionForToken(ctx, token, template)
+
+	// Ensure file exists
+	if template.FileURL == nil || *template.FileURL == "" {
+		return nil, nil, fmt.Errorf("file not available")
+	}
+
+	return token, template, nil
+}
+
+// versionForToken applies the download version policy. Under "purchased"
+// it returns a copy of template pointing at the file of the version on the
+// order item, falling back to the file snapshotted at purchase when that
+// version is no longer in the history.
+func (s *DownloadTokenService) versionForToken(ctx context.Context, token *domain.DownloadToken, template *domain.Template) *domain.Template {
+	if servesLatestVersion(ctx, s.settingsService) {
+		return template
+	}
+
+	item, err := s.orderItemRepo.GetByID(ctx, token.OrderItemID)
+	if err != nil {
+		logger.Error("Failed to load order item for version policy",
+			zap.Int64("order_item_id", token.OrderItemID),
+			zap.Error(err),
+		)
+		return template
+	}
+	if item.TemplateVersion == "" || item.TemplateVersion == template.CurrentVersion {
+		return template
+	}
+
+	purchased := *template
+	purchased.CurrentVersion = item.TemplateVersion
+
+	version, err := s.templateRepo.GetVersionByNumber(ctx, template.ID, item.TemplateVersion)
+	switch {
+	case err == nil:
+		purchased.FileURL = &version.FileURL
+		purchased.FileSizeMB = version.FileSizeMB
+	case errors.Is(err, domain.ErrNotFound) && item.FileURL != nil && *item.FileURL != "":
+		purchased.FileURL = item.FileURL
+		purchased.FileSizeMB = item.FileSizeMB
+	default:
+		if !errors.Is(err, domain.ErrNotFound) {
+			logger.Error("Failed to load purchased template version",
+				zap.Int64("template_id", template.ID),
+				zap.String("version", item.TemplateVersion),
+				zap.Error(err),
+			)
+		}
+		return template
+	}
+	return &purchased
+}
+
 func (s *DownloadTokenService) validateToken(token *domain.DownloadToken, email string) error {
 	// Check if revoked
 	if token.IsRevoked {
@@ -255,6 +965,15 @@ func (s *DownloadTokenService) validateToken(token *domain.DownloadToken, email
 		return fmt.Errorf("token has been revoked")
 	}
 
+	// Check if frozen (open payment dispute)
+	if token.FrozenAt != nil {
+		logger.Warn("Frozen token used",
+			zap.String("token", token.Token[:16]+"..."),
+			zap.String("email", email),
+		)
+		return fmt.Errorf("token is temporarily suspended")
+	}
+
 	// Check expiration
 	if time.Now().After(token.ExpiresAt) {
 		logger.Warn("Expired token used",
@@ -359,6 +1078,37 @@ func (s *DownloadTokenService) generateFallbackToken() string {
 	return hex.EncodeToString(hash[:])
 }
 
+func downloadFileName(template *domain.Template) string {
+	format := ""
+	if template.FileFormat != nil {
+		format = *template.FileFormat
+	}
+	return fmt.Sprintf("%s_%s.%s", template.Slug, template.CurrentVersion, getFileExtension(format))
+}
+
+func templateFileSize(template *domain.Template) int64 {
+	if template.FileSizeMB == nil {
+		return 0
+	}
+	return int64(*template.FileSizeMB * 1024 * 1024)
+}
+
+// totalFileSize reads the full size from Content-Range on a partial
+// response, or Content-Length on a full one. It returns -1 if unknown.
+func totalFileSize(resp *http.Response) int64 {
+	if resp.StatusCode == http.StatusOK {
+		return resp.ContentLength
+	}
+
+	contentRange := resp.Header.Get("Content-Range")
+	if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+		if size, err := strconv.ParseInt(contentRange[i+1:], 10, 64); err == nil {
+			return size
+		}
+	}
+	return -1
+}
+
 func getFileExtension(format string) string {
 	switch format {
 	case "XLSX":
@@ -16,18 +16,19 @@ import (
 )
 
 type EmailService struct {
-	cfg    *config.Config
-	dialer *gomail.Dialer
+	cfg             *config.Config
+	dialer          *gomail.Dialer
+	settingsService *SettingsService
 }
 
-func NewEmailService(cfg *config.Config) *EmailService {
+func NewEmailService(cfg *config.Config, settingsService *SettingsService) *EmailService {
 	dialer := gomail.NewDialer(
 		cfg.Email.SMTPHost,
 		cfg.Email.SMTPPort,
 		cfg.Email.SMTPUsername,
 		cfg.Email.SMTPPassword,
 	)
-	return &EmailService{cfg: cfg, dialer: dialer}
+	return &EmailService{cfg: cfg, dialer: dialer, settingsService: settingsService}
 }
 
 // ============================================================================
@@ -68,6 +69,7 @@ func (s *EmailService) SendOrderReceived(ctx context.Context, order *domain.Orde
 		"ItemCount":    len(items),
 		"Date":         order.CreatedAt.Format("January 2, 2006"),
 		"TrackingURL":  fmt.Sprintf("%s/order-tracking", s.cfg.Frontend.URL),
+		"SupportEmail": s.supportEmail(ctx),
 		"Year":         time.Now().Year(),
 	}
 
@@ -77,7 +79,7 @@ func (s *EmailService) SendOrderReceived(ctx context.Context, order *domain.Orde
 	}
 
 	m := gomail.NewMessage()
-	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.cfg.Email.FromName, s.cfg.Email.FromEmail))
+	m.SetHeader("From", s.fromHeader(ctx))
 	m.SetHeader("To", order.CustomerEmail)
 	m.SetHeader("Subject", subject)
 	m.SetBody("text/html", htmlBody)
@@ -130,11 +132,13 @@ func (s *EmailService) SendOrderConfirmation(ctx context.Context, order *domain.
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(order.CustomerEmail, subject, htmlBody)
+	return s.sendEmail(ctx, order.CustomerEmail, subject, htmlBody)
 }
 
-// SendOrderApproval — fires after admin approves. Contains download links.
-func (s *EmailService) SendOrderApproval(ctx context.Context, order *domain.Order, downloadTokens []*domain.DownloadToken) error {
+// SendOrderApproval — fires after admin approves. Contains download links
+// and license keys. bundle is optional and adds a single "download
+// everything" ZIP link.
+func (s *EmailService) SendOrderApproval(ctx context.Context, order *domain.Order, downloadTokens []*domain.DownloadToken, bundle *domain.DownloadBundleToken, licenses []*domain.LicenseWithTemplate) error {
 	subject := fmt.Sprintf("Your Downloads Are Ready — %s 🎉", order.OrderNumber)
 
 	downloads := make([]map[string]string, len(downloadTokens))
@@ -145,6 +149,11 @@ func (s *EmailService) SendOrderApproval(ctx context.Context, order *domain.Orde
 		}
 	}
 
+	var bundleURL string
+	if bundle != nil {
+		bundleURL = fmt.Sprintf("%s/download/bundle?token=%s&email=%s", s.cfg.Frontend.URL, bundle.Token, order.CustomerEmail)
+	}
+
 	var expiresAt string
 	if order.DownloadsExpiresAt != nil {
 		expiresAt = order.DownloadsExpiresAt.Format("January 2, 2006")
@@ -154,8 +163,12 @@ func (s *EmailService) SendOrderApproval(ctx context.Context, order *domain.Orde
 		"CustomerName": order.CustomerName,
 		"OrderNumber":  order.OrderNumber,
 		"Downloads":    downloads,
-		"MaxDownloads": 5,
+		"BundleURL":    bundleURL,
+		"Licenses":     licenses,
+		"VerifyURL":    fmt.Sprintf("%s/licenses/verify", s.cfg.Frontend.URL),
+		"MaxDownloads": s.settingsService.GetInt(ctx, domain.SettingDownloadMaxPerToken, 5),
 		"ExpiresAt":    expiresAt,
+		"SupportEmail": s.supportEmail(ctx),
 		"Year":         time.Now().Year(),
 	}
 
@@ -163,7 +176,7 @@ func (s *EmailService) SendOrderApproval(ctx context.Context, order *domain.Orde
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(order.CustomerEmail, subject, htmlBody)
+	return s.sendEmail(ctx, order.CustomerEmail, subject, htmlBody)
 }
 
 // SendOrderRejection — fires after admin rejects.
@@ -174,7 +187,7 @@ func (s *EmailService) SendOrderRejection(ctx context.Context, order *domain.Ord
 		"CustomerName": order.CustomerName,
 		"OrderNumber":  order.OrderNumber,
 		"Reason":       reason,
-		"SupportEmail": "info@merrakisolutions.com",
+		"SupportEmail": s.supportEmail(ctx),
 		"Year":         time.Now().Year(),
 	}
 
@@ -182,7 +195,7 @@ func (s *EmailService) SendOrderRejection(ctx context.Context, order *domain.Ord
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(order.CustomerEmail, subject, htmlBody)
+	return s.sendEmail(ctx, order.CustomerEmail, subject, htmlBody)
 }
 
 // ============================================================================
@@ -203,7 +216,89 @@ func (s *EmailService) SendAdminOrderNotification(ctx context.Context, order *do
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(s.cfg.Email.FromEmail, subject, htmlBody)
+	return s.sendEmail(ctx, s.cfg.Email.FromEmail, subject, htmlBody)
+}
+
+func (s *EmailService) SendAdminDisputeNotification(ctx context.Context, order *domain.Order, status, amount, reason, respondBy string) error {
+	subject := fmt.Sprintf("Payment Dispute (%s) - %s", status, order.OrderNumber)
+	data := map[string]interface{}{
+		"OrderNumber":   order.OrderNumber,
+		"CustomerName":  order.CustomerName,
+		"CustomerEmail": order.CustomerEmail,
+		"Status":        status,
+		"Amount":        amount,
+		"Reason":        reason,
+		"RespondBy":     respondBy,
+		"AdminURL":      fmt.Sprintf("%s/admin/orders/%d", s.cfg.Frontend.AdminURL, order.ID),
+	}
+	htmlBody, err := s.renderTemplate("admin_dispute_notification", data)
+	if err != nil {
+		return err
+	}
+	return s.sendEmail(ctx, s.cfg.Email.FromEmail, subject, htmlBody)
+}
+
+// ============================================================================
+// TEMPLATE UPDATES
+// ============================================================================
+
+// SendTemplateUpdate — tells an opted-in buyer that a new version shipped.
+// servesLatest reflects the download version policy, so the email says
+// whether their existing links now deliver the new file.
+func (s *EmailService) SendTemplateUpdate(ctx context.Context, subscriber *domain.TemplateUpdateSubscriber, tmpl *domain.Template, version *domain.TemplateVersion, servesLatest bool) error {
+	subject := fmt.Sprintf("%s %s is available", tmpl.Name, version.VersionNumber)
+	data := map[string]interface{}{
+		"CustomerName":     subscriber.CustomerName,
+		"OrderNumber":      subscriber.OrderNumber,
+		"TemplateName":     tmpl.Name,
+		"Version":          version.VersionNumber,
+		"PurchasedVersion": derefStr(subscriber.PurchasedVersion),
+		"Changelog":        derefStr(version.Changelog),
+		"ServesLatest":     servesLatest,
+		"TrackingURL":      fmt.Sprintf("%s/order-tracking", s.cfg.Frontend.URL),
+		"SupportEmail":     s.supportEmail(ctx),
+		"Year":             time.Now().Year(),
+	}
+	htmlBody, err := s.renderTemplate("template_update", data)
+	if err != nil {
+		return err
+	}
+	return s.sendEmail(ctx, subscriber.CustomerEmail, subject, htmlBody)
+}
+
+// ============================================================================
+// CUSTOMER ACCOUNTS
+// ============================================================================
+
+// SendCustomerMagicLink — single-use sign-in link for the customer portal.
+func (s *EmailService) SendCustomerMagicLink(ctx context.Context, email, link string, expiresIn time.Duration) error {
+	subject := "Your Merraki sign-in link"
+	data := map[string]interface{}{
+		"Link":         link,
+		"ExpiresIn":    int(expiresIn.Minutes()),
+		"SupportEmail": s.supportEmail(ctx),
+		"Year":         time.Now().Year(),
+	}
+	htmlBody, err := s.renderTemplate("customer_magic_link", data)
+	if err != nil {
+		return err
+	}
+	return s.sendEmail(ctx, email, subject, htmlBody)
+}
+
+// SendOrderLookupCode — one-time code that unlocks guest order lookup.
+func (s *EmailService) SendOrderLookupCode(ctx context.Context, email, code string, expiresIn time.Duration) error {
+	subject := fmt.Sprintf("%s is your Merraki verification code", code)
+	data := map[string]interface{}{
+		"Code":      code,
+		"ExpiresIn": int(expiresIn.Minutes()),
+		"Year":      time.Now().Year(),
+	}
+	htmlBody, err := s.renderTemplate("order_lookup_code", data)
+	if err != nil {
+		return err
+	}
+	return s.sendEmail(ctx, email, subject, htmlBody)
 }
 
 // ============================================================================
@@ -217,7 +312,7 @@ func (s *EmailService) SendNewsletterWelcome(ctx context.Context, email, name st
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(email, subject, htmlBody)
+	return s.sendEmail(ctx, email, subject, htmlBody)
 }
 
 func (s *EmailService) SendNewsletterCampaign(ctx context.Context, email, name, subject, content string) error {
@@ -226,7 +321,7 @@ func (s *EmailService) SendNewsletterCampaign(ctx context.Context, email, name,
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(email, subject, htmlBody)
+	return s.sendEmail(ctx, email, subject, htmlBody)
 }
 
 func (s *EmailService) SendNewsletterConfirmation(ctx context.Context, email, name string) error {
@@ -236,7 +331,7 @@ func (s *EmailService) SendNewsletterConfirmation(ctx context.Context, email, na
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(email, subject, htmlBody)
+	return s.sendEmail(ctx, email, subject, htmlBody)
 }
 
 // ============================================================================
@@ -250,7 +345,7 @@ func (s *EmailService) SendContactReply(ctx context.Context, email, name, replyM
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(email, subject, htmlBody)
+	return s.sendEmail(ctx, email, subject, htmlBody)
 }
 
 func (s *EmailService) SendContactNotificationToAdmin(ctx context.Context, contact *domain.Contact) error {
@@ -267,16 +362,16 @@ func (s *EmailService) SendContactNotificationToAdmin(ctx context.Context, conta
 	if err != nil {
 		return err
 	}
-	return s.sendEmail(s.cfg.Email.FromEmail, subject, htmlBody)
+	return s.sendEmail(ctx, s.cfg.Email.FromEmail, subject, htmlBody)
 }
 
 // ============================================================================
 // CORE SEND
 // ============================================================================
 
-func (s *EmailService) sendEmail(to, subject, htmlBody string) error {
+func (s *EmailService) sendEmail(ctx context.Context, to, subject, htmlBody string) error {
 	m := gomail.NewMessage()
-	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.cfg.Email.FromName, s.cfg.Email.FromEmail))
+	m.SetHeader("From", s.fromHeader(ctx))
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", subject)
 	m.SetBody("text/html", htmlBody)
@@ -290,6 +385,16 @@ func (s *EmailService) sendEmail(to, subject, htmlBody string) error {
 	return nil
 }
 
+// fromHeader uses the store name from settings, falling back to EMAIL_FROM_NAME.
+func (s *EmailService) fromHeader(ctx context.Context) string {
+	name := s.settingsService.GetString(ctx, domain.SettingStoreName, s.cfg.Email.FromName)
+	return fmt.Sprintf("%s <%s>", name, s.cfg.Email.FromEmail)
+}
+
+func (s *EmailService) supportEmail(ctx context.Context) string {
+	return s.settingsService.GetString(ctx, domain.SettingSupportEmail, "info@merrakisolutions.com")
+}
+
 // ============================================================================
 // TEMPLATE RENDERING
 // ============================================================================
@@ -305,13 +410,17 @@ func (s *EmailService) renderTemplate(templateName string, data interface{}) (st
 
 func (s *EmailService) getEmailTemplate(name string) *template.Template {
 	templates := map[string]string{
-		"order_received":           orderReceivedTemplate,
-		"order_confirmation":       orderConfirmationTemplate,
-		"order_approval":           orderApprovalTemplate,
-		"order_rejection":          orderRejectionTemplate,
-		"newsletter_welcome":       newsletterWelcomeTemplate,
-		"contact_reply":            contactReplyTemplate,
-		"admin_order_notification": adminOrderNotificationTemplate,
+		"order_received":             orderReceivedTemplate,
+		"order_confirmation":         orderConfirmationTemplate,
+		"order_approval":             orderApprovalTemplate,
+		"order_rejection":            orderRejectionTemplate,
+		"newsletter_welcome":         newsletterWelcomeTemplate,
+		"contact_reply":              contactReplyTemplate,
+		"admin_order_notification":   adminOrderNotificationTemplate,
+		"admin_dispute_notification": adminDisputeNotificationTemplate,
+		"customer_magic_link":        customerMagicLinkTemplate,
+		"order_lookup_code":          orderLookupCodeTemplate,
+		"template_update":            templateUpdateTemplate,
 	}
 
 	tmplString, exists := templates[name]
@@ -408,7 +517,7 @@ const orderReceivedTemplate = `
       <a href="{{.TrackingURL}}" class="btn">Track Your Order →</a>
     </div>
     <p style="font-size:13px;color:#9898AE;text-align:center;margin:0">
-      Questions? Reply to this email or contact <a href="mailto:info@merrakisolutions.com" style="color:#3B7BF6">info@merrakisolutions.com</a>
+      Questions? Reply to this email or contact <a href="mailto:{{.SupportEmail}}" style="color:#3B7BF6">{{.SupportEmail}}</a>
     </p>
   </div>
   <div class="foot">
@@ -458,6 +567,8 @@ const orderApprovalTemplate = `
   .body{padding:36px 40px}
   .dl-btn{display:block;background:linear-gradient(135deg,#3B7BF6,#7AABFF);color:#fff;text-decoration:none;border-radius:12px;padding:14px 24px;font-size:15px;font-weight:700;text-align:center;margin:10px 0}
   .notice{background:#DCFCE7;border-left:4px solid #059669;border-radius:0 8px 8px 0;padding:14px 18px;font-size:13px;color:#065F46;margin:20px 0}
+  .license{background:#F5F7FB;border-radius:8px;padding:12px 16px;margin:8px 0;font-size:13px}
+  .license code{display:block;margin-top:6px;font-size:11px;color:#5A5A72;word-break:break-all}
   .foot{background:#F5F7FB;padding:24px 40px;text-align:center;font-size:12px;color:#9898AE}
 </style></head>
 <body>
@@ -470,10 +581,23 @@ const orderApprovalTemplate = `
     {{range .Downloads}}
     <a href="{{.url}}" class="dl-btn">⬇ Download {{.name}}</a>
     {{end}}
+    {{if .BundleURL}}
+    <a href="{{.BundleURL}}" class="dl-btn" style="background:linear-gradient(135deg,#059669,#34D399)">⬇ Download everything (ZIP)</a>
+    {{end}}
     <div class="notice">
       ⏰ Links expire on <strong>{{.ExpiresAt}}</strong> · Max {{.MaxDownloads}} downloads per template · Keep these links private
     </div>
-    <p style="color:#9898AE;font-size:13px">Questions? <a href="mailto:info@merrakisolutions.com" style="color:#3B7BF6">info@merrakisolutions.com</a></p>
+    {{if .Licenses}}
+    <p><strong>Your License Keys:</strong></p>
+    {{range .Licenses}}
+    <div class="license">
+      <div style="font-weight:600">{{.TemplateName}} · {{.Tier}}{{if gt .Seats 1}} ({{.Seats}} seats){{end}}</div>
+      <code>{{.LicenseKey}}</code>
+    </div>
+    {{end}}
+    <p style="font-size:13px;color:#5A5A72">Keep these keys as proof of purchase. Anyone can check a key at <a href="{{.VerifyURL}}" style="color:#3B7BF6">{{.VerifyURL}}</a>.</p>
+    {{end}}
+    <p style="color:#9898AE;font-size:13px">Questions? <a href="mailto:{{.SupportEmail}}" style="color:#3B7BF6">{{.SupportEmail}}</a></p>
   </div>
   <div class="foot">© {{.Year}} Merraki Solutions</div>
 </div>
@@ -568,3 +692,94 @@ const adminOrderNotificationTemplate = `
   <div class="foot">Merraki Admin Panel</div>
 </div>
 </body></html>`
+
+const adminDisputeNotificationTemplate = `
+<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><style>
+  body{margin:0;padding:0;background:#F5F7FB;font-family:"Helvetica Neue",Arial,sans-serif}
+  .wrap{max-width:600px;margin:32px auto;background:#fff;border-radius:16px;overflow:hidden;box-shadow:0 4px 24px rgba(10,10,20,0.08)}
+  .head{background:linear-gradient(135deg,#DC2626,#F87171);padding:32px 40px;text-align:center}
+  .head h1{margin:0;color:#fff;font-size:22px;font-weight:800}
+  .body{padding:32px 40px}
+  .info-box{background:#F5F7FB;border-radius:12px;padding:20px 24px;margin:20px 0}
+  .info-row{display:flex;justify-content:space-between;margin-bottom:10px;font-size:14px}
+  .info-label{color:#9898AE}
+  .info-val{font-weight:600;color:#0A0A0F}
+  .btn{display:inline-block;background:linear-gradient(135deg,#3B7BF6,#7AABFF);color:#fff;text-decoration:none;border-radius:12px;padding:14px 32px;font-size:15px;font-weight:700}
+  .foot{background:#F5F7FB;padding:20px 40px;text-align:center;font-size:12px;color:#9898AE}
+</style></head>
+<body>
+<div class="wrap">
+  <div class="head"><h1>⚠️ Payment Dispute: {{.Status}}</h1></div>
+  <div class="body">
+    <p style="color:#5A5A72;font-size:14px">The payment gateway reported a dispute on this order. Downloads are suspended while the dispute is open.</p>
+    <div class="info-box">
+      <div class="info-row"><span class="info-label">Order</span><span class="info-val">{{.OrderNumber}}</span></div>
+      <div class="info-row"><span class="info-label">Customer</span><span class="info-val">{{.CustomerName}}</span></div>
+      <div class="info-row"><span class="info-label">Email</span><span class="info-val">{{.CustomerEmail}}</span></div>
+      <div class="info-row"><span class="info-label">Disputed Amount</span><span class="info-val" style="color:#DC2626;font-size:16px">{{.Amount}}</span></div>
+      {{if .Reason}}<div class="info-row"><span class="info-label">Reason</span><span class="info-val">{{.Reason}}</span></div>{{end}}
+      {{if .RespondBy}}<div class="info-row" style="margin-bottom:0"><span class="info-label">Respond By</span><span class="info-val">{{.RespondBy}}</span></div>{{end}}
+    </div>
+    <div style="text-align:center;margin:24px 0">
+      <a href="{{.AdminURL}}" class="btn">View Order →</a>
+    </div>
+  </div>
+  <div class="foot">Merraki Admin Panel</div>
+</div>
+</body></html>`
+
+const customerMagicLinkTemplate = `
+<!DOCTYPE html>
+<html><head><meta charset="UTF-8"></head>
+<body style="font-family:Arial,sans-serif;color:#333">
+  <div style="max-width:600px;margin:0 auto;padding:20px">
+    <h2 style="color:#3B7BF6">Sign in to Merraki</h2>
+    <p>Click the button below to sign in and view your orders, downloads and invoices.</p>
+    <p style="text-align:center;margin:24px 0">
+      <a href="{{.Link}}" style="display:inline-block;background:#3B7BF6;color:#fff;text-decoration:none;border-radius:8px;padding:12px 28px;font-weight:700">Sign in →</a>
+    </p>
+    <p style="font-size:13px;color:#666">This link expires in {{.ExpiresIn}} minutes and can only be used once. If you didn't request it, you can ignore this email.</p>
+    <p style="font-size:13px;color:#666">Questions? Contact <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></p>
+    <p style="font-size:12px;color:#999">© {{.Year}} Merraki Solutions</p>
+  </div>
+</body></html>`
+
+const orderLookupCodeTemplate = `
+<!DOCTYPE html>
+<html><head><meta charset="UTF-8"></head>
+<body style="font-family:Arial,sans-serif;color:#333">
+  <div style="max-width:600px;margin:0 auto;padding:20px">
+    <h2 style="color:#3B7BF6">Your verification code</h2>
+    <p>Use this code to view your Merraki orders and downloads:</p>
+    <p style="font-size:32px;font-weight:800;letter-spacing:8px;text-align:center;margin:24px 0">{{.Code}}</p>
+    <p style="font-size:13px;color:#666">The code expires in {{.ExpiresIn}} minutes. If you didn't request it, you can ignore this email.</p>
+    <p style="font-size:12px;color:#999">© {{.Year}} Merraki Solutions</p>
+  </div>
+</body></html>`
+
+const templateUpdateTemplate = `
+<!DOCTYPE html>
+<html><head><meta charset="UTF-8"></head>
+<body style="font-family:Arial,sans-serif;color:#333">
+  <div style="max-width:600px;margin:0 auto;padding:20px">
+    <h2 style="color:#3B7BF6">{{.TemplateName}} {{.Version}} is out</h2>
+    <p>Hi {{.CustomerName}},</p>
+    <p>A new version of <strong>{{.TemplateName}}</strong>, which you bought in order <strong>{{.OrderNumber}}</strong>, has been released.</p>
+    {{if .Changelog}}
+    <div style="background:#f5f7fb;border-radius:8px;padding:16px;margin:16px 0;white-space:pre-line">{{.Changelog}}</div>
+    {{end}}
+    {{if .ServesLatest}}
+    <p>Your existing download links now deliver version {{.Version}}.</p>
+    {{else}}
+    <p>Your existing download links keep delivering the version you purchased{{if .PurchasedVersion}} ({{.PurchasedVersion}}){{end}}. Contact us if you would like the new version.</p>
+    {{end}}
+    <p style="text-align:center;margin:24px 0">
+      <a href="{{.TrackingURL}}" style="display:inline-block;background:#3B7BF6;color:#fff;text-decoration:none;border-radius:8px;padding:12px 28px;font-weight:700">View my order →</a>
+    </p>
+    <p style="font-size:13px;color:#666">You are receiving this because you asked to be told about updates. You can turn these emails off for this order in your account.</p>
+    <p style="font-size:13px;color:#666">Questions? Contact <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></p>
+    <p style="font-size:12px;color:#999">© {{.Year}} Merraki Solutions</p>
+  </div>
+</body></html>`
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/crypto"
+	"github.com/merraki/merraki-backend/internal/pkg/imaging"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// IMAGE SERVICE - Responsive renditions of gallery and blog images
+// ============================================================================
+//
+// The original upload is never stored: only re-encoded pixels are, so EXIF
+// (GPS position, camera serials) and anything smuggled in metadata or
+// trailing bytes are dropped along the way.
+
+var defaultRenditionWidths = []int{320, 640, 960, 1280, 1920}
+
+// Enough for a placeholder in either orientation
+const (
+	blurHashXComponents = 4
+	blurHashYComponents = 3
+)
+
+type ImageService struct {
+	storageService *StorageService
+	widths         []int
+	quality        int
+	maxPixels      int
+}
+
+func NewImageService(storageService *StorageService, cfg *config.Config) *ImageService {
+	var widths []int
+	for _, w := range cfg.Image.RenditionWidths {
+		if w > 0 {
+			widths = append(widths, w)
+		}
+	}
+	if len(widths) == 0 {
+		widths = defaultRenditionWidths
+	}
+	sort.Ints(widths)
+
+	quality := cfg.Image.JPEGQuality
+	if quality < 1 || quality > 100 {
+		quality = 82
+	}
+	megapixels := cfg.Image.MaxMegapixels
+	if megapixels <= 0 {
+		megapixels = 25
+	}
+
+	return &ImageService{
+		storageService: storageService,
+		widths:         widths,
+		quality:        quality,
+		maxPixels:      megapixels * 1000 * 1000,
+	}
+}
+
+// ProcessedImage is an uploaded image after processing. Width and Height
+// are of the upright original.
+type ProcessedImage struct {
+	Width      int
+	Height     int
+	BlurHash   string
+	Renditions domain.ImageRenditions
+}
+
+// URL returns the widest rendition, for clients that don't use srcset.
+func (p *ProcessedImage) URL() string {
+	return p.Renditions[len(p.Renditions)-1].URL
+}
+
+// Process decodes an uploaded image and stores a rendition at each
+// configured width narrower than it, plus one at its own width (capped at
+// the widest configured). Renditions are JPEG unless the image has
+// transparency, which JPEG can't carry; those are PNG. Each also gets a
+// WebP copy when that is smaller.
+func (s *ImageService) Process(ctx context.Context, file *multipart.FileHeader, folder string) (*ProcessedImage, error) {
+	if err := s.storageService.ValidateUpload(folder, file.Filename, file.Size); err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := imaging.Decode(data, s.maxPixels)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupported):
+		return nil, fmt.Errorf("%w: only JPEG, PNG, GIF and WebP images can be processed", domain.ErrInvalidInput)
+	case errors.Is(err, imaging.ErrTooLarge):
+		return nil, fmt.Errorf("%w: image is larger than %d megapixels", domain.ErrInvalidInput, s.maxPixels/1000/1000)
+	case err != nil:
+		return nil, fmt.Errorf("%w: image could not be decoded", domain.ErrInvalidInput)
+	}
+
+	return s.ProcessImage(ctx, img, file.Filename, folder)
+}
+
+// ProcessImage stores renditions of an already decoded image, such as a
+// generated template preview. filename only names the stored files.
+func (s *ImageService) ProcessImage(ctx context.Context, img *image.RGBA, filename, folder string) (*ProcessedImage, error) {
+	format := "jpg"
+	if !imaging.Opaque(img) {
+		format = "png"
+	}
+
+	base, err := renditionBaseName(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	result := &ProcessedImage{Width: bounds.Dx(), Height: bounds.Dy()}
+	for _, width := range s.renditionWidths(bounds.Dx()) {
+		resized := imaging.Resize(img, width)
+		if result.BlurHash == "" {
+			result.BlurHash = imaging.BlurHash(resized, blurHashXComponents, blurHashYComponents)
+		}
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, resized, format, s.quality); err != nil {
+			s.DeleteRenditions(ctx, result.Renditions)
+			return nil, err
+		}
+		size := buf.Len()
+
+		name := fmt.Sprintf("%s-%dw.%s", base, width, format)
+		uploaded, err := s.storageService.UploadFromReader(ctx, &buf, name, folder)
+		if err != nil {
+			s.DeleteRenditions(ctx, result.Renditions)
+			return nil, err
+		}
+
+		result.Renditions = append(result.Renditions, domain.ImageRendition{
+			URL:      uploaded.URL,
+			Width:    resized.Bounds().Dx(),
+			Height:   resized.Bounds().Dy(),
+			Format:   format,
+			Bytes:    size,
+			PublicID: uploaded.PublicID,
+		})
+
+		webp, err := s.storeWebP(ctx, resized, size, fmt.Sprintf("%s-%dw.webp", base, width), folder)
+		if err != nil {
+			s.DeleteRenditions(ctx, result.Renditions)
+			return nil, err
+		}
+		result.Renditions[len(result.Renditions)-1].WebP = webp
+	}
+
+	return result, nil
+}
+
+// storeWebP stores img as WebP under name if it beats the size of the
+// primary rendition. The encoder is lossless, so this mostly pays off for
+// graphics and transparent images rather than photos. It returns nil when
+// the WebP copy was not worth keeping.
+func (s *ImageService) storeWebP(ctx context.Context, img image.Image, primaryBytes int, name, folder string) (*domain.ImageVariant, error) {
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, "webp", s.quality); err != nil {
+		return nil, err
+	}
+	size := buf.Len()
+	if size >= primaryBytes {
+		return nil, nil
+	}
+
+	uploaded, err := s.storageService.UploadFromReader(ctx, &buf, name, folder)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ImageVariant{
+		URL:      uploaded.URL,
+		Format:   "webp",
+		Bytes:    size,
+		PublicID: uploaded.PublicID,
+	}, nil
+}
+
+// DeleteRenditions removes stored renditions. Failures are logged, not
+// returned: a leftover file is harmless and the caller has moved on.
+func (s *ImageService) DeleteRenditions(ctx context.Context, renditions domain.ImageRenditions) {
+	for _, r := range renditions {
+		publicIDs := []string{r.PublicID}
+		if r.WebP != nil {
+			publicIDs = append(publicIDs, r.WebP.PublicID)
+		}
+		for _, publicID := range publicIDs {
+			if publicID == "" {
+				continue
+			}
+			if err := s.storageService.DeleteFile(ctx, publicID); err != nil {
+				logger.Warn("Failed to delete image rendition", zap.String("public_id", publicID), zap.Error(err))
+			}
+		}
+	}
+}
+
+// renditionWidths are the configured widths below width, then width itself
+// unless a configured width already caps it.
+func (s *ImageService) renditionWidths(width int) []int {
+	var widths []int
+	for _, w := range s.widths {
+		if w < width {
+			widths = append(widths, w)
+		}
+	}
+
+	largest := width
+	if widest := s.widths[len(s.widths)-1]; largest > widest {
+		largest = widest
+	}
+	if len(widths) == 0 || widths[len(widths)-1] != largest {
+		widths = append(widths, largest)
+	}
+	return widths
+}
+
+// renditionBaseName is a readable, unique stem shared by an image's
+// renditions.
+func renditionBaseName(filename string) (string, error) {
+	stem := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	stem = slug.Make(strings.TrimSuffix(stem, path.Ext(stem)))
+	if len(stem) > 60 {
+		stem = strings.Trim(stem[:60], "-")
+	}
+	if stem == "" {
+		stem = "image"
+	}
+
+	token, err := crypto.GenerateRandomToken(6)
+	if err != nil {
+		return "", err
+	}
+	return stem + "-" + token, nil
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/crypto"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// LICENSE SERVICE - Signed license keys per order item
+// ============================================================================
+
+// licenseKeyPrefix marks the key format version so it can change later
+// without breaking keys already in customers' hands.
+const licenseKeyPrefix = "MRK1-"
+
+type LicenseService struct {
+	licenseRepo   repository.LicenseRepository
+	orderRepo     repository.OrderRepository
+	orderItemRepo repository.OrderItemRepository
+	templateRepo  repository.TemplateRepository
+	privateKey    ed25519.PrivateKey
+}
+
+func NewLicenseService(
+	licenseRepo repository.LicenseRepository,
+	orderRepo repository.OrderRepository,
+	orderItemRepo repository.OrderItemRepository,
+	templateRepo repository.TemplateRepository,
+	cfg *config.Config,
+) (*LicenseService, error) {
+	seed, err := licenseSeed(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LicenseService{
+		licenseRepo:   licenseRepo,
+		orderRepo:     orderRepo,
+		orderItemRepo: orderItemRepo,
+		templateRepo:  templateRepo,
+		privateKey:    ed25519.NewKeyFromSeed(seed),
+	}, nil
+}
+
+// licenseSeed decodes LICENSE_SIGNING_KEY. Without one, a seed is derived
+// from the PASETO key so existing deployments keep working; the API and
+// worker then still agree on the key.
+func licenseSeed(cfg *config.Config) ([]byte, error) {
+	if cfg.License.SigningKey == "" {
+		logger.Warn("LICENSE_SIGNING_KEY not set, deriving license key from PASETO key")
+		sum := sha256.Sum256([]byte("merraki-license-v1:" + cfg.Auth.PasetoKey))
+		return sum[:], nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.License.SigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("LICENSE_SIGNING_KEY must be a base64-encoded %d-byte seed", ed25519.SeedSize)
+	}
+	return seed, nil
+}
+
+// licensePayload is what the key signs. The buyer's email is only present
+// as a hash so a shared key does not leak it.
+type licensePayload struct {
+	Serial     string             `json:"sn"`
+	TemplateID int64              `json:"tid"`
+	Tier       domain.LicenseTier `json:"tier"`
+	Seats      int                `json:"seats"`
+	EmailHash  string             `json:"eh"`
+	IssuedAt   int64              `json:"iat"`
+}
+
+// PublicKey returns the base64 verification key, for offline checks.
+func (s *LicenseService) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.privateKey.Public().(ed25519.PublicKey))
+}
+
+// ============================================================================
+// ISSUE - Called after order approval
+// ============================================================================
+
+// IssueForOrder creates one license per order item. Items that already have
+// a license are left alone, so the job can safely be retried.
+func (s *LicenseService) IssueForOrder(ctx context.Context, orderID int64) (int, error) {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+	if order.Status != domain.OrderStatusApproved {
+		return 0, fmt.Errorf("order must be approved to issue licenses")
+	}
+
+	items, err := s.orderItemRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	issued := 0
+	for _, item := range items {
+		tier, seats := domain.LicenseTierPersonal, 1
+		template, err := s.templateRepo.FindByID(ctx, item.TemplateID)
+		if err != nil {
+			return issued, err
+		}
+		if template != nil && template.LicenseTier.IsValid() {
+			tier, seats = template.LicenseTier, template.LicenseSeats
+		}
+
+		serial, err := crypto.GenerateRandomToken(16)
+		if err != nil {
+			return issued, err
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		key, err := s.sign(licensePayload{
+			Serial:     serial,
+			TemplateID: item.TemplateID,
+			Tier:       tier,
+			Seats:      seats,
+			EmailHash:  licenseEmailHash(order.CustomerEmail),
+			IssuedAt:   now.Unix(),
+		})
+		if err != nil {
+			return issued, err
+		}
+
+		created, err := s.licenseRepo.Create(ctx, &domain.License{
+			Serial:        serial,
+			LicenseKey:    key,
+			OrderID:       order.ID,
+			OrderItemID:   item.ID,
+			TemplateID:    item.TemplateID,
+			CustomerEmail: order.CustomerEmail,
+			CustomerName:  order.CustomerName,
+			Tier:          tier,
+			Seats:         seats,
+			IssuedAt:      now,
+		})
+		if err != nil {
+			return issued, err
+		}
+		if created {
+			issued++
+		}
+	}
+
+	if issued > 0 {
+		logger.Info("Licenses issued",
+			zap.String("order_number", order.OrderNumber),
+			zap.Int("count", issued),
+		)
+	}
+	return issued, nil
+}
+
+// RevokeForOrder revokes every active license on the order, e.g. after a
+// refund or a lost chargeback.
+func (s *LicenseService) RevokeForOrder(ctx context.Context, orderID int64, reason string) (int64, error) {
+	revoked, err := s.licenseRepo.RevokeByOrderID(ctx, orderID, reason)
+	if err != nil {
+		return 0, err
+	}
+	if revoked > 0 {
+		logger.Info("Licenses revoked",
+			zap.Int64("order_id", orderID),
+			zap.Int64("count", revoked),
+			zap.String("reason", reason),
+		)
+	}
+	return revoked, nil
+}
+
+func (s *LicenseService) GetByOrderID(ctx context.Context, orderID int64) ([]*domain.LicenseWithTemplate, error) {
+	return s.licenseRepo.GetByOrderID(ctx, orderID)
+}
+
+// ============================================================================
+// VERIFY - Public
+// ============================================================================
+
+type LicenseVerification struct {
+	Valid        bool                 `json:"valid"`
+	Reason       string               `json:"reason,omitempty"`
+	Serial       string               `json:"serial,omitempty"`
+	Status       domain.LicenseStatus `json:"status,omitempty"`
+	Tier         domain.LicenseTier   `json:"tier,omitempty"`
+	Seats        int                  `json:"seats,omitempty"`
+	TemplateName string               `json:"template_name,omitempty"`
+	TemplateSlug string               `json:"template_slug,omitempty"`
+	LicensedTo   string               `json:"licensed_to,omitempty"`
+	IssuedAt     *time.Time           `json:"issued_at,omitempty"`
+	RevokedAt    *time.Time           `json:"revoked_at,omitempty"`
+}
+
+// Verify checks a key's signature and its current status. A key that is
+// well-formed but not valid is reported in the result rather than as an
+// error; email is optional and, when given, must match the licensee.
+func (s *LicenseService) Verify(ctx context.Context, key, email string) (*LicenseVerification, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("%w: license_key is required", domain.ErrInvalidInput)
+	}
+
+	payload, ok := s.open(key)
+	if !ok {
+		return &LicenseVerification{Valid: false, Reason: "invalid_signature"}, nil
+	}
+
+	license, err := s.licenseRepo.FindBySerial(ctx, payload.Serial)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && license.LicenseKey != key) {
+		return &LicenseVerification{Valid: false, Reason: "unknown_license", Serial: payload.Serial}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LicenseVerification{
+		Valid:        license.Status == domain.LicenseStatusActive,
+		Serial:       license.Serial,
+		Status:       license.Status,
+		Tier:         license.Tier,
+		Seats:        license.Seats,
+		TemplateName: license.TemplateName,
+		TemplateSlug: license.TemplateSlug,
+		LicensedTo:   maskEmail(license.CustomerEmail),
+		IssuedAt:     &license.IssuedAt,
+		RevokedAt:    license.RevokedAt,
+	}
+
+	switch {
+	case license.Status == domain.LicenseStatusRevoked:
+		result.Reason = "revoked"
+	case email != "" && licenseEmailHash(email) != payload.EmailHash:
+		result.Valid = false
+		result.Reason = "email_mismatch"
+	}
+
+	return result, nil
+}
+
+// ============================================================================
+// KEY FORMAT - MRK1-<base64url payload>.<base64url signature>
+// ============================================================================
+
+func (s *LicenseService) sign(payload licensePayload) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	sig := ed25519.Sign(s.privateKey, []byte(licenseKeyPrefix+encoded))
+	return licenseKeyPrefix + encoded + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func (s *LicenseService) open(key string) (*licensePayload, bool) {
+	if !strings.HasPrefix(key, licenseKeyPrefix) {
+		return nil, false
+	}
+	encoded, sigPart, found := strings.Cut(strings.TrimPrefix(key, licenseKeyPrefix), ".")
+	if !found {
+		return nil, false
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return nil, false
+	}
+	publicKey := s.privateKey.Public().(ed25519.PublicKey)
+	if !ed25519.Verify(publicKey, []byte(licenseKeyPrefix+encoded), sig) {
+		return nil, false
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false
+	}
+	var payload licensePayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Serial == "" {
+		return nil, false
+	}
+	return &payload, true
+}
+
+func licenseEmailHash(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:16])
+}
+
+// maskEmail keeps enough of an address to recognise it: "j***@example.com".
+func maskEmail(email string) string {
+	local, domainPart, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domainPart
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"math"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/merraki/merraki-backend/internal/domain"
@@ -21,6 +23,7 @@ type OrderService struct {
 	orderRepo       repository.OrderRepository
 	orderItemRepo   repository.OrderItemRepository
 	templateRepo    repository.TemplateRepository
+	bundleRepo      repository.BundleRepository
 	paymentRepo     repository.PaymentRepository
 	idempotencyRepo repository.IdempotencyKeyRepository
 	transitionRepo  repository.OrderStateTransitionRepository
@@ -28,12 +31,17 @@ type OrderService struct {
 	paymentService  *PaymentService
 	emailService    *EmailService
 	jobRepo         repository.BackgroundJobRepository
+	settingsService *SettingsService
+	approvalRules   *ApprovalRuleService
+	riskService     *RiskService
+	webhooks        *OutboundWebhookService
 }
 
 func NewOrderService(
 	orderRepo repository.OrderRepository,
 	orderItemRepo repository.OrderItemRepository,
 	templateRepo repository.TemplateRepository,
+	bundleRepo repository.BundleRepository,
 	paymentRepo repository.PaymentRepository,
 	idempotencyRepo repository.IdempotencyKeyRepository,
 	transitionRepo repository.OrderStateTransitionRepository,
@@ -41,11 +49,16 @@ func NewOrderService(
 	paymentService *PaymentService,
 	emailService *EmailService,
 	jobRepo repository.BackgroundJobRepository,
+	settingsService *SettingsService,
+	approvalRules *ApprovalRuleService,
+	riskService *RiskService,
+	webhooks *OutboundWebhookService,
 ) *OrderService {
 	return &OrderService{
 		orderRepo:       orderRepo,
 		orderItemRepo:   orderItemRepo,
 		templateRepo:    templateRepo,
+		bundleRepo:      bundleRepo,
 		paymentRepo:     paymentRepo,
 		idempotencyRepo: idempotencyRepo,
 		transitionRepo:  transitionRepo,
@@ -53,6 +66,10 @@ func NewOrderService(
 		paymentService:  paymentService,
 		emailService:    emailService,
 		jobRepo:         jobRepo,
+		settingsService: settingsService,
+		approvalRules:   approvalRules,
+		riskService:     riskService,
+		webhooks:        webhooks,
 	}
 }
 
@@ -67,12 +84,17 @@ type CreateOrderRequest struct {
 	BillingAddress    *domain.BillingAddress `json:"billing_address"`
 	Items             []CreateOrderItem      `json:"items" validate:"required,min=1,dive"`
 	IdempotencyKey    string                 `json:"idempotency_key"`
+	NotifyUpdates     bool                   `json:"notify_updates"`
 	CustomerIP        string                 `json:"-"`
 	CustomerUserAgent string                 `json:"-"`
+	CustomerCountry   string                 `json:"-"`
 }
 
+// CreateOrderItem is either a single template or a bundle, which is
+// expanded into one order item per template it contains
 type CreateOrderItem struct {
-	TemplateID int64 `json:"template_id" validate:"required"`
+	TemplateID int64 `json:"template_id,omitempty" validate:"required_without=BundleID"`
+	BundleID   int64 `json:"bundle_id,omitempty" validate:"required_without=TemplateID"`
 }
 
 func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest) (*domain.Order, error) {
@@ -95,6 +117,22 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 	var subtotalCents int64
 
 	for _, item := range req.Items {
+		if item.BundleID != 0 {
+			if item.TemplateID != 0 {
+				return nil, fmt.Errorf("item must have either template_id or bundle_id, not both")
+			}
+
+			bundleItems, err := s.bundleOrderItems(ctx, item.BundleID)
+			if err != nil {
+				return nil, err
+			}
+			for _, bundleItem := range bundleItems {
+				orderItems = append(orderItems, bundleItem)
+				subtotalCents += bundleItem.PriceUSDCents
+			}
+			continue
+		}
+
 		template, err := s.templateRepo.FindByID(ctx, item.TemplateID)
 		if err != nil || template == nil {
 			return nil, fmt.Errorf("template %d not found", item.TemplateID)
@@ -106,22 +144,17 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 
 		priceCents := template.GetCurrentPriceCents()
 
-		orderItems = append(orderItems, &domain.OrderItem{
-			TemplateID:      template.ID,
-			TemplateName:    template.Name,
-			TemplateSlug:    template.Slug,
-			TemplateVersion: template.CurrentVersion,
-			PriceUSDCents:   priceCents,
-			FileURL:         template.FileURL,
-			FileFormat:      template.FileFormat,
-			FileSizeMB:      template.FileSizeMB,
-		})
+		orderItems = append(orderItems, newOrderItem(template, priceCents))
 
 		subtotalCents += priceCents
 	}
 
 	// 3. Calculate totals (all in cents)
 	taxCents := int64(0)
+	if s.settingsService.GetBool(ctx, domain.SettingTaxEnabled, false) {
+		ratePercent := s.settingsService.GetFloat(ctx, domain.SettingTaxRatePercent, 0)
+		taxCents = int64(math.Round(float64(subtotalCents) * ratePercent / 100))
+	}
 	discountCents := int64(0)
 	totalCents := subtotalCents + taxCents - discountCents
 
@@ -139,12 +172,16 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 		TotalAmountUSDCents:    totalCents,
 		PaymentGateway:         "razorpay",
 		Status:                 domain.OrderStatusPending,
+		NotifyUpdates:          req.NotifyUpdates,
 		Metadata:               make(domain.JSONMap),
 	}
 
 	if req.IdempotencyKey != "" {
 		order.IdempotencyKey = &req.IdempotencyKey
 	}
+	if req.CustomerCountry != "" {
+		order.CustomerCountry = &req.CustomerCountry
+	}
 
 	if req.BillingAddress != nil {
 		order.BillingName = &req.BillingAddress.Name
@@ -195,6 +232,54 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 	return order, nil
 }
 
+func newOrderItem(template *domain.Template, priceCents int64) *domain.OrderItem {
+	return &domain.OrderItem{
+		TemplateID:      template.ID,
+		TemplateName:    template.Name,
+		TemplateSlug:    template.Slug,
+		TemplateVersion: template.CurrentVersion,
+		PriceUSDCents:   priceCents,
+		FileURL:         template.FileURL,
+		FileFormat:      template.FileFormat,
+		FileSizeMB:      template.FileSizeMB,
+	}
+}
+
+// bundleOrderItems snapshots each template in a bundle as its own order
+// item, priced at its prorated share of the bundle price, so downloads and
+// invoices treat them like any other purchase.
+func (s *OrderService) bundleOrderItems(ctx context.Context, bundleID int64) ([]*domain.OrderItem, error) {
+	bundle, err := s.bundleRepo.FindByID(ctx, bundleID)
+	if err != nil {
+		return nil, fmt.Errorf("bundle %d not found", bundleID)
+	}
+	if !bundle.IsAvailable() {
+		return nil, fmt.Errorf("bundle %d is not available for purchase", bundleID)
+	}
+
+	templates, err := s.bundleRepo.GetTemplates(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("bundle %d is not available for purchase", bundleID)
+	}
+	for _, template := range templates {
+		if !template.IsAvailable() {
+			return nil, fmt.Errorf("bundle %d is not available for purchase", bundleID)
+		}
+	}
+
+	prices := bundle.ProratePrices(templates)
+	items := make([]*domain.OrderItem, len(templates))
+	for i, template := range templates {
+		items[i] = newOrderItem(template, prices[i])
+		items[i].BundleID = &bundle.ID
+		items[i].BundleName = &bundle.Name
+	}
+	return items, nil
+}
+
 // ============================================================================
 // INITIATE PAYMENT - Create Razorpay order
 // ============================================================================
@@ -326,6 +411,7 @@ func (s *OrderService) VerifyPayment(ctx context.Context, req *VerifyPaymentRequ
 	}
 
 	// 4. Verify Razorpay signature
+	payment.VerificationAttempts++
 	isValid := s.paymentService.VerifyPaymentSignature(
 		req.RazorpayOrderID,
 		req.RazorpayPaymentID,
@@ -358,16 +444,17 @@ func (s *OrderService) VerifyPayment(ctx context.Context, req *VerifyPaymentRequ
 	payment.Status = domain.PaymentStatusCaptured
 	payment.VerifiedAt = &now
 	payment.CapturedAt = &now
+	s.applyGatewayDetails(ctx, payment)
 	if err := s.paymentRepo.Update(ctx, payment); err != nil {
 		return nil, err
 	}
 
-	// 6. Update order — always send to admin_review
-	order.GatewayPaymentID = &req.RazorpayPaymentID
-	order.Status = domain.OrderStatusAdminReview
-	if err := s.orderRepo.Update(ctx, order); err != nil {
+	// 6. Update order — approval rules decide between approved and admin_review
+	if err := s.orderRepo.MarkPaid(ctx, order.ID, req.RazorpayPaymentID); err != nil {
 		return nil, err
 	}
+	order.GatewayPaymentID = &req.RazorpayPaymentID
+	order.Status = domain.OrderStatusPaid
 
 	// 7. Save idempotency key
 	if req.IdempotencyKey != "" {
@@ -384,12 +471,7 @@ func (s *OrderService) VerifyPayment(ctx context.Context, req *VerifyPaymentRequ
 	_ = s.enqueueJob(ctx, "send_order_received_email", map[string]interface{}{
 		"order_id": order.ID,
 	})
-	_ = s.enqueueJob(ctx, "send_admin_review_notification", map[string]interface{}{
-		"order_id":     order.ID,
-		"order_number": order.OrderNumber,
-		"amount_cents": order.TotalAmountUSDCents,
-		"customer":     order.CustomerEmail,
-	})
+	s.routePaidOrder(ctx, order, payment)
 
 	s.logActivity(ctx, "payment_verified", order.ID, 0, map[string]interface{}{
 		"payment_id":   payment.ID,
@@ -436,6 +518,7 @@ func (s *OrderService) MarkPaymentCaptured(
 	now := time.Now()
 	payment.CapturedAt = &now
 	payment.GatewayPaymentID = &gatewayPaymentID
+	s.applyGatewayDetails(ctx, payment)
 
 	if err := s.paymentRepo.Update(ctx, payment); err != nil {
 		return err
@@ -451,12 +534,11 @@ func (s *OrderService) MarkPaymentCaptured(
 	if order.Status == domain.OrderStatusPending ||
 		order.Status == domain.OrderStatusPaymentInitiated {
 
-		order.Status = domain.OrderStatusPaid // ✅ FIXED
-		order.GatewayPaymentID = &gatewayPaymentID
-
-		if err := s.orderRepo.Update(ctx, order); err != nil {
+		if err := s.orderRepo.MarkPaid(ctx, order.ID, gatewayPaymentID); err != nil {
 			return err
 		}
+		order.Status = domain.OrderStatusPaid
+		order.GatewayPaymentID = &gatewayPaymentID
 
 		s.logActivity(ctx, "payment_captured", order.ID, 0, map[string]interface{}{
 			"payment_id": gatewayPaymentID,
@@ -466,6 +548,8 @@ func (s *OrderService) MarkPaymentCaptured(
 			zap.String("order_number", order.OrderNumber),
 			zap.String("payment_id", gatewayPaymentID),
 		)
+
+		s.routePaidOrder(ctx, order, payment)
 	}
 
 	return nil
@@ -530,12 +614,144 @@ func (s *OrderService) MarkPaymentFailed(
 	return nil
 }
 
+// ============================================================================
+// APPROVAL ROUTING - Rules decide between auto-approval and admin review
+// ============================================================================
+
+// routePaidOrder scores a freshly paid order for risk, then runs the approval
+// rules on it. Passing orders are approved with triggered_by "rules"; the rest
+// go to admin_review with the failing reasons recorded on the transition.
+func (s *OrderService) routePaidOrder(ctx context.Context, order *domain.Order, payment *domain.Payment) {
+	s.webhooks.PublishOrder(ctx, domain.OutboundEventOrderPaid, order)
+
+	if _, err := s.riskService.Assess(ctx, order, payment); err != nil {
+		logger.Error("Failed to score order risk",
+			zap.String("order_number", order.OrderNumber),
+			zap.Error(err),
+		)
+	}
+
+	evaluation, err := s.approvalRules.Evaluate(ctx, order)
+	if err != nil {
+		logger.Error("Failed to evaluate approval rules",
+			zap.String("order_number", order.OrderNumber),
+			zap.Error(err),
+		)
+		evaluation = &domain.ApprovalEvaluation{
+			OrderID: order.ID,
+			Reasons: []string{"Approval rules could not be evaluated"},
+		}
+	}
+
+	metadata := domain.JSONMap{
+		"rules":      evaluation.Results,
+		"risk_score": order.RiskScore,
+	}
+
+	if evaluation.Approved {
+		err := s.orderRepo.AutoApprove(ctx, order.ID, "rules", "All approval rules passed", metadata, s.downloadsExpiresAt(ctx))
+		if err == nil {
+			order.Status = domain.OrderStatusApproved
+
+			_ = s.enqueueJob(ctx, "generate_download_tokens", map[string]interface{}{
+				"order_id": order.ID,
+			})
+			_ = s.enqueueJob(ctx, "send_order_confirmation_email", map[string]interface{}{
+				"order_id":     order.ID,
+				"order_number": order.OrderNumber,
+				"customer":     order.CustomerEmail,
+			})
+
+			s.logActivity(ctx, "order_auto_approved", order.ID, 0, map[string]interface{}{
+				"rules": evaluation.Results,
+			})
+			s.webhooks.PublishOrder(ctx, domain.OutboundEventOrderApproved, order)
+
+			logger.Info("Order auto-approved by rules",
+				zap.String("order_number", order.OrderNumber),
+			)
+			return
+		}
+
+		logger.Error("Auto-approval failed, falling back to admin review",
+			zap.String("order_number", order.OrderNumber),
+			zap.Error(err),
+		)
+		evaluation.Reasons = append(evaluation.Reasons, "Auto-approval failed")
+	}
+
+	reason := strings.Join(evaluation.Reasons, "; ")
+	if err := s.orderRepo.MoveToReview(ctx, order.ID, "rules", reason, metadata); err != nil {
+		logger.Error("Failed to move order to admin review",
+			zap.String("order_number", order.OrderNumber),
+			zap.Error(err),
+		)
+		return
+	}
+	order.Status = domain.OrderStatusAdminReview
+
+	_ = s.enqueueJob(ctx, "send_admin_review_notification", map[string]interface{}{
+		"order_id":     order.ID,
+		"order_number": order.OrderNumber,
+		"amount_cents": order.TotalAmountUSDCents,
+		"customer":     order.CustomerEmail,
+		"reasons":      evaluation.Reasons,
+		"risk_score":   order.RiskScore,
+	})
+}
+
+// applyGatewayDetails copies the payment method and card details from the
+// gateway onto the payment record so risk checks can use them. Failures are
+// logged and ignored; the payment itself is already captured.
+func (s *OrderService) applyGatewayDetails(ctx context.Context, payment *domain.Payment) {
+	if payment.GatewayPaymentID == nil || *payment.GatewayPaymentID == "" {
+		return
+	}
+
+	details, err := s.paymentService.FetchPayment(ctx, *payment.GatewayPaymentID)
+	if err != nil {
+		logger.Warn("Failed to fetch payment details from gateway",
+			zap.String("gateway_payment_id", *payment.GatewayPaymentID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	payment.Method = nullableStr(details.Method)
+	payment.Bank = nullableStr(details.Bank)
+	payment.Wallet = nullableStr(details.Wallet)
+	payment.VPA = nullableStr(details.VPA)
+	if details.Email != "" {
+		payment.CustomerEmail = &details.Email
+	}
+	if last4, ok := details.Card["last4"].(string); ok && last4 != "" {
+		payment.CardLast4 = &last4
+	}
+	if network, ok := details.Card["network"].(string); ok && network != "" {
+		payment.CardNetwork = &network
+	}
+}
+
+// ReassessRisk re-runs risk scoring on an existing order.
+func (s *OrderService) ReassessRisk(ctx context.Context, orderID int64, adminID int64) (*domain.RiskAssessment, error) {
+	assessment, err := s.riskService.Reassess(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, "reassess_order_risk", orderID, adminID, map[string]interface{}{
+		"risk_score": assessment.Score,
+	})
+
+	return assessment, nil
+}
+
 // ============================================================================
 // ADMIN ACTIONS
 // ============================================================================
 
 func (s *OrderService) ApproveOrder(ctx context.Context, orderID int64, adminID int64, notes *string) error {
-	if err := s.orderRepo.Approve(ctx, orderID, adminID, notes); err != nil {
+	if err := s.orderRepo.Approve(ctx, orderID, adminID, notes, s.downloadsExpiresAt(ctx)); err != nil {
 		return err
 	}
 
@@ -556,6 +772,7 @@ func (s *OrderService) ApproveOrder(ctx context.Context, orderID int64, adminID
 	s.logActivity(ctx, "order_approved", order.ID, adminID, map[string]interface{}{
 		"notes": notes,
 	})
+	s.webhooks.PublishOrder(ctx, domain.OutboundEventOrderApproved, order)
 
 	logger.Info("Order approved",
 		zap.String("order_number", order.OrderNumber),
@@ -619,6 +836,7 @@ func (s *OrderService) MarkOrderAsPaid(ctx context.Context, orderID int64, admin
 	})
 
 	s.logActivity(ctx, "order_marked_as_paid", order.ID, 0, nil)
+	s.webhooks.PublishOrder(ctx, domain.OutboundEventOrderPaid, order)
 
 	logger.Info("Order marked as paid",
 		zap.String("order_number", order.OrderNumber),
@@ -678,6 +896,11 @@ func (s *OrderService) generateOrderNumber() string {
 	return fmt.Sprintf("ORD-%s-%s", time.Now().Format("20060102"), generateRandomString(6))
 }
 
+func (s *OrderService) downloadsExpiresAt(ctx context.Context) time.Time {
+	days := s.settingsService.GetInt(ctx, domain.SettingDownloadExpiryDays, 30)
+	return time.Now().AddDate(0, 0, days)
+}
+
 func (s *OrderService) enqueueJob(ctx context.Context, jobType string, payload map[string]interface{}) error {
 	return s.jobRepo.Create(ctx, &domain.BackgroundJob{
 		JobType:     jobType,
@@ -11,15 +11,18 @@ import (
 type NewsletterService struct {
 	newsletterRepo *postgres.NewsletterRepository
 	emailSvc       *EmailService
+	webhooks       *OutboundWebhookService
 }
 
 func NewNewsletterService(
 	newsletterRepo *postgres.NewsletterRepository,
 	emailSvc *EmailService,
+	webhooks *OutboundWebhookService,
 ) *NewsletterService {
 	return &NewsletterService{
 		newsletterRepo: newsletterRepo,
 		emailSvc:       emailSvc,
+		webhooks:       webhooks,
 	}
 }
 
@@ -62,6 +65,13 @@ func (s *NewsletterService) Subscribe(ctx context.Context, req *SubscribeRequest
 	// Send confirmation email
 	_ = s.emailSvc.SendNewsletterConfirmation(ctx, req.Email, req.Name)
 
+	s.webhooks.Publish(ctx, domain.OutboundEventNewsletterSubscribed, map[string]interface{}{
+		"subscriber_id": subscriber.ID,
+		"email":         subscriber.Email,
+		"name":          req.Name,
+		"source":        subscriber.Source,
+	})
+
 	return nil
 }
 
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/jwt"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	lookupCodeDigits      = 6
+	lookupCodeMaxAttempts = 5
+	lookupCodeCooldown    = time.Minute
+	lookupResendCooldown  = 10 * time.Minute
+
+	// Wrong guesses are counted per email over this window, well past any
+	// code's lifetime, so requesting new codes does not buy more guesses
+	lookupAttemptWindow = 24 * time.Hour
+)
+
+// ============================================================================
+// ORDER LOOKUP SERVICE - Emailed one-time codes for guest order access
+// ============================================================================
+
+type OrderLookupService struct {
+	codeStore       repository.OrderLookupCodeStore
+	orderRepo       repository.OrderRepository
+	jobRepo         repository.BackgroundJobRepository
+	downloadService *DownloadTokenService
+	emailService    *EmailService
+	pasetoMaker     *jwt.PasetoMaker
+	cfg             *config.Config
+}
+
+func NewOrderLookupService(
+	codeStore repository.OrderLookupCodeStore,
+	orderRepo repository.OrderRepository,
+	jobRepo repository.BackgroundJobRepository,
+	downloadService *DownloadTokenService,
+	emailService *EmailService,
+	cfg *config.Config,
+) (*OrderLookupService, error) {
+	pasetoMaker, err := jwt.NewPasetoMaker(cfg.Auth.PasetoKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderLookupService{
+		codeStore:       codeStore,
+		orderRepo:       orderRepo,
+		jobRepo:         jobRepo,
+		downloadService: downloadService,
+		emailService:    emailService,
+		pasetoMaker:     pasetoMaker,
+		cfg:             cfg,
+	}, nil
+}
+
+// LookupSession is returned once a code has been verified.
+type LookupSession struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	Email       string `json:"email"`
+}
+
+// ============================================================================
+// CODES
+// ============================================================================
+
+// RequestCode emails a code when the address has orders. The caller sees
+// the same result either way, so it cannot be used to probe for buyers.
+func (s *OrderLookupService) RequestCode(ctx context.Context, email string) error {
+	email = normalizeLookupEmail(email)
+	if email == "" || !strings.Contains(email, "@") {
+		return fmt.Errorf("%w: a valid email is required", domain.ErrInvalidInput)
+	}
+
+	acquired, err := s.codeStore.AcquireCooldown(ctx, "code:"+email, lookupCodeCooldown)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+
+	_, total, err := s.orderRepo.FindByEmail(ctx, email, 1, 0)
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		return nil
+	}
+
+	// A locked-out email would only be sent codes it cannot use
+	attempts, err := s.codeStore.CountAttempts(ctx, email)
+	if err != nil {
+		return err
+	}
+	if attempts >= lookupCodeMaxAttempts {
+		logger.Warn("Order lookup code withheld, too many failed attempts", zap.String("email", email))
+		return nil
+	}
+
+	code, err := generateLookupCode()
+	if err != nil {
+		return fmt.Errorf("failed to generate lookup code: %w", err)
+	}
+
+	ttl := s.cfg.Auth.LookupCodeExpires
+	if err := s.codeStore.SaveCode(ctx, email, hashLookupCode(email, code), ttl); err != nil {
+		return fmt.Errorf("failed to store lookup code: %w", err)
+	}
+
+	return s.emailService.SendOrderLookupCode(ctx, email, code, ttl)
+}
+
+// VerifyCode checks a code and issues a lookup session. A code is burned
+// after a successful check or after too many wrong guesses. Wrong guesses
+// are counted per email, so once the limit is reached no code works until
+// lookupAttemptWindow has passed.
+func (s *OrderLookupService) VerifyCode(ctx context.Context, email, code string) (*LookupSession, error) {
+	email = normalizeLookupEmail(email)
+	code = strings.TrimSpace(code)
+	if email == "" || code == "" {
+		return nil, fmt.Errorf("%w: email and code are required", domain.ErrInvalidInput)
+	}
+
+	invalid := fmt.Errorf("%w: code is invalid or has expired", domain.ErrInvalidInput)
+
+	// The attempt is counted before the code is compared, so parallel
+	// guesses cannot all slip in under the limit
+	stored, err := s.codeStore.RecordAttempt(ctx, email, lookupAttemptWindow)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	if stored.Attempts > lookupCodeMaxAttempts {
+		_ = s.codeStore.DeleteCode(ctx, email)
+		return nil, invalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored.CodeHash), []byte(hashLookupCode(email, code))) != 1 {
+		if stored.Attempts >= lookupCodeMaxAttempts {
+			_ = s.codeStore.DeleteCode(ctx, email)
+			logger.Warn("Order lookup code locked after too many attempts", zap.String("email", email))
+		}
+		return nil, invalid
+	}
+
+	if err := s.codeStore.DeleteCode(ctx, email); err != nil {
+		return nil, err
+	}
+	if err := s.codeStore.ClearAttempts(ctx, email); err != nil {
+		logger.Error("Failed to clear order lookup attempts", zap.String("email", email), zap.Error(err))
+	}
+
+	ttl := s.cfg.Auth.LookupSessionExpires
+	token, err := s.pasetoMaker.CreateLookupToken(email, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LookupSession{
+		AccessToken: token,
+		ExpiresIn:   int(ttl.Seconds()),
+		Email:       email,
+	}, nil
+}
+
+// ============================================================================
+// SESSION-SCOPED DATA
+// ============================================================================
+
+func (s *OrderLookupService) GetOrders(ctx context.Context, email string, page, limit int) ([]*domain.Order, int, error) {
+	offset := (page - 1) * limit
+	return s.orderRepo.FindByEmail(ctx, email, limit, offset)
+}
+
+func (s *OrderLookupService) GetOrderByNumber(ctx context.Context, email, orderNumber string) (*domain.OrderWithItems, error) {
+	order, err := s.orderRepo.FindByOrderNumber(ctx, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// Another buyer's order looks the same as a missing one
+	if !strings.EqualFold(order.CustomerEmail, email) {
+		return nil, domain.ErrNotFound
+	}
+
+	return s.orderRepo.GetWithItems(ctx, order.ID)
+}
+
+func (s *OrderLookupService) GetOrderByID(ctx context.Context, email string, id int64) (*domain.OrderWithItems, error) {
+	order, err := s.orderRepo.GetWithItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Another buyer's order looks the same as a missing one
+	if !strings.EqualFold(order.Order.CustomerEmail, email) {
+		return nil, domain.ErrNotFound
+	}
+
+	return order, nil
+}
+
+func (s *OrderLookupService) GetDownloads(ctx context.Context, email string) ([]*domain.DownloadToken, error) {
+	return s.downloadService.GetTokensByEmail(ctx, email)
+}
+
+// ResendDownloadLinks queues the approval email (which carries the
+// download links) again for an approved order.
+func (s *OrderLookupService) ResendDownloadLinks(ctx context.Context, email, orderNumber string) error {
+	order, err := s.orderRepo.FindByOrderNumber(ctx, orderNumber)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(order.CustomerEmail, email) {
+		return domain.ErrNotFound
+	}
+	if order.Status != domain.OrderStatusApproved || !order.DownloadsEnabled {
+		return fmt.Errorf("%w: downloads are not available for this order", domain.ErrInvalidInput)
+	}
+
+	acquired, err := s.codeStore.AcquireCooldown(ctx, fmt.Sprintf("resend:%d", order.ID), lookupResendCooldown)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return fmt.Errorf("%w: download links were sent recently, please check your inbox", domain.ErrInvalidInput)
+	}
+
+	return s.jobRepo.Create(ctx, &domain.BackgroundJob{
+		JobType:     "send_order_approval_email",
+		Payload:     domain.JSONMap{"order_id": order.ID},
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+	})
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func normalizeLookupEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// hashLookupCode binds the code to the email so a stored hash is useless
+// for any other address.
+func hashLookupCode(email, code string) string {
+	return jwt.HashToken(email + ":" + code)
+}
+
+func generateLookupCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < lookupCodeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", lookupCodeDigits, n.Int64()), nil
+}
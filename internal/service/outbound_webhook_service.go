@@ -0,0 +1,415 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/crypto"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// OUTBOUND WEBHOOK SERVICE - Store events pushed to subscribed systems
+// ============================================================================
+
+const (
+	outboundWebhookTimeout     = 10 * time.Second
+	outboundWebhookMaxAttempts = 6
+	outboundResponseBodyLimit  = 2048
+)
+
+type OutboundWebhookService struct {
+	subRepo         repository.WebhookSubscriptionRepository
+	deliveryRepo    repository.WebhookDeliveryRepository
+	jobRepo         repository.BackgroundJobRepository
+	activityLogRepo repository.ActivityLogRepository
+	httpClient      *http.Client
+}
+
+func NewOutboundWebhookService(
+	subRepo repository.WebhookSubscriptionRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	jobRepo repository.BackgroundJobRepository,
+	activityLogRepo repository.ActivityLogRepository,
+) *OutboundWebhookService {
+	return &OutboundWebhookService{
+		subRepo:         subRepo,
+		deliveryRepo:    deliveryRepo,
+		jobRepo:         jobRepo,
+		activityLogRepo: activityLogRepo,
+		httpClient:      &http.Client{Timeout: outboundWebhookTimeout},
+	}
+}
+
+// ============================================================================
+// SUBSCRIPTIONS
+// ============================================================================
+
+// CreateSubscription stores a new subscription. A signing secret is generated
+// when none is supplied.
+func (s *OutboundWebhookService) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription, createdBy int64) error {
+	if err := validateWebhookSubscription(sub); err != nil {
+		return err
+	}
+
+	if sub.Secret == "" {
+		secret, err := crypto.GenerateRandomToken(24)
+		if err != nil {
+			return fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		sub.Secret = "whsec_" + secret
+	}
+
+	sub.CreatedBy = &createdBy
+	if err := s.subRepo.Create(ctx, sub); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "create_webhook_subscription", sub.ID, createdBy, map[string]interface{}{
+		"name":        sub.Name,
+		"url":         sub.URL,
+		"event_types": sub.EventTypes,
+	})
+
+	return nil
+}
+
+func (s *OutboundWebhookService) GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	return s.subRepo.FindByID(ctx, id)
+}
+
+func (s *OutboundWebhookService) GetAllSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return s.subRepo.GetAll(ctx)
+}
+
+// UpdateSubscription replaces a subscription's settings. An empty secret
+// keeps the current one.
+func (s *OutboundWebhookService) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription, updatedBy int64) error {
+	existing, err := s.subRepo.FindByID(ctx, sub.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateWebhookSubscription(sub); err != nil {
+		return err
+	}
+
+	if sub.Secret == "" {
+		sub.Secret = existing.Secret
+	}
+	sub.CreatedBy = existing.CreatedBy
+	sub.CreatedAt = existing.CreatedAt
+
+	if err := s.subRepo.Update(ctx, sub); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "update_webhook_subscription", sub.ID, updatedBy, map[string]interface{}{
+		"url":            sub.URL,
+		"event_types":    sub.EventTypes,
+		"is_active":      sub.IsActive,
+		"secret_rotated": sub.Secret != existing.Secret,
+	})
+
+	return nil
+}
+
+func (s *OutboundWebhookService) DeleteSubscription(ctx context.Context, id int64, deletedBy int64) error {
+	sub, err := s.subRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.subRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logActivity(ctx, "delete_webhook_subscription", id, deletedBy, map[string]interface{}{
+		"name": sub.Name,
+		"url":  sub.URL,
+	})
+
+	return nil
+}
+
+// ============================================================================
+// PUBLISHING
+// ============================================================================
+
+// Publish records a delivery for every active subscription to the event and
+// queues it for the worker. It never fails the caller; problems are logged.
+// A nil service publishes nothing.
+func (s *OutboundWebhookService) Publish(ctx context.Context, event domain.OutboundEvent, data map[string]interface{}) {
+	if s == nil {
+		return
+	}
+
+	subs, err := s.subRepo.GetActiveForEvent(ctx, string(event))
+	if err != nil {
+		logger.Error("Failed to load webhook subscriptions",
+			zap.String("event", string(event)),
+			zap.Error(err),
+		)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	eventID, err := crypto.GenerateRandomToken(16)
+	if err != nil {
+		logger.Error("Failed to generate webhook event ID", zap.Error(err))
+		return
+	}
+	eventID = "evt_" + eventID
+
+	payload := domain.JSONMap{
+		"id":         eventID,
+		"event":      string(event),
+		"created_at": time.Now().UTC().Format(time.RFC3339),
+		"data":       data,
+	}
+
+	for _, sub := range subs {
+		delivery := &domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        eventID,
+			EventType:      string(event),
+			Payload:        payload,
+			Status:         domain.WebhookDeliveryPending,
+		}
+		if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+			logger.Error("Failed to record webhook delivery",
+				zap.Int64("subscription_id", sub.ID),
+				zap.String("event", string(event)),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if err := s.enqueueDelivery(ctx, delivery.ID); err != nil {
+			logger.Error("Failed to queue webhook delivery",
+				zap.Int64("delivery_id", delivery.ID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// PublishOrder publishes an order event with the order summary as data.
+func (s *OutboundWebhookService) PublishOrder(ctx context.Context, event domain.OutboundEvent, order *domain.Order) {
+	if s == nil || order == nil {
+		return
+	}
+
+	s.Publish(ctx, event, map[string]interface{}{
+		"order_id":               order.ID,
+		"order_number":           order.OrderNumber,
+		"status":                 order.Status,
+		"customer_email":         order.CustomerEmail,
+		"customer_name":          order.CustomerName,
+		"billing_country":        order.BillingCountry,
+		"total_amount_usd_cents": order.TotalAmountUSDCents,
+		"created_at":             order.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (s *OutboundWebhookService) enqueueDelivery(ctx context.Context, deliveryID int64) error {
+	return s.jobRepo.Create(ctx, &domain.BackgroundJob{
+		JobType:     "deliver_outbound_webhook",
+		Payload:     domain.JSONMap{"delivery_id": deliveryID},
+		Status:      domain.JobStatusPending,
+		MaxRetries:  outboundWebhookMaxAttempts,
+		ScheduledAt: time.Now(),
+		Priority:    0,
+	})
+}
+
+// ============================================================================
+// DELIVERY
+// ============================================================================
+
+// Deliver POSTs a delivery to its subscription. The body is signed with the
+// subscription secret:
+//
+//	X-Merraki-Signature: hex(HMAC-SHA256(secret, body))
+//
+// A non-2xx response or transport error is returned so the worker retries
+// the job; finalAttempt marks the delivery failed instead of pending.
+func (s *OutboundWebhookService) Deliver(ctx context.Context, deliveryID int64, finalAttempt bool) error {
+	delivery, err := s.deliveryRepo.FindByID(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil // Subscription was deleted along with its deliveries
+		}
+		return err
+	}
+
+	if delivery.Status == domain.WebhookDeliveryDelivered {
+		return nil
+	}
+
+	sub, err := s.subRepo.FindByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	sendErr := s.send(ctx, sub, delivery, body)
+
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = domain.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+	} else {
+		delivery.Status = domain.WebhookDeliveryPending
+		if finalAttempt {
+			delivery.Status = domain.WebhookDeliveryFailed
+		}
+		delivery.LastError = strPtr(sendErr.Error())
+	}
+
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery); err != nil {
+		logger.Error("Failed to record webhook delivery attempt",
+			zap.Int64("delivery_id", delivery.ID),
+			zap.Error(err),
+		)
+	}
+
+	return sendErr
+}
+
+// send performs one HTTP attempt and stores the response on the delivery.
+func (s *OutboundWebhookService) send(ctx context.Context, sub *domain.WebhookSubscription, delivery *domain.WebhookDelivery, body []byte) error {
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = nil
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Merraki-Webhooks/1.0")
+	req.Header.Set("X-Merraki-Event", delivery.EventType)
+	req.Header.Set("X-Merraki-Event-Id", delivery.EventID)
+	req.Header.Set("X-Merraki-Signature", signWebhookBody(sub.Secret, body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, outboundResponseBodyLimit))
+	status := resp.StatusCode
+	delivery.ResponseStatus = &status
+	delivery.ResponseBody = nullableStr(string(respBody))
+
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("endpoint responded with status %d", status)
+	}
+	return nil
+}
+
+func signWebhookBody(secret string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ============================================================================
+// DELIVERY LOG
+// ============================================================================
+
+func (s *OutboundWebhookService) GetDeliveries(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.WebhookDelivery, int, error) {
+	return s.deliveryRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+}
+
+func (s *OutboundWebhookService) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	return s.deliveryRepo.FindByID(ctx, id)
+}
+
+// Redeliver queues a delivery again with a fresh retry budget.
+func (s *OutboundWebhookService) Redeliver(ctx context.Context, id int64, adminID int64) (*domain.WebhookDelivery, error) {
+	if err := s.deliveryRepo.ResetForRedelivery(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.enqueueDelivery(ctx, id); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.deliveryRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, "redeliver_webhook", delivery.SubscriptionID, adminID, map[string]interface{}{
+		"delivery_id": id,
+		"event_type":  delivery.EventType,
+	})
+
+	return delivery, nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func validateWebhookSubscription(sub *domain.WebhookSubscription) error {
+	sub.Name = strings.TrimSpace(sub.Name)
+	if sub.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+
+	u, err := url.Parse(strings.TrimSpace(sub.URL))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", domain.ErrInvalidInput)
+	}
+	sub.URL = u.String()
+
+	if len(sub.EventTypes) == 0 {
+		return fmt.Errorf("%w: event_types must list at least one event", domain.ErrInvalidInput)
+	}
+	for _, event := range sub.EventTypes {
+		if !domain.IsValidOutboundEvent(event) {
+			return fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidInput, event)
+		}
+	}
+
+	return nil
+}
+
+func (s *OutboundWebhookService) logActivity(ctx context.Context, action string, entityID int64, adminID int64, metadata map[string]interface{}) {
+	if s.activityLogRepo == nil {
+		return
+	}
+
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		Action:     action,
+		EntityType: strPtr("webhook_subscription"),
+		EntityID:   &entityID,
+		AdminID:    &adminID,
+		Details:    domain.JSONMap(metadata),
+	})
+}
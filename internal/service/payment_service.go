@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -198,12 +200,15 @@ type WebhookResult struct {
 	GatewayOrderID    string
 	GatewayPaymentID  string
 	SignatureValid    bool
+	Payload           map[string]interface{}
+	Webhook           *domain.PaymentWebhook
+	Duplicate         bool // already received under the same event ID
 }
 
 func (s *PaymentService) ProcessWebhook(
 	ctx context.Context,
 	payload []byte,
-	signature, sourceIP, userAgent string,
+	signature, eventID, sourceIP, userAgent string,
 ) (*WebhookResult, error) {
 
 	// 1. Verify signature
@@ -225,6 +230,7 @@ func (s *PaymentService) ProcessWebhook(
 
 	// 4. Save webhook (audit)
 	webhook := &domain.PaymentWebhook{
+		WebhookID:         nullableStr(eventID),
 		EventType:         event.Event,
 		GatewayOrderID:    nullableStr(gatewayOrderID),
 		GatewayPaymentID:  nullableStr(gatewayPaymentID),
@@ -236,7 +242,8 @@ func (s *PaymentService) ProcessWebhook(
 		MaxRetries:        3,
 	}
 
-	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+	created, err := s.webhookRepo.CreateUnique(ctx, webhook)
+	if err != nil {
 		return nil, err
 	}
 
@@ -245,6 +252,9 @@ func (s *PaymentService) ProcessWebhook(
 		GatewayOrderID:   gatewayOrderID,
 		GatewayPaymentID: gatewayPaymentID,
 		SignatureValid:   isValid,
+		Payload:          event.Payload,
+		Webhook:          webhook,
+		Duplicate:        !created,
 	}, nil
 }
 
@@ -438,6 +448,119 @@ func (s *PaymentService) createRefundInternal(ctx context.Context, req *CreateRe
 	return &refund, nil
 }
 
+// ============================================================================
+// PAYMENT & SETTLEMENT LISTINGS - Used by reconciliation
+// ============================================================================
+
+// razorpayPageSize is the maximum "count" the listing endpoints accept.
+const razorpayPageSize = 100
+
+type razorpayCollection[T any] struct {
+	Entity string `json:"entity"`
+	Count  int    `json:"count"`
+	Items  []T    `json:"items"`
+}
+
+// RazorpaySettlementItem is one row of the combined settlement recon report.
+type RazorpaySettlementItem struct {
+	EntityID     string `json:"entity_id"`
+	Type         string `json:"type"` // payment, refund, adjustment, ...
+	Amount       int64  `json:"amount"`
+	Fee          int64  `json:"fee"` // includes tax
+	Tax          int64  `json:"tax"`
+	Settled      bool   `json:"settled"`
+	SettlementID string `json:"settlement_id"`
+	SettledAt    int64  `json:"settled_at"`
+	PaymentID    string `json:"payment_id"`
+	OrderID      string `json:"order_id"`
+}
+
+// ListPayments returns every gateway payment created in [from, to).
+func (s *PaymentService) ListPayments(ctx context.Context, from, to time.Time) ([]*RazorpayPayment, error) {
+	var all []*RazorpayPayment
+	for skip := 0; ; skip += razorpayPageSize {
+		params := url.Values{}
+		params.Set("from", strconv.FormatInt(from.Unix(), 10))
+		params.Set("to", strconv.FormatInt(to.Unix()-1, 10))
+		params.Set("count", strconv.Itoa(razorpayPageSize))
+		params.Set("skip", strconv.Itoa(skip))
+
+		var page razorpayCollection[*RazorpayPayment]
+		if err := s.getCollection(ctx, "/payments", params, &page); err != nil {
+			return nil, err
+		}
+
+		all = append(all, page.Items...)
+		if len(page.Items) < razorpayPageSize {
+			return all, nil
+		}
+	}
+}
+
+// ListSettlementRecon returns the settlement recon rows for a single day.
+func (s *PaymentService) ListSettlementRecon(ctx context.Context, day time.Time) ([]*RazorpaySettlementItem, error) {
+	var all []*RazorpaySettlementItem
+	for skip := 0; ; skip += razorpayPageSize {
+		params := url.Values{}
+		params.Set("year", strconv.Itoa(day.Year()))
+		params.Set("month", strconv.Itoa(int(day.Month())))
+		params.Set("day", strconv.Itoa(day.Day()))
+		params.Set("count", strconv.Itoa(razorpayPageSize))
+		params.Set("skip", strconv.Itoa(skip))
+
+		var page razorpayCollection[*RazorpaySettlementItem]
+		if err := s.getCollection(ctx, "/settlements/recon/combined", params, &page); err != nil {
+			return nil, err
+		}
+
+		all = append(all, page.Items...)
+		if len(page.Items) < razorpayPageSize {
+			return all, nil
+		}
+	}
+}
+
+func (s *PaymentService) getCollection(ctx context.Context, path string, params url.Values, dst interface{}) error {
+	_, err := s.circuitBreaker.Execute(ctx, func() (interface{}, error) {
+		return nil, s.getInternal(ctx, path, params, dst)
+	})
+	return err
+}
+
+func (s *PaymentService) getInternal(ctx context.Context, path string, params url.Values, dst interface{}) error {
+	endpoint := s.config.Payment.Razorpay.BaseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.SetBasicAuth(s.config.Payment.Razorpay.KeyID, s.config.Payment.Razorpay.KeySecret)
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("razorpay API error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("razorpay error: %s", string(body))
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
 // ============================================================================
 // HELPERS
 // ============================================================================
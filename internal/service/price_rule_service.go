@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// PRICE RULE SERVICE - Scheduled sales and template price history
+// ============================================================================
+//
+// Rules never touch templates directly. The apply_price_rules job works out
+// each covered template's sale price from the rules active at that moment
+// and writes it, so overlapping rules, edits and cancellations all settle
+// the same way. The job runs when a rule starts or ends, after every admin
+// change, and hourly as a safety net. A sale price set by hand on the
+// template is left alone.
+
+type PriceRuleService struct {
+	priceRuleRepo   repository.PriceRuleRepository
+	templateRepo    repository.TemplateRepository
+	categoryRepo    repository.CategoryRepository
+	jobRepo         repository.BackgroundJobRepository
+	activityLogRepo repository.ActivityLogRepository
+}
+
+func NewPriceRuleService(
+	priceRuleRepo repository.PriceRuleRepository,
+	templateRepo repository.TemplateRepository,
+	categoryRepo repository.CategoryRepository,
+	jobRepo repository.BackgroundJobRepository,
+	activityLogRepo repository.ActivityLogRepository,
+) *PriceRuleService {
+	return &PriceRuleService{
+		priceRuleRepo:   priceRuleRepo,
+		templateRepo:    templateRepo,
+		categoryRepo:    categoryRepo,
+		jobRepo:         jobRepo,
+		activityLogRepo: activityLogRepo,
+	}
+}
+
+type PriceRuleRequest struct {
+	Name              string    `json:"name"`
+	TemplateID        *int64    `json:"template_id"`
+	CategoryID        *int64    `json:"category_id"`
+	SalePriceUSDCents *int64    `json:"sale_price_usd_cents"`
+	DiscountPercent   *int      `json:"discount_percent"`
+	StartsAt          time.Time `json:"starts_at"`
+	EndsAt            time.Time `json:"ends_at"`
+}
+
+// ============================================================================
+// ADMIN - Rules
+// ============================================================================
+
+func (s *PriceRuleService) GetAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.PriceRule, int, error) {
+	return s.priceRuleRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+}
+
+func (s *PriceRuleService) Get(ctx context.Context, id int64) (*domain.PriceRule, error) {
+	return s.priceRuleRepo.FindByID(ctx, id)
+}
+
+func (s *PriceRuleService) Create(ctx context.Context, req PriceRuleRequest, adminID int64) (*domain.PriceRule, error) {
+	rule := &domain.PriceRule{CreatedBy: &adminID}
+	if err := s.apply(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.priceRuleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.scheduleRule(ctx, rule)
+
+	s.logActivity(ctx, "create_price_rule", rule.ID, adminID, domain.JSONMap{
+		"name":      rule.Name,
+		"starts_at": rule.StartsAt,
+		"ends_at":   rule.EndsAt,
+	})
+	return rule, nil
+}
+
+// Update changes a rule that has not yet ended. Templates are repriced by
+// the next apply run.
+func (s *PriceRuleService) Update(ctx context.Context, id int64, req PriceRuleRequest, adminID int64) (*domain.PriceRule, error) {
+	rule, err := s.priceRuleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Status != domain.PriceRuleStatusScheduled && rule.Status != domain.PriceRuleStatusActive {
+		return nil, fmt.Errorf("%w: %s rules cannot be changed", domain.ErrInvalidStateTransition, rule.Status)
+	}
+	if err := s.apply(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.priceRuleRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.scheduleRule(ctx, rule)
+
+	s.logActivity(ctx, "update_price_rule", rule.ID, adminID, domain.JSONMap{
+		"name":      rule.Name,
+		"starts_at": rule.StartsAt,
+		"ends_at":   rule.EndsAt,
+	})
+	return rule, nil
+}
+
+// Cancel stops a rule before it ends; templates it priced go back to their
+// list price, or to another active rule's price, on the next apply run
+func (s *PriceRuleService) Cancel(ctx context.Context, id, adminID int64) (*domain.PriceRule, error) {
+	rule, err := s.priceRuleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Status != domain.PriceRuleStatusScheduled && rule.Status != domain.PriceRuleStatusActive {
+		return nil, fmt.Errorf("%w: rule has already %s", domain.ErrInvalidStateTransition, rule.Status)
+	}
+
+	rule.Status = domain.PriceRuleStatusCancelled
+	if err := s.priceRuleRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.enqueueApply(ctx, time.Now(), false)
+
+	s.logActivity(ctx, "cancel_price_rule", rule.ID, adminID, domain.JSONMap{
+		"name": rule.Name,
+	})
+	return rule, nil
+}
+
+// ============================================================================
+// ADMIN - Price history
+// ============================================================================
+
+func (s *PriceRuleService) GetPriceHistory(ctx context.Context, templateID int64, page, limit int) ([]*domain.TemplatePriceHistory, int, error) {
+	template, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if template == nil {
+		return nil, 0, domain.ErrNotFound
+	}
+	return s.priceRuleRepo.GetHistory(ctx, templateID, limit, (page-1)*limit)
+}
+
+// ============================================================================
+// WORKER - Apply rules
+// ============================================================================
+
+// Apply brings rule statuses up to date and reprices every template an
+// active rule covers, or that a rule priced before. Where rules overlap the
+// lowest price wins. It returns how many templates changed.
+func (s *PriceRuleService) Apply(ctx context.Context) (int, error) {
+	if err := s.priceRuleRepo.UpdateStatuses(ctx, time.Now()); err != nil {
+		return 0, err
+	}
+
+	rules, err := s.priceRuleRepo.GetActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+	templates, err := s.priceRuleRepo.GetAffectedTemplates(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, template := range templates {
+		if template.SaleRuleID == nil && template.SalePriceUSDCents != nil {
+			continue
+		}
+
+		var salePrice *int64
+		var endsAt *time.Time
+		var ruleID *int64
+		if rule, price := bestPriceRule(rules, template); rule != nil {
+			salePrice, endsAt, ruleID = &price, &rule.EndsAt, &rule.ID
+		}
+
+		if equalInt64Ptr(template.SalePriceUSDCents, salePrice) &&
+			equalInt64Ptr(template.SaleRuleID, ruleID) &&
+			equalTimePtr(template.SaleEndsAt, endsAt) {
+			continue
+		}
+
+		err := s.templateRepo.Patch(ctx, template.ID, map[string]interface{}{
+			"sale_price_usd_cents": salePrice,
+			"sale_ends_at":         endsAt,
+			"sale_rule_id":         ruleID,
+		})
+		if err != nil {
+			return changed, fmt.Errorf("failed to reprice template %d: %w", template.ID, err)
+		}
+		changed++
+
+		// A reverted sale is credited to the rule that ended
+		historyRuleID := ruleID
+		if historyRuleID == nil {
+			historyRuleID = template.SaleRuleID
+		}
+		after := *template
+		after.SalePriceUSDCents = salePrice
+		recordPriceChange(ctx, s.priceRuleRepo, template, &after, domain.PriceChangeSourcePriceRule, historyRuleID, nil)
+	}
+
+	return changed, nil
+}
+
+// bestPriceRule returns the covering rule with the lowest sale price for the
+// template, or nil if none discounts it
+func bestPriceRule(rules []*domain.PriceRule, template *domain.Template) (*domain.PriceRule, int64) {
+	var best *domain.PriceRule
+	var bestPrice int64
+	for _, rule := range rules {
+		if !rule.Covers(template) {
+			continue
+		}
+		price, ok := rule.SalePriceFor(template)
+		if !ok {
+			continue
+		}
+		if best == nil || price < bestPrice {
+			best, bestPrice = rule, price
+		}
+	}
+	return best, bestPrice
+}
+
+// recordPriceChange writes a price history entry if the template's list or
+// sale price differs between before and after; before is nil for a new
+// template. History is an audit trail, so failures are logged rather than
+// failing the change itself.
+func recordPriceChange(ctx context.Context, repo repository.PriceRuleRepository, before, after *domain.Template, source domain.PriceChangeSource, ruleID, adminID *int64) {
+	entry := &domain.TemplatePriceHistory{
+		TemplateID:        after.ID,
+		PriceUSDCents:     after.PriceUSDCents,
+		SalePriceUSDCents: after.SalePriceUSDCents,
+		Source:            source,
+		PriceRuleID:       ruleID,
+		ChangedBy:         adminID,
+	}
+	if before != nil {
+		if before.PriceUSDCents == after.PriceUSDCents && equalInt64Ptr(before.SalePriceUSDCents, after.SalePriceUSDCents) {
+			return
+		}
+		entry.PreviousPriceUSDCents = &before.PriceUSDCents
+		entry.PreviousSalePriceUSDCents = before.SalePriceUSDCents
+	}
+
+	if err := repo.CreateHistory(ctx, entry); err != nil {
+		logger.Error("Failed to record template price change",
+			zap.Int64("template_id", after.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// apply validates req and copies it onto rule, which is (re)scheduled
+func (s *PriceRuleService) apply(ctx context.Context, rule *domain.PriceRule, req PriceRuleRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+
+	if (req.TemplateID == nil) == (req.CategoryID == nil) {
+		return fmt.Errorf("%w: set exactly one of template_id and category_id", domain.ErrInvalidInput)
+	}
+	if (req.SalePriceUSDCents == nil) == (req.DiscountPercent == nil) {
+		return fmt.Errorf("%w: set exactly one of sale_price_usd_cents and discount_percent", domain.ErrInvalidInput)
+	}
+	if req.DiscountPercent != nil && (*req.DiscountPercent < 1 || *req.DiscountPercent > 99) {
+		return fmt.Errorf("%w: discount_percent must be between 1 and 99", domain.ErrInvalidInput)
+	}
+
+	if req.TemplateID != nil {
+		template, err := s.templateRepo.FindByID(ctx, *req.TemplateID)
+		if err != nil {
+			return err
+		}
+		if template == nil {
+			return fmt.Errorf("%w: template %d not found", domain.ErrInvalidInput, *req.TemplateID)
+		}
+		if req.SalePriceUSDCents != nil && (*req.SalePriceUSDCents < 0 || *req.SalePriceUSDCents >= template.PriceUSDCents) {
+			return fmt.Errorf("%w: sale_price_usd_cents must be below the template's price", domain.ErrInvalidInput)
+		}
+	} else {
+		if req.SalePriceUSDCents != nil {
+			return fmt.Errorf("%w: category rules take a discount_percent", domain.ErrInvalidInput)
+		}
+		if _, err := s.categoryRepo.FindByID(ctx, *req.CategoryID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return fmt.Errorf("%w: category %d not found", domain.ErrInvalidInput, *req.CategoryID)
+			}
+			return err
+		}
+	}
+
+	now := time.Now()
+	if req.StartsAt.IsZero() {
+		req.StartsAt = now
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", domain.ErrInvalidInput)
+	}
+	if !req.EndsAt.After(now) {
+		return fmt.Errorf("%w: ends_at must be in the future", domain.ErrInvalidInput)
+	}
+
+	rule.Name = req.Name
+	rule.TemplateID = req.TemplateID
+	rule.CategoryID = req.CategoryID
+	rule.SalePriceUSDCents = req.SalePriceUSDCents
+	rule.DiscountPercent = req.DiscountPercent
+	rule.StartsAt = req.StartsAt
+	rule.EndsAt = req.EndsAt
+	rule.Status = domain.PriceRuleStatusScheduled
+	return nil
+}
+
+// scheduleRule queues apply runs for the rule's start, or now if it has
+// already started, and for its end
+func (s *PriceRuleService) scheduleRule(ctx context.Context, rule *domain.PriceRule) {
+	if rule.StartsAt.After(time.Now()) {
+		s.enqueueApply(ctx, rule.StartsAt, true)
+	} else {
+		s.enqueueApply(ctx, time.Now(), false)
+	}
+	s.enqueueApply(ctx, rule.EndsAt, true)
+}
+
+// enqueueApply queues an apply_price_rules run at the given time. Runs at a
+// rule boundary are deduplicated, so rules sharing a start or end time
+// share a run.
+func (s *PriceRuleService) enqueueApply(ctx context.Context, at time.Time, dedupe bool) {
+	if err := enqueuePriceRules(ctx, s.jobRepo, at, dedupe); err != nil {
+		logger.Error("Failed to schedule apply_price_rules job", zap.Time("at", at), zap.Error(err))
+	}
+}
+
+func enqueuePriceRules(ctx context.Context, jobRepo repository.BackgroundJobRepository, at time.Time, dedupe bool) error {
+	job := &domain.BackgroundJob{
+		JobType:     "apply_price_rules",
+		Payload:     make(domain.JSONMap),
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: at,
+	}
+	if !dedupe {
+		return jobRepo.Create(ctx, job)
+	}
+
+	jobID := "apply_price_rules:" + at.UTC().Format(time.RFC3339)
+	job.JobID = &jobID
+	_, err := jobRepo.CreateUnique(ctx, job)
+	return err
+}
+
+func (s *PriceRuleService) logActivity(ctx context.Context, action string, ruleID, adminID int64, details domain.JSONMap) {
+	if s.activityLogRepo == nil {
+		return
+	}
+
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		AdminID:    &adminID,
+		Action:     action,
+		EntityType: strPtr("price_rule"),
+		EntityID:   &ruleID,
+		Details:    details,
+	})
+}
+
+func equalInt64Ptr(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func equalTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"github.com/merraki/merraki-backend/internal/repository/postgres"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// PUBLISHING SERVICE - Scheduled templates and blog posts
+// ============================================================================
+//
+// An admin schedules an item by saving it with the "scheduled" status and a
+// future published_at. That queues a publish_scheduled_content job for the
+// moment it is due, and the hourly scheduler queues one as a safety net.
+// The job flips every due item live in a single UPDATE, so whichever run
+// gets there first publishes it and later runs find nothing to do.
+//
+// There is no catalog cache in the API itself; the storefront caches pages
+// and revalidates on the template.published and blog_post.published
+// webhooks sent here.
+
+const (
+	announcementTemplate = "template"
+	announcementBlogPost = "blog_post"
+
+	// newsletter_campaigns.preview_text is VARCHAR(255)
+	maxPreviewTextLength = 255
+)
+
+type PublishingService struct {
+	templateRepo repository.TemplateRepository
+	postRepo     *postgres.BlogPostRepository
+	campaignRepo repository.NewsletterCampaignRepository
+	jobRepo      repository.BackgroundJobRepository
+	emailService *EmailService
+	webhooks     *OutboundWebhookService
+	cfg          *config.Config
+}
+
+func NewPublishingService(
+	templateRepo repository.TemplateRepository,
+	postRepo *postgres.BlogPostRepository,
+	campaignRepo repository.NewsletterCampaignRepository,
+	jobRepo repository.BackgroundJobRepository,
+	emailService *EmailService,
+	webhooks *OutboundWebhookService,
+	cfg *config.Config,
+) *PublishingService {
+	return &PublishingService{
+		templateRepo: templateRepo,
+		postRepo:     postRepo,
+		campaignRepo: campaignRepo,
+		jobRepo:      jobRepo,
+		emailService: emailService,
+		webhooks:     webhooks,
+		cfg:          cfg,
+	}
+}
+
+// ============================================================================
+// PUBLISHING - Called from the worker
+// ============================================================================
+
+// PublishDue makes every scheduled template and post whose publish time has
+// passed live, and returns how many it published.
+func (s *PublishingService) PublishDue(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	templates, err := s.templateRepo.PublishScheduled(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish scheduled templates: %w", err)
+	}
+	for _, t := range templates {
+		logger.Info("Scheduled template published",
+			zap.Int64("template_id", t.ID),
+			zap.String("slug", t.Slug),
+		)
+		s.webhooks.Publish(ctx, domain.OutboundEventTemplatePublished, map[string]interface{}{
+			"template_id":  t.ID,
+			"slug":         t.Slug,
+			"name":         t.Name,
+			"category_id":  t.CategoryID,
+			"published_at": formatPublishedAt(t.PublishedAt),
+		})
+		if t.AnnounceOnPublish {
+			s.enqueueAnnouncement(ctx, announcementTemplate, t.ID)
+		}
+	}
+
+	posts, err := s.postRepo.PublishScheduled(ctx, now)
+	if err != nil {
+		return len(templates), fmt.Errorf("failed to publish scheduled blog posts: %w", err)
+	}
+	for _, p := range posts {
+		logger.Info("Scheduled blog post published",
+			zap.Int64("post_id", p.ID),
+			zap.String("slug", p.Slug),
+		)
+		s.webhooks.Publish(ctx, domain.OutboundEventBlogPostPublished, map[string]interface{}{
+			"post_id":      p.ID,
+			"slug":         p.Slug,
+			"title":        p.Title,
+			"category_id":  p.CategoryID,
+			"published_at": formatPublishedAt(p.PublishedAt),
+		})
+		if p.AnnounceOnPublish {
+			s.enqueueAnnouncement(ctx, announcementBlogPost, p.ID)
+		}
+	}
+
+	return len(templates) + len(posts), nil
+}
+
+// Announce emails active newsletter subscribers about a template or post
+// that has just been published, recording the send as a newsletter
+// campaign. A retry resumes the same campaign and only emails subscribers
+// still pending; failed sends are logged and not attempted again.
+func (s *PublishingService) Announce(ctx context.Context, contentType string, id int64) (int, error) {
+	campaign, err := s.announcementFor(ctx, contentType, id)
+	if err != nil {
+		return 0, err
+	}
+	if campaign == nil {
+		// Unpublished again before the announcement went out
+		return 0, nil
+	}
+
+	if err := s.campaignRepo.FindOrCreate(ctx, campaign); err != nil {
+		return 0, fmt.Errorf("failed to create campaign: %w", err)
+	}
+	if campaign.Status == "sent" {
+		return 0, nil
+	}
+
+	if _, err := s.campaignRepo.AddRecipients(ctx, campaign.ID); err != nil {
+		return 0, fmt.Errorf("failed to add campaign recipients: %w", err)
+	}
+
+	recipients, err := s.campaignRepo.GetPendingRecipients(ctx, campaign.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get campaign recipients: %w", err)
+	}
+
+	sent := 0
+	for _, r := range recipients {
+		name := ""
+		if r.Name != nil {
+			name = *r.Name
+		}
+
+		if err := s.emailService.SendNewsletterCampaign(ctx, r.Email, name, campaign.Subject, campaign.Content); err != nil {
+			logger.Warn("Failed to send publish announcement",
+				zap.Int64("campaign_id", campaign.ID),
+				zap.Int64("subscriber_id", r.SubscriberID),
+				zap.Error(err),
+			)
+			_ = s.campaignRepo.MarkRecipientFailed(ctx, r.ID, err.Error())
+			continue
+		}
+		_ = s.campaignRepo.MarkRecipientSent(ctx, r.ID)
+		sent++
+	}
+
+	if err := s.campaignRepo.Finish(ctx, campaign.ID); err != nil {
+		return sent, fmt.Errorf("failed to finish campaign: %w", err)
+	}
+
+	return sent, nil
+}
+
+// announcementFor builds the campaign for a published item, or returns nil
+// if the item is gone or no longer live. The slug includes the publish time
+// so each publication gets its own campaign.
+func (s *PublishingService) announcementFor(ctx context.Context, contentType string, id int64) (*domain.NewsletterCampaign, error) {
+	campaign := &domain.NewsletterCampaign{
+		FromName:  s.cfg.Email.FromName,
+		FromEmail: s.cfg.Email.FromEmail,
+		Status:    "draft",
+	}
+
+	switch contentType {
+	case announcementTemplate:
+		t, err := s.templateRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get template: %w", err)
+		}
+		if t == nil || !t.IsAvailable() || t.PublishedAt == nil {
+			return nil, nil
+		}
+
+		summary := t.Description
+		if t.Tagline != nil && *t.Tagline != "" {
+			summary = *t.Tagline
+		}
+
+		campaign.Subject = "New template: " + t.Name
+		campaign.Slug = fmt.Sprintf("announce-template-%d-%d", t.ID, t.PublishedAt.Unix())
+		campaign.PreviewText = &summary
+		campaign.Content = announcementHTML(t.Name, summary,
+			fmt.Sprintf("%s/templates/%s", s.cfg.Frontend.URL, t.Slug), "View template")
+
+	case announcementBlogPost:
+		p, err := s.postRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blog post: %w", err)
+		}
+		if p == nil || p.Status != "published" || p.PublishedAt == nil {
+			return nil, nil
+		}
+
+		summary := ""
+		if p.Excerpt != nil {
+			summary = *p.Excerpt
+		}
+
+		campaign.Subject = p.Title
+		campaign.Slug = fmt.Sprintf("announce-blog-post-%d-%d", p.ID, p.PublishedAt.Unix())
+		campaign.PreviewText = &summary
+		campaign.Content = announcementHTML(p.Title, summary,
+			fmt.Sprintf("%s/blog/%s", s.cfg.Frontend.URL, p.Slug), "Read the post")
+
+	default:
+		return nil, fmt.Errorf("%w: unknown announcement type %q", domain.ErrInvalidInput, contentType)
+	}
+
+	plain := campaign.Subject + "\n\n" + *campaign.PreviewText
+	campaign.PlainText = &plain
+	if preview := []rune(*campaign.PreviewText); len(preview) > maxPreviewTextLength {
+		trimmed := string(preview[:maxPreviewTextLength-1]) + "…"
+		campaign.PreviewText = &trimmed
+	}
+	return campaign, nil
+}
+
+func (s *PublishingService) enqueueAnnouncement(ctx context.Context, contentType string, id int64) {
+	err := s.jobRepo.Create(ctx, &domain.BackgroundJob{
+		JobType: "send_publish_announcement",
+		Payload: domain.JSONMap{
+			"content_type": contentType,
+			"id":           id,
+		},
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error("Failed to enqueue publish announcement",
+			zap.String("content_type", contentType),
+			zap.Int64("id", id),
+			zap.Error(err),
+		)
+	}
+}
+
+// ============================================================================
+// SCHEDULING
+// ============================================================================
+
+// validatePublishAt checks the publish time of an item being scheduled
+func validatePublishAt(publishedAt *time.Time) error {
+	if publishedAt == nil {
+		return apperrors.New("INVALID_PUBLISH_DATE", "published_at is required to schedule publishing", 400)
+	}
+	if !publishedAt.After(time.Now()) {
+		return apperrors.New("INVALID_PUBLISH_DATE", "published_at must be in the future to schedule publishing", 400)
+	}
+	return nil
+}
+
+// patchedStatus returns the status a PATCH leaves an item with
+func patchedStatus(updates map[string]interface{}, current string) string {
+	if status, ok := updates["status"]; ok && status != nil {
+		return fmt.Sprint(status)
+	}
+	return current
+}
+
+// patchedPublishAt returns the published_at a PATCH leaves an item with,
+// storing a parsed time back into updates
+func patchedPublishAt(updates map[string]interface{}, current *time.Time) (*time.Time, error) {
+	value, ok := updates["published_at"]
+	if !ok {
+		return current, nil
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &v, nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, apperrors.New("INVALID_PUBLISH_DATE", "published_at must be an RFC 3339 timestamp", 400)
+		}
+		updates["published_at"] = parsed
+		return &parsed, nil
+	}
+	return nil, apperrors.New("INVALID_PUBLISH_DATE", "published_at must be an RFC 3339 timestamp", 400)
+}
+
+// enqueuePublishing queues a publish_scheduled_content run for at. Items due
+// at the same moment share one job.
+func enqueuePublishing(ctx context.Context, jobRepo repository.BackgroundJobRepository, at time.Time) error {
+	jobID := "publish_scheduled_content:" + at.UTC().Format(time.RFC3339)
+	_, err := jobRepo.CreateUnique(ctx, &domain.BackgroundJob{
+		JobID:       &jobID,
+		JobType:     "publish_scheduled_content",
+		Payload:     make(domain.JSONMap),
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: at,
+	})
+	return err
+}
+
+func announcementHTML(title, summary, link, cta string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h2>%s</h2>", html.EscapeString(title))
+	if summary != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", html.EscapeString(summary))
+	}
+	fmt.Fprintf(&b, `<p><a href="%s">%s</a></p>`, html.EscapeString(link), html.EscapeString(cta))
+	return b.String()
+}
+
+func formatPublishedAt(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
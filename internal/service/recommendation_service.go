@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/repository"
+)
+
+// ============================================================================
+// RECOMMENDATION SERVICE - Related templates for the detail page
+// ============================================================================
+
+// relatedPerTemplate is how many matches the nightly job keeps per template,
+// and so the most the endpoint can return.
+const relatedPerTemplate = 24
+
+type RecommendationService struct {
+	templateRepo repository.TemplateRepository
+}
+
+func NewRecommendationService(templateRepo repository.TemplateRepository) *RecommendationService {
+	return &RecommendationService{
+		templateRepo: templateRepo,
+	}
+}
+
+// GetRelated returns up to limit templates related to the one at slug. The
+// nightly matches come first; when there are too few, as for a template
+// added since the last run, popular templates from the same category and
+// then the whole catalog fill the gap.
+func (s *RecommendationService) GetRelated(ctx context.Context, slug string, limit int) ([]*domain.RelatedTemplate, error) {
+	if limit < 1 || limit > relatedPerTemplate {
+		limit = 6
+	}
+
+	template, err := s.templateRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil || !template.IsAvailable() {
+		return nil, domain.ErrNotFound
+	}
+
+	related, err := s.templateRepo.GetRelated(ctx, template.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range related {
+		r.Reason = relatedReason(r)
+	}
+
+	if len(related) < limit {
+		exclude := make([]int64, 0, len(related))
+		for _, r := range related {
+			exclude = append(exclude, r.ID)
+		}
+
+		popular, err := s.templateRepo.GetPopularRelated(ctx, template, exclude, limit-len(related))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range popular {
+			r.Reason = "popular"
+			if r.SameCategory {
+				r.Reason = "same_category"
+			}
+		}
+		related = append(related, popular...)
+	}
+
+	return related, nil
+}
+
+// Refresh recomputes every template's matches. It runs nightly from the
+// worker and returns how many pairs were stored.
+func (s *RecommendationService) Refresh(ctx context.Context) (int64, error) {
+	return s.templateRepo.RefreshRelated(ctx, relatedPerTemplate)
+}
+
+func relatedReason(r *domain.RelatedTemplate) string {
+	switch {
+	case r.CoPurchases > 0:
+		return "bought_together"
+	case r.SharedTags > 0:
+		return "shared_tags"
+	case r.SameCategory:
+		return "same_category"
+	}
+	return "popular"
+}
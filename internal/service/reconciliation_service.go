@@ -0,0 +1,355 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// RECONCILIATION SERVICE - Daily gateway vs. database payment check
+// ============================================================================
+
+type ReconciliationService struct {
+	reconRepo       repository.ReconciliationRepository
+	paymentRepo     repository.PaymentRepository
+	paymentService  *PaymentService
+	activityLogRepo repository.ActivityLogRepository
+}
+
+func NewReconciliationService(
+	reconRepo repository.ReconciliationRepository,
+	paymentRepo repository.PaymentRepository,
+	paymentService *PaymentService,
+	activityLogRepo repository.ActivityLogRepository,
+) *ReconciliationService {
+	return &ReconciliationService{
+		reconRepo:       reconRepo,
+		paymentRepo:     paymentRepo,
+		paymentService:  paymentService,
+		activityLogRepo: activityLogRepo,
+	}
+}
+
+// ============================================================================
+// RUN
+// ============================================================================
+
+// Run reconciles the payments captured on the given UTC day. Gateway fees and
+// net amounts are copied onto matching payments, settlement IDs are filled in
+// from that day's settlement report, and every mismatch is stored as an item
+// on the run. triggeredBy is nil for the scheduled job.
+func (s *ReconciliationService) Run(ctx context.Context, day time.Time, triggeredBy *int64) (*domain.ReconciliationRun, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	run := &domain.ReconciliationRun{
+		PeriodStart: from,
+		PeriodEnd:   to,
+		Status:      domain.ReconciliationRunning,
+		TriggeredBy: triggeredBy,
+	}
+	if err := s.reconRepo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	items, err := s.reconcile(ctx, run)
+	if err == nil {
+		for _, item := range items {
+			item.RunID = run.ID
+		}
+		err = s.reconRepo.AddItems(ctx, items)
+	}
+
+	run.Status = domain.ReconciliationCompleted
+	run.MismatchCount = len(items)
+	if err != nil {
+		run.Status = domain.ReconciliationFailed
+		run.Error = strPtr(err.Error())
+	}
+
+	if completeErr := s.reconRepo.CompleteRun(ctx, run); completeErr != nil {
+		return nil, completeErr
+	}
+
+	if triggeredBy != nil {
+		s.logActivity(ctx, "run_reconciliation", run.ID, *triggeredBy, map[string]interface{}{
+			"period_start": from.Format("2006-01-02"),
+			"mismatches":   run.MismatchCount,
+		})
+	}
+
+	logger.Info("Payment reconciliation finished",
+		zap.Int64("run_id", run.ID),
+		zap.String("day", from.Format("2006-01-02")),
+		zap.String("status", string(run.Status)),
+		zap.Int("matched", run.MatchedCount),
+		zap.Int("mismatches", run.MismatchCount),
+	)
+
+	return run, err
+}
+
+func (s *ReconciliationService) reconcile(ctx context.Context, run *domain.ReconciliationRun) ([]*domain.ReconciliationItem, error) {
+	var items []*domain.ReconciliationItem
+
+	gatewayPayments, err := s.paymentService.ListPayments(ctx, run.PeriodStart, run.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gateway payments: %w", err)
+	}
+
+	// 1. Every payment the gateway captured must be captured here too
+	capturedAtGateway := make(map[string]bool)
+	for _, gp := range gatewayPayments {
+		if gp.Status != "captured" && gp.Status != "refunded" {
+			continue
+		}
+		capturedAtGateway[gp.ID] = true
+		run.GatewayPaymentCount++
+
+		local := s.findLocalPayment(ctx, gp)
+		if local == nil {
+			items = append(items, gatewayItem(domain.ReconciliationUnknownPayment, gp, nil,
+				"no payment record for this gateway order"))
+			continue
+		}
+
+		switch local.Status {
+		case domain.PaymentStatusCaptured, domain.PaymentStatusRefunded, domain.PaymentStatusDisputed:
+		default:
+			items = append(items, gatewayItem(domain.ReconciliationPendingLocally, gp, local,
+				"captured at the gateway but not locally"))
+			continue
+		}
+
+		if local.AmountUSDCents != gp.Amount {
+			items = append(items, gatewayItem(domain.ReconciliationAmountMismatch, gp, local,
+				"captured amounts differ"))
+			continue
+		}
+
+		run.MatchedCount++
+		s.applyFees(ctx, run, local, gp.Fee, gp.Amount)
+	}
+
+	// 2. Every payment captured here must be captured at the gateway. Payments
+	// created before the window but captured inside it are not in the listing,
+	// so they are fetched individually before being flagged.
+	localPayments, err := s.paymentRepo.GetCapturedBetween(ctx, run.PeriodStart, run.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load captured payments: %w", err)
+	}
+	run.LocalPaymentCount = len(localPayments)
+
+	for _, local := range localPayments {
+		if local.GatewayPaymentID == nil || *local.GatewayPaymentID == "" {
+			items = append(items, localItem(local, nil, "captured locally without a gateway payment ID"))
+			continue
+		}
+		if capturedAtGateway[*local.GatewayPaymentID] {
+			continue
+		}
+
+		gp, err := s.paymentService.FetchPayment(ctx, *local.GatewayPaymentID)
+		if err != nil || (gp.Status != "captured" && gp.Status != "refunded") {
+			items = append(items, localItem(local, gp, "not captured at the gateway"))
+			continue
+		}
+
+		run.MatchedCount++
+		s.applyFees(ctx, run, local, gp.Fee, gp.Amount)
+	}
+
+	// 3. Settlement details for payments settled on this day
+	settlements, err := s.paymentService.ListSettlementRecon(ctx, run.PeriodStart)
+	if err != nil {
+		logger.Warn("Failed to load settlement report, skipping settlement details",
+			zap.Int64("run_id", run.ID),
+			zap.Error(err),
+		)
+		return items, nil
+	}
+
+	for _, row := range settlements {
+		if row.Type != "payment" || !row.Settled || row.EntityID == "" {
+			continue
+		}
+		local, err := s.paymentRepo.FindByGatewayPaymentID(ctx, row.EntityID)
+		if err != nil {
+			continue
+		}
+
+		settlementID := row.SettlementID
+		settledAt := time.Unix(row.SettledAt, 0)
+		if err := s.paymentRepo.UpdateSettlement(ctx, local.ID, row.Fee, row.Amount-row.Fee, &settlementID, &settledAt); err != nil {
+			logger.Warn("Failed to store settlement details",
+				zap.String("gateway_payment_id", row.EntityID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return items, nil
+}
+
+// findLocalPayment matches on the gateway payment ID first, then on the
+// gateway order ID for payments whose capture never reached us.
+func (s *ReconciliationService) findLocalPayment(ctx context.Context, gp *RazorpayPayment) *domain.Payment {
+	if payment, err := s.paymentRepo.FindByGatewayPaymentID(ctx, gp.ID); err == nil && payment != nil {
+		return payment
+	}
+	if gp.OrderID == "" {
+		return nil
+	}
+	if payment, err := s.paymentRepo.FindByGatewayOrderID(ctx, gp.OrderID); err == nil && payment != nil {
+		return payment
+	}
+	return nil
+}
+
+func (s *ReconciliationService) applyFees(ctx context.Context, run *domain.ReconciliationRun, local *domain.Payment, feeCents, amountCents int64) {
+	netCents := amountCents - feeCents
+	if err := s.paymentRepo.UpdateSettlement(ctx, local.ID, feeCents, netCents, nil, nil); err != nil {
+		logger.Warn("Failed to store gateway fee",
+			zap.Int64("payment_id", local.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	run.FeesUpdatedCount++
+	run.GatewayFeeUSDCents += feeCents
+	run.NetAmountUSDCents += netCents
+}
+
+// ============================================================================
+// REPORTS
+// ============================================================================
+
+func (s *ReconciliationService) GetRuns(ctx context.Context, page, limit int) ([]*domain.ReconciliationRun, int, error) {
+	return s.reconRepo.GetRuns(ctx, limit, (page-1)*limit)
+}
+
+func (s *ReconciliationService) GetReport(ctx context.Context, runID int64) (*domain.ReconciliationReport, error) {
+	run, err := s.reconRepo.FindRunByID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.reconRepo.GetItems(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*domain.ReconciliationItem{}
+	}
+
+	return &domain.ReconciliationReport{Run: run, Items: items}, nil
+}
+
+// ExportCSV renders the mismatches of a run as CSV.
+func (s *ReconciliationService) ExportCSV(ctx context.Context, runID int64) ([]byte, error) {
+	report, err := s.GetReport(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	_ = w.Write([]string{
+		"issue", "payment_id", "gateway_payment_id", "gateway_order_id",
+		"local_status", "gateway_status", "local_amount_usd", "gateway_amount_usd", "details",
+	})
+	for _, item := range report.Items {
+		paymentID := ""
+		if item.PaymentID != nil {
+			paymentID = strconv.FormatInt(*item.PaymentID, 10)
+		}
+		_ = w.Write([]string{
+			string(item.Issue),
+			paymentID,
+			derefStr(item.GatewayPaymentID),
+			derefStr(item.GatewayOrderID),
+			derefStr(item.LocalStatus),
+			derefStr(item.GatewayStatus),
+			centsCell(item.LocalAmountUSDCents),
+			centsCell(item.GatewayAmountUSDCents),
+			derefStr(item.Details),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func gatewayItem(issue domain.ReconciliationIssue, gp *RazorpayPayment, local *domain.Payment, details string) *domain.ReconciliationItem {
+	item := &domain.ReconciliationItem{
+		Issue:                 issue,
+		GatewayPaymentID:      nullableStr(gp.ID),
+		GatewayOrderID:        nullableStr(gp.OrderID),
+		GatewayStatus:         nullableStr(gp.Status),
+		GatewayAmountUSDCents: &gp.Amount,
+		Details:               &details,
+	}
+	if local != nil {
+		item.PaymentID = &local.ID
+		item.LocalStatus = strPtr(string(local.Status))
+		item.LocalAmountUSDCents = &local.AmountUSDCents
+	}
+	return item
+}
+
+// localItem flags a payment captured here that the gateway does not confirm.
+// gp is nil when the gateway lookup failed.
+func localItem(local *domain.Payment, gp *RazorpayPayment, details string) *domain.ReconciliationItem {
+	item := &domain.ReconciliationItem{
+		Issue:               domain.ReconciliationMissingInGateway,
+		PaymentID:           &local.ID,
+		GatewayPaymentID:    local.GatewayPaymentID,
+		GatewayOrderID:      nullableStr(local.GatewayOrderID),
+		LocalStatus:         strPtr(string(local.Status)),
+		LocalAmountUSDCents: &local.AmountUSDCents,
+		Details:             &details,
+	}
+	if gp != nil {
+		item.GatewayStatus = nullableStr(gp.Status)
+		item.GatewayAmountUSDCents = &gp.Amount
+	}
+	return item
+}
+
+func centsCell(cents *int64) string {
+	if cents == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", domain.CentsToUSD(*cents))
+}
+
+func (s *ReconciliationService) logActivity(ctx context.Context, action string, entityID int64, adminID int64, metadata map[string]interface{}) {
+	if s.activityLogRepo == nil {
+		return
+	}
+
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		Action:     action,
+		EntityType: strPtr("reconciliation_run"),
+		EntityID:   &entityID,
+		AdminID:    &adminID,
+		Details:    domain.JSONMap(metadata),
+	})
+}
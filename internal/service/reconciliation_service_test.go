@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// ============================================================================
+// RAZORPAY STAND-IN
+// ============================================================================
+
+const (
+	testKeyID     = "rzp_test_key"
+	testKeySecret = "rzp_test_secret"
+)
+
+// fakeRazorpay serves the listing and fetch endpoints reconciliation uses,
+// paging the way Razorpay does with count and skip.
+type fakeRazorpay struct {
+	mu          sync.Mutex
+	payments    []*RazorpayPayment
+	settlements []*RazorpaySettlementItem
+	requests    []*http.Request
+}
+
+func (f *fakeRazorpay) start(t *testing.T) *PaymentService {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{}
+	cfg.Payment.Razorpay = config.RazorpayConfig{
+		KeyID:     testKeyID,
+		KeySecret: testKeySecret,
+		BaseURL:   server.URL,
+	}
+	return NewPaymentService(cfg, nil, fakeCircuitBreakerRepo{})
+}
+
+func (f *fakeRazorpay) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	f.mu.Unlock()
+
+	if key, secret, ok := r.BasicAuth(); !ok || key != testKeyID || secret != testKeySecret {
+		http.Error(w, `{"error":{"code":"BAD_REQUEST_ERROR"}}`, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/payments":
+		from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		to, _ := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+		var matched []*RazorpayPayment
+		for _, p := range f.payments {
+			if p.CreatedAt >= from && p.CreatedAt <= to {
+				matched = append(matched, p)
+			}
+		}
+		writePage(w, r, matched)
+
+	case strings.HasPrefix(r.URL.Path, "/payments/"):
+		id := strings.TrimPrefix(r.URL.Path, "/payments/")
+		for _, p := range f.payments {
+			if p.ID == id {
+				_ = json.NewEncoder(w).Encode(p)
+				return
+			}
+		}
+		http.Error(w, `{"error":{"code":"BAD_REQUEST_ERROR","description":"The id provided does not exist"}}`, http.StatusBadRequest)
+
+	case r.URL.Path == "/settlements/recon/combined":
+		writePage(w, r, f.settlements)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writePage[T any](w http.ResponseWriter, r *http.Request, all []T) {
+	count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+	skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+	if count <= 0 || count > razorpayPageSize {
+		count = 10
+	}
+
+	page := []T{}
+	if skip < len(all) {
+		end := skip + count
+		if end > len(all) {
+			end = len(all)
+		}
+		page = all[skip:end]
+	}
+
+	_ = json.NewEncoder(w).Encode(razorpayCollection[T]{
+		Entity: "collection",
+		Count:  len(page),
+		Items:  page,
+	})
+}
+
+// ============================================================================
+// FAKE REPOSITORIES
+// ============================================================================
+
+type fakeCircuitBreakerRepo struct{}
+
+func (fakeCircuitBreakerRepo) GetByServiceName(ctx context.Context, serviceName string) (*domain.CircuitBreakerState, error) {
+	return nil, domain.ErrNotFound
+}
+func (fakeCircuitBreakerRepo) UpdateState(ctx context.Context, state *domain.CircuitBreakerState) error {
+	return nil
+}
+func (fakeCircuitBreakerRepo) IncrementFailure(ctx context.Context, serviceName string) error {
+	return nil
+}
+func (fakeCircuitBreakerRepo) IncrementSuccess(ctx context.Context, serviceName string) error {
+	return nil
+}
+func (fakeCircuitBreakerRepo) ResetCounts(ctx context.Context, serviceName string) error { return nil }
+
+// fakePaymentRepo implements the lookups reconciliation uses; the embedded
+// interface panics if anything else is called.
+type fakePaymentRepo struct {
+	repository.PaymentRepository
+	payments []*domain.Payment
+}
+
+func (r *fakePaymentRepo) FindByGatewayPaymentID(ctx context.Context, id string) (*domain.Payment, error) {
+	for _, p := range r.payments {
+		if p.GatewayPaymentID != nil && *p.GatewayPaymentID == id {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakePaymentRepo) FindByGatewayOrderID(ctx context.Context, id string) (*domain.Payment, error) {
+	for _, p := range r.payments {
+		if p.GatewayOrderID == id {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakePaymentRepo) GetCapturedBetween(ctx context.Context, from, to time.Time) ([]*domain.Payment, error) {
+	var captured []*domain.Payment
+	for _, p := range r.payments {
+		if p.CapturedAt != nil && !p.CapturedAt.Before(from) && p.CapturedAt.Before(to) {
+			captured = append(captured, p)
+		}
+	}
+	return captured, nil
+}
+
+func (r *fakePaymentRepo) UpdateSettlement(ctx context.Context, id int64, feeCents, netCents int64, settlementID *string, settledAt *time.Time) error {
+	for _, p := range r.payments {
+		if p.ID == id {
+			p.GatewayFeeUSDCents = &feeCents
+			p.NetAmountUSDCents = &netCents
+			if settlementID != nil {
+				p.SettlementID = settlementID
+			}
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+type fakeReconRepo struct {
+	run   *domain.ReconciliationRun
+	items []*domain.ReconciliationItem
+}
+
+func (r *fakeReconRepo) CreateRun(ctx context.Context, run *domain.ReconciliationRun) error {
+	run.ID = 1
+	r.run = run
+	return nil
+}
+func (r *fakeReconRepo) CompleteRun(ctx context.Context, run *domain.ReconciliationRun) error {
+	return nil
+}
+func (r *fakeReconRepo) FindRunByID(ctx context.Context, id int64) (*domain.ReconciliationRun, error) {
+	return r.run, nil
+}
+func (r *fakeReconRepo) GetRuns(ctx context.Context, limit, offset int) ([]*domain.ReconciliationRun, int, error) {
+	return []*domain.ReconciliationRun{r.run}, 1, nil
+}
+func (r *fakeReconRepo) AddItems(ctx context.Context, items []*domain.ReconciliationItem) error {
+	r.items = append(r.items, items...)
+	return nil
+}
+func (r *fakeReconRepo) GetItems(ctx context.Context, runID int64) ([]*domain.ReconciliationItem, error) {
+	return r.items, nil
+}
+
+// ============================================================================
+// TESTS
+// ============================================================================
+
+var reconDay = time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+
+func localPayment(id int64, gatewayPaymentID, gatewayOrderID string, amount int64, status domain.PaymentStatus, capturedAt *time.Time) *domain.Payment {
+	p := &domain.Payment{
+		ID:             id,
+		GatewayOrderID: gatewayOrderID,
+		AmountUSDCents: amount,
+		Status:         status,
+		CapturedAt:     capturedAt,
+	}
+	if gatewayPaymentID != "" {
+		p.GatewayPaymentID = &gatewayPaymentID
+	}
+	return p
+}
+
+func issuesByPayment(items []*domain.ReconciliationItem) map[string]domain.ReconciliationIssue {
+	issues := make(map[string]domain.ReconciliationIssue)
+	for _, item := range items {
+		key := ""
+		if item.GatewayPaymentID != nil {
+			key = *item.GatewayPaymentID
+		}
+		issues[key] = item.Issue
+	}
+	return issues
+}
+
+func TestListPaymentsPaginates(t *testing.T) {
+	gateway := &fakeRazorpay{}
+	inWindow := reconDay.Add(time.Hour).Unix()
+	for i := 0; i < 2*razorpayPageSize+17; i++ {
+		gateway.payments = append(gateway.payments, &RazorpayPayment{
+			ID:        "pay_" + strconv.Itoa(i),
+			Status:    "captured",
+			CreatedAt: inWindow,
+		})
+	}
+	// Outside the window on both sides
+	gateway.payments = append(gateway.payments,
+		&RazorpayPayment{ID: "pay_before", CreatedAt: reconDay.Add(-time.Second).Unix()},
+		&RazorpayPayment{ID: "pay_after", CreatedAt: reconDay.AddDate(0, 0, 1).Unix()},
+	)
+
+	payments := gateway.start(t)
+	got, err := payments.ListPayments(context.Background(), reconDay, reconDay.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("ListPayments: %v", err)
+	}
+
+	if len(got) != 2*razorpayPageSize+17 {
+		t.Fatalf("got %d payments, want %d", len(got), 2*razorpayPageSize+17)
+	}
+	seen := make(map[string]bool)
+	for _, p := range got {
+		if seen[p.ID] {
+			t.Fatalf("payment %s returned twice", p.ID)
+		}
+		seen[p.ID] = true
+	}
+	if len(gateway.requests) != 3 {
+		t.Errorf("made %d requests, want 3 pages", len(gateway.requests))
+	}
+	if to := gateway.requests[0].URL.Query().Get("to"); to != strconv.FormatInt(reconDay.AddDate(0, 0, 1).Unix()-1, 10) {
+		t.Errorf("to = %s, want the last second of the day", to)
+	}
+}
+
+func TestListSettlementReconPaginates(t *testing.T) {
+	gateway := &fakeRazorpay{}
+	for i := 0; i < razorpayPageSize; i++ {
+		gateway.settlements = append(gateway.settlements, &RazorpaySettlementItem{
+			EntityID: "pay_" + strconv.Itoa(i),
+			Type:     "payment",
+		})
+	}
+
+	payments := gateway.start(t)
+	got, err := payments.ListSettlementRecon(context.Background(), reconDay)
+	if err != nil {
+		t.Fatalf("ListSettlementRecon: %v", err)
+	}
+	if len(got) != razorpayPageSize {
+		t.Fatalf("got %d rows, want %d", len(got), razorpayPageSize)
+	}
+	// A full page needs one more request to find the end
+	if len(gateway.requests) != 2 {
+		t.Errorf("made %d requests, want 2", len(gateway.requests))
+	}
+	q := gateway.requests[0].URL.Query()
+	if q.Get("year") != "2026" || q.Get("month") != "3" || q.Get("day") != "14" {
+		t.Errorf("queried %s, want 2026-3-14", gateway.requests[0].URL.RawQuery)
+	}
+}
+
+func TestReconciliationRun(t *testing.T) {
+	created := reconDay.Add(2 * time.Hour).Unix()
+	captured := reconDay.Add(2 * time.Hour)
+	earlier := reconDay.AddDate(0, 0, -1)
+
+	gateway := &fakeRazorpay{
+		payments: []*RazorpayPayment{
+			{ID: "pay_match", OrderID: "order_match", Amount: 1000, Fee: 30, Status: "captured", CreatedAt: created},
+			{ID: "pay_amount", OrderID: "order_amount", Amount: 1500, Fee: 45, Status: "captured", CreatedAt: created},
+			{ID: "pay_pending", OrderID: "order_pending", Amount: 2000, Fee: 60, Status: "captured", CreatedAt: created},
+			{ID: "pay_unknown", OrderID: "order_unknown", Amount: 500, Fee: 15, Status: "captured", CreatedAt: created},
+			{ID: "pay_failed", OrderID: "order_failed", Amount: 700, Status: "failed", CreatedAt: created},
+			// Created the day before, captured inside the window
+			{ID: "pay_late", OrderID: "order_late", Amount: 900, Fee: 27, Status: "captured", CreatedAt: earlier.Unix()},
+			{ID: "pay_authorized", OrderID: "order_authorized", Amount: 800, Status: "authorized", CreatedAt: earlier.Unix()},
+		},
+		settlements: []*RazorpaySettlementItem{
+			{EntityID: "pay_match", Type: "payment", Amount: 1000, Fee: 30, Settled: true, SettlementID: "setl_1", SettledAt: captured.Unix()},
+			{EntityID: "rfnd_1", Type: "refund", Amount: 100, Settled: true, SettlementID: "setl_1"},
+		},
+	}
+
+	paymentRepo := &fakePaymentRepo{payments: []*domain.Payment{
+		localPayment(1, "pay_match", "order_match", 1000, domain.PaymentStatusCaptured, &captured),
+		localPayment(2, "pay_amount", "order_amount", 1200, domain.PaymentStatusCaptured, &captured),
+		// Capture webhook never arrived, so only the gateway order ID is known
+		localPayment(3, "", "order_pending", 2000, domain.PaymentStatusCreated, nil),
+		localPayment(4, "pay_late", "order_late", 900, domain.PaymentStatusCaptured, &captured),
+		localPayment(5, "pay_authorized", "order_authorized", 800, domain.PaymentStatusCaptured, &captured),
+		localPayment(6, "pay_missing", "order_missing", 600, domain.PaymentStatusCaptured, &captured),
+		localPayment(7, "", "order_no_id", 400, domain.PaymentStatusCaptured, &captured),
+	}}
+	reconRepo := &fakeReconRepo{}
+
+	recon := NewReconciliationService(reconRepo, paymentRepo, gateway.start(t), nil)
+	run, err := recon.Run(context.Background(), reconDay.Add(13*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if run.Status != domain.ReconciliationCompleted {
+		t.Errorf("status = %s, want completed", run.Status)
+	}
+	if !run.PeriodStart.Equal(reconDay) || !run.PeriodEnd.Equal(reconDay.AddDate(0, 0, 1)) {
+		t.Errorf("period = %s..%s, want the whole UTC day", run.PeriodStart, run.PeriodEnd)
+	}
+
+	want := map[string]domain.ReconciliationIssue{
+		"pay_amount":     domain.ReconciliationAmountMismatch,
+		"pay_pending":    domain.ReconciliationPendingLocally,
+		"pay_unknown":    domain.ReconciliationUnknownPayment,
+		"pay_authorized": domain.ReconciliationMissingInGateway,
+		"pay_missing":    domain.ReconciliationMissingInGateway,
+		"":               domain.ReconciliationMissingInGateway,
+	}
+	got := issuesByPayment(reconRepo.items)
+	if len(got) != len(want) || len(reconRepo.items) != len(want) {
+		t.Errorf("got %d items %v, want %v", len(reconRepo.items), got, want)
+	}
+	for id, issue := range want {
+		if got[id] != issue {
+			t.Errorf("%q: issue = %q, want %q", id, got[id], issue)
+		}
+	}
+	if run.MismatchCount != len(want) {
+		t.Errorf("mismatch count = %d, want %d", run.MismatchCount, len(want))
+	}
+
+	for _, item := range reconRepo.items {
+		if item.RunID != run.ID {
+			t.Errorf("item %v has run ID %d, want %d", item.GatewayPaymentID, item.RunID, run.ID)
+		}
+		if item.Issue == domain.ReconciliationAmountMismatch &&
+			(*item.LocalAmountUSDCents != 1200 || *item.GatewayAmountUSDCents != 1500) {
+			t.Errorf("amount mismatch recorded %d vs %d, want 1200 vs 1500",
+				*item.LocalAmountUSDCents, *item.GatewayAmountUSDCents)
+		}
+	}
+
+	// Matched: pay_match from the listing, pay_late fetched individually
+	if run.MatchedCount != 2 {
+		t.Errorf("matched = %d, want 2", run.MatchedCount)
+	}
+	if run.GatewayPaymentCount != 4 {
+		t.Errorf("gateway payments = %d, want the 4 captured in the window", run.GatewayPaymentCount)
+	}
+	if run.LocalPaymentCount != 6 {
+		t.Errorf("local payments = %d, want 6", run.LocalPaymentCount)
+	}
+	if run.GatewayFeeUSDCents != 57 || run.NetAmountUSDCents != 1843 {
+		t.Errorf("fees = %d net = %d, want 57 and 1843", run.GatewayFeeUSDCents, run.NetAmountUSDCents)
+	}
+
+	checkFees := func(p *domain.Payment, fee, net int64) {
+		t.Helper()
+		if p.GatewayFeeUSDCents == nil || *p.GatewayFeeUSDCents != fee ||
+			p.NetAmountUSDCents == nil || *p.NetAmountUSDCents != net {
+			t.Errorf("payment %d: fee/net = %v/%v, want %d/%d",
+				p.ID, p.GatewayFeeUSDCents, p.NetAmountUSDCents, fee, net)
+		}
+	}
+	checkFees(paymentRepo.payments[0], 30, 970)
+	checkFees(paymentRepo.payments[3], 27, 873)
+	if paymentRepo.payments[1].GatewayFeeUSDCents != nil {
+		t.Errorf("fees stored for a payment with mismatched amounts")
+	}
+
+	if s := paymentRepo.payments[0].SettlementID; s == nil || *s != "setl_1" {
+		t.Errorf("settlement ID = %v, want setl_1", s)
+	}
+}
+
+func TestReconciliationRunGatewayDown(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Payment.Razorpay = config.RazorpayConfig{BaseURL: "http://127.0.0.1:1"}
+	payments := NewPaymentService(cfg, nil, fakeCircuitBreakerRepo{})
+
+	reconRepo := &fakeReconRepo{}
+	recon := NewReconciliationService(reconRepo, &fakePaymentRepo{}, payments, nil)
+
+	run, err := recon.Run(context.Background(), reconDay, nil)
+	if err == nil {
+		t.Fatal("Run succeeded with the gateway unreachable")
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("circuit opened after a single failure: %v", err)
+	}
+	if run == nil || run.Status != domain.ReconciliationFailed || run.Error == nil {
+		t.Errorf("run = %+v, want it recorded as failed with the error", run)
+	}
+}
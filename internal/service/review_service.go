@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// REVIEW SERVICE - Verified-buyer ratings of templates
+// ============================================================================
+//
+// A review is accepted only from an email with an approved order for the
+// template, and shown only once an admin approves it. The template's
+// rating_average and rating_count are recomputed whenever the set of
+// approved reviews may have changed.
+
+const (
+	minReviewBodyLength  = 10
+	maxReviewBodyLength  = 5000
+	maxReviewTitleLength = 150
+)
+
+type ReviewService struct {
+	reviewRepo      repository.TemplateReviewRepository
+	orderItemRepo   repository.OrderItemRepository
+	orderRepo       repository.OrderRepository
+	templateRepo    repository.TemplateRepository
+	activityLogRepo repository.ActivityLogRepository
+}
+
+func NewReviewService(
+	reviewRepo repository.TemplateReviewRepository,
+	orderItemRepo repository.OrderItemRepository,
+	orderRepo repository.OrderRepository,
+	templateRepo repository.TemplateRepository,
+	activityLogRepo repository.ActivityLogRepository,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:      reviewRepo,
+		orderItemRepo:   orderItemRepo,
+		orderRepo:       orderRepo,
+		templateRepo:    templateRepo,
+		activityLogRepo: activityLogRepo,
+	}
+}
+
+type SubmitReviewRequest struct {
+	TemplateID int64  `json:"template_id"`
+	Rating     int    `json:"rating"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+}
+
+// ============================================================================
+// CUSTOMER
+// ============================================================================
+
+// Submit records a signed-in customer's review of a template they bought.
+// Submitting again replaces their review and sends it back to moderation.
+func (s *ReviewService) Submit(ctx context.Context, customerID int64, email string, req SubmitReviewRequest) (*domain.TemplateReview, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Body = strings.TrimSpace(req.Body)
+
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, fmt.Errorf("%w: rating must be between 1 and 5", domain.ErrInvalidInput)
+	}
+	if n := utf8.RuneCountInString(req.Body); n < minReviewBodyLength || n > maxReviewBodyLength {
+		return nil, fmt.Errorf("%w: review must be between %d and %d characters", domain.ErrInvalidInput, minReviewBodyLength, maxReviewBodyLength)
+	}
+	if utf8.RuneCountInString(req.Title) > maxReviewTitleLength {
+		return nil, fmt.Errorf("%w: title must be at most %d characters", domain.ErrInvalidInput, maxReviewTitleLength)
+	}
+
+	template, err := s.templateRepo.FindByID(ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	item, err := s.orderItemRepo.FindApprovedPurchase(ctx, template.ID, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: only buyers of this template can review it", domain.ErrForbidden)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.FindByID(ctx, item.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &domain.TemplateReview{
+		TemplateID:    template.ID,
+		OrderID:       order.ID,
+		OrderItemID:   item.ID,
+		CustomerID:    &customerID,
+		CustomerEmail: email,
+		ReviewerName:  reviewerName(order.CustomerName),
+		Rating:        req.Rating,
+		Title:         nullableStr(req.Title),
+		Body:          req.Body,
+		Status:        domain.ReviewStatusPending,
+	}
+	if err := s.reviewRepo.Upsert(ctx, review); err != nil {
+		return nil, err
+	}
+
+	// The review may have replaced an approved one
+	s.refreshRating(ctx, template.ID)
+
+	return review, nil
+}
+
+func (s *ReviewService) ListMine(ctx context.Context, email string) ([]*domain.TemplateReview, error) {
+	return s.reviewRepo.GetByEmail(ctx, email)
+}
+
+// ============================================================================
+// PUBLIC
+// ============================================================================
+
+// ListApproved returns a page of a template's approved reviews. sort is
+// "newest" (default), "oldest", "rating_desc" or "rating_asc".
+func (s *ReviewService) ListApproved(ctx context.Context, slug, sort string, page, limit int) ([]*domain.PublicTemplateReview, int, error) {
+	template, err := s.findPublicTemplate(ctx, slug)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filters := map[string]interface{}{
+		"template_id": template.ID,
+		"status":      domain.ReviewStatusApproved,
+		"sort":        sort,
+	}
+	reviews, total, err := s.reviewRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*domain.PublicTemplateReview, len(reviews))
+	for i, review := range reviews {
+		result[i] = review.Public()
+	}
+	return result, total, nil
+}
+
+func (s *ReviewService) GetRatingSummary(ctx context.Context, slug string) (*domain.RatingSummary, error) {
+	template, err := s.findPublicTemplate(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.reviewRepo.GetRatingSummary(ctx, template.ID)
+}
+
+func (s *ReviewService) findPublicTemplate(ctx context.Context, slug string) (*domain.Template, error) {
+	template, err := s.templateRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil || !template.IsAvailable() {
+		return nil, domain.ErrNotFound
+	}
+	return template, nil
+}
+
+// ============================================================================
+// ADMIN - Moderation
+// ============================================================================
+
+func (s *ReviewService) GetAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.TemplateReview, int, error) {
+	return s.reviewRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+}
+
+func (s *ReviewService) Get(ctx context.Context, id int64) (*domain.TemplateReview, error) {
+	return s.reviewRepo.FindByID(ctx, id)
+}
+
+// Moderate approves or rejects a review, or returns it to pending.
+func (s *ReviewService) Moderate(ctx context.Context, id int64, status domain.ReviewStatus, note string, adminID int64) (*domain.TemplateReview, error) {
+	if !status.IsValid() {
+		return nil, fmt.Errorf("%w: status must be pending, approved or rejected", domain.ErrInvalidInput)
+	}
+
+	review, err := s.reviewRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := review.Status
+
+	review.Status = status
+	review.ModerationNote = nullableStr(strings.TrimSpace(note))
+	review.ModeratedBy = &adminID
+	if err := s.reviewRepo.Moderate(ctx, review); err != nil {
+		return nil, err
+	}
+
+	if previous == domain.ReviewStatusApproved || status == domain.ReviewStatusApproved {
+		s.refreshRating(ctx, review.TemplateID)
+	}
+
+	s.logActivity(ctx, "moderate_review", review.ID, adminID, domain.JSONMap{
+		"template_id": review.TemplateID,
+		"from":        previous,
+		"to":          status,
+	})
+
+	return review, nil
+}
+
+func (s *ReviewService) Delete(ctx context.Context, id, adminID int64) error {
+	review, err := s.reviewRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.reviewRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if review.Status == domain.ReviewStatusApproved {
+		s.refreshRating(ctx, review.TemplateID)
+	}
+
+	s.logActivity(ctx, "delete_review", id, adminID, domain.JSONMap{
+		"template_id": review.TemplateID,
+		"rating":      review.Rating,
+	})
+	return nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// refreshRating logs rather than fails: the review itself is saved, and
+// the next change recomputes the rating from scratch.
+func (s *ReviewService) refreshRating(ctx context.Context, templateID int64) {
+	if err := s.reviewRepo.RefreshTemplateRating(ctx, templateID); err != nil {
+		logger.Error("Failed to refresh template rating",
+			zap.Int64("template_id", templateID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *ReviewService) logActivity(ctx context.Context, action string, reviewID, adminID int64, details domain.JSONMap) {
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		AdminID:    &adminID,
+		Action:     action,
+		EntityType: strPtr("template_review"),
+		EntityID:   &reviewID,
+		Details:    details,
+	})
+}
+
+// reviewerName shortens the order's customer name to a first name and last
+// initial, e.g. "Priya Sharma" to "Priya S.".
+func reviewerName(customerName string) string {
+	parts := strings.Fields(customerName)
+	switch len(parts) {
+	case 0:
+		return "Verified buyer"
+	case 1:
+		return parts[0]
+	}
+	last, _ := utf8.DecodeRuneInString(parts[len(parts)-1])
+	return parts[0] + " " + string(last) + "."
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// RISK SERVICE - Fraud scoring for paid orders
+// ============================================================================
+
+// Points added per signal; the total is capped at domain.MaxRiskScore.
+var riskSignalPoints = map[domain.RiskSignal]int{
+	domain.RiskSignalEmailVelocity:   20,
+	domain.RiskSignalIPVelocity:      20,
+	domain.RiskSignalCardVelocity:    25,
+	domain.RiskSignalCountryMismatch: 25,
+	domain.RiskSignalDisposableEmail: 30,
+	domain.RiskSignalFailedPayments:  20,
+}
+
+type RiskService struct {
+	orderRepo       repository.OrderRepository
+	paymentRepo     repository.PaymentRepository
+	settingsService *SettingsService
+}
+
+func NewRiskService(
+	orderRepo repository.OrderRepository,
+	paymentRepo repository.PaymentRepository,
+	settingsService *SettingsService,
+) *RiskService {
+	return &RiskService{
+		orderRepo:       orderRepo,
+		paymentRepo:     paymentRepo,
+		settingsService: settingsService,
+	}
+}
+
+// ============================================================================
+// ASSESSMENT
+// ============================================================================
+
+// Assess scores the order, stores the result on it and updates the order in
+// place. payment may be nil, in which case card checks are skipped.
+func (s *RiskService) Assess(ctx context.Context, order *domain.Order, payment *domain.Payment) (*domain.RiskAssessment, error) {
+	assessment := s.score(ctx, order, payment)
+
+	if err := s.orderRepo.UpdateRisk(ctx, order.ID, assessment.Score, assessment.Reasons); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order.RiskScore = &assessment.Score
+	order.RiskReasons = assessment.Reasons
+	order.RiskEvaluatedAt = &now
+
+	if assessment.Score > 0 {
+		logger.Info("Order risk scored",
+			zap.String("order_number", order.OrderNumber),
+			zap.Int("score", assessment.Score),
+			zap.Int("signals", len(assessment.Reasons)),
+		)
+	}
+
+	return assessment, nil
+}
+
+// Reassess re-scores an existing order using its most recent payment.
+func (s *RiskService) Reassess(ctx context.Context, orderID int64) (*domain.RiskAssessment, error) {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	var payment *domain.Payment
+	payments, err := s.paymentRepo.GetByOrderID(ctx, orderID)
+	if err == nil && len(payments) > 0 {
+		payment = payments[0]
+		for _, p := range payments {
+			if p.Status == domain.PaymentStatusCaptured {
+				payment = p
+				break
+			}
+		}
+	}
+
+	return s.Assess(ctx, order, payment)
+}
+
+func (s *RiskService) score(ctx context.Context, order *domain.Order, payment *domain.Payment) *domain.RiskAssessment {
+	assessment := &domain.RiskAssessment{
+		OrderID: order.ID,
+		Reasons: domain.RiskReasons{},
+	}
+
+	add := func(signal domain.RiskSignal, detail string) {
+		points := riskSignalPoints[signal]
+		assessment.Reasons = append(assessment.Reasons, domain.RiskReason{
+			Signal: signal,
+			Points: points,
+			Detail: detail,
+		})
+		assessment.Score += points
+	}
+
+	windowHours := s.settingsService.GetInt(ctx, domain.SettingRiskVelocityWindowHours, 24)
+	maxOrders := s.settingsService.GetInt(ctx, domain.SettingRiskVelocityMaxOrders, 3)
+	maxFailed := s.settingsService.GetInt(ctx, domain.SettingRiskMaxFailedPayments, 2)
+	since := time.Now().Add(-time.Duration(windowHours) * time.Hour)
+
+	// Velocity - the current order counts towards the limit
+	if count, err := s.orderRepo.CountRecentByEmail(ctx, order.CustomerEmail, since, order.ID); err != nil {
+		s.logCheckError(order, "email_velocity", err)
+	} else if count+1 > maxOrders {
+		add(domain.RiskSignalEmailVelocity, fmt.Sprintf("%d orders from this email in %dh", count+1, windowHours))
+	}
+
+	if ip := strings.TrimSpace(derefStr(order.CustomerIP)); ip != "" {
+		if count, err := s.orderRepo.CountRecentByIP(ctx, ip, since, order.ID); err != nil {
+			s.logCheckError(order, "ip_velocity", err)
+		} else if count+1 > maxOrders {
+			add(domain.RiskSignalIPVelocity, fmt.Sprintf("%d orders from IP %s in %dh", count+1, ip, windowHours))
+		}
+	}
+
+	if payment != nil && payment.CardLast4 != nil && *payment.CardLast4 != "" {
+		if count, err := s.paymentRepo.CountRecentOrdersByCardLast4(ctx, *payment.CardLast4, since, order.ID); err != nil {
+			s.logCheckError(order, "card_velocity", err)
+		} else if count+1 > maxOrders {
+			add(domain.RiskSignalCardVelocity, fmt.Sprintf("%d orders with card ending %s in %dh", count+1, *payment.CardLast4, windowHours))
+		}
+	}
+
+	// Billing country vs. IP country ("XX" is Cloudflare's unknown)
+	ipCountry := strings.ToUpper(strings.TrimSpace(derefStr(order.CustomerCountry)))
+	billingCountry := strings.ToUpper(strings.TrimSpace(order.BillingCountry))
+	if ipCountry != "" && ipCountry != "XX" && billingCountry != "" && ipCountry != billingCountry {
+		add(domain.RiskSignalCountryMismatch, fmt.Sprintf("billing country %s, IP country %s", billingCountry, ipCountry))
+	}
+
+	// Disposable email domain
+	if domainName := emailDomain(order.CustomerEmail); domainName != "" {
+		for _, disposable := range s.settingsService.GetStrings(ctx, domain.SettingRiskDisposableDomains, nil) {
+			if strings.EqualFold(domainName, strings.TrimSpace(disposable)) {
+				add(domain.RiskSignalDisposableEmail, fmt.Sprintf("disposable email domain %s", domainName))
+				break
+			}
+		}
+	}
+
+	// Failed payments by this customer plus failed verifications on this payment
+	failed, err := s.paymentRepo.CountRecentFailedByEmail(ctx, order.CustomerEmail, since)
+	if err != nil {
+		s.logCheckError(order, "failed_payments", err)
+	}
+	if payment != nil && payment.VerificationAttempts > 1 {
+		failed += payment.VerificationAttempts - 1
+	}
+	if failed >= maxFailed {
+		add(domain.RiskSignalFailedPayments, fmt.Sprintf("%d failed payment attempts in %dh", failed, windowHours))
+	}
+
+	if assessment.Score > domain.MaxRiskScore {
+		assessment.Score = domain.MaxRiskScore
+	}
+
+	return assessment
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *RiskService) logCheckError(order *domain.Order, check string, err error) {
+	logger.Warn("Risk check failed",
+		zap.String("order_number", order.OrderNumber),
+		zap.String("check", check),
+		zap.Error(err),
+	)
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[at+1:]))
+}
+
+func derefStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// SETTINGS SERVICE - Runtime configuration with in-process cache
+// ============================================================================
+
+// settingsCacheTTL bounds how stale another process (API vs worker) can be
+// after an update; the process that performs the update invalidates at once.
+const settingsCacheTTL = 1 * time.Minute
+
+type SettingsService struct {
+	settingsRepo    repository.SettingsRepository
+	activityLogRepo repository.ActivityLogRepository
+
+	mu       sync.RWMutex
+	cache    map[string]*domain.Setting
+	loadedAt time.Time
+}
+
+func NewSettingsService(
+	settingsRepo repository.SettingsRepository,
+	activityLogRepo repository.ActivityLogRepository,
+) *SettingsService {
+	return &SettingsService{
+		settingsRepo:    settingsRepo,
+		activityLogRepo: activityLogRepo,
+	}
+}
+
+// ============================================================================
+// READ
+// ============================================================================
+
+func (s *SettingsService) GetAll(ctx context.Context) ([]*domain.Setting, error) {
+	settings, err := s.settingsRepo.GetAll(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to load settings", 500)
+	}
+	return settings, nil
+}
+
+func (s *SettingsService) Get(ctx context.Context, key string) (*domain.Setting, error) {
+	setting, err := s.settingsRepo.FindByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to load setting", 500)
+	}
+	return setting, nil
+}
+
+func (s *SettingsService) GetHistory(ctx context.Context, key string, limit, offset int) ([]*domain.SettingChange, int, error) {
+	return s.settingsRepo.GetHistory(ctx, key, limit, offset)
+}
+
+// ============================================================================
+// UPDATE
+// ============================================================================
+
+func (s *SettingsService) Update(ctx context.Context, updates []*domain.SettingUpdate, adminID int64) ([]*domain.Setting, error) {
+	if len(updates) == 0 {
+		return nil, apperrors.New("VALIDATION_ERROR", "No settings provided", 422)
+	}
+
+	// Validate every value against its schema before touching the database
+	seen := make(map[string]bool, len(updates))
+	for _, u := range updates {
+		if seen[u.Key] {
+			return nil, apperrors.New("VALIDATION_ERROR", fmt.Sprintf("Duplicate setting %s", u.Key), 422)
+		}
+		seen[u.Key] = true
+
+		current, err := s.settingsRepo.FindByKey(ctx, u.Key)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, apperrors.New("SETTING_NOT_FOUND", fmt.Sprintf("Unknown setting %s", u.Key), 404)
+			}
+			return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to load setting", 500)
+		}
+
+		if err := validateSettingValue(current.Schema, u.Value); err != nil {
+			return nil, apperrors.New("VALIDATION_ERROR", fmt.Sprintf("Invalid value for %s: %s", u.Key, err.Error()), 422)
+		}
+	}
+
+	updated, err := s.settingsRepo.UpdateMany(ctx, updates, adminID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrVersionConflict):
+			return nil, apperrors.New("VERSION_CONFLICT", "Settings were changed by someone else; reload and try again", 409)
+		case errors.Is(err, domain.ErrNotFound):
+			return nil, apperrors.New("SETTING_NOT_FOUND", err.Error(), 404)
+		}
+		return nil, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to update settings", 500)
+	}
+
+	s.InvalidateCache()
+
+	changes := make(map[string]interface{}, len(updated))
+	for _, st := range updated {
+		changes[st.Key] = st.Value
+	}
+	s.logActivity(ctx, "update_settings", adminID, map[string]interface{}{
+		"changes": changes,
+	})
+
+	logger.Info("Settings updated",
+		zap.Int64("admin_id", adminID),
+		zap.Int("count", len(updated)),
+	)
+
+	return updated, nil
+}
+
+// ============================================================================
+// CACHE
+// ============================================================================
+
+// InvalidateCache forces the next typed read to reload from the database.
+func (s *SettingsService) InvalidateCache() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+func (s *SettingsService) cached(ctx context.Context, key string) (*domain.Setting, bool) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < settingsCacheTTL {
+		setting, ok := s.cache[key]
+		s.mu.RUnlock()
+		return setting, ok
+	}
+	s.mu.RUnlock()
+
+	settings, err := s.settingsRepo.GetAll(ctx)
+	if err != nil {
+		logger.Error("Failed to load settings, using defaults", zap.Error(err))
+		return nil, false
+	}
+
+	cache := make(map[string]*domain.Setting, len(settings))
+	for _, st := range settings {
+		cache[st.Key] = st
+	}
+
+	s.mu.Lock()
+	s.cache = cache
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	setting, ok := cache[key]
+	return setting, ok
+}
+
+// ============================================================================
+// TYPED GETTERS - fall back to def when the key is missing or malformed
+// ============================================================================
+
+func (s *SettingsService) GetInt(ctx context.Context, key string, def int) int {
+	var v int
+	if !s.decode(ctx, key, &v) {
+		return def
+	}
+	return v
+}
+
+func (s *SettingsService) GetFloat(ctx context.Context, key string, def float64) float64 {
+	var v float64
+	if !s.decode(ctx, key, &v) {
+		return def
+	}
+	return v
+}
+
+func (s *SettingsService) GetBool(ctx context.Context, key string, def bool) bool {
+	var v bool
+	if !s.decode(ctx, key, &v) {
+		return def
+	}
+	return v
+}
+
+func (s *SettingsService) GetString(ctx context.Context, key string, def string) string {
+	var v string
+	if !s.decode(ctx, key, &v) || v == "" {
+		return def
+	}
+	return v
+}
+
+func (s *SettingsService) GetStrings(ctx context.Context, key string, def []string) []string {
+	var v []string
+	if !s.decode(ctx, key, &v) {
+		return def
+	}
+	return v
+}
+
+func (s *SettingsService) decode(ctx context.Context, key string, dst interface{}) bool {
+	if s == nil {
+		return false
+	}
+	setting, ok := s.cached(ctx, key)
+	if !ok || setting == nil {
+		return false
+	}
+	if err := json.Unmarshal(setting.Value, dst); err != nil {
+		logger.Warn("Malformed setting value", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// ============================================================================
+// SCHEMA VALIDATION
+// ============================================================================
+
+// validateSettingValue checks raw against the JSON-schema subset stored on the
+// setting: type, minimum, maximum, minLength, maxLength, enum, format, pattern.
+func validateSettingValue(schema domain.JSONMap, raw domain.JSONValue) error {
+	if len(raw) == 0 {
+		return fmt.Errorf("value is required")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("value is not valid JSON")
+	}
+
+	schemaType, _ := schema["type"].(string)
+	switch schemaType {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string")
+		}
+		length := float64(utf8.RuneCountInString(str))
+		if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+			return fmt.Errorf("must be at least %v characters", min)
+		}
+		if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+			return fmt.Errorf("must be at most %v characters", max)
+		}
+		if format, _ := schema["format"].(string); format == "email" {
+			if _, err := mail.ParseAddress(str); err != nil {
+				return fmt.Errorf("must be a valid email address")
+			}
+		}
+		if pattern, _ := schema["pattern"].(string); pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil || !re.MatchString(str) {
+				return fmt.Errorf("does not match the required pattern")
+			}
+		}
+
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected a number")
+		}
+		if schemaType == "integer" {
+			if _, err := num.Int64(); err != nil {
+				return fmt.Errorf("expected an integer")
+			}
+		}
+		f, err := num.Float64()
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		if min, ok := schemaNumber(schema, "minimum"); ok && f < min {
+			return fmt.Errorf("must be >= %v", min)
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && f > max {
+			return fmt.Errorf("must be <= %v", max)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a boolean")
+		}
+
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("expected an object")
+		}
+
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Errorf("expected an array")
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v", enum)
+	}
+
+	return nil
+}
+
+func schemaNumber(schema domain.JSONMap, key string) (float64, bool) {
+	switch v := schema[key].(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *SettingsService) logActivity(ctx context.Context, action string, adminID int64, metadata map[string]interface{}) {
+	if s.activityLogRepo == nil {
+		return
+	}
+
+	var adminIDPtr *int64
+	if adminID > 0 {
+		adminIDPtr = &adminID
+	}
+
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		Action:     action,
+		EntityType: strPtr("settings"),
+		AdminID:    adminIDPtr,
+		Details:    domain.JSONMap(metadata),
+	})
+}
@@ -4,190 +4,434 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
 	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/pkg/scanner"
+	"github.com/merraki/merraki-backend/internal/pkg/storage"
+	"go.uber.org/zap"
 )
 
+// ============================================================================
+// STORAGE SERVICE - Uploads and delivery on the configured backend
+// ============================================================================
+
+// publicFolders are served without signatures; everything else (template
+// files, invoices, watermarked copies) is private.
+var publicFolders = map[string]bool{
+	"images": true,
+	"blog":   true,
+}
+
+// quarantineFolder holds uploads that failed the malware scan, kept for
+// review but never served.
+const quarantineFolder = "quarantine"
+
+// uploadPolicy is what UploadFile accepts for a folder. Formats are checked
+// against the sniffed content, not the client's filename or Content-Type.
+type uploadPolicy struct {
+	formats  []string
+	maxBytes int64
+}
+
+func (p uploadPolicy) allows(format string) bool {
+	for _, f := range p.formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func uploadPolicies(cfg *config.Config) map[string]uploadPolicy {
+	const mb = 1024 * 1024
+	images := uploadPolicy{
+		formats:  []string{"png", "jpg", "gif", "webp"},
+		maxBytes: int64(cfg.Upload.MaxImageMB) * mb,
+	}
+	return map[string]uploadPolicy{
+		"templates": {
+			formats:  []string{"xlsx", "xlsm", "xls", "docx", "doc", "pptx", "ppt", "pdf", "zip"},
+			maxBytes: int64(cfg.Upload.MaxTemplateMB) * mb,
+		},
+		"images": images,
+		"blog":   images,
+	}
+}
+
+// storageHealthTTL limits how often health checks reach the backend;
+// Cloudinary rate-limits its admin API.
+const storageHealthTTL = 30 * time.Second
+
 type StorageService struct {
-	cloudinary *cloudinary.Cloudinary
-	cfg        *config.Config
+	store    storage.Storage
+	scanner  scanner.Scanner
+	policies map[string]uploadPolicy
+
+	healthMu      sync.Mutex
+	healthErr     error
+	healthChecked time.Time
 }
 
 func NewStorageService(cfg *config.Config) (*StorageService, error) {
-	cld, err := cloudinary.NewFromParams(
-		cfg.Storage.CloudinaryName,
-		cfg.Storage.CloudinaryKey,
-		cfg.Storage.CloudinarySecret,
-	)
+	store, err := newStorageBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	fileScanner, err := newUploadScanner(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	logger.Info("Storage backend initialized",
+		zap.String("provider", store.Provider()),
+		zap.String("scanner", fileScanner.Name()),
+	)
 	return &StorageService{
-		cloudinary: cld,
-		cfg:        cfg,
+		store:    store,
+		scanner:  fileScanner,
+		policies: uploadPolicies(cfg),
 	}, nil
 }
 
+func newStorageBackend(cfg *config.Config) (storage.Storage, error) {
+	sc := cfg.Storage
+
+	switch strings.ToLower(sc.Provider) {
+	case "", "cloudinary":
+		return storage.NewCloudinary(storage.CloudinaryConfig{
+			CloudName: sc.CloudinaryName,
+			APIKey:    sc.CloudinaryKey,
+			APISecret: sc.CloudinarySecret,
+			Folder:    sc.CloudinaryFolder,
+		})
+
+	case "local":
+		baseURL := sc.LocalBaseURL
+		if baseURL == "" {
+			baseURL = fmt.Sprintf("http://localhost:%d/api/v1/public/files", cfg.Server.Port)
+		}
+		return storage.NewLocal(storage.LocalConfig{
+			Root:       sc.LocalPath,
+			BaseURL:    baseURL,
+			SigningKey: storageSigningKey(cfg),
+		})
+
+	case "s3", "minio":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:     sc.S3Endpoint,
+			Region:       sc.S3Region,
+			AccessKey:    sc.S3AccessKey,
+			SecretKey:    sc.S3SecretKey,
+			UseSSL:       sc.S3UseSSL,
+			PathStyle:    sc.S3PathStyle,
+			Bucket:       sc.S3Bucket,
+			PublicBucket: sc.S3PublicBucket,
+			PublicURL:    sc.S3PublicURL,
+		})
+	}
+
+	return nil, fmt.Errorf("unknown STORAGE_PROVIDER %q", sc.Provider)
+}
+
+func newUploadScanner(cfg *config.Config) (scanner.Scanner, error) {
+	switch strings.ToLower(cfg.Upload.Scanner) {
+	case "", "none":
+		return scanner.Noop{}, nil
+	case "clamav":
+		return scanner.NewClamAV(cfg.Upload.ClamAVAddress, cfg.Upload.ScanTimeout)
+	}
+	return nil, fmt.Errorf("unknown UPLOAD_SCANNER %q", cfg.Upload.Scanner)
+}
+
+// storageSigningKey signs local download links. The API and worker must
+// agree on it, so without STORAGE_SIGNING_KEY it is derived from the
+// PASETO key both already share.
+func storageSigningKey(cfg *config.Config) []byte {
+	if cfg.Storage.SigningKey != "" {
+		return []byte(cfg.Storage.SigningKey)
+	}
+	logger.Warn("STORAGE_SIGNING_KEY not set, deriving storage signing key from PASETO key")
+	sum := sha256.Sum256([]byte("merraki-storage-v1:" + cfg.Auth.PasetoKey))
+	return sum[:]
+}
+
+func folderVisibility(folder string) storage.Visibility {
+	if publicFolders[folder] {
+		return storage.Public
+	}
+	return storage.Private
+}
+
+// ============================================================================
+// UPLOAD / DELETE
+// ============================================================================
+
 type UploadResult struct {
 	URL          string
 	PublicID     string
 	Format       string
 	ResourceType string
 	Bytes        int
+
+	// Set by UploadFile from the file's content
+	ContentType   string
+	Checksum      string
+	ScanStatus    domain.ScanStatus
+	ScanSignature string
 }
 
+// UploadFile validates an admin upload against the folder's policy, hashes
+// and scans it, then stores it. A file that fails the scan is stored in the
+// quarantine folder instead and returned with ScanStatusQuarantined; callers
+// must not publish it.
 func (s *StorageService) UploadFile(ctx context.Context, file *multipart.FileHeader, folder string) (*UploadResult, error) {
+	if err := s.ValidateUpload(folder, file.Filename, file.Size); err != nil {
+		return nil, err
+	}
+
 	src, err := file.Open()
 	if err != nil {
 		return nil, apperrors.Wrap(err, "STORAGE_ERROR", "Failed to open file", 500)
 	}
 	defer src.Close()
 
-	uploadParams := uploader.UploadParams{
-		Folder:       fmt.Sprintf("%s/%s", s.cfg.Storage.CloudinaryFolder, folder),
-		ResourceType: "auto",
+	return s.upload(ctx, src, file.Size, file.Filename, folder)
+}
+
+// UploadStagedFile runs a file assembled on local disk, such as a finished
+// chunked upload, through the same checks as UploadFile.
+func (s *StorageService) UploadStagedFile(ctx context.Context, path, filename, folder string) (*UploadResult, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "STORAGE_ERROR", "Failed to open file", 500)
 	}
+	defer src.Close()
 
-	result, err := s.cloudinary.Upload.Upload(ctx, src, uploadParams)
+	info, err := src.Stat()
 	if err != nil {
-		return nil, apperrors.Wrap(err, "STORAGE_ERROR", "Failed to upload file", 500)
+		return nil, apperrors.Wrap(err, "STORAGE_ERROR", "Failed to open file", 500)
+	}
+	if err := s.ValidateUpload(folder, filename, info.Size()); err != nil {
+		return nil, err
 	}
 
-	return &UploadResult{
-		URL:          result.SecureURL,
-		PublicID:     result.PublicID,
-		Format:       result.Format,
-		ResourceType: result.ResourceType,
-		Bytes:        result.Bytes,
-	}, nil
+	return s.upload(ctx, src, info.Size(), filename, folder)
 }
 
-func (s *StorageService) UploadFromReader(ctx context.Context, reader io.Reader, filename, folder string) (*UploadResult, error) {
-	uploadParams := uploader.UploadParams{
-		Folder:       fmt.Sprintf("%s/%s", s.cfg.Storage.CloudinaryFolder, folder),
-		ResourceType: "auto",
-		PublicID:     filename,
+// ValidateUpload checks what can be known before the content arrives: the
+// size and that the extension is allowed in folder. The content itself is
+// checked when the file is uploaded.
+func (s *StorageService) ValidateUpload(folder, filename string, size int64) error {
+	policy, ok := s.policies[folder]
+	if !ok {
+		return fmt.Errorf("no upload policy for folder %q", folder)
+	}
+	if size <= 0 {
+		return fmt.Errorf("%w: file is empty", domain.ErrInvalidInput)
+	}
+	if size > policy.maxBytes {
+		return fmt.Errorf("%w: file is larger than %d MB", domain.ErrInvalidInput, policy.maxBytes/(1024*1024))
+	}
+	if !policy.allows(storage.Extension(filename)) {
+		return fmt.Errorf("%w: file type is not allowed, expected one of %s",
+			domain.ErrInvalidInput, strings.Join(policy.formats, ", "))
+	}
+	return nil
+}
+
+// uploadSource is satisfied by multipart files and *os.File.
+type uploadSource interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+func (s *StorageService) upload(ctx context.Context, src uploadSource, size int64, filename, folder string) (*UploadResult, error) {
+	policy := s.policies[folder]
+
+	fileType, err := storage.Sniff(src, size, filename)
+	if errors.Is(err, storage.ErrUnknownFileType) || (err == nil && !policy.allows(fileType.Format)) {
+		return nil, fmt.Errorf("%w: file type is not allowed, expected one of %s",
+			domain.ErrInvalidInput, strings.Join(policy.formats, ", "))
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, "STORAGE_ERROR", "Failed to read file", 500)
+	}
+	if ext := storage.Extension(filename); ext != fileType.Format {
+		return nil, fmt.Errorf("%w: file content is %s but the name ends in .%s",
+			domain.ErrInvalidInput, fileType.Format, ext)
+	}
+
+	checksum, verdict, err := s.inspect(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan file: %w", err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, apperrors.Wrap(err, "STORAGE_ERROR", "Failed to read file", 500)
+	}
+
+	opts := storage.PutOptions{
+		Folder:      folder,
+		Filename:    filename,
+		ContentType: fileType.ContentType,
+		Visibility:  folderVisibility(folder),
+		Size:        size,
+	}
+	status := domain.ScanStatusUnscanned
+	switch {
+	case verdict.Infected:
+		status = domain.ScanStatusQuarantined
+		opts.Folder = quarantineFolder
+		opts.Visibility = storage.Private
+		logger.Warn("Upload failed malware scan, quarantining",
+			zap.String("filename", filename),
+			zap.String("folder", folder),
+			zap.String("signature", verdict.Signature),
+			zap.String("sha256", checksum),
+		)
+	case verdict.Scanned:
+		status = domain.ScanStatusClean
 	}
 
-	result, err := s.cloudinary.Upload.Upload(ctx, reader, uploadParams)
+	result, err := s.put(ctx, src, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Format = fileType.Format
+	result.ContentType = fileType.ContentType
+	result.Checksum = checksum
+	result.ScanStatus = status
+	result.ScanSignature = verdict.Signature
+	return result, nil
+}
+
+// inspect hashes r while the scanner reads it, so the file is read once.
+func (s *StorageService) inspect(ctx context.Context, r io.Reader) (string, *scanner.Result, error) {
+	hash := sha256.New()
+	tee := io.TeeReader(r, hash)
+
+	verdict, err := s.scanner.Scan(ctx, tee)
+	if err != nil {
+		return "", nil, err
+	}
+	// A scanner may stop reading once it has a verdict
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return "", nil, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), verdict, nil
+}
+
+// UploadFromReader stores reader under filename, replacing any earlier
+// upload with the same name in the folder.
+func (s *StorageService) UploadFromReader(ctx context.Context, reader io.Reader, filename, folder string) (*UploadResult, error) {
+	return s.put(ctx, reader, storage.PutOptions{
+		Folder:     folder,
+		Name:       filename,
+		Filename:   filename,
+		Visibility: folderVisibility(folder),
+		Size:       -1,
+	})
+}
+
+func (s *StorageService) put(ctx context.Context, r io.Reader, opts storage.PutOptions) (*UploadResult, error) {
+	obj, err := s.store.Put(ctx, r, opts)
 	if err != nil {
 		return nil, apperrors.Wrap(err, "STORAGE_ERROR", "Failed to upload file", 500)
 	}
 
+	url := obj.URL
+	if url == "" {
+		url = s.store.PublicURL(obj.Key)
+	}
+
 	return &UploadResult{
-		URL:          result.SecureURL,
-		PublicID:     result.PublicID,
-		Format:       result.Format,
-		ResourceType: result.ResourceType,
-		Bytes:        result.Bytes,
+		URL:          url,
+		PublicID:     obj.Key,
+		Format:       obj.Format,
+		ResourceType: obj.ResourceType,
+		Bytes:        int(obj.Size),
 	}, nil
 }
 
 func (s *StorageService) DeleteFile(ctx context.Context, publicID string) error {
-	_, err := s.cloudinary.Upload.Destroy(ctx, uploader.DestroyParams{
-		PublicID: publicID,
-	})
-	if err != nil {
+	if err := s.store.Delete(ctx, publicID); err != nil {
 		return apperrors.Wrap(err, "STORAGE_ERROR", "Failed to delete file", 500)
 	}
 	return nil
 }
 
-// ============================================================================
-// GENERATE SIGNED URL - Cloudinary Private CDN with Signature
-// ============================================================================
-
-func (s *StorageService) GenerateSignedURL(ctx context.Context, publicID string, duration time.Duration) (string, error) {
-	// Calculate expiration timestamp
-	expirationTime := time.Now().Add(duration).Unix()
-	
-	// Build the string to sign
-	// Format: {public_id}{timestamp}{api_secret}
-	stringToSign := fmt.Sprintf("%s%d%s", publicID, expirationTime, s.cfg.Storage.CloudinarySecret)
-	
-	// Generate SHA-256 signature
-	hash := sha256.New()
-	hash.Write([]byte(stringToSign))
-	signature := hex.EncodeToString(hash.Sum(nil))[:16] // Use first 16 characters
-	
-	// Build authenticated URL
-	// Cloudinary authenticated URL format:
-	// https://res.cloudinary.com/{cloud_name}/image/authenticated/s--{signature}--/v1/{public_id}.{format}
-	baseURL := fmt.Sprintf("https://res.cloudinary.com/%s", s.cfg.Storage.CloudinaryName)
-	
-	// Extract resource type and format from publicID
-	// publicID format: folder/filename or folder/subfolder/filename
-	resourceType := "raw" // Default for files
-	
-	// Build the signed URL
-	signedURL := fmt.Sprintf(
-		"%s/%s/authenticated/s--%s--/%s?exp=%d",
-		baseURL,
-		resourceType,
-		signature,
-		publicID,
-		expirationTime,
-	)
-	
-	return signedURL, nil
+// Stat returns the stored size and type of a file, or storage.ErrNotFound.
+func (s *StorageService) Stat(ctx context.Context, publicID string) (*storage.Object, error) {
+	return s.store.Stat(ctx, publicID)
 }
 
 // ============================================================================
-// ALTERNATIVE: Generate Simple Signed URL (More Compatible)
+// DELIVERY
 // ============================================================================
 
+// GenerateSignedDownloadURL returns a short-lived link that downloads a
+// private file as an attachment.
 func (s *StorageService) GenerateSignedDownloadURL(ctx context.Context, publicID string, duration time.Duration) (string, error) {
-	// For raw file downloads, use type: "upload" with attachment flag
-	expirationTime := time.Now().Add(duration).Unix()
-	
-	// Cloudinary URL structure for raw files:
-	// https://res.cloudinary.com/{cloud_name}/raw/upload/{public_id}
-	
-	// Generate signature for private download
-	// Parameters to sign: public_id + timestamp
-	paramsToSign := fmt.Sprintf("public_id=%s&timestamp=%d%s", 
-		publicID, 
-		expirationTime,
-		s.cfg.Storage.CloudinarySecret,
-	)
-	
-	hash := sha256.New()
-	hash.Write([]byte(paramsToSign))
-	signature := hex.EncodeToString(hash.Sum(nil))
-	
-	// Build download URL with signature
-	downloadURL := fmt.Sprintf(
-		"https://res.cloudinary.com/%s/raw/upload/fl_attachment/%s?timestamp=%d&signature=%s",
-		s.cfg.Storage.CloudinaryName,
-		publicID,
-		expirationTime,
-		signature,
-	)
-	
-	return downloadURL, nil
+	return s.store.SignedURL(ctx, publicID, duration)
+}
+
+// OpenFile fetches a stored file, forwarding the given request headers
+// (e.g. Range and If-Range). The caller must close the response body.
+func (s *StorageService) OpenFile(ctx context.Context, publicID string, header http.Header) (*http.Response, error) {
+	return s.store.Open(ctx, publicID, header)
+}
+
+// GetPublicURL returns the permanent URL of a file in a public folder.
+func (s *StorageService) GetPublicURL(publicID string) string {
+	return s.store.PublicURL(publicID)
+}
+
+// Local returns the local backend, whose signed links are served by the
+// API itself.
+func (s *StorageService) Local() (*storage.Local, bool) {
+	local, ok := s.store.(*storage.Local)
+	return local, ok
 }
 
 // ============================================================================
-// PUBLIC URL (No Authentication) - Use only for public resources
+// HEALTH
 // ============================================================================
 
-func (s *StorageService) GetPublicURL(publicID string, resourceType string) string {
-	if resourceType == "" {
-		resourceType = "raw"
+func (s *StorageService) Provider() string {
+	return s.store.Provider()
+}
+
+// Health pings the backend, reusing the last result for storageHealthTTL.
+func (s *StorageService) Health(ctx context.Context) error {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	if !s.healthChecked.IsZero() && time.Since(s.healthChecked) < storageHealthTTL {
+		return s.healthErr
 	}
-	
-	return fmt.Sprintf(
-		"https://res.cloudinary.com/%s/%s/upload/%s",
-		s.cfg.Storage.CloudinaryName,
-		resourceType,
-		publicID,
-	)
-}
\ No newline at end of file
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	s.healthErr = s.store.Ping(ctx)
+	s.healthChecked = time.Now()
+	if s.healthErr != nil {
+		logger.Warn("Storage health check failed",
+			zap.String("provider", s.store.Provider()),
+			zap.Error(s.healthErr),
+		)
+	}
+	return s.healthErr
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/config"
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/pkg/preview"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// TEMPLATE PREVIEW SERVICE - Watermarked gallery images of a template's file
+// ============================================================================
+//
+// Previews are generated by the worker whenever a template's file changes,
+// and can be re-run by an admin. Each run replaces the previous run's
+// images; images uploaded by hand are left alone.
+
+const (
+	previewWatermark  = "PREVIEW"
+	maxPreviewBytes   = 100 * 1024 * 1024
+	previewRenderTime = 2 * time.Minute
+)
+
+type TemplatePreviewService struct {
+	templateRepo   repository.TemplateRepository
+	jobRepo        repository.BackgroundJobRepository
+	storageService *StorageService
+	imageService   *ImageService
+	options        preview.Options
+}
+
+func NewTemplatePreviewService(
+	templateRepo repository.TemplateRepository,
+	jobRepo repository.BackgroundJobRepository,
+	storageService *StorageService,
+	imageService *ImageService,
+	cfg *config.Config,
+) *TemplatePreviewService {
+	maxPages := cfg.Preview.MaxPages
+	if maxPages <= 0 {
+		maxPages = 3
+	}
+	width := cfg.Preview.Width
+	if width <= 0 {
+		width = 1200
+	}
+
+	return &TemplatePreviewService{
+		templateRepo:   templateRepo,
+		jobRepo:        jobRepo,
+		storageService: storageService,
+		imageService:   imageService,
+		options: preview.Options{
+			MaxPages: maxPages,
+			Width:    width,
+			PDFToPPM: cfg.Preview.PDFToPPM,
+		},
+	}
+}
+
+// Enqueue schedules preview generation for a template, for the admin
+// re-run endpoint.
+func (s *TemplatePreviewService) Enqueue(ctx context.Context, templateID int64) (*domain.BackgroundJob, error) {
+	template, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, domain.ErrNotFound
+	}
+	if template.FileURL == nil || *template.FileURL == "" {
+		return nil, fmt.Errorf("%w: template has no file", domain.ErrInvalidInput)
+	}
+	if !preview.Supports(derefStr(template.FileFormat)) {
+		return nil, fmt.Errorf("%w: previews can only be generated for PDF and XLSX files", domain.ErrInvalidInput)
+	}
+
+	return enqueueTemplatePreviews(ctx, s.jobRepo, templateID)
+}
+
+func enqueueTemplatePreviews(ctx context.Context, jobRepo repository.BackgroundJobRepository, templateID int64) (*domain.BackgroundJob, error) {
+	job := &domain.BackgroundJob{
+		JobType:     "generate_template_previews",
+		Payload:     domain.JSONMap{"template_id": templateID},
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+	}
+	if err := jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Generate renders the template's file into watermarked preview images
+// and swaps them in for the previous ones. It returns how many were made;
+// templates in other formats, or PDFs without pdftoppm installed, get none
+// and keep their current previews.
+func (s *TemplatePreviewService) Generate(ctx context.Context, templateID int64) (int, error) {
+	template, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return 0, err
+	}
+	if template == nil {
+		return 0, domain.ErrNotFound
+	}
+	format := derefStr(template.FileFormat)
+	if template.FileURL == nil || !preview.Supports(format) {
+		return 0, nil
+	}
+
+	data, err := s.readFile(ctx, *template.FileURL)
+	if err != nil {
+		return 0, err
+	}
+
+	renderCtx, cancel := context.WithTimeout(ctx, previewRenderTime)
+	pages, err := preview.Render(renderCtx, data, format, s.options)
+	cancel()
+	if errors.Is(err, preview.ErrNoRenderer) {
+		logger.Warn("Skipping PDF preview, pdftoppm is not installed", zap.Int64("template_id", templateID))
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to render preview: %w", err)
+	}
+
+	existing, err := s.templateRepo.GetImages(ctx, templateID)
+	if err != nil {
+		return 0, err
+	}
+	// Previews go after the uploaded images, and lead only when none of
+	// those is primary
+	var old []*domain.TemplateImage
+	order, hasPrimary := 0, false
+	for _, image := range existing {
+		if image.IsPreview {
+			old = append(old, image)
+			continue
+		}
+		if image.DisplayOrder >= order {
+			order = image.DisplayOrder + 1
+		}
+		hasPrimary = hasPrimary || image.IsPrimary
+	}
+
+	var created []*domain.TemplateImage
+	for i, page := range pages {
+		preview.Watermark(page.Image, previewWatermark)
+
+		processed, err := s.imageService.ProcessImage(ctx, page.Image, template.Slug+"-preview", "images")
+		if err != nil {
+			s.deleteImages(ctx, created)
+			return 0, err
+		}
+
+		image := &domain.TemplateImage{
+			TemplateID:   templateID,
+			URL:          processed.URL(),
+			AltText:      strPtr(fmt.Sprintf("%s preview – %s", template.Name, page.Label)),
+			DisplayOrder: order + i,
+			IsPrimary:    !hasPrimary && i == 0,
+			IsPreview:    true,
+			Width:        &processed.Width,
+			Height:       &processed.Height,
+			BlurHash:     &processed.BlurHash,
+			Renditions:   processed.Renditions,
+		}
+		if err := s.templateRepo.CreateImage(ctx, image); err != nil {
+			s.imageService.DeleteRenditions(ctx, processed.Renditions)
+			s.deleteImages(ctx, created)
+			return 0, err
+		}
+		created = append(created, image)
+	}
+
+	// A hand-entered preview URL is kept; one pointing at our own previous
+	// preview follows the new one
+	if len(created) > 0 && s.ownsPreviewURL(template.PreviewURL, old) {
+		if err := s.templateRepo.Patch(ctx, templateID, map[string]interface{}{"preview_url": created[0].URL}); err != nil {
+			return len(created), err
+		}
+	}
+
+	s.deleteImages(ctx, old)
+	return len(created), nil
+}
+
+func (s *TemplatePreviewService) readFile(ctx context.Context, fileURL string) ([]byte, error) {
+	resp, err := s.storageService.OpenFile(ctx, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPreviewBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxPreviewBytes {
+		return nil, fmt.Errorf("file too large to preview")
+	}
+	return data, nil
+}
+
+func (s *TemplatePreviewService) ownsPreviewURL(previewURL *string, previews []*domain.TemplateImage) bool {
+	if previewURL == nil || *previewURL == "" {
+		return true
+	}
+	for _, image := range previews {
+		if image.URL == *previewURL {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteImages removes preview images and their renditions. Failures are
+// logged; a leftover preview is replaced on the next run.
+func (s *TemplatePreviewService) deleteImages(ctx context.Context, images []*domain.TemplateImage) {
+	for _, image := range images {
+		if err := s.templateRepo.DeleteImage(ctx, image.ID); err != nil {
+			logger.Warn("Failed to delete template preview", zap.Int64("image_id", image.ID), zap.Error(err))
+			continue
+		}
+		s.imageService.DeleteRenditions(ctx, image.Renditions)
+	}
+}
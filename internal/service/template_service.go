@@ -3,30 +3,51 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"mime/multipart"
 	"time"
 
 	"github.com/gosimple/slug"
 	"github.com/lib/pq"
 	"github.com/merraki/merraki-backend/internal/domain"
 	apperrors "github.com/merraki/merraki-backend/internal/pkg/errors"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
 	"github.com/merraki/merraki-backend/internal/repository/postgres"
+	"go.uber.org/zap"
 )
 
 type TemplateService struct {
 	templateRepo *postgres.TemplateRepository
 	categoryRepo *postgres.CategoryRepository
 	logRepo      *postgres.ActivityLogRepository
+	imageService *ImageService
+	priceRepo    *postgres.PriceRuleRepository
+	jobRepo      repository.BackgroundJobRepository
 }
 
+// uploadOwnedTemplateColumns are set from uploaded files and versions, not
+// from client input.
+var uploadOwnedTemplateColumns = []string{"file_url", "file_size_mb", "file_format", "current_version"}
+
+// ruleOwnedTemplateColumns are set by scheduled price rules
+var ruleOwnedTemplateColumns = []string{"sale_ends_at", "sale_rule_id"}
+
 func NewTemplateService(
 	templateRepo *postgres.TemplateRepository,
 	categoryRepo *postgres.CategoryRepository,
 	logRepo *postgres.ActivityLogRepository,
+	imageService *ImageService,
+	priceRepo *postgres.PriceRuleRepository,
+	jobRepo repository.BackgroundJobRepository,
 ) *TemplateService {
 	return &TemplateService{
 		templateRepo: templateRepo,
 		categoryRepo: categoryRepo,
 		logRepo:      logRepo,
+		imageService: imageService,
+		priceRepo:    priceRepo,
+		jobRepo:      jobRepo,
 	}
 }
 
@@ -59,19 +80,32 @@ func (s *TemplateService) CreateTemplate(ctx context.Context, template *domain.T
 		}
 	}
 
-	// Set published_at when creating as active
-	if template.Status == domain.TemplateStatusActive && template.PublishedAt == nil {
-		now := time.Now()
-		template.PublishedAt = &now
+	switch template.Status {
+	case domain.TemplateStatusScheduled:
+		if err := validatePublishAt(template.PublishedAt); err != nil {
+			return err
+		}
+	case domain.TemplateStatusActive:
+		// Set published_at when creating as active
+		if template.PublishedAt == nil {
+			now := time.Now()
+			template.PublishedAt = &now
+		}
 	}
 
 	if template.MetaKeywords == nil {
 		template.MetaKeywords = pq.StringArray{}
 	}
 
+	if err := normalizeLicense(template); err != nil {
+		return err
+	}
+
 	if err := s.templateRepo.Create(ctx, template); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to create template", 500)
 	}
+	recordPriceChange(ctx, s.priceRepo, nil, template, domain.PriceChangeSourceInitial, nil, &createdBy)
+	s.schedulePublishing(ctx, template.ID, template.Status, template.PublishedAt)
 
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &createdBy,
@@ -181,22 +215,55 @@ func (s *TemplateService) UpdateTemplate(ctx context.Context, template *domain.T
 		}
 	}
 
-	// Set published_at when first becoming active
-	if template.Status == domain.TemplateStatusActive &&
-		existing.Status != domain.TemplateStatusActive &&
-		template.PublishedAt == nil {
-		now := time.Now()
-		template.PublishedAt = &now
+	if template.PublishedAt == nil {
+		template.PublishedAt = existing.PublishedAt
+	}
+
+	switch template.Status {
+	case domain.TemplateStatusScheduled:
+		if existing.Status != domain.TemplateStatusScheduled || !equalTimePtr(existing.PublishedAt, template.PublishedAt) {
+			if err := validatePublishAt(template.PublishedAt); err != nil {
+				return err
+			}
+		}
+	case domain.TemplateStatusActive:
+		// Set published_at when first becoming active; publishing by hand
+		// ahead of a schedule publishes now
+		if existing.Status != domain.TemplateStatusActive &&
+			(template.PublishedAt == nil || template.PublishedAt.After(time.Now())) {
+			now := time.Now()
+			template.PublishedAt = &now
+		}
 	}
 
 	if template.MetaKeywords == nil {
 		template.MetaKeywords = pq.StringArray{}
 	}
 
+	// The file, its measured size and format and the current version only
+	// change through uploads and version rollbacks
+	template.FileURL = existing.FileURL
+	template.FileSizeMB = existing.FileSizeMB
+	template.FileFormat = existing.FileFormat
+	template.CurrentVersion = existing.CurrentVersion
+
+	if err := normalizeLicense(template); err != nil {
+		return err
+	}
+
 	if err := s.templateRepo.Update(ctx, template); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to update template", 500)
 	}
 
+	// A sale price edited by hand takes over from the price rule that set it
+	if existing.SaleRuleID != nil && !equalInt64Ptr(existing.SalePriceUSDCents, template.SalePriceUSDCents) {
+		if err := s.templateRepo.Patch(ctx, template.ID, map[string]interface{}{"sale_ends_at": nil, "sale_rule_id": nil}); err != nil {
+			return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to update template", 500)
+		}
+	}
+	recordPriceChange(ctx, s.priceRepo, existing, template, domain.PriceChangeSourceAdmin, nil, &updatedBy)
+	s.schedulePublishing(ctx, template.ID, template.Status, template.PublishedAt)
+
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &updatedBy,
 		Action:     "update_template",
@@ -220,12 +287,42 @@ func (s *TemplateService) PatchTemplate(ctx context.Context, id int64, updates m
 		return apperrors.ErrNotFound
 	}
 
-	// Set published_at when first activating via patch
-	if status, ok := updates["status"].(domain.TemplateStatus); ok &&
-		status == domain.TemplateStatusActive &&
-		existing.Status != domain.TemplateStatusActive {
-		if _, hasPublishedAt := updates["published_at"]; !hasPublishedAt {
-			updates["published_at"] = time.Now()
+	for _, key := range uploadOwnedTemplateColumns {
+		delete(updates, key)
+	}
+	for _, key := range ruleOwnedTemplateColumns {
+		delete(updates, key)
+	}
+
+	_, patchesPrice := updates["price_usd_cents"]
+	if _, ok := updates["sale_price_usd_cents"]; ok {
+		patchesPrice = true
+		if existing.SaleRuleID != nil {
+			updates["sale_ends_at"] = nil
+			updates["sale_rule_id"] = nil
+		}
+	}
+
+	status := domain.TemplateStatus(patchedStatus(updates, string(existing.Status)))
+	publishAt, err := patchedPublishAt(updates, existing.PublishedAt)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case domain.TemplateStatusScheduled:
+		if existing.Status != domain.TemplateStatusScheduled || !equalTimePtr(existing.PublishedAt, publishAt) {
+			if err := validatePublishAt(publishAt); err != nil {
+				return err
+			}
+		}
+	case domain.TemplateStatusActive:
+		// Set published_at when first activating via patch
+		if existing.Status != domain.TemplateStatusActive &&
+			(publishAt == nil || publishAt.After(time.Now())) {
+			now := time.Now()
+			updates["published_at"] = now
+			publishAt = &now
 		}
 	}
 
@@ -233,6 +330,13 @@ func (s *TemplateService) PatchTemplate(ctx context.Context, id int64, updates m
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to patch template", 500)
 	}
 
+	if patchesPrice {
+		if patched, err := s.templateRepo.FindByID(ctx, id); err == nil && patched != nil {
+			recordPriceChange(ctx, s.priceRepo, existing, patched, domain.PriceChangeSourceAdmin, nil, &updatedBy)
+		}
+	}
+	s.schedulePublishing(ctx, id, status, publishAt)
+
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &updatedBy,
 		Action:     "patch_template",
@@ -244,6 +348,21 @@ func (s *TemplateService) PatchTemplate(ctx context.Context, id int64, updates m
 	return nil
 }
 
+// schedulePublishing queues the job that will publish a scheduled template.
+// A failure is only logged: the hourly run publishes it a little late.
+func (s *TemplateService) schedulePublishing(ctx context.Context, id int64, status domain.TemplateStatus, publishAt *time.Time) {
+	if status != domain.TemplateStatusScheduled || publishAt == nil || s.jobRepo == nil {
+		return
+	}
+
+	if err := enqueuePublishing(ctx, s.jobRepo, *publishAt); err != nil {
+		logger.Error("Failed to schedule template publishing",
+			zap.Int64("template_id", id),
+			zap.Error(err),
+		)
+	}
+}
+
 func (s *TemplateService) DeleteTemplate(ctx context.Context, id, deletedBy int64) error {
 	template, err := s.templateRepo.FindByID(ctx, id)
 	if err != nil {
@@ -275,15 +394,25 @@ func (s *TemplateService) SearchTemplates(ctx context.Context, query string, lim
 	return s.templateRepo.Search(ctx, query, limit)
 }
 
-func (s *TemplateService) GetTemplatesByCategory(ctx context.Context, categorySlug string, limit, offset int) ([]*domain.Template, int, error) {
+// GetTemplatesByCategory lists active templates in a category and, with
+// includeDescendants, in its active subcategories too
+func (s *TemplateService) GetTemplatesByCategory(ctx context.Context, categorySlug string, includeDescendants bool, limit, offset int) ([]*domain.Template, int, error) {
 	category, err := s.categoryRepo.FindBySlug(ctx, categorySlug)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, 0, apperrors.ErrNotFound
+	}
 	if err != nil {
 		return nil, 0, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to find category", 500)
 	}
-	if category == nil {
-		return nil, 0, apperrors.ErrNotFound
+
+	categoryIDs := []int64{category.ID}
+	if includeDescendants {
+		categoryIDs, err = s.categoryRepo.GetSubtreeIDs(ctx, category.ID, true)
+		if err != nil {
+			return nil, 0, apperrors.Wrap(err, "DATABASE_ERROR", "Failed to find subcategories", 500)
+		}
 	}
-	return s.templateRepo.GetByCategory(ctx, category.ID, limit, offset)
+	return s.templateRepo.GetByCategories(ctx, categoryIDs, limit, offset)
 }
 
 func (s *TemplateService) GetTemplatesByTag(ctx context.Context, tag string, limit, offset int) ([]*domain.Template, int, error) {
@@ -322,10 +451,64 @@ func (s *TemplateService) AddImage(ctx context.Context, image *domain.TemplateIm
 	return nil
 }
 
+// UploadImage processes an uploaded gallery image into renditions and adds
+// it to the template. image carries the alt text and ordering; its URL is
+// the widest rendition.
+func (s *TemplateService) UploadImage(ctx context.Context, templateID int64, file *multipart.FileHeader, image *domain.TemplateImage, createdBy int64) error {
+	template, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return domain.ErrNotFound
+	}
+
+	processed, err := s.imageService.Process(ctx, file, "images")
+	if err != nil {
+		return err
+	}
+
+	image.TemplateID = templateID
+	image.URL = processed.URL()
+	image.Width = &processed.Width
+	image.Height = &processed.Height
+	image.BlurHash = &processed.BlurHash
+	image.Renditions = processed.Renditions
+
+	if err := s.templateRepo.CreateImage(ctx, image); err != nil {
+		s.imageService.DeleteRenditions(ctx, processed.Renditions)
+		return err
+	}
+
+	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
+		AdminID:    &createdBy,
+		Action:     "add_template_image",
+		EntityType: strPtr("template_image"),
+		EntityID:   &image.ID,
+		Details: domain.JSONMap{
+			"template_id": templateID,
+			"width":       processed.Width,
+			"height":      processed.Height,
+			"renditions":  len(processed.Renditions),
+		},
+	})
+
+	return nil
+}
+
 func (s *TemplateService) DeleteImage(ctx context.Context, id, deletedBy int64) error {
+	image, err := s.templateRepo.FindImageByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to find image", 500)
+	}
+
 	if err := s.templateRepo.DeleteImage(ctx, id); err != nil {
 		return apperrors.Wrap(err, "DATABASE_ERROR", "Failed to delete image", 500)
 	}
+	s.imageService.DeleteRenditions(ctx, image.Renditions)
 
 	_ = s.logRepo.Create(ctx, &domain.ActivityLog{
 		AdminID:    &deletedBy,
@@ -402,4 +585,19 @@ func (s *TemplateService) UpdateTags(ctx context.Context, templateID int64, tags
 
 func (s *TemplateService) GetTags(ctx context.Context, templateID int64) ([]string, error) {
 	return s.templateRepo.GetTags(ctx, templateID)
-}
\ No newline at end of file
+}
+
+// normalizeLicense defaults templates to a single-seat personal license.
+// Only team licenses may carry more than one seat.
+func normalizeLicense(template *domain.Template) error {
+	if template.LicenseTier == "" {
+		template.LicenseTier = domain.LicenseTierPersonal
+	}
+	if !template.LicenseTier.IsValid() {
+		return apperrors.New("INVALID_LICENSE_TIER", "License tier must be personal, commercial or team", 400)
+	}
+	if template.LicenseSeats <= 0 || template.LicenseTier != domain.LicenseTierTeam {
+		template.LicenseSeats = 1
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/pkg/preview"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// TEMPLATE VERSION SERVICE - File versions, rollback and buyer notices
+// ============================================================================
+
+// defaultTemplateVersion matches the templates.current_version column default.
+const defaultTemplateVersion = "1.0"
+
+type TemplateVersionService struct {
+	templateRepo    repository.TemplateRepository
+	orderRepo       repository.OrderRepository
+	jobRepo         repository.BackgroundJobRepository
+	activityLogRepo repository.ActivityLogRepository
+	storageService  *StorageService
+	emailService    *EmailService
+	settingsService *SettingsService
+}
+
+func NewTemplateVersionService(
+	templateRepo repository.TemplateRepository,
+	orderRepo repository.OrderRepository,
+	jobRepo repository.BackgroundJobRepository,
+	activityLogRepo repository.ActivityLogRepository,
+	storageService *StorageService,
+	emailService *EmailService,
+	settingsService *SettingsService,
+) *TemplateVersionService {
+	return &TemplateVersionService{
+		templateRepo:    templateRepo,
+		orderRepo:       orderRepo,
+		jobRepo:         jobRepo,
+		activityLogRepo: activityLogRepo,
+		storageService:  storageService,
+		emailService:    emailService,
+		settingsService: settingsService,
+	}
+}
+
+type UploadVersionRequest struct {
+	VersionNumber string
+	Changelog     string
+	NotifyBuyers  bool
+}
+
+// ============================================================================
+// ADMIN
+// ============================================================================
+
+func (s *TemplateVersionService) ListVersions(ctx context.Context, templateID int64) ([]*domain.TemplateVersion, error) {
+	if _, err := s.getTemplate(ctx, templateID); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.GetVersions(ctx, templateID)
+}
+
+// UploadVersion stores file as a new version and makes it current. Opted-in
+// buyers are emailed from a background job when NotifyBuyers is set.
+func (s *TemplateVersionService) UploadVersion(ctx context.Context, templateID int64, file *multipart.FileHeader, req UploadVersionRequest, adminID int64) (*domain.TemplateVersion, error) {
+	return s.uploadVersion(ctx, templateID, req, adminID, func() (*UploadResult, error) {
+		return s.storageService.UploadFile(ctx, file, "templates")
+	})
+}
+
+// UploadStagedVersion is UploadVersion for a file assembled on local disk
+// by a chunked upload.
+func (s *TemplateVersionService) UploadStagedVersion(ctx context.Context, templateID int64, path, filename string, req UploadVersionRequest, adminID int64) (*domain.TemplateVersion, error) {
+	return s.uploadVersion(ctx, templateID, req, adminID, func() (*UploadResult, error) {
+		return s.storageService.UploadStagedFile(ctx, path, filename, "templates")
+	})
+}
+
+// CheckNewVersion reports whether versionNumber can be uploaded for the
+// template, so a chunked upload can fail before any bytes are sent.
+func (s *TemplateVersionService) CheckNewVersion(ctx context.Context, templateID int64, versionNumber string) error {
+	_, err := s.checkNewVersion(ctx, templateID, versionNumber)
+	return err
+}
+
+func (s *TemplateVersionService) checkNewVersion(ctx context.Context, templateID int64, versionNumber string) (*domain.Template, error) {
+	if versionNumber == "" || len(versionNumber) > 20 {
+		return nil, fmt.Errorf("%w: version_number is required and must be at most 20 characters", domain.ErrInvalidInput)
+	}
+
+	template, err := s.getTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A quarantined upload may be retried under the same number
+	existing, err := s.templateRepo.GetVersionByNumber(ctx, templateID, versionNumber)
+	if err == nil && existing.ScanStatus != domain.ScanStatusQuarantined {
+		return nil, fmt.Errorf("%w: version %s already exists", domain.ErrInvalidInput, versionNumber)
+	}
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *TemplateVersionService) uploadVersion(ctx context.Context, templateID int64, req UploadVersionRequest, adminID int64, store func() (*UploadResult, error)) (*domain.TemplateVersion, error) {
+	req.VersionNumber = strings.TrimSpace(req.VersionNumber)
+	template, err := s.checkNewVersion(ctx, templateID, req.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the file being replaced available for rollback
+	if err := s.recordCurrentFile(ctx, template); err != nil {
+		return nil, err
+	}
+
+	result, err := store()
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	version := newUploadedVersion(templateID, req.VersionNumber, result, adminID)
+	version.Changelog = nullableStr(strings.TrimSpace(req.Changelog))
+	if err := s.templateRepo.SaveVersionFile(ctx, version); err != nil {
+		return nil, err
+	}
+
+	// Kept in the version list for review, but never made current
+	if version.ScanStatus == domain.ScanStatusQuarantined {
+		s.logQuarantine(ctx, template.ID, adminID, version.VersionNumber, result)
+		return version, fmt.Errorf("%w: %s", domain.ErrQuarantined, result.ScanSignature)
+	}
+
+	if err := s.activate(ctx, template.ID, version, result.Format); err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, "upload_template_version", template.ID, adminID, domain.JSONMap{
+		"version":        version.VersionNumber,
+		"previous":       template.CurrentVersion,
+		"notify_buyers":  req.NotifyBuyers,
+		"file_public_id": version.FileURL,
+		"sha256":         result.Checksum,
+		"scan_status":    result.ScanStatus,
+	})
+
+	if req.NotifyBuyers {
+		if err := s.jobRepo.Create(ctx, &domain.BackgroundJob{
+			JobType: "send_template_update_emails",
+			Payload: domain.JSONMap{
+				"template_id": template.ID,
+				"version_id":  version.ID,
+			},
+			Status:      domain.JobStatusPending,
+			MaxRetries:  3,
+			ScheduledAt: time.Now(),
+		}); err != nil {
+			logger.Error("Failed to enqueue template update emails",
+				zap.Int64("template_id", template.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return version, nil
+}
+
+// Rollback makes an earlier version current again. Buyers are not emailed.
+func (s *TemplateVersionService) Rollback(ctx context.Context, templateID, versionID, adminID int64) (*domain.TemplateVersion, error) {
+	template, err := s.getTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.templateRepo.GetVersionByID(ctx, templateID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if version.IsCurrent {
+		return nil, fmt.Errorf("%w: version %s is already current", domain.ErrInvalidInput, version.VersionNumber)
+	}
+	if version.ScanStatus == domain.ScanStatusQuarantined {
+		return nil, fmt.Errorf("%w: version %s is quarantined", domain.ErrQuarantined, version.VersionNumber)
+	}
+
+	if err := s.activate(ctx, templateID, version, ""); err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, "rollback_template_version", templateID, adminID, domain.JSONMap{
+		"version":  version.VersionNumber,
+		"previous": template.CurrentVersion,
+	})
+
+	return version, nil
+}
+
+// ReplaceCurrentFile swaps the file of the current version in place, for
+// fixing a bad upload without shipping a new version.
+func (s *TemplateVersionService) ReplaceCurrentFile(ctx context.Context, templateID int64, file *multipart.FileHeader, adminID int64) (*UploadResult, error) {
+	template, err := s.getTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.storageService.UploadFile(ctx, file, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	// The current version keeps its old file
+	if result.ScanStatus == domain.ScanStatusQuarantined {
+		s.logQuarantine(ctx, templateID, adminID, currentVersionNumber(template), result)
+		return result, fmt.Errorf("%w: %s", domain.ErrQuarantined, result.ScanSignature)
+	}
+
+	version := newUploadedVersion(templateID, currentVersionNumber(template), result, adminID)
+	version.IsCurrent = true
+	if err := s.templateRepo.SaveVersionFile(ctx, version); err != nil {
+		return nil, err
+	}
+	if err := s.activate(ctx, templateID, version, result.Format); err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, "replace_template_file", templateID, adminID, domain.JSONMap{
+		"version":        version.VersionNumber,
+		"file_public_id": version.FileURL,
+		"sha256":         result.Checksum,
+		"scan_status":    result.ScanStatus,
+	})
+
+	return result, nil
+}
+
+// ============================================================================
+// BUYER NOTIFICATIONS - Called from the worker
+// ============================================================================
+
+// NotifyBuyers emails every opted-in buyer of the template about version.
+// It returns how many emails were sent; a failed send is logged and skipped
+// so one bad address does not hold up the rest.
+func (s *TemplateVersionService) NotifyBuyers(ctx context.Context, templateID, versionID int64) (int, error) {
+	template, err := s.getTemplate(ctx, templateID)
+	if err != nil {
+		return 0, err
+	}
+	version, err := s.templateRepo.GetVersionByID(ctx, templateID, versionID)
+	if err != nil {
+		return 0, err
+	}
+
+	// A rollback since the upload means there is nothing new to announce
+	if !version.IsCurrent {
+		logger.Info("Skipping update emails for a version that is no longer current",
+			zap.Int64("template_id", templateID),
+			zap.String("version", version.VersionNumber),
+		)
+		return 0, nil
+	}
+
+	subscribers, err := s.orderRepo.FindUpdateSubscribers(ctx, templateID, version.VersionNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	servesLatest := s.ServesLatest(ctx)
+	sent := 0
+	for _, subscriber := range subscribers {
+		if err := s.emailService.SendTemplateUpdate(ctx, subscriber, template, version, servesLatest); err != nil {
+			logger.Error("Failed to send template update email",
+				zap.String("order_number", subscriber.OrderNumber),
+				zap.Error(err),
+			)
+			continue
+		}
+		sent++
+	}
+
+	logger.Info("Template update emails sent",
+		zap.Int64("template_id", templateID),
+		zap.String("version", version.VersionNumber),
+		zap.Int("sent", sent),
+		zap.Int("subscribers", len(subscribers)),
+	)
+	return sent, nil
+}
+
+// ServesLatest reports whether download links deliver the current version
+// (the default) rather than the version that was purchased.
+func (s *TemplateVersionService) ServesLatest(ctx context.Context) bool {
+	return servesLatestVersion(ctx, s.settingsService)
+}
+
+func servesLatestVersion(ctx context.Context, settings *SettingsService) bool {
+	return settings.GetString(ctx, domain.SettingDownloadVersion, domain.VersionPolicyLatest) != domain.VersionPolicyPurchased
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *TemplateVersionService) getTemplate(ctx context.Context, templateID int64) (*domain.Template, error) {
+	template, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, domain.ErrNotFound
+	}
+	return template, nil
+}
+
+// recordCurrentFile adds a version row for a template whose file was set
+// before versions were tracked, e.g. through create or patch.
+func (s *TemplateVersionService) recordCurrentFile(ctx context.Context, template *domain.Template) error {
+	if template.FileURL == nil || *template.FileURL == "" {
+		return nil
+	}
+
+	_, err := s.templateRepo.GetVersionByNumber(ctx, template.ID, currentVersionNumber(template))
+	if err == nil || !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	return s.templateRepo.SaveVersionFile(ctx, &domain.TemplateVersion{
+		TemplateID:    template.ID,
+		VersionNumber: currentVersionNumber(template),
+		FileURL:       *template.FileURL,
+		FileSizeMB:    template.FileSizeMB,
+		IsCurrent:     true,
+	})
+}
+
+func newUploadedVersion(templateID int64, versionNumber string, result *UploadResult, adminID int64) *domain.TemplateVersion {
+	sizeMB := float64(result.Bytes) / (1024 * 1024)
+	return &domain.TemplateVersion{
+		TemplateID:     templateID,
+		VersionNumber:  versionNumber,
+		FileURL:        result.PublicID,
+		FileSizeMB:     &sizeMB,
+		UploadedBy:     &adminID,
+		ChecksumSHA256: nullableStr(result.Checksum),
+		ContentType:    nullableStr(result.ContentType),
+		ScanStatus:     result.ScanStatus,
+		ScanSignature:  nullableStr(result.ScanSignature),
+	}
+}
+
+// activate makes version current and, for a fresh upload, records the
+// file format sniffed from its content. Previews are regenerated from the
+// new file in the background.
+func (s *TemplateVersionService) activate(ctx context.Context, templateID int64, version *domain.TemplateVersion, format string) error {
+	if err := s.templateRepo.ActivateVersion(ctx, templateID, version.ID); err != nil {
+		return err
+	}
+	version.IsCurrent = true
+
+	if format != "" {
+		if err := s.templateRepo.Patch(ctx, templateID, map[string]interface{}{"file_format": format}); err != nil {
+			return err
+		}
+	}
+
+	// A rollback doesn't know the format; the worker skips unsupported ones
+	if format == "" || preview.Supports(format) {
+		if _, err := enqueueTemplatePreviews(ctx, s.jobRepo, templateID); err != nil {
+			logger.Error("Failed to enqueue template previews",
+				zap.Int64("template_id", templateID),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+func (s *TemplateVersionService) logQuarantine(ctx context.Context, templateID, adminID int64, versionNumber string, result *UploadResult) {
+	logger.Warn("Template upload quarantined",
+		zap.Int64("template_id", templateID),
+		zap.String("version", versionNumber),
+		zap.String("signature", result.ScanSignature),
+	)
+	s.logActivity(ctx, "quarantine_template_upload", templateID, adminID, domain.JSONMap{
+		"version":        versionNumber,
+		"file_public_id": result.PublicID,
+		"sha256":         result.Checksum,
+		"signature":      result.ScanSignature,
+	})
+}
+
+func (s *TemplateVersionService) logActivity(ctx context.Context, action string, templateID, adminID int64, details domain.JSONMap) {
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		AdminID:    &adminID,
+		Action:     action,
+		EntityType: strPtr("template"),
+		EntityID:   &templateID,
+		Details:    details,
+	})
+}
+
+func currentVersionNumber(template *domain.Template) string {
+	if template.CurrentVersion == "" {
+		return defaultTemplateVersion
+	}
+	return template.CurrentVersion
+}
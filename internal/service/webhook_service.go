@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// WEBHOOK SERVICE - Stored webhook dispatch, retries and replay
+// ============================================================================
+
+const (
+	webhookRetryBaseDelay = time.Minute
+	webhookRetryMaxDelay  = time.Hour
+
+	// How long a sweeper's claim on a webhook lasts before another replica
+	// may take it over
+	webhookClaimLease = 10 * time.Minute
+)
+
+type WebhookService struct {
+	webhookRepo     repository.PaymentWebhookRepository
+	orderService    *OrderService
+	disputeService  *DisputeService
+	activityLogRepo repository.ActivityLogRepository
+}
+
+func NewWebhookService(
+	webhookRepo repository.PaymentWebhookRepository,
+	orderService *OrderService,
+	disputeService *DisputeService,
+	activityLogRepo repository.ActivityLogRepository,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo:     webhookRepo,
+		orderService:    orderService,
+		disputeService:  disputeService,
+		activityLogRepo: activityLogRepo,
+	}
+}
+
+// ============================================================================
+// PROCESSING
+// ============================================================================
+
+// Process applies a stored webhook and records the outcome on it. A failure
+// schedules the next attempt with exponential backoff; once max_retries is
+// reached the sweeper leaves the webhook for an admin to replay.
+// Webhooks whose signature did not verify are never applied.
+func (s *WebhookService) Process(ctx context.Context, webhook *domain.PaymentWebhook) error {
+	if !webhook.SignatureVerified {
+		logger.Warn("Ignoring webhook with unverified signature",
+			zap.Int64("webhook_id", webhook.ID),
+			zap.String("event", webhook.EventType),
+		)
+		return s.webhookRepo.IncrementRetryCount(ctx, webhook.ID, "signature not verified", nil)
+	}
+
+	if err := s.dispatch(ctx, webhook); err != nil {
+		var nextRetryAt *time.Time
+		if webhook.RetryCount+1 < webhook.MaxRetries {
+			next := time.Now().Add(webhookRetryDelay(webhook.RetryCount))
+			nextRetryAt = &next
+		}
+
+		if recordErr := s.webhookRepo.IncrementRetryCount(ctx, webhook.ID, err.Error(), nextRetryAt); recordErr != nil {
+			logger.Error("Failed to record webhook failure",
+				zap.Int64("webhook_id", webhook.ID),
+				zap.Error(recordErr),
+			)
+		}
+		return err
+	}
+
+	return s.webhookRepo.MarkAsProcessed(ctx, webhook.ID)
+}
+
+// dispatch routes a webhook to the service that owns its event type.
+func (s *WebhookService) dispatch(ctx context.Context, webhook *domain.PaymentWebhook) error {
+	gatewayOrderID := derefStr(webhook.GatewayOrderID)
+	gatewayPaymentID := derefStr(webhook.GatewayPaymentID)
+
+	switch webhook.EventType {
+	case "payment.captured":
+		return s.orderService.MarkPaymentCaptured(ctx, gatewayOrderID, gatewayPaymentID)
+
+	case "payment.failed":
+		return s.orderService.MarkPaymentFailed(ctx, gatewayOrderID)
+	}
+
+	if IsDisputeEvent(webhook.EventType) {
+		payload, _ := webhook.Payload["payload"].(map[string]interface{})
+		return s.disputeService.HandleWebhookEvent(ctx, webhook.EventType, payload)
+	}
+
+	logger.Warn("unhandled webhook event", zap.String("event", webhook.EventType))
+	return nil
+}
+
+// SweepUnprocessed retries webhooks that are due. Every API replica runs a
+// sweeper, so webhooks are claimed before they are dispatched. It returns how
+// many were applied successfully.
+func (s *WebhookService) SweepUnprocessed(ctx context.Context, limit int) (int, error) {
+	webhooks, err := s.webhookRepo.ClaimUnprocessed(ctx, limit, webhookClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, webhook := range webhooks {
+		if err := s.Process(ctx, webhook); err != nil {
+			logger.Warn("Webhook retry failed",
+				zap.Int64("webhook_id", webhook.ID),
+				zap.String("event", webhook.EventType),
+				zap.Int("attempt", webhook.RetryCount+1),
+				zap.Error(err),
+			)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// webhookRetryDelay doubles the wait after every failed attempt.
+func webhookRetryDelay(retryCount int) time.Duration {
+	delay := webhookRetryBaseDelay << retryCount
+	if delay <= 0 || delay > webhookRetryMaxDelay {
+		return webhookRetryMaxDelay
+	}
+	return delay
+}
+
+// ============================================================================
+// ADMIN
+// ============================================================================
+
+func (s *WebhookService) GetAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.PaymentWebhook, int, error) {
+	return s.webhookRepo.GetAll(ctx, filters, limit, (page-1)*limit)
+}
+
+func (s *WebhookService) Get(ctx context.Context, id int64) (*domain.PaymentWebhook, error) {
+	return s.webhookRepo.FindByID(ctx, id)
+}
+
+// Replay applies a webhook again on an admin's request, regardless of its
+// retry budget or whether it was already processed. The payment handlers are
+// idempotent, so replaying a processed webhook is harmless. It takes the same
+// claim as the sweeper, so a webhook a sweeper is dispatching is refused with
+// ErrVersionConflict rather than applied twice at once. When the replay
+// itself fails, the refreshed webhook is returned alongside the error.
+func (s *WebhookService) Replay(ctx context.Context, id, adminID int64) (*domain.PaymentWebhook, error) {
+	webhook, err := s.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !webhook.SignatureVerified {
+		return nil, fmt.Errorf("%w: webhook signature was not verified", domain.ErrInvalidInput)
+	}
+
+	claimed, err := s.webhookRepo.Claim(ctx, id, webhookClaimLease)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("%w: webhook is being processed, try again shortly", domain.ErrVersionConflict)
+	}
+
+	processErr := s.Process(ctx, webhook)
+
+	details := map[string]interface{}{
+		"event_type": webhook.EventType,
+	}
+	if processErr != nil {
+		details["error"] = processErr.Error()
+	}
+	s.logActivity(ctx, "replay_webhook", webhook.ID, adminID, details)
+
+	updated, err := s.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return updated, processErr
+}
+
+func (s *WebhookService) logActivity(ctx context.Context, action string, entityID int64, adminID int64, metadata map[string]interface{}) {
+	if s.activityLogRepo == nil {
+		return
+	}
+
+	_ = s.activityLogRepo.Create(ctx, &domain.ActivityLog{
+		Action:     action,
+		EntityType: strPtr("payment_webhook"),
+		EntityID:   &entityID,
+		AdminID:    &adminID,
+		Details:    domain.JSONMap(metadata),
+	})
+}
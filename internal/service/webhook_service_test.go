@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/domain"
+	"github.com/merraki/merraki-backend/internal/repository"
+)
+
+// fakeWebhookRepo keeps one webhook and its processing claim; the embedded
+// interface panics if anything else is called.
+type fakeWebhookRepo struct {
+	repository.PaymentWebhookRepository
+	webhook   *domain.PaymentWebhook
+	claimed   bool
+	processed int
+}
+
+func (r *fakeWebhookRepo) FindByID(ctx context.Context, id int64) (*domain.PaymentWebhook, error) {
+	if r.webhook == nil || r.webhook.ID != id {
+		return nil, domain.ErrNotFound
+	}
+	copied := *r.webhook
+	return &copied, nil
+}
+
+func (r *fakeWebhookRepo) Claim(ctx context.Context, id int64, lease time.Duration) (bool, error) {
+	if r.claimed {
+		return false, nil
+	}
+	r.claimed = true
+	return true, nil
+}
+
+func (r *fakeWebhookRepo) MarkAsProcessed(ctx context.Context, id int64) error {
+	r.processed++
+	r.claimed = false
+	r.webhook.Processed = true
+	return nil
+}
+
+func TestWebhookReplayTakesClaim(t *testing.T) {
+	repo := &fakeWebhookRepo{webhook: &domain.PaymentWebhook{
+		ID:                7,
+		EventType:         "order.paid", // not routed, so dispatch succeeds
+		SignatureVerified: true,
+	}}
+	svc := NewWebhookService(repo, nil, nil, nil)
+
+	// A sweeper is dispatching it
+	repo.claimed = true
+	if _, err := svc.Replay(context.Background(), 7, 1); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("replay while claimed: err = %v, want ErrVersionConflict", err)
+	}
+	if repo.processed != 0 {
+		t.Fatalf("replay while claimed processed the webhook %d times", repo.processed)
+	}
+
+	repo.claimed = false
+	webhook, err := svc.Replay(context.Background(), 7, 1)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if repo.processed != 1 || !webhook.Processed {
+		t.Errorf("replay: processed %d times, webhook = %+v", repo.processed, webhook)
+	}
+	if repo.claimed {
+		t.Error("replay left the claim held")
+	}
+}
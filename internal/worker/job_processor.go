@@ -27,9 +27,18 @@ type JobProcessor struct {
 
 	emailService     *service.EmailService
 	downloadTokenSvc *service.DownloadTokenService
+	licenseService   *service.LicenseService
+	versionService   *service.TemplateVersionService
+	uploadService    *service.ChunkedUploadService
+	previewService   *service.TemplatePreviewService
+	recommendations  *service.RecommendationService
+	priceRules       *service.PriceRuleService
 	paymentService   *service.PaymentService
 	pdfService       *service.PDFService
 	storageService   *service.StorageService
+	reconService     *service.ReconciliationService
+	webhooks         *service.OutboundWebhookService
+	publishing       *service.PublishingService
 
 	workerID       string
 	maxConcurrency int
@@ -47,9 +56,18 @@ func NewJobProcessor(
 	idempotencyRepo repository.IdempotencyKeyRepository,
 	emailService *service.EmailService,
 	downloadTokenSvc *service.DownloadTokenService,
+	licenseService *service.LicenseService,
+	versionService *service.TemplateVersionService,
+	uploadService *service.ChunkedUploadService,
+	previewService *service.TemplatePreviewService,
+	recommendations *service.RecommendationService,
+	priceRules *service.PriceRuleService,
 	paymentService *service.PaymentService,
 	pdfService *service.PDFService,
 	storageService *service.StorageService,
+	reconService *service.ReconciliationService,
+	webhooks *service.OutboundWebhookService,
+	publishing *service.PublishingService,
 	workerID string,
 ) *JobProcessor {
 	return &JobProcessor{
@@ -62,9 +80,18 @@ func NewJobProcessor(
 		idempotencyRepo:   idempotencyRepo,
 		emailService:      emailService,
 		downloadTokenSvc:  downloadTokenSvc,
+		licenseService:    licenseService,
+		versionService:    versionService,
+		uploadService:     uploadService,
+		previewService:    previewService,
+		recommendations:   recommendations,
+		priceRules:        priceRules,
 		paymentService:    paymentService,
 		pdfService:        pdfService,
 		storageService:    storageService,
+		reconService:      reconService,
+		webhooks:          webhooks,
+		publishing:        publishing,
 		workerID:          workerID,
 		maxConcurrency:    5,
 		pollInterval:      5 * time.Second,
@@ -204,6 +231,27 @@ func (w *JobProcessor) executeJob(ctx context.Context, job *domain.BackgroundJob
 	case "send_admin_review_notification":
 		return w.handleSendAdminReviewNotification(ctx, job)
 
+	case "send_admin_dispute_notification":
+		return w.handleSendAdminDisputeNotification(ctx, job)
+
+	case "send_template_update_emails":
+		return w.handleSendTemplateUpdateEmails(ctx, job)
+
+	case "generate_template_previews":
+		return w.handleGenerateTemplatePreviews(ctx, job)
+
+	case "refresh_related_templates":
+		return w.handleRefreshRelatedTemplates(ctx, job)
+
+	case "apply_price_rules":
+		return w.handleApplyPriceRules(ctx, job)
+
+	case "publish_scheduled_content":
+		return w.handlePublishScheduledContent(ctx, job)
+
+	case "send_publish_announcement":
+		return w.handleSendPublishAnnouncement(ctx, job)
+
 	case "generate_download_tokens":
 		return w.handleGenerateDownloadTokens(ctx, job)
 
@@ -219,6 +267,15 @@ func (w *JobProcessor) executeJob(ctx context.Context, job *domain.BackgroundJob
 	case "cleanup_idempotency_keys":
 		return w.handleCleanupIdempotencyKeys(ctx, job)
 
+	case "cleanup_upload_sessions":
+		return w.handleCleanupUploadSessions(ctx, job)
+
+	case "reconcile_payments":
+		return w.handleReconcilePayments(ctx, job)
+
+	case "deliver_outbound_webhook":
+		return w.handleDeliverOutboundWebhook(ctx, job)
+
 	default:
 		return fmt.Errorf("unknown job type: %s", job.JobType)
 	}
@@ -268,8 +325,19 @@ func (w *JobProcessor) handleSendOrderApprovalEmail(ctx context.Context, job *do
 		return fmt.Errorf("failed to get download tokens: %w", err)
 	}
 
+	// Bundle link is optional (single-item orders have none)
+	bundle, err := w.downloadTokenSvc.GetBundleToken(ctx, orderID)
+	if err != nil {
+		logger.Warn("Failed to load bundle token", zap.Int64("order_id", orderID), zap.Error(err))
+	}
+
+	licenses, err := w.licenseService.GetByOrderID(ctx, orderID)
+	if err != nil {
+		logger.Warn("Failed to load licenses", zap.Int64("order_id", orderID), zap.Error(err))
+	}
+
 	// Send email
-	return w.emailService.SendOrderApproval(ctx, order, tokens)
+	return w.emailService.SendOrderApproval(ctx, order, tokens, bundle, licenses)
 }
 
 func (w *JobProcessor) handleSendOrderRejectionEmail(ctx context.Context, job *domain.BackgroundJob) error {
@@ -306,6 +374,134 @@ func (w *JobProcessor) handleSendAdminReviewNotification(ctx context.Context, jo
 	return w.emailService.SendAdminOrderNotification(ctx, order)
 }
 
+func (w *JobProcessor) handleSendAdminDisputeNotification(ctx context.Context, job *domain.BackgroundJob) error {
+	orderID, err := w.getInt64FromPayload(job.Payload, "order_id")
+	if err != nil {
+		return err
+	}
+
+	status, _ := w.getStringFromPayload(job.Payload, "status")
+	reason, _ := w.getStringFromPayload(job.Payload, "reason")
+	respondBy, _ := w.getStringFromPayload(job.Payload, "respond_by")
+	currency, _ := w.getStringFromPayload(job.Payload, "currency")
+	amountMinor, err := w.getInt64FromPayload(job.Payload, "amount_minor")
+	if err != nil {
+		// Jobs queued before amounts carried their currency were USD cents
+		amountMinor, _ = w.getInt64FromPayload(job.Payload, "amount_cents")
+	}
+
+	// Get order
+	order, err := w.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	amount := domain.FormatMinorAmount(amountMinor, currency)
+	return w.emailService.SendAdminDisputeNotification(ctx, order, status, amount, reason, respondBy)
+}
+
+// handleSendTemplateUpdateEmails tells opted-in buyers about a new version.
+// Individual send failures are logged by the service rather than failing
+// the job, so a retry never emails the same buyers twice.
+func (w *JobProcessor) handleSendTemplateUpdateEmails(ctx context.Context, job *domain.BackgroundJob) error {
+	templateID, err := w.getInt64FromPayload(job.Payload, "template_id")
+	if err != nil {
+		return err
+	}
+	versionID, err := w.getInt64FromPayload(job.Payload, "version_id")
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.versionService.NotifyBuyers(ctx, templateID, versionID); err != nil {
+		return fmt.Errorf("failed to send template update emails: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// JOB HANDLERS - Template Previews
+// ============================================================================
+
+func (w *JobProcessor) handleGenerateTemplatePreviews(ctx context.Context, job *domain.BackgroundJob) error {
+	templateID, err := w.getInt64FromPayload(job.Payload, "template_id")
+	if err != nil {
+		return err
+	}
+
+	count, err := w.previewService.Generate(ctx, templateID)
+	if err != nil {
+		return fmt.Errorf("failed to generate template previews: %w", err)
+	}
+
+	logger.Info("Template previews generated",
+		zap.Int64("template_id", templateID),
+		zap.Int("count", count),
+	)
+	return nil
+}
+
+func (w *JobProcessor) handleRefreshRelatedTemplates(ctx context.Context, job *domain.BackgroundJob) error {
+	pairs, err := w.recommendations.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh related templates: %w", err)
+	}
+
+	logger.Info("Related templates refreshed", zap.Int64("pairs", pairs))
+	return nil
+}
+
+func (w *JobProcessor) handleApplyPriceRules(ctx context.Context, job *domain.BackgroundJob) error {
+	changed, err := w.priceRules.Apply(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to apply price rules: %w", err)
+	}
+
+	logger.Info("Price rules applied", zap.Int("templates_changed", changed))
+	return nil
+}
+
+// ============================================================================
+// JOB HANDLERS - Scheduled Publishing
+// ============================================================================
+
+func (w *JobProcessor) handlePublishScheduledContent(ctx context.Context, job *domain.BackgroundJob) error {
+	published, err := w.publishing.PublishDue(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to publish scheduled content: %w", err)
+	}
+
+	if published > 0 {
+		logger.Info("Scheduled content published", zap.Int("count", published))
+	}
+	return nil
+}
+
+// handleSendPublishAnnouncement emails newsletter subscribers about a newly
+// published template or blog post
+func (w *JobProcessor) handleSendPublishAnnouncement(ctx context.Context, job *domain.BackgroundJob) error {
+	contentType, ok := job.Payload["content_type"].(string)
+	if !ok {
+		return fmt.Errorf("missing key in payload: content_type")
+	}
+	id, err := w.getInt64FromPayload(job.Payload, "id")
+	if err != nil {
+		return err
+	}
+
+	sent, err := w.publishing.Announce(ctx, contentType, id)
+	if err != nil {
+		return fmt.Errorf("failed to send publish announcement: %w", err)
+	}
+
+	logger.Info("Publish announcement sent",
+		zap.String("content_type", contentType),
+		zap.Int64("id", id),
+		zap.Int("recipients", sent),
+	)
+	return nil
+}
+
 // ============================================================================
 // JOB HANDLERS - Download Tokens
 // ============================================================================
@@ -325,6 +521,27 @@ func (w *JobProcessor) handleGenerateDownloadTokens(ctx context.Context, job *do
 		zap.Int64("order_id", orderID),
 	)
 
+	// Licenses are issued alongside the tokens; both steps skip items
+	// that are already done, so a retry is safe
+	if _, err := w.licenseService.IssueForOrder(ctx, orderID); err != nil {
+		return fmt.Errorf("failed to issue licenses: %w", err)
+	}
+
+	// The approval email carries the download links and license keys, so it
+	// is only queued once both exist. The job ID keeps a retry of this job
+	// from sending it twice.
+	jobID := fmt.Sprintf("send_order_approval_email:%d", orderID)
+	if _, err := w.jobRepo.CreateUnique(ctx, &domain.BackgroundJob{
+		JobType:     "send_order_approval_email",
+		JobID:       &jobID,
+		Payload:     domain.JSONMap{"order_id": orderID},
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to queue approval email: %w", err)
+	}
+
 	return nil
 }
 
@@ -460,8 +677,37 @@ func (w *JobProcessor) handlePaymentFailedWebhook(ctx context.Context, webhook *
 }
 
 func (w *JobProcessor) handleRefundProcessedWebhook(ctx context.Context, webhook *domain.PaymentWebhook, data map[string]interface{}) error {
-	// Handle refund webhook
-	logger.Info("Refund webhook processed")
+	// Refunds issued from the Razorpay dashboard only reach us here, so the
+	// licenses are revoked on the webhook as well as in process_refund
+	var paymentID string
+	if webhook.GatewayPaymentID != nil {
+		paymentID = *webhook.GatewayPaymentID
+	} else {
+		payload, _ := data["payload"].(map[string]interface{})
+		refund, _ := payload["refund"].(map[string]interface{})
+		entity, _ := refund["entity"].(map[string]interface{})
+		paymentID, _ = entity["payment_id"].(string)
+	}
+	if paymentID == "" {
+		return fmt.Errorf("webhook missing payment ID")
+	}
+
+	payment, err := w.paymentRepo.FindByGatewayPaymentID(ctx, paymentID)
+	if err != nil {
+		return fmt.Errorf("failed to find payment: %w", err)
+	}
+
+	revoked, err := w.licenseService.RevokeForOrder(ctx, payment.OrderID, "Order refunded")
+	if err != nil {
+		return fmt.Errorf("failed to revoke licenses: %w", err)
+	}
+
+	logger.Info("Refund webhook processed",
+		zap.String("payment_id", paymentID),
+		zap.Int64("order_id", payment.OrderID),
+		zap.Int64("licenses_revoked", revoked),
+	)
+
 	return nil
 }
 
@@ -493,6 +739,12 @@ func (w *JobProcessor) handleProcessRefund(ctx context.Context, job *domain.Back
 		return fmt.Errorf("failed to get payment: %w", err)
 	}
 
+	// Revoke before the money moves: a failure here retries the job without
+	// having refunded, whereas a retry after CreateRefund would refund twice
+	if _, err := w.licenseService.RevokeForOrder(ctx, order.ID, "Order refunded"); err != nil {
+		return fmt.Errorf("failed to revoke licenses: %w", err)
+	}
+
 	// Process refund via Razorpay
 	refund, err := w.paymentService.CreateRefund(ctx, &service.CreateRefundRequest{
 		PaymentID: *payment.GatewayPaymentID,
@@ -526,6 +778,8 @@ func (w *JobProcessor) handleProcessRefund(ctx context.Context, job *domain.Back
 		zap.String("refund_id", refund.ID),
 	)
 
+	w.webhooks.PublishOrder(ctx, domain.OutboundEventOrderRefunded, order)
+
 	return nil
 }
 
@@ -559,6 +813,51 @@ func (w *JobProcessor) handleCleanupIdempotencyKeys(ctx context.Context, job *do
 	return nil
 }
 
+func (w *JobProcessor) handleCleanupUploadSessions(ctx context.Context, job *domain.BackgroundJob) error {
+	if _, err := w.uploadService.CleanupExpired(ctx); err != nil {
+		return fmt.Errorf("failed to cleanup upload sessions: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// JOB HANDLERS - Reconciliation
+// ============================================================================
+
+func (w *JobProcessor) handleReconcilePayments(ctx context.Context, job *domain.BackgroundJob) error {
+	date, err := w.getStringFromPayload(job.Payload, "date")
+	if err != nil {
+		return err
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return fmt.Errorf("invalid reconciliation date %q: %w", date, err)
+	}
+
+	if _, err := w.reconService.Run(ctx, day, nil); err != nil {
+		return fmt.Errorf("failed to reconcile payments: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// JOB HANDLERS - Outbound Webhooks
+// ============================================================================
+
+func (w *JobProcessor) handleDeliverOutboundWebhook(ctx context.Context, job *domain.BackgroundJob) error {
+	deliveryID, err := w.getInt64FromPayload(job.Payload, "delivery_id")
+	if err != nil {
+		return err
+	}
+
+	// The processor gives up once this attempt fails on the last retry
+	finalAttempt := job.RetryCount+1 >= job.MaxRetries
+
+	return w.webhooks.Deliver(ctx, deliveryID, finalAttempt)
+}
+
 // ============================================================================
 // HELPER FUNCTIONS
 // ============================================================================
@@ -60,6 +60,20 @@ func (s *ScheduledJobRunner) scheduleJobs(ctx context.Context) {
 	// Schedule cleanup jobs
 	s.scheduleCleanupExpiredTokens(ctx)
 	s.scheduleCleanupIdempotencyKeys(ctx)
+	s.scheduleCleanupUploadSessions(ctx)
+
+	// Schedule reconciliation
+	s.scheduleDailyReconciliation(ctx)
+
+	// Recommendations
+	s.scheduleNightlyRelatedTemplates(ctx)
+
+	// Scheduled sales; each rule also queues runs at its own start and end
+	s.scheduleHourlyPriceRules(ctx)
+
+	// Scheduled templates and blog posts; each also queues a run for its
+	// own publish time
+	s.scheduleHourlyPublishing(ctx)
 }
 
 func (s *ScheduledJobRunner) scheduleCleanupExpiredTokens(ctx context.Context) {
@@ -90,4 +104,100 @@ func (s *ScheduledJobRunner) scheduleCleanupIdempotencyKeys(ctx context.Context)
 	if err := s.jobRepo.Create(ctx, job); err != nil {
 		logger.Error("Failed to schedule cleanup_idempotency_keys job", zap.Error(err))
 	}
-}
\ No newline at end of file
+}
+
+func (s *ScheduledJobRunner) scheduleCleanupUploadSessions(ctx context.Context) {
+	job := &domain.BackgroundJob{
+		JobType:     "cleanup_upload_sessions",
+		Payload:     make(domain.JSONMap),
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+		Priority:    0,
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		logger.Error("Failed to schedule cleanup_upload_sessions job", zap.Error(err))
+	}
+}
+
+// scheduleDailyReconciliation enqueues one reconciliation job per day for the
+// previous UTC day. The job ID carries the date, so hourly ticks after the
+// first one are no-ops.
+func (s *ScheduledJobRunner) scheduleDailyReconciliation(ctx context.Context) {
+	date := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	jobID := "reconcile_payments:" + date
+	job := &domain.BackgroundJob{
+		JobID:       &jobID,
+		JobType:     "reconcile_payments",
+		Payload:     domain.JSONMap{"date": date},
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+		Priority:    0,
+	}
+
+	if _, err := s.jobRepo.CreateUnique(ctx, job); err != nil {
+		logger.Error("Failed to schedule reconcile_payments job", zap.Error(err))
+	}
+}
+
+// scheduleNightlyRelatedTemplates enqueues one related-templates refresh per
+// UTC day, deduplicated by date like the reconciliation job.
+func (s *ScheduledJobRunner) scheduleNightlyRelatedTemplates(ctx context.Context) {
+	jobID := "refresh_related_templates:" + time.Now().UTC().Format("2006-01-02")
+	job := &domain.BackgroundJob{
+		JobID:       &jobID,
+		JobType:     "refresh_related_templates",
+		Payload:     make(domain.JSONMap),
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+		Priority:    0,
+	}
+
+	if _, err := s.jobRepo.CreateUnique(ctx, job); err != nil {
+		logger.Error("Failed to schedule refresh_related_templates job", zap.Error(err))
+	}
+}
+
+// scheduleHourlyPriceRules re-applies price rules once an hour, in case a
+// run queued for a rule's start or end was lost. The job ID shares the
+// format of those runs, so one falling on the hour is not repeated.
+func (s *ScheduledJobRunner) scheduleHourlyPriceRules(ctx context.Context) {
+	hour := time.Now().UTC().Truncate(time.Hour)
+	jobID := "apply_price_rules:" + hour.Format(time.RFC3339)
+	job := &domain.BackgroundJob{
+		JobID:       &jobID,
+		JobType:     "apply_price_rules",
+		Payload:     make(domain.JSONMap),
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+		Priority:    0,
+	}
+
+	if _, err := s.jobRepo.CreateUnique(ctx, job); err != nil {
+		logger.Error("Failed to schedule apply_price_rules job", zap.Error(err))
+	}
+}
+
+// scheduleHourlyPublishing publishes anything whose own publish run was
+// lost, at most an hour late
+func (s *ScheduledJobRunner) scheduleHourlyPublishing(ctx context.Context) {
+	hour := time.Now().UTC().Truncate(time.Hour)
+	jobID := "publish_scheduled_content:" + hour.Format(time.RFC3339)
+	job := &domain.BackgroundJob{
+		JobID:       &jobID,
+		JobType:     "publish_scheduled_content",
+		Payload:     make(domain.JSONMap),
+		Status:      domain.JobStatusPending,
+		MaxRetries:  3,
+		ScheduledAt: time.Now(),
+		Priority:    0,
+	}
+
+	if _, err := s.jobRepo.CreateUnique(ctx, job); err != nil {
+		logger.Error("Failed to schedule publish_scheduled_content job", zap.Error(err))
+	}
+}
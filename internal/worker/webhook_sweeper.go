@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/merraki/merraki-backend/internal/pkg/logger"
+	"github.com/merraki/merraki-backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// WEBHOOK SWEEPER - Retries stored webhooks that failed to apply
+// ============================================================================
+
+const webhookSweepBatchSize = 50
+
+type WebhookSweeper struct {
+	webhookService *service.WebhookService
+	ticker         *time.Ticker
+	done           chan struct{}
+}
+
+func NewWebhookSweeper(webhookService *service.WebhookService) *WebhookSweeper {
+	return &WebhookSweeper{
+		webhookService: webhookService,
+		ticker:         time.NewTicker(1 * time.Minute), // Backoff is enforced per webhook
+		done:           make(chan struct{}),
+	}
+}
+
+func (s *WebhookSweeper) Start(ctx context.Context) error {
+	logger.Info("Starting webhook sweeper")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.ticker.Stop()
+			return nil
+
+		case <-s.done:
+			s.ticker.Stop()
+			return nil
+
+		case <-s.ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *WebhookSweeper) Stop() {
+	close(s.done)
+}
+
+func (s *WebhookSweeper) sweep(ctx context.Context) {
+	processed, err := s.webhookService.SweepUnprocessed(ctx, webhookSweepBatchSize)
+	if err != nil {
+		logger.Error("Failed to sweep unprocessed webhooks", zap.Error(err))
+		return
+	}
+
+	if processed > 0 {
+		logger.Info("Reprocessed stored webhooks", zap.Int("count", processed))
+	}
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_settings_history_changed_at;
+DROP INDEX IF EXISTS idx_settings_history_key;
+
+DROP TABLE IF EXISTS settings_history;
+
+DROP TRIGGER IF EXISTS update_settings_updated_at ON settings;
+DROP TABLE IF EXISTS settings;
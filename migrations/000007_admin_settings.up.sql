@@ -0,0 +1,50 @@
+-- ============================================================================
+-- ADMIN SETTINGS - Typed, versioned runtime configuration
+-- ============================================================================
+CREATE TABLE settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value JSONB NOT NULL,
+
+    -- JSON-schema subset used to validate updates (type, minimum, maximum,
+    -- minLength, maxLength, enum, format, pattern)
+    schema JSONB NOT NULL DEFAULT '{}',
+    description TEXT,
+
+    -- Optimistic locking
+    version INT NOT NULL DEFAULT 1,
+    updated_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_settings_updated_at BEFORE UPDATE ON settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- SETTINGS HISTORY - Who changed what, and when
+-- ============================================================================
+CREATE TABLE settings_history (
+    id BIGSERIAL PRIMARY KEY,
+    setting_key VARCHAR(100) NOT NULL REFERENCES settings(key) ON DELETE CASCADE,
+    old_value JSONB,
+    new_value JSONB NOT NULL,
+    version INT NOT NULL,
+    changed_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_settings_history_key ON settings_history(setting_key);
+CREATE INDEX idx_settings_history_changed_at ON settings_history(changed_at DESC);
+
+-- ============================================================================
+-- SEED DATA
+-- ============================================================================
+INSERT INTO settings (key, value, schema, description) VALUES
+('downloads.expiry_days', '30', '{"type": "integer", "minimum": 1, "maximum": 3650}', 'Days a download link stays valid after approval'),
+('downloads.max_per_token', '5', '{"type": "integer", "minimum": 1, "maximum": 100}', 'Maximum downloads allowed per purchased item'),
+('orders.auto_approve', 'false', '{"type": "boolean"}', 'Approve paid orders without manual review'),
+('store.name', '"Merraki Solutions"', '{"type": "string", "minLength": 1, "maxLength": 100}', 'Store name shown in customer emails'),
+('store.support_email', '"info@merrakisolutions.com"', '{"type": "string", "format": "email"}', 'Support address shown to customers'),
+('tax.enabled', 'false', '{"type": "boolean"}', 'Charge tax on new orders'),
+('tax.rate_percent', '0', '{"type": "number", "minimum": 0, "maximum": 100}', 'Tax rate applied to the order subtotal when tax is enabled');
@@ -0,0 +1,9 @@
+UPDATE settings
+SET description = 'Approve paid orders without manual review'
+WHERE key = 'orders.auto_approve';
+
+DROP INDEX IF EXISTS idx_orders_email_status;
+
+DROP TRIGGER IF EXISTS update_approval_rules_updated_at ON approval_rules;
+DROP INDEX IF EXISTS idx_approval_rules_active;
+DROP TABLE IF EXISTS approval_rules;
@@ -0,0 +1,34 @@
+-- ============================================================================
+-- APPROVAL RULES - Auto-approval of paid orders
+-- ============================================================================
+-- An order is auto-approved only when auto-approval is enabled in settings
+-- and every active rule passes. Otherwise it goes to admin_review with the
+-- failing reasons recorded on the state transition.
+CREATE TABLE approval_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+
+    -- 'max_total', 'returning_customer', 'country_allowlist', 'no_risk_flags'
+    rule_type VARCHAR(50) NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+
+    is_active BOOLEAN DEFAULT true,
+    priority INT DEFAULT 0,
+
+    created_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_approval_rules_active ON approval_rules(is_active, priority);
+
+CREATE TRIGGER update_approval_rules_updated_at BEFORE UPDATE ON approval_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Customer-history rules match the email case-insensitively
+CREATE INDEX IF NOT EXISTS idx_orders_email_status ON orders(LOWER(customer_email), status);
+
+UPDATE settings
+SET description = 'Approve paid orders without manual review when every active approval rule passes'
+WHERE key = 'orders.auto_approve';
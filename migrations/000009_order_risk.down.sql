@@ -0,0 +1,15 @@
+DELETE FROM settings WHERE key IN (
+    'risk.velocity_window_hours',
+    'risk.velocity_max_orders',
+    'risk.max_failed_payments',
+    'risk.disposable_email_domains'
+);
+
+DROP INDEX IF EXISTS idx_payments_card_last4;
+DROP INDEX IF EXISTS idx_orders_customer_ip;
+DROP INDEX IF EXISTS idx_orders_risk_score;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS risk_evaluated_at,
+    DROP COLUMN IF EXISTS risk_reasons,
+    DROP COLUMN IF EXISTS risk_score;
@@ -0,0 +1,19 @@
+-- ============================================================================
+-- ORDER RISK SCORING
+-- ============================================================================
+-- Scored once per order after payment capture. risk_score is 0-100, higher is
+-- riskier; risk_reasons lists the signals that contributed to it.
+ALTER TABLE orders
+    ADD COLUMN risk_score INT,
+    ADD COLUMN risk_reasons JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN risk_evaluated_at TIMESTAMP;
+
+CREATE INDEX idx_orders_risk_score ON orders(risk_score DESC) WHERE risk_score IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_orders_customer_ip ON orders(customer_ip, created_at);
+CREATE INDEX IF NOT EXISTS idx_payments_card_last4 ON payments(card_last4, created_at) WHERE card_last4 IS NOT NULL;
+
+INSERT INTO settings (key, value, schema, description) VALUES
+('risk.velocity_window_hours', '24', '{"type": "integer", "minimum": 1, "maximum": 720}', 'Look-back window for order velocity checks'),
+('risk.velocity_max_orders', '3', '{"type": "integer", "minimum": 1, "maximum": 100}', 'Orders allowed per email, IP or card within the velocity window before it counts as a risk signal'),
+('risk.max_failed_payments', '2', '{"type": "integer", "minimum": 1, "maximum": 50}', 'Failed payment attempts by the same customer within the velocity window before it counts as a risk signal'),
+('risk.disposable_email_domains', '["mailinator.com", "guerrillamail.com", "10minutemail.com", "tempmail.com", "temp-mail.org", "yopmail.com", "trashmail.com", "sharklasers.com", "getnada.com", "dispostable.com"]', '{"type": "array"}', 'Email domains treated as disposable');
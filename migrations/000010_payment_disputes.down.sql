@@ -0,0 +1,11 @@
+ALTER TABLE download_tokens
+    DROP COLUMN IF EXISTS frozen_reason,
+    DROP COLUMN IF EXISTS frozen_at;
+
+DROP INDEX IF EXISTS idx_dispute_evidence_dispute;
+DROP TABLE IF EXISTS dispute_evidence;
+
+DROP TRIGGER IF EXISTS update_payment_disputes_updated_at ON payment_disputes;
+DROP INDEX IF EXISTS idx_payment_disputes_status;
+DROP INDEX IF EXISTS idx_payment_disputes_order;
+DROP TABLE IF EXISTS payment_disputes;
@@ -0,0 +1,56 @@
+-- ============================================================================
+-- PAYMENT DISPUTES - Chargebacks raised through the gateway
+-- ============================================================================
+CREATE TABLE payment_disputes (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments(id),
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+
+    gateway_dispute_id VARCHAR(255) NOT NULL UNIQUE,
+    reason_code VARCHAR(100),
+    reason_description TEXT,
+
+    amount_usd_cents BIGINT NOT NULL DEFAULT 0,
+    amount_deducted_usd_cents BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) DEFAULT 'USD',
+
+    -- 'chargeback', 'fraud', 'retrieval', 'pre_arbitration', 'arbitration'
+    phase VARCHAR(30),
+    -- 'open', 'under_review', 'won', 'lost', 'closed'
+    status VARCHAR(30) NOT NULL DEFAULT 'open',
+
+    respond_by TIMESTAMP,
+    resolved_at TIMESTAMP,
+
+    gateway_payload JSONB,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_disputes_order ON payment_disputes(order_id);
+CREATE INDEX idx_payment_disputes_status ON payment_disputes(status, respond_by);
+
+CREATE TRIGGER update_payment_disputes_updated_at BEFORE UPDATE ON payment_disputes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- DISPUTE EVIDENCE - Notes gathered by admins while contesting
+-- ============================================================================
+CREATE TABLE dispute_evidence (
+    id BIGSERIAL PRIMARY KEY,
+    dispute_id BIGINT NOT NULL REFERENCES payment_disputes(id) ON DELETE CASCADE,
+    notes TEXT NOT NULL,
+    document_url TEXT,
+    added_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dispute_evidence_dispute ON dispute_evidence(dispute_id, created_at);
+
+-- ============================================================================
+-- DOWNLOAD TOKEN FREEZE - Reversible suspension while a dispute is open
+-- ============================================================================
+ALTER TABLE download_tokens
+    ADD COLUMN frozen_at TIMESTAMP,
+    ADD COLUMN frozen_reason TEXT;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_reconciliation_items_run;
+DROP TABLE IF EXISTS reconciliation_items;
+
+DROP INDEX IF EXISTS idx_reconciliation_runs_period;
+DROP TABLE IF EXISTS reconciliation_runs;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS settled_at,
+    DROP COLUMN IF EXISTS settlement_id;
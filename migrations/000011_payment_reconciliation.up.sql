@@ -0,0 +1,60 @@
+-- ============================================================================
+-- PAYMENT SETTLEMENT DETAILS
+-- ============================================================================
+ALTER TABLE payments
+    ADD COLUMN settlement_id VARCHAR(255),
+    ADD COLUMN settled_at TIMESTAMP;
+
+-- ============================================================================
+-- RECONCILIATION RUNS - One per day checked against the gateway
+-- ============================================================================
+CREATE TABLE reconciliation_runs (
+    id BIGSERIAL PRIMARY KEY,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+
+    -- 'running', 'completed', 'failed'
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+
+    gateway_payment_count INT DEFAULT 0,
+    local_payment_count INT DEFAULT 0,
+    matched_count INT DEFAULT 0,
+    mismatch_count INT DEFAULT 0,
+    fees_updated_count INT DEFAULT 0,
+
+    gateway_fee_usd_cents BIGINT DEFAULT 0,
+    net_amount_usd_cents BIGINT DEFAULT 0,
+
+    error TEXT,
+    triggered_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_reconciliation_runs_period ON reconciliation_runs(period_start DESC);
+
+-- ============================================================================
+-- RECONCILIATION ITEMS - Mismatches found during a run
+-- ============================================================================
+CREATE TABLE reconciliation_items (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+    payment_id BIGINT REFERENCES payments(id) ON DELETE SET NULL,
+
+    gateway_payment_id VARCHAR(255),
+    gateway_order_id VARCHAR(255),
+
+    -- 'pending_locally', 'missing_in_gateway', 'unknown_payment', 'amount_mismatch'
+    issue VARCHAR(50) NOT NULL,
+
+    local_status VARCHAR(20),
+    gateway_status VARCHAR(20),
+    local_amount_usd_cents BIGINT,
+    gateway_amount_usd_cents BIGINT,
+    details TEXT,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reconciliation_items_run ON reconciliation_items(run_id);
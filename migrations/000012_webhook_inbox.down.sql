@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_webhooks_retry;
+
+ALTER TABLE payment_webhooks
+    DROP COLUMN IF EXISTS next_retry_at;
+
+DROP INDEX IF EXISTS idx_webhooks_webhook_id;
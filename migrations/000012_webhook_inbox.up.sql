@@ -0,0 +1,13 @@
+-- ============================================================================
+-- WEBHOOK INBOX - Dedupe deliveries and schedule retries
+-- ============================================================================
+
+-- Razorpay sends the same event ID (X-Razorpay-Event-Id) on every redelivery
+CREATE UNIQUE INDEX idx_webhooks_webhook_id ON payment_webhooks(webhook_id);
+
+-- When the sweeper may pick the webhook up again (NULL = not yet attempted)
+ALTER TABLE payment_webhooks
+    ADD COLUMN next_retry_at TIMESTAMP;
+
+CREATE INDEX idx_webhooks_retry ON payment_webhooks(next_retry_at)
+    WHERE processed = false;
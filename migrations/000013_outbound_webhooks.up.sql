@@ -0,0 +1,58 @@
+-- ============================================================================
+-- OUTBOUND WEBHOOK SUBSCRIPTIONS - Store events pushed to other systems
+-- ============================================================================
+CREATE TABLE webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    url VARCHAR(1000) NOT NULL,
+
+    -- Shared secret for the X-Merraki-Signature HMAC
+    secret VARCHAR(255) NOT NULL,
+
+    -- 'order.paid', 'order.approved', 'order.refunded',
+    -- 'newsletter.subscribed', 'contact.created'
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+
+    is_active BOOLEAN DEFAULT true,
+
+    created_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_subscriptions_events ON webhook_subscriptions USING GIN(event_types);
+
+CREATE TRIGGER update_webhook_subscriptions_updated_at BEFORE UPDATE ON webhook_subscriptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- OUTBOUND WEBHOOK DELIVERIES - One per event per subscription
+-- ============================================================================
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+
+    -- 'pending', 'delivered', 'failed'
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT DEFAULT 0,
+
+    -- Last attempt
+    response_status INT,
+    response_body TEXT,
+    last_error TEXT,
+    last_attempt_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_status ON webhook_deliveries(status);
+
+CREATE TRIGGER update_webhook_deliveries_updated_at BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_orders_customer_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_id;
+
+DROP INDEX IF EXISTS idx_customer_login_tokens_customer;
+DROP TABLE IF EXISTS customer_login_tokens;
+
+DROP TRIGGER IF EXISTS update_customers_updated_at ON customers;
+DROP INDEX IF EXISTS idx_customers_email;
+DROP TABLE IF EXISTS customers;
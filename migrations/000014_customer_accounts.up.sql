@@ -0,0 +1,44 @@
+-- ============================================================================
+-- CUSTOMERS - Passwordless buyer accounts (magic-link login)
+-- ============================================================================
+CREATE TABLE customers (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+
+    -- Set the first time a magic link is redeemed
+    email_verified_at TIMESTAMP,
+
+    last_login_at TIMESTAMP,
+    last_login_ip VARCHAR(45),
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_customers_email ON customers(LOWER(email));
+
+CREATE TRIGGER update_customers_updated_at BEFORE UPDATE ON customers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- CUSTOMER LOGIN TOKENS - Single-use magic links (only the hash is stored)
+-- ============================================================================
+CREATE TABLE customer_login_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    requested_ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customer_login_tokens_customer ON customer_login_tokens(customer_id);
+
+-- ============================================================================
+-- ORDERS - Link guest orders to the customer that claimed them
+-- ============================================================================
+ALTER TABLE orders ADD COLUMN customer_id BIGINT REFERENCES customers(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_orders_customer_id ON orders(customer_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_download_tokens_email_lower;
+DROP INDEX IF EXISTS idx_orders_customer_email_lower;
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ORDER LOOKUP - Case-insensitive email matching
+-- ============================================================================
+-- Lookup codes themselves live in Redis; these indexes back the
+-- LOWER(customer_email) queries used once an email has been verified.
+CREATE INDEX IF NOT EXISTS idx_orders_customer_email_lower ON orders(LOWER(customer_email));
+CREATE INDEX IF NOT EXISTS idx_download_tokens_email_lower ON download_tokens(LOWER(customer_email));
@@ -0,0 +1,11 @@
+DELETE FROM settings WHERE key IN (
+    'downloads.proxy_enabled',
+    'downloads.count_threshold_percent'
+);
+
+DROP INDEX IF EXISTS idx_downloads_token_uncounted;
+
+ALTER TABLE downloads
+    DROP COLUMN IF EXISTS counted,
+    DROP COLUMN IF EXISTS range_header,
+    DROP COLUMN IF EXISTS bytes_served;
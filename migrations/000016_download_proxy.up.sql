@@ -0,0 +1,15 @@
+-- ============================================================================
+-- DOWNLOADS - Byte accounting for proxied (streamed) transfers
+-- ============================================================================
+ALTER TABLE downloads
+    ADD COLUMN bytes_served BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN range_header VARCHAR(100),
+    -- Set once this transfer (alone or with earlier resumed ones) has been
+    -- charged against the token's max_downloads
+    ADD COLUMN counted BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_downloads_token_uncounted ON downloads(token_id) WHERE counted = false;
+
+INSERT INTO settings (key, value, schema, description) VALUES
+('downloads.proxy_enabled', 'false', '{"type": "boolean"}', 'Stream files through the API instead of redirecting to a signed storage URL'),
+('downloads.count_threshold_percent', '80', '{"type": "integer", "minimum": 1, "maximum": 100}', 'Share of the file that must be delivered before a proxied download counts against the limit');
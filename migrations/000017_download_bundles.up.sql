@@ -0,0 +1,15 @@
+-- ============================================================================
+-- DOWNLOAD BUNDLES - One ZIP link per multi-item order
+-- ============================================================================
+-- The bundle token only authorizes the order + email pair; each file is
+-- still checked and counted against its own download_tokens row.
+CREATE TABLE download_bundle_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    order_id BIGINT NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    customer_email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    download_count INT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
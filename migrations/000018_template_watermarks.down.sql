@@ -0,0 +1,7 @@
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS watermarked_at,
+    DROP COLUMN IF EXISTS watermark_source_url,
+    DROP COLUMN IF EXISTS watermarked_file_url;
+
+ALTER TABLE templates
+    DROP COLUMN IF EXISTS watermark_enabled;
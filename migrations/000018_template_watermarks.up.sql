@@ -0,0 +1,14 @@
+-- ============================================================================
+-- TEMPLATES - Optional per-buyer watermarking of deliverables
+-- ============================================================================
+ALTER TABLE templates
+    ADD COLUMN watermark_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- ============================================================================
+-- ORDER ITEMS - Cached watermarked copy of the deliverable
+-- ============================================================================
+ALTER TABLE order_items
+    ADD COLUMN watermarked_file_url TEXT,
+    -- Template file the cached copy was stamped from; a new upload invalidates it
+    ADD COLUMN watermark_source_url TEXT,
+    ADD COLUMN watermarked_at TIMESTAMP;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS licenses;
+
+ALTER TABLE templates
+    DROP COLUMN IF EXISTS license_seats,
+    DROP COLUMN IF EXISTS license_tier;
@@ -0,0 +1,36 @@
+-- ============================================================================
+-- TEMPLATES - License tier sold with each template
+-- ============================================================================
+ALTER TABLE templates
+    ADD COLUMN license_tier VARCHAR(20) NOT NULL DEFAULT 'personal'
+        CHECK (license_tier IN ('personal', 'commercial', 'team')),
+    ADD COLUMN license_seats INT NOT NULL DEFAULT 1 CHECK (license_seats > 0);
+
+-- ============================================================================
+-- LICENSES - One signed license key per order item
+-- ============================================================================
+CREATE TABLE licenses (
+    id BIGSERIAL PRIMARY KEY,
+    -- Random serial embedded in the signed key; used to look the license up
+    serial VARCHAR(32) NOT NULL UNIQUE,
+    license_key TEXT NOT NULL,
+
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id BIGINT NOT NULL UNIQUE REFERENCES order_items(id) ON DELETE CASCADE,
+    template_id BIGINT NOT NULL REFERENCES templates(id),
+    customer_email VARCHAR(255) NOT NULL,
+    customer_name VARCHAR(255) NOT NULL,
+
+    tier VARCHAR(20) NOT NULL,
+    seats INT NOT NULL DEFAULT 1,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'revoked')),
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoke_reason TEXT,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_licenses_order ON licenses(order_id);
+CREATE INDEX idx_licenses_email ON licenses(LOWER(customer_email));
@@ -0,0 +1,4 @@
+DELETE FROM settings WHERE key = 'downloads.version_policy';
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS notify_updates;
@@ -0,0 +1,19 @@
+-- ============================================================================
+-- TEMPLATE VERSIONS - Backfill history for templates uploaded before
+-- versions were tracked, so the first new version can be rolled back
+-- ============================================================================
+INSERT INTO template_versions (template_id, version_number, file_url, file_size_mb, is_current)
+SELECT t.id, COALESCE(t.current_version, '1.0'), t.file_url, t.file_size_mb, true
+FROM templates t
+WHERE t.file_url IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM template_versions v WHERE v.template_id = t.id)
+ON CONFLICT (template_id, version_number) DO NOTHING;
+
+-- ============================================================================
+-- ORDERS - Opt-in to "new version available" emails
+-- ============================================================================
+ALTER TABLE orders
+    ADD COLUMN notify_updates BOOLEAN NOT NULL DEFAULT false;
+
+INSERT INTO settings (key, value, schema, description) VALUES
+('downloads.version_policy', '"latest"', '{"type": "string", "enum": ["latest", "purchased"]}', 'Whether existing download links serve the latest template version or the version that was purchased');
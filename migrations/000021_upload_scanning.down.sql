@@ -0,0 +1,7 @@
+ALTER TABLE template_versions
+    DROP CONSTRAINT IF EXISTS chk_template_versions_quarantine_not_current,
+    DROP CONSTRAINT IF EXISTS chk_template_versions_scan_status,
+    DROP COLUMN IF EXISTS scan_signature,
+    DROP COLUMN IF EXISTS scan_status,
+    DROP COLUMN IF EXISTS content_type,
+    DROP COLUMN IF EXISTS checksum_sha256;
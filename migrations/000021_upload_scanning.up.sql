@@ -0,0 +1,14 @@
+-- ============================================================================
+-- TEMPLATE VERSIONS - Checksum, detected type and malware scan verdict of
+-- each uploaded file
+-- ============================================================================
+ALTER TABLE template_versions
+    ADD COLUMN checksum_sha256 CHAR(64),
+    ADD COLUMN content_type VARCHAR(100),
+    ADD COLUMN scan_status VARCHAR(20) NOT NULL DEFAULT 'unscanned',
+    ADD COLUMN scan_signature VARCHAR(255),
+    ADD CONSTRAINT chk_template_versions_scan_status
+        CHECK (scan_status IN ('clean', 'quarantined', 'unscanned')),
+    -- A quarantined file must never be served
+    ADD CONSTRAINT chk_template_versions_quarantine_not_current
+        CHECK (NOT (is_current AND scan_status = 'quarantined'));
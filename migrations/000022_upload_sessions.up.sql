@@ -0,0 +1,31 @@
+-- ============================================================================
+-- UPLOAD SESSIONS - Resumable, chunked admin uploads of template files
+-- ============================================================================
+CREATE TABLE upload_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+    admin_id BIGINT NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+
+    filename VARCHAR(255) NOT NULL,
+    total_bytes BIGINT NOT NULL CHECK (total_bytes > 0),
+    received_bytes BIGINT NOT NULL DEFAULT 0 CHECK (received_bytes <= total_bytes),
+
+    -- Applied to the template version created on finalize
+    version_number VARCHAR(20) NOT NULL,
+    changelog TEXT,
+    notify_buyers BOOLEAN NOT NULL DEFAULT false,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'uploading'
+        CHECK (status IN ('uploading', 'completed', 'failed')),
+    failure_reason TEXT,
+    version_id BIGINT REFERENCES template_versions(id) ON DELETE SET NULL,
+
+    -- Set while one request writes a chunk or finalizes, so concurrent
+    -- requests for the same session are refused instead of interleaved
+    locked_until TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_upload_sessions_expires ON upload_sessions(expires_at);
@@ -0,0 +1,11 @@
+ALTER TABLE blog_posts
+    DROP COLUMN IF EXISTS featured_image_renditions,
+    DROP COLUMN IF EXISTS featured_image_blurhash,
+    DROP COLUMN IF EXISTS featured_image_height,
+    DROP COLUMN IF EXISTS featured_image_width;
+
+ALTER TABLE template_images
+    DROP COLUMN IF EXISTS renditions,
+    DROP COLUMN IF EXISTS blurhash,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width;
@@ -0,0 +1,16 @@
+-- ============================================================================
+-- IMAGE RENDITIONS - Dimensions, blurhash placeholder and resized copies of
+-- uploaded template gallery and blog featured images
+-- ============================================================================
+ALTER TABLE template_images
+    ADD COLUMN width INT,
+    ADD COLUMN height INT,
+    ADD COLUMN blurhash VARCHAR(100),
+    -- [{url, width, height, format, bytes, public_id}], narrowest first
+    ADD COLUMN renditions JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE blog_posts
+    ADD COLUMN featured_image_width INT,
+    ADD COLUMN featured_image_height INT,
+    ADD COLUMN featured_image_blurhash VARCHAR(100),
+    ADD COLUMN featured_image_renditions JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_template_images_preview;
+
+ALTER TABLE template_images
+    DROP COLUMN IF EXISTS is_preview;
@@ -0,0 +1,9 @@
+-- ============================================================================
+-- TEMPLATE PREVIEWS - Gallery images generated from a template's own file
+-- ============================================================================
+-- Preview images are replaced wholesale each time previews are regenerated;
+-- images uploaded by an admin are never touched.
+ALTER TABLE template_images
+    ADD COLUMN is_preview BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_template_images_preview ON template_images(template_id) WHERE is_preview = TRUE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_templates_rating;
+
+ALTER TABLE templates
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_average;
+
+DROP TABLE IF EXISTS template_reviews;
@@ -0,0 +1,45 @@
+-- ============================================================================
+-- TEMPLATE REVIEWS - Ratings from verified buyers, moderated before display
+-- ============================================================================
+CREATE TABLE template_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+
+    -- The approved purchase that entitles the buyer to review
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    order_item_id BIGINT NOT NULL REFERENCES order_items(id),
+    customer_id BIGINT REFERENCES customers(id) ON DELETE SET NULL,
+    customer_email VARCHAR(255) NOT NULL,
+    reviewer_name VARCHAR(255) NOT NULL,
+
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    title VARCHAR(150),
+    body TEXT NOT NULL,
+
+    -- 'pending', 'approved', 'rejected'
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    moderation_note TEXT,
+    moderated_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+    moderated_at TIMESTAMP,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- One review per buyer per template; resubmitting edits it
+    UNIQUE (template_id, customer_email)
+);
+
+CREATE INDEX idx_template_reviews_template ON template_reviews(template_id, status, created_at DESC);
+CREATE INDEX idx_template_reviews_status ON template_reviews(status, created_at);
+
+CREATE TRIGGER update_template_reviews_updated_at BEFORE UPDATE ON template_reviews
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- TEMPLATE RATING - Approved reviews only, kept in step by the application
+-- ============================================================================
+ALTER TABLE templates
+    ADD COLUMN rating_average NUMERIC(3, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_templates_rating ON templates(rating_average DESC, rating_count DESC);
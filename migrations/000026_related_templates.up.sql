@@ -0,0 +1,22 @@
+-- ============================================================================
+-- RELATED TEMPLATES - "You may also like", precomputed nightly
+-- ============================================================================
+-- Rebuilt wholesale by the refresh_related_templates job from shared tags,
+-- shared category and co-purchases in approved orders. Templates created
+-- since the last run have no rows and get a live fallback instead.
+CREATE TABLE template_relations (
+    template_id BIGINT NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+    related_template_id BIGINT NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+
+    score DOUBLE PRECISION NOT NULL,
+    co_purchases INT NOT NULL DEFAULT 0,
+    shared_tags INT NOT NULL DEFAULT 0,
+    same_category BOOLEAN NOT NULL DEFAULT FALSE,
+
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (template_id, related_template_id),
+    CHECK (template_id <> related_template_id)
+);
+
+CREATE INDEX idx_template_relations_score ON template_relations(template_id, score DESC);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_order_items_bundle;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS bundle_name,
+    DROP COLUMN IF EXISTS bundle_id;
+
+DROP TABLE IF EXISTS template_bundle_images;
+DROP TABLE IF EXISTS template_bundle_items;
+DROP TABLE IF EXISTS template_bundles;
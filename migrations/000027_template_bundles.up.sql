@@ -0,0 +1,63 @@
+-- ============================================================================
+-- TEMPLATE BUNDLES - Curated packs of templates sold at one price
+-- ============================================================================
+CREATE TABLE template_bundles (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL UNIQUE,
+    tagline VARCHAR(255),
+    description TEXT NOT NULL DEFAULT '',
+
+    price_usd_cents BIGINT NOT NULL CHECK (price_usd_cents >= 0),
+    sale_price_usd_cents BIGINT CHECK (sale_price_usd_cents >= 0),
+
+    -- 'draft', 'active', 'archived', as for templates
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    is_featured BOOLEAN NOT NULL DEFAULT FALSE,
+
+    meta_title VARCHAR(255),
+    meta_description TEXT,
+
+    published_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_template_bundles_status ON template_bundles(status, created_at DESC);
+
+CREATE TRIGGER update_template_bundles_updated_at BEFORE UPDATE ON template_bundles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE template_bundle_items (
+    bundle_id BIGINT NOT NULL REFERENCES template_bundles(id) ON DELETE CASCADE,
+    template_id BIGINT NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+    display_order INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (bundle_id, template_id)
+);
+
+CREATE INDEX idx_template_bundle_items_template ON template_bundle_items(template_id);
+
+CREATE TABLE template_bundle_images (
+    id BIGSERIAL PRIMARY KEY,
+    bundle_id BIGINT NOT NULL REFERENCES template_bundles(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    alt_text VARCHAR(255),
+    display_order INT NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    width INT,
+    height INT,
+    blurhash VARCHAR(100),
+    renditions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_template_bundle_images_bundle ON template_bundle_images(bundle_id, display_order);
+
+-- ============================================================================
+-- ORDER ITEMS - Templates bought as part of a bundle
+-- ============================================================================
+ALTER TABLE order_items
+    ADD COLUMN bundle_id BIGINT REFERENCES template_bundles(id) ON DELETE SET NULL,
+    ADD COLUMN bundle_name VARCHAR(255);
+
+CREATE INDEX idx_order_items_bundle ON order_items(bundle_id) WHERE bundle_id IS NOT NULL;
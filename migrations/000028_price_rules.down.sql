@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS template_price_history;
+
+ALTER TABLE templates
+    DROP COLUMN IF EXISTS sale_rule_id,
+    DROP COLUMN IF EXISTS sale_ends_at;
+
+DROP TABLE IF EXISTS template_price_rules;
@@ -0,0 +1,69 @@
+-- ============================================================================
+-- PRICE RULES - Scheduled sales on a template or a whole category
+-- ============================================================================
+CREATE TABLE template_price_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+
+    -- Exactly one target
+    template_id BIGINT REFERENCES templates(id) ON DELETE CASCADE,
+    category_id BIGINT REFERENCES categories(id) ON DELETE CASCADE,
+
+    -- Exactly one discount: a fixed sale price (template rules only) or a
+    -- percentage off each template's list price
+    sale_price_usd_cents BIGINT CHECK (sale_price_usd_cents >= 0),
+    discount_percent INT CHECK (discount_percent BETWEEN 1 AND 99),
+
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+
+    -- 'scheduled', 'active', 'ended', 'cancelled'
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+
+    created_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK ((template_id IS NULL) <> (category_id IS NULL)),
+    CHECK ((sale_price_usd_cents IS NULL) <> (discount_percent IS NULL)),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_template_price_rules_status ON template_price_rules(status, starts_at, ends_at);
+CREATE INDEX idx_template_price_rules_template ON template_price_rules(template_id) WHERE template_id IS NOT NULL;
+CREATE INDEX idx_template_price_rules_category ON template_price_rules(category_id) WHERE category_id IS NOT NULL;
+
+CREATE TRIGGER update_template_price_rules_updated_at BEFORE UPDATE ON template_price_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- The rule behind a template's current sale price, and when that sale ends.
+-- Both are NULL for sale prices set by hand.
+ALTER TABLE templates
+    ADD COLUMN sale_ends_at TIMESTAMP,
+    ADD COLUMN sale_rule_id BIGINT REFERENCES template_price_rules(id) ON DELETE SET NULL;
+
+-- ============================================================================
+-- PRICE HISTORY - One row per change of a template's list or sale price
+-- ============================================================================
+CREATE TABLE template_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+
+    price_usd_cents BIGINT NOT NULL,
+    sale_price_usd_cents BIGINT,
+    previous_price_usd_cents BIGINT,
+    previous_sale_price_usd_cents BIGINT,
+
+    -- 'initial', 'admin', 'price_rule'
+    source VARCHAR(20) NOT NULL,
+    price_rule_id BIGINT REFERENCES template_price_rules(id) ON DELETE SET NULL,
+    changed_by BIGINT REFERENCES admins(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_template_price_history_template ON template_price_history(template_id, created_at DESC);
+
+-- Start every existing template's history at its current price
+INSERT INTO template_price_history (template_id, price_usd_cents, sale_price_usd_cents, source)
+SELECT id, price_usd_cents, sale_price_usd_cents, 'initial' FROM templates;
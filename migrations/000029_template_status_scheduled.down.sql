@@ -0,0 +1,15 @@
+-- Postgres cannot drop a value from an enum, so the type is rebuilt
+-- without it. Any template still scheduled falls back to a draft.
+
+UPDATE templates SET status = 'draft' WHERE status = 'scheduled';
+
+ALTER TYPE template_status RENAME TO template_status_old;
+
+CREATE TYPE template_status AS ENUM ('draft', 'active', 'archived');
+
+ALTER TABLE templates ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE templates
+    ALTER COLUMN status TYPE template_status USING status::text::template_status;
+ALTER TABLE templates ALTER COLUMN status SET DEFAULT 'draft';
+
+DROP TYPE template_status_old;
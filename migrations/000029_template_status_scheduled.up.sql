@@ -0,0 +1,7 @@
+-- ============================================================================
+-- TEMPLATE STATUS - 'scheduled' for templates waiting to be published
+-- ============================================================================
+-- On its own because a value added with ADD VALUE cannot be used in the
+-- transaction that adds it; 000030 indexes on it.
+
+ALTER TYPE template_status ADD VALUE IF NOT EXISTS 'scheduled';
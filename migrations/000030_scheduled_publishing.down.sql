@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_blog_posts_scheduled;
+DROP INDEX IF EXISTS idx_templates_scheduled;
+
+-- Scheduled items fall back to drafts
+UPDATE blog_posts SET status = 'draft' WHERE status = 'scheduled';
+UPDATE templates SET status = 'draft' WHERE status = 'scheduled';
+
+ALTER TABLE blog_posts DROP COLUMN IF EXISTS announce_on_publish;
+ALTER TABLE templates DROP COLUMN IF EXISTS announce_on_publish;
@@ -0,0 +1,14 @@
+-- ============================================================================
+-- SCHEDULED PUBLISHING - Templates and blog posts that go live at published_at
+-- ============================================================================
+-- A 'scheduled' item stays hidden until the worker flips it to 'active'
+-- (templates) or 'published' (blog posts) once published_at has passed.
+
+ALTER TABLE templates
+    ADD COLUMN announce_on_publish BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE blog_posts
+    ADD COLUMN announce_on_publish BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_templates_scheduled ON templates(published_at) WHERE status = 'scheduled';
+CREATE INDEX idx_blog_posts_scheduled ON blog_posts(published_at) WHERE status = 'scheduled';
@@ -0,0 +1,2 @@
+ALTER TABLE payment_webhooks
+    DROP COLUMN IF EXISTS processing_started_at;
@@ -0,0 +1,8 @@
+-- ============================================================================
+-- WEBHOOK CLAIMS - Keep sweepers on different replicas off the same webhook
+-- ============================================================================
+
+-- Set when a sweeper claims the webhook, cleared once the attempt is
+-- recorded. A claim older than the lease is treated as abandoned.
+ALTER TABLE payment_webhooks
+    ADD COLUMN processing_started_at TIMESTAMP;
@@ -0,0 +1,3 @@
+ALTER TABLE payment_disputes ALTER COLUMN currency SET DEFAULT 'USD';
+ALTER TABLE payment_disputes RENAME COLUMN amount_deducted_minor TO amount_deducted_usd_cents;
+ALTER TABLE payment_disputes RENAME COLUMN amount_minor TO amount_usd_cents;
@@ -0,0 +1,9 @@
+-- ============================================================================
+-- PAYMENT DISPUTES - Amounts are in the dispute's own currency, not USD
+-- ============================================================================
+
+-- Razorpay reports dispute amounts in the smallest unit of the dispute's
+-- currency (usually INR paise), so the columns are named for that
+ALTER TABLE payment_disputes RENAME COLUMN amount_usd_cents TO amount_minor;
+ALTER TABLE payment_disputes RENAME COLUMN amount_deducted_usd_cents TO amount_deducted_minor;
+ALTER TABLE payment_disputes ALTER COLUMN currency DROP DEFAULT;